@@ -4,6 +4,9 @@ package main
 
 import (
 	"os"
+	// The timezone database, for the vault timezone setting on machines
+	// without one installed.
+	_ "time/tzdata"
 
 	"github.com/Sanmoo/my-tasks2/internal/cli"
 )
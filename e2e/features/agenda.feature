@@ -0,0 +1,61 @@
+Feature: Agenda
+
+  mt agenda shows the in_progress Issues, then the deferrals ending,
+  deadlines due and recurring spawns over the next --days days, grouped
+  by day. The events,
+  the window and the ordering are pure logic covered at Seam 2
+  (internal/agenda); these scenarios cover the wiring against a
+  temporary Vault, with the times relative to now.
+
+  Background:
+    When I run `mt init --prefix pkm <vault>`
+    Then the exit code is 0
+
+  Scenario: agenda lists in-progress Issues and the coming events by day
+    When I run `mt create --vault <vault> "write report" --status in_progress`
+    Then the exit code is 0
+    When I run `mt create --vault <vault> "pay the rent" --deadline +2d`
+    Then the exit code is 0
+    When I run `mt create --vault <vault> "call the bank" --defer +1d`
+    Then the exit code is 0
+    When I run `mt create --vault <vault> "far away" --deadline +30d`
+    Then the exit code is 0
+    When I run `mt agenda --vault <vault>`
+    Then the exit code is 0
+    And stdout matches "^In progress:\n  ◐ pkm-[a-z0-9]+  write report\n"
+    And stdout matches "\n[A-Z][a-z]{2} [0-9]{2}-[0-9]{2}:\n  [0-9]{2}:[0-9]{2} defer     ○ pkm-[a-z0-9]+  call the bank\n"
+    And stdout matches "  [0-9]{2}:[0-9]{2} deadline  ○ pkm-[a-z0-9]+  pay the rent\n"
+    And stdout does not contain "far away"
+    When I run `mt agenda --vault <vault> --days 31`
+    Then the exit code is 0
+    And stdout contains "far away"
+
+  Scenario: agenda shows the next occurrence of a recurring Issue
+    When I run `mt create --vault <vault> "water the plants" --deadline +1d --recur 2d`
+    Then the exit code is 0
+    When I run `mt agenda --vault <vault>`
+    Then the exit code is 0
+    And stdout matches "  [0-9]{2}:[0-9]{2} deadline  ○ pkm-[a-z0-9]+  water the plants\n"
+    And stdout matches "  [0-9]{2}:[0-9]{2} spawn     ○ pkm-[a-z0-9]+  water the plants\n"
+
+  Scenario: an empty agenda prints nothing
+    When I run `mt agenda --vault <vault>`
+    Then the exit code is 0
+    And stdout is empty
+
+  Scenario: --all-vaults merges every bookmark
+    When I run `mt bookmark add pkm <vault>`
+    Then the exit code is 0
+    When I run `mt create @pkm "pay the rent" --deadline +1d`
+    Then the exit code is 0
+    When I run `mt agenda --all-vaults`
+    Then the exit code is 0
+    And stdout contains "pay the rent"
+
+  Scenario: agenda rejects a non-positive --days and a vault with --all-vaults
+    When I run `mt agenda --vault <vault> --days 0`
+    Then the exit code is 2
+    And stderr contains "--days must be positive"
+    When I run `mt agenda --all-vaults --vault <vault>`
+    Then the exit code is 2
+    And stderr contains "--all-vaults takes no @bookmark or --vault"
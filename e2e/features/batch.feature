@@ -0,0 +1,205 @@
+Feature: Batch operations on many Issues
+
+  done, status, defer, undefer, dep add/rm, label add/rm and comment
+  take several Issues at once: a list of IDs (or handles), - for IDs read
+  from stdin (one per line, as printed by mt list --format ids), or a
+  --where query.
+  Every batch is one plan: all targets are validated first and any
+  failure writes nothing. A batch prints a summary line, naming five
+  Issues at most; more than five Issues need a confirmation (or --yes).
+  The query language is pure logic covered at Seam 2 (internal/query); these scenarios cover the
+  process.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      status: [open, in_progress, done]
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: buy bread
+      status: open
+      labels: [errands]
+      created_at: 2026-01-01T10:00
+      rank: 1
+      ---
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: buy milk
+      status: open
+      labels: [errands]
+      created_at: 2026-01-01T10:00
+      rank: 2
+      ---
+      """
+    And the file "<vault>/issues/pkm-003.md" is written with:
+      """
+      ---
+      title: post office
+      status: open
+      labels: [errands]
+      created_at: 2026-01-01T10:00
+      rank: 3
+      ---
+      """
+    And the file "<vault>/issues/pkm-004.md" is written with:
+      """
+      ---
+      title: write report
+      status: open
+      labels: [work]
+      created_at: 2026-01-01T10:00
+      rank: 4
+      ---
+      """
+    And the file "<vault>/issues/pkm-005.md" is written with:
+      """
+      ---
+      title: review PR
+      status: open
+      labels: [work]
+      created_at: 2026-01-01T10:00
+      rank: 5
+      ---
+      """
+    And the file "<vault>/issues/pkm-006.md" is written with:
+      """
+      ---
+      title: call mom
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 6
+      ---
+      """
+
+  Scenario: done closes several Issues and prints a summary
+    When I run `mt done --vault <vault> pkm-001 pkm-002`
+    Then the exit code is 0
+    And stdout contains "done: 2 issues (pkm-001, pkm-002)"
+    And stdout contains "pkm-001 is now done: buy bread"
+    And stdout contains "pkm-002 is now done: buy milk"
+    And the file "<vault>/issues/pkm-001.md" contains "status: done"
+    And the file "<vault>/issues/pkm-002.md" contains "status: done"
+
+  Scenario: a single ID keeps the single-Issue output
+    When I run `mt done --vault <vault> pkm-001`
+    Then the exit code is 0
+    And stdout contains "pkm-001 is now done: buy bread"
+    And stdout does not contain "done: 1 issues"
+
+  Scenario: an invalid target aborts the whole batch
+    When I run `mt done --vault <vault> pkm-001 pkm-404`
+    Then the exit code is 1
+    And stderr contains "issue pkm-404 not found"
+    And the file "<vault>/issues/pkm-001.md" contains "status: open"
+
+  Scenario: list --format ids prints only IDs
+    When I run `mt list --vault <vault> --format ids --label work`
+    Then the exit code is 0
+    And stdout matches "^pkm-004\npkm-005\n$"
+
+  Scenario: defer reads the target IDs from stdin
+    When I run `mt defer --vault <vault> - +1w` with stdin:
+      """
+      pkm-004
+      pkm-005
+      """
+    Then the exit code is 0
+    And stdout contains "pkm-004 deferred until"
+    And stdout contains "pkm-005 deferred until"
+    And the file "<vault>/issues/pkm-004.md" contains "deferred_until:"
+    And the file "<vault>/issues/pkm-005.md" contains "deferred_until:"
+    And the file "<vault>/issues/pkm-006.md" does not contain "deferred_until:"
+
+  Scenario: defer of several IDs takes a two-word absolute time
+    When I run `mt defer --vault <vault> pkm-001 pkm-002 26-08-20 08:00`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "deferred_until: 2026-08-20T08:00"
+    And the file "<vault>/issues/pkm-002.md" contains "deferred_until: 2026-08-20T08:00"
+
+  Scenario: --where selects the targets by query
+    When I run `mt status --vault <vault> --where 'labels=errands rank>=2' in_progress`
+    Then the exit code is 0
+    And stdout contains "status: 2 issues (pkm-002, pkm-003)"
+    And the file "<vault>/issues/pkm-001.md" contains "status: open"
+    And the file "<vault>/issues/pkm-002.md" contains "status: in_progress"
+    And the file "<vault>/issues/pkm-003.md" contains "status: in_progress"
+
+  Scenario: list --where narrows the listing
+    When I run `mt list --vault <vault> --where 'title~buy'`
+    Then the exit code is 0
+    And stdout contains "pkm-001  buy bread"
+    And stdout contains "pkm-002  buy milk"
+    And stdout does not contain "pkm-003"
+
+  Scenario: a malformed query is a usage error
+    When I run `mt done --vault <vault> --where 'colour=red'`
+    Then the exit code is 2
+    And stderr contains "unknown field"
+
+  Scenario: --where with explicit IDs is a usage error
+    When I run `mt done --vault <vault> --where status=open pkm-001`
+    Then the exit code is 2
+    And stderr contains "do not also pass issue IDs"
+
+  Scenario: a query that matches nothing is a user error
+    When I run `mt done --vault <vault> --where status=in_progress`
+    Then the exit code is 1
+    And stderr contains "no issues match"
+
+  Scenario: a batch above the threshold asks for confirmation
+    When I run `mt done --vault <vault> --where status=open` with stdin:
+      """
+      n
+      """
+    Then the exit code is 1
+    And stderr contains "Apply done to 6 issues? [y/N]"
+    And stderr contains "done aborted: no issues changed"
+    And the file "<vault>/issues/pkm-006.md" contains "status: open"
+    When I run `mt done --vault <vault> --where status=open` with stdin:
+      """
+      y
+      """
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-006.md" contains "status: done"
+
+  Scenario: --yes applies a large batch read from stdin without asking
+    When I run `mt comment --vault <vault> --yes - batch note` with stdin:
+      """
+      pkm-001
+      pkm-002
+      pkm-003
+      pkm-004
+      pkm-005
+      pkm-006
+      """
+    Then the exit code is 0
+    And stdout contains "comment: 6 issues (pkm-001, pkm-002, pkm-003, pkm-004, pkm-005 … and 1 more)"
+    And the file "<vault>/issues/pkm-001.md" contains "batch note"
+    And the file "<vault>/issues/pkm-006.md" contains "batch note"
+
+  Scenario: comment takes the leading existing IDs as targets
+    When I run `mt comment --vault <vault> pkm-001 pkm-002 after pkm-003`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "after pkm-003"
+    And the file "<vault>/issues/pkm-002.md" contains "after pkm-003"
+    And the file "<vault>/issues/pkm-003.md" does not contain "after pkm-003"
+    When I run `mt comment --vault <vault> pkm-001 pkm-003`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "pkm-003"
+    And the file "<vault>/issues/pkm-003.md" does not contain "pkm-003"
+
+  Scenario: dep add blocks several Issues by one blocker
+    When I run `mt dep add --vault <vault> pkm-002 pkm-003 pkm-001`
+    Then the exit code is 0
+    And stdout contains "pkm-002 is now blocked by pkm-001"
+    And stdout contains "pkm-003 is now blocked by pkm-001"
+    When I run `mt dep add --vault <vault> pkm-004 pkm-001 pkm-001`
+    Then the exit code is 1
+    And stderr contains "issue pkm-001 cannot block itself"
+    And the file "<vault>/issues/pkm-004.md" does not contain "blocked_by"
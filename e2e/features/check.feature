@@ -291,3 +291,157 @@ Feature: Check vault
     And the file "<vault>/issues/pkm-001.md" does not contain "rank: 5"
     And the file "<vault>/issues/pkm-002.md" does not contain "rank: 9"
     And the file "<vault>/issues/pkm-003.md" does not contain "rank:"
+
+  Scenario: check reports every finding in one run, grouped by Issue
+    Given the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: blocked
+      labels: []
+      created_at: 2026-01-01
+      rank: 2
+      blocked_by: [pkm-404]
+      ---
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: second
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      id: pkm-002
+      rank: 2
+      ---
+      """
+    When I run `mt check --vault <vault>`
+    Then the exit code is 1
+    And stderr matches "pkm-001:\n  error: status .blocked. for issue pkm-001[^\n]*\n  error: invalid datetime for issue pkm-001: created_at[^\n]*\(--fix\)\n  error: blocked_by of issue pkm-001 references unknown issue pkm-404 \(--fix\)\npkm-002:\n  error: [^\n]*unknown field .id.\nvault:\n  error: duplicate rank: 2 \(pkm-001, pkm-002\) \(--fix\)\n  warning: rank gap: 1 \(--fix\)\n"
+    And stderr contains "check found 5 errors and 1 warning"
+    And stdout does not contain "OK"
+
+  Scenario: check --format json lists the findings for tools
+    Given the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 3
+      deadline: tomorrow
+      ---
+      """
+    When I run `mt check --vault <vault> --format json`
+    Then the exit code is 1
+    And stdout matches "\{\n      .id.: .pkm-001.,\n      .field.: .deadline.,\n      .rule.: .invalid-datetime.,\n      .severity.: .error.,\n      .message.: .invalid datetime for issue pkm-001: deadline=[^\n]*,\n      .fixable.: false\n    \}"
+    And stdout matches ".rule.: .rank-gap.,\n      .severity.: .warning."
+    And stdout matches ".errors.: 1,\n  .warnings.: 1\n\}"
+
+  Scenario: a clean vault is an empty JSON report
+    When I run `mt check --vault <vault> --format json`
+    Then the exit code is 0
+    And stdout matches "^\{\n  .findings.: \[\],\n  .errors.: 0,\n  .warnings.: 0\n\}\n$"
+
+  Scenario: check rejects an unknown format
+    When I run `mt check --vault <vault> --format yaml`
+    Then the exit code is 2
+    And stderr contains "unknown check format"
+
+  Scenario: --fix leaves the vault alone while other errors remain
+    Given the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 5
+      deadline: tomorrow
+      ---
+      """
+    When I run `mt check --fix --vault <vault>`
+    Then the exit code is 1
+    And stderr contains "Not fixing: correct the other errors first"
+    And the file "<vault>/issues/pkm-001.md" contains "rank: 5"
+
+  Scenario: --fix --dry-run previews every repair and writes nothing
+    Given the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: open
+      labels: [casa, casa]
+      created_at: 2026-01-01
+      rank: 3
+      deadline:
+      blocked_by: [pkm-404]
+      ---
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      status: done
+      title: second
+      labels: []
+      created_at: 2026-01-01T10:00:00
+      ---
+      """
+    When I run `mt check --fix --dry-run --vault <vault>`
+    Then the exit code is 1
+    And stdout matches "^pkm-001: deadline: omit empty field\npkm-001: created_at: 2026-01-01 → 2026-01-01T00:00\npkm-001: blocked_by: drop unknown pkm-404\npkm-001: labels: drop duplicate casa\npkm-001: rank: 3 → 1\npkm-002: reorder fields canonically\npkm-002: created_at: 2026-01-01T10:00:00 → 2026-01-01T10:00\npkm-002: completed_at: set to [0-9T:-]+\nWould fix 2 Issues \(dry run: nothing written\)\n$"
+    And the file "<vault>/issues/pkm-001.md" contains "blocked_by: [pkm-404]"
+    And the file "<vault>/issues/pkm-002.md" does not contain "completed_at"
+
+  Scenario: --fix repairs datetimes, blockers, labels and field order
+    Given the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: open
+      labels: [casa, casa]
+      created_at: 2026-01-01
+      deadline:
+      blocked_by: [pkm-002, pkm-404]
+      ---
+
+      ## Description
+      kept body
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      status: done
+      title: second
+      labels: []
+      created_at: 2026-01-01T10:00
+      ---
+      """
+    When I run `mt check --fix --vault <vault>`
+    Then the exit code is 0
+    And stdout contains "Fixed 2 Issues"
+    And stdout contains "OK"
+    And the file "<vault>/issues/pkm-001.md" matches "^---\ntitle: first\nstatus: open\nlabels: \[casa\]\ncreated_at: 2026-01-01T00:00\nblocked_by: \[pkm-002\]\n---\n"
+    And the file "<vault>/issues/pkm-001.md" contains "kept body"
+    And the file "<vault>/issues/pkm-002.md" matches "^---\ntitle: second\nstatus: done\nlabels: \[\]\ncreated_at: 2026-01-01T10:00\ncompleted_at: [0-9]{4}-"
+
+  Scenario: --fix --format json lists the repairs
+    Given the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: open
+      labels: []
+      created_at: 2026-01-01
+      ---
+      """
+    When I run `mt check --fix --dry-run --vault <vault> --format json`
+    Then the exit code is 1
+    And stdout matches ".fixes.: \[\n    \{\n      .id.: .pkm-001.,\n      .changes.: \[\n        .created_at: 2026-01-01 → 2026-01-01T00:00.\n      \]"
+    And stdout matches ".fixed.: 0,\n  .dry_run.: true"
+
+  Scenario: --dry-run needs --fix
+    When I run `mt check --dry-run --vault <vault>`
+    Then the exit code is 2
+    And stderr contains "--dry-run needs --fix"
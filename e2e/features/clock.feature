@@ -0,0 +1,68 @@
+Feature: Clock override
+
+  The global --at <when> flag, or the MT_NOW environment variable, runs
+  a command as if now were that time — any expression mt defer takes,
+  resolved against the real clock. Queries answer "what will be ready
+  Monday morning?" and writes stamp that time, so scenarios can pin the
+  clock. The expressions are pure logic covered at Seam 2
+  (internal/deferral); these scenarios cover the wiring.
+
+  Background:
+    When I run `mt init --prefix pkm <vault>`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: call the bank
+      status: open
+      labels: []
+      created_at: 2026-08-01T10:00
+      deferred_until: 2026-08-17T09:00
+      deadline: 2026-08-20T18:00
+      ---
+      """
+
+  Scenario: --at answers what will be ready then
+    When I run `mt ready --vault <vault> --at "26-08-16 12:00"`
+    Then the exit code is 0
+    And stdout is empty
+    When I run `mt ready --vault <vault> --at "26-08-17 09:00"`
+    Then the exit code is 0
+    And stdout contains "call the bank"
+    When I run `mt list --vault <vault> --at "26-08-16 12:00"`
+    Then the exit code is 0
+    And stdout contains "call the bank [defer 08-17 09:00]"
+
+  Scenario: --at previews the overdue view and undefer
+    When I run `mt overdue --vault <vault> --at "26-08-21 08:00"`
+    Then the exit code is 0
+    And stdout contains "call the bank [expirada 08-17]"
+    When I run `mt undefer --vault <vault> --at "26-08-16 12:00"`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "deferred_until: 2026-08-17T09:00"
+
+  Scenario: MT_NOW pins the clock of every command
+    Given the environment variable "MT_NOW" is "26-08-15 10:00"
+    When I run `mt create --vault <vault> "buy bread" --deadline +2d`
+    Then the exit code is 0
+    And I remember the issue ID
+    And the file "<vault>/issues/<id>.md" contains "created_at: 2026-08-15T10:00"
+    And the file "<vault>/issues/<id>.md" contains "deadline: 2026-08-17T10:00"
+    When I run `mt defer --vault <vault> <id> tomorrow`
+    Then the exit code is 0
+    And the file "<vault>/issues/<id>.md" contains "deferred_until: 2026-08-16T09:00"
+
+  Scenario: --at wins over MT_NOW
+    Given the environment variable "MT_NOW" is "26-08-15 10:00"
+    When I run `mt done --vault <vault> pkm-001 --at "26-08-18 11:30"`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "completed_at: 2026-08-18T11:30"
+
+  Scenario: a malformed --at or MT_NOW is a usage error
+    When I run `mt ready --vault <vault> --at someday`
+    Then the exit code is 2
+    And stderr contains "--at: invalid time"
+    Given the environment variable "MT_NOW" is "someday"
+    When I run `mt ready --vault <vault>`
+    Then the exit code is 2
+    And stderr contains "MT_NOW: invalid time"
@@ -64,3 +64,199 @@ Feature: Comment
     When I run `mt comment --vault <vault> pkm-0001`
     Then the exit code is 2
     And stderr contains "comment needs"
+
+  Scenario: comments lists anchors and threads replies
+    Given the file "<vault>/issues/pkm-0001.md" is written with:
+      """
+      ---
+      title: t
+      status: open
+      labels: []
+      created_at: 2026-08-15T09:30
+      ---
+
+      ## Description
+      ## Notes
+      ## Comments
+      ### 2026-08-16T14:05
+      Comprei metade da lista.
+      <!-- comment: 4f2b9c1a -->
+      ### 2026-08-16T14:30
+      segunda
+      linha
+      <!-- comment: 11111111 -->
+      """
+    When I run `mt comment --vault <vault> --reply-to 4f2b9c1a pkm-0001 "Achei o resto."`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-0001.md" matches "### [0-9T:-]+ \(reply to 4f2b9c1a\)\nAchei o resto\.\n<!-- comment: [0-9a-f]{8} -->"
+    When I run `mt comments --vault <vault> pkm-0001`
+    Then the exit code is 0
+    And stdout matches "^4f2b9c1a  2026-08-16T14:05  Comprei metade da lista\.\n  [0-9a-f]{8}  [0-9T:-]+  Achei o resto\.\n11111111  2026-08-16T14:30  segunda\n {28}linha\n$"
+
+  Scenario: a reply to an unknown anchor changes nothing
+    When I run `mt create --vault <vault> t`
+    Then the exit code is 0
+    And I remember the issue ID
+    When I run `mt comment --vault <vault> --reply-to deadbeef <id> "oi"`
+    Then the exit code is 1
+    And stderr contains "comment deadbeef not found"
+    And the file "<vault>/issues/<id>.md" does not contain "oi"
+
+  Scenario: comment edit rewrites one comment in $EDITOR
+    Given the file "<vault>/issues/pkm-0001.md" is written with:
+      """
+      ---
+      title: t
+      status: open
+      labels: []
+      created_at: 2026-08-15T09:30
+      ---
+
+      ## Description
+      ## Notes
+      ## Comments
+      ### 2026-08-16T14:05
+      texto antigo
+      <!-- comment: 4f2b9c1a -->
+      ### 2026-08-16T14:30
+      outro
+      <!-- comment: 11111111 -->
+      """
+    And the fake editor writes
+      """
+      texto novo
+      em duas linhas
+      """
+    When I run `mt comment edit --vault <vault> pkm-0001 4f2b9c1a`
+    Then the exit code is 0
+    And stdout contains "Updated comment 4f2b9c1a of pkm-0001"
+    And the file "<vault>/issues/pkm-0001.md" matches "### 2026-08-16T14:05\ntexto novo\nem duas linhas\n<!-- comment: 4f2b9c1a -->\n### 2026-08-16T14:30\noutro\n"
+
+  Scenario: comment edit rejects text that breaks the comment block
+    Given the file "<vault>/issues/pkm-0001.md" is written with:
+      """
+      ---
+      title: t
+      status: open
+      labels: []
+      created_at: 2026-08-15T09:30
+      ---
+
+      ## Comments
+      ### 2026-08-16T14:05
+      texto antigo
+      <!-- comment: 4f2b9c1a -->
+      """
+    And the fake editor writes
+      """
+      ### um heading
+      """
+    When I run `mt comment edit --vault <vault> pkm-0001 4f2b9c1a`
+    Then the exit code is 1
+    And stderr contains "must not contain a heading line"
+    And the file "<vault>/issues/pkm-0001.md" contains "texto antigo"
+
+  Scenario: comment redact keeps the anchor and the thread
+    Given the file "<vault>/issues/pkm-0001.md" is written with:
+      """
+      ---
+      title: t
+      status: open
+      labels: []
+      created_at: 2026-08-15T09:30
+      ---
+
+      ## Comments
+      ### 2026-08-16T14:05
+      senha: hunter2
+      <!-- comment: 4f2b9c1a -->
+      ### 2026-08-16T15:00 (reply to 4f2b9c1a)
+      apaga isso
+      <!-- comment: 9e0d7c2b -->
+      """
+    When I run `mt comment redact --vault <vault> pkm-0001 4f2b9c1a`
+    Then the exit code is 0
+    And stdout contains "Redacted comment 4f2b9c1a of pkm-0001"
+    And the file "<vault>/issues/pkm-0001.md" does not contain "hunter2"
+    And the file "<vault>/issues/pkm-0001.md" contains "<!-- comment: 4f2b9c1a -->"
+    When I run `mt comments --vault <vault> pkm-0001`
+    Then stdout contains "4f2b9c1a  2026-08-16T14:05  *[redacted]*"
+    And stdout contains "  9e0d7c2b  2026-08-16T15:00  apaga isso"
+    When I run `mt comment edit --vault <vault> pkm-0001 4f2b9c1a`
+    Then the exit code is 1
+    And stderr contains "comment 4f2b9c1a is redacted"
+
+  Scenario: comment subcommands need an issue and an anchor
+    When I run `mt comment redact --vault <vault> pkm-0001`
+    Then the exit code is 2
+    And stderr contains "comment redact needs an issue ID and a comment anchor"
+
+  Scenario: a lone - reads a multi-line comment from stdin
+    When I run `mt create --vault <vault> t`
+    Then the exit code is 0
+    And I remember the issue ID
+    When I run `mt comment --vault <vault> <id> -` with stdin:
+      """
+      Lista:
+      - pão
+      - leite
+
+      ```sh
+      make check
+      ```
+      """
+    Then the exit code is 0
+    And the file "<vault>/issues/<id>.md" matches "### [0-9T:-]+\nLista:\n- pão\n- leite\n\n```sh\nmake check\n```\n<!-- comment: [0-9a-f]{8} -->\n$"
+
+  Scenario: --edit composes the comment in $EDITOR, dropping # lines
+    When I run `mt create --vault <vault> t`
+    Then the exit code is 0
+    And I remember the issue ID
+    Given the fake editor writes
+      """
+      # Write the comment for the issue below.
+
+      primeiro parágrafo
+
+      segundo parágrafo
+      """
+    When I run `mt comment --vault <vault> --edit <id>`
+    Then the exit code is 0
+    And the file "<vault>/issues/<id>.md" matches "### [0-9T:-]+\nprimeiro parágrafo\n\nsegundo parágrafo\n<!-- comment: [0-9a-f]{8} -->\n$"
+
+  Scenario: an empty composed comment adds nothing
+    When I run `mt create --vault <vault> t`
+    Then the exit code is 0
+    And I remember the issue ID
+    Given the fake editor writes
+      """
+      # only the header
+      """
+    When I run `mt comment --vault <vault> --edit <id>`
+    Then the exit code is 1
+    And stderr contains "empty comment"
+    And the file "<vault>/issues/<id>.md" does not contain "<!-- comment: "
+
+  Scenario: text that would break the comment block is refused
+    When I run `mt create --vault <vault> t`
+    Then the exit code is 0
+    And I remember the issue ID
+    When I run `mt comment --vault <vault> <id> -` with stdin:
+      """
+      antes
+      ### não é um comentário
+      """
+    Then the exit code is 1
+    And stderr contains "must not contain a heading line"
+    When I run `mt comment --vault <vault> <id> "<!-- comment: 12345678 -->"`
+    Then the exit code is 1
+    And stderr contains "must not contain an anchor marker"
+    And the file "<vault>/issues/<id>.md" does not contain "<!-- comment: "
+
+  Scenario: stdin cannot carry both the IDs and the text
+    When I run `mt comment --vault <vault> - -` with stdin:
+      """
+      pkm-0001
+      """
+    Then the exit code is 2
+    And stderr contains "stdin can carry the issue IDs or the comment text, not both"
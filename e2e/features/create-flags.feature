@@ -0,0 +1,152 @@
+Feature: Rich create flags
+
+  mt create and mt q capture a complete Issue in one call: deadline,
+  deferral, queue placement, blockers, initial status and body. Every
+  flag is checked — times through the defer parser, the placement
+  through the quick-order plan, blockers against the vault — before the
+  file is written, so a failed create leaves the vault untouched.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      status: [open, in_progress, review, done]
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 1
+      ---
+      """
+
+  Scenario: one call sets dates, placement, blockers, status and description
+    Given the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: second
+      status: done
+      labels: []
+      created_at: 2026-01-01T10:00
+      ---
+      """
+    When I run `mt create --vault <vault> --deadline "26-09-01 18:00" --rank top --blocked-by pkm-002 --status in_progress --description "Levar a nota." pagar conta`
+    Then the exit code is 0
+    And I remember the issue ID
+    And the file "<vault>/issues/<id>.md" contains "status: in_progress"
+    And the file "<vault>/issues/<id>.md" contains "deadline: 2026-09-01T18:00"
+    And the file "<vault>/issues/<id>.md" contains "rank: 1"
+    And the file "<vault>/issues/<id>.md" contains "blocked_by: [pkm-002]"
+    And the file "<vault>/issues/<id>.md" matches "## Description\nLevar a nota.\n## Notes"
+    And the file "<vault>/issues/pkm-001.md" contains "rank: 2"
+
+  Scenario: q takes the same flags and prints only the ID
+    When I run `mt q --vault <vault> --defer +2d --rank 2 --label casa trocar lâmpada`
+    Then the exit code is 0
+    And stdout matches "^pkm-[a-z0-9]+\n$"
+    And I remember the issue ID
+    And the file "<vault>/issues/<id>.md" contains "labels: [casa]"
+    And the file "<vault>/issues/<id>.md" contains "deferred_until: "
+    And the file "<vault>/issues/<id>.md" contains "rank: 2"
+    And the file "<vault>/issues/pkm-001.md" contains "rank: 1"
+
+  Scenario: any status of the vault's list is accepted
+    When I run `mt create --vault <vault> --status review revisar`
+    Then the exit code is 0
+    And I remember the issue ID
+    And the file "<vault>/issues/<id>.md" contains "status: review"
+    And the file "<vault>/issues/<id>.md" does not contain "started_at"
+
+  Scenario: in_progress stamps started_at
+    When I run `mt create --vault <vault> --status in_progress agora`
+    Then the exit code is 0
+    And I remember the issue ID
+    And the file "<vault>/issues/<id>.md" contains "status: in_progress"
+    And the file "<vault>/issues/<id>.md" contains "started_at: "
+
+  Scenario: --body - reads the whole body from stdin
+    When I run `mt create --vault <vault> --body - from stdin` with stdin:
+      """
+      ## Description
+      veio do pipe
+      ## Notes
+      ## Comments
+      """
+    Then the exit code is 0
+    And I remember the issue ID
+    And the file "<vault>/issues/<id>.md" matches "---\n\n## Description\nveio do pipe\n## Notes"
+
+  Scenario: --edit opens the new Issue in the editor
+    Given the fake editor writes
+      """
+      ---
+      title: editada
+      status: open
+      labels: []
+      created_at: 2026-08-15T09:30
+      ---
+
+      ## Description
+      escrito no editor
+      ## Notes
+      ## Comments
+      """
+    When I run `mt create --vault <vault> --edit rascunho`
+    Then the exit code is 0
+    And I remember the issue ID
+    And the file "<vault>/issues/<id>.md" contains "escrito no editor"
+    When I run `mt undo --vault <vault>`
+    Then the exit code is 0
+    And the directory "<vault>/issues" contains 1 files
+
+  Scenario: flags override the template
+    Given the file "<vault>/templates/bug.md" is written with:
+      """
+      ---
+      labels: [bug]
+      deadline: +3d
+      rank: top
+      ---
+      """
+    When I run `mt create --vault <vault> --template bug --deadline "26-12-24 12:00" --rank bottom falha`
+    Then the exit code is 0
+    And I remember the issue ID
+    And the file "<vault>/issues/<id>.md" contains "deadline: 2026-12-24T12:00"
+    And the file "<vault>/issues/<id>.md" contains "rank: 2"
+    And the file "<vault>/issues/<id>.md" contains "labels: [bug]"
+
+  Scenario: a blocker that does not exist fails before writing
+    When I run `mt create --vault <vault> --blocked-by pkm-404 thing`
+    Then the exit code is 1
+    And stderr contains "--blocked-by: issue pkm-404 not found"
+    And the directory "<vault>/issues" contains 1 files
+
+  Scenario: a status outside the vault's list fails before writing
+    When I run `mt create --vault <vault> --status waiting thing`
+    Then the exit code is 1
+    And stderr contains "is not in the vault's status list"
+    And the directory "<vault>/issues" contains 1 files
+
+  Scenario: a queue position past the end fails before writing
+    When I run `mt create --vault <vault> --rank 5 thing`
+    Then the exit code is 1
+    And stderr contains "rank position must be between 1 and 2"
+    And the directory "<vault>/issues" contains 1 files
+
+  Scenario Outline: malformed flag values are usage errors
+    When I run `mt create --vault <vault> <flags> thing`
+    Then the exit code is 2
+    And stderr contains "<message>"
+    And the directory "<vault>/issues" contains 1 files
+
+    Examples:
+      | flags                                   | message                                  |
+      | --deadline someday                      | --deadline:                              |
+      | --defer +3x                             | --defer:                                 |
+      | --rank middle                           | --rank: rank must be top, bottom         |
+      | --description x --body -                | mutually exclusive                       |
+      | --defer +1d --status in_progress        | --defer keeps the Issue open             |
+      | --blocked-by a/b                        | invalid issue ID                         |
@@ -0,0 +1,106 @@
+Feature: Custom frontmatter fields
+
+  A vault declares its own frontmatter fields in mt.yaml, each with a
+  type. Issues keep them across rewrites, check validates them, show
+  displays them and --where filters on them. Parsing, rendering and type
+  checks are pure logic covered at Seam 2 (internal/issue, internal/check,
+  internal/query); these scenarios cover the wiring.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      fields:
+        - {name: energy, type: enum, values: [low, medium, high]}
+        - {name: estimate, type: int}
+        - {name: contexts, type: list}
+        - {name: client, type: id-ref}
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: call the client
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      energy: low
+      estimate: 2
+      contexts: [phone]
+      client: pkm-002
+      ---
+
+      ## Description
+      ## Notes
+      ## Comments
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: the client
+      status: open
+      labels: []
+      created_at: 2026-01-01T11:00
+      energy: high
+      ---
+      """
+
+  Scenario: check accepts the declared fields
+    When I run `mt check --vault <vault>`
+    Then the exit code is 0
+    And stdout contains "OK"
+
+  Scenario: show displays the custom fields
+    When I run `mt show --vault <vault> pkm-001`
+    Then the exit code is 0
+    And stdout matches "energy: low\nestimate: 2\ncontexts: phone\nclient: pkm-002\n"
+
+  Scenario: list --where filters on custom fields
+    When I run `mt list --vault <vault> --where "energy=low contexts=phone estimate<=3"`
+    Then the exit code is 0
+    And stdout contains "call the client"
+    And stdout does not contain "pkm-002"
+    When I run `mt list --vault <vault> --where "energy=high" --format ids`
+    Then the exit code is 0
+    And stdout matches "^pkm-002\n$"
+
+  Scenario: a rewrite keeps the custom fields in place
+    When I run `mt status --vault <vault> pkm-001 in_progress`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" matches "status: in_progress\nlabels: \[\]\ncreated_at: 2026-01-01T10:00\nenergy: low\nestimate: 2\ncontexts: \[phone\]\nclient: pkm-002\n---"
+
+  Scenario: check reports values that do not fit their type
+    Given the file "<vault>/issues/pkm-003.md" is written with:
+      """
+      ---
+      title: wrong
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      energy: extreme
+      estimate: lots
+      client: pkm-404
+      mood: happy
+      ---
+      """
+    When I run `mt check --vault <vault>`
+    Then the exit code is 1
+    And stderr matches "unknown field .mood."
+    And stderr matches "invalid enum for issue pkm-003: energy=.extreme. \(want one of low, medium, high\)"
+    And stderr matches "invalid int for issue pkm-003: estimate=.lots. \(want an integer\)"
+    And stderr contains "client of issue pkm-003 references unknown issue pkm-404"
+
+  Scenario: an unknown field query is a usage error
+    When I run `mt list --vault <vault> --where "mood=happy"`
+    Then the exit code is 2
+    And stderr contains "unknown field"
+
+  Scenario: an invalid declaration fails every command
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      fields:
+        - {name: deadline, type: datetime}
+      """
+    When I run `mt check --vault <vault>`
+    Then the exit code is 1
+    And stderr matches "custom field .deadline. is a built-in field"
@@ -1,8 +1,10 @@
 Feature: Defer issues
 
   mt defer <id> <when> sets deferred_until keeping the Issue open: it
-  accepts an absolute YY-MM-DD HH:MM (the hour is kept, not truncated)
-  and relative durations (+2d, +1w, +3h) computed from now. A deferred
+  accepts an absolute YY-MM-DD HH:MM (the hour is kept, not truncated),
+  times of day, named days in English and Portuguese (next friday 18:00,
+  amanhã) with the vault's default_time when no time is given, and
+  relative durations (+30m, +2d, +1w, +3bd, +2mo) computed from now. A deferred
   Issue stays visible in list, marked with a [defer ...] suffix, and
   becomes available on its own when now >= deferred_until; when the
   deferral is expired, mt undefer archives the
@@ -106,3 +108,51 @@ Feature: Defer issues
     When I run `mt defer --vault <vault> <id>`
     Then the exit code is 2
     And stderr contains "defer needs an issue ID and a time"
+
+  Scenario: defer takes a named day and a time of day as separate words
+    When I run `mt create --vault <vault> "ligar para o banco"`
+    Then the exit code is 0
+    And I remember the issue ID
+    When I run `mt defer --vault <vault> <id> next friday 18:00`
+    Then the exit code is 0
+    And stdout matches "<id> deferred until [0-9]{4}-[0-9]{2}-[0-9]{2}T18:00"
+    And the file "<vault>/issues/<id>.md" matches "deferred_until: [0-9]{4}-[0-9]{2}-[0-9]{2}T18:00"
+    When I run `mt defer --vault <vault> <id> amanhã 7:15`
+    Then the exit code is 0
+    And the file "<vault>/issues/<id>.md" matches "deferred_until: [0-9]{4}-[0-9]{2}-[0-9]{2}T07:15"
+
+  Scenario: a day without a time gets the vault's default_time
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      status: [open, in_progress, done]
+      default_time: "08:30"
+      """
+    When I run `mt create --vault <vault> "pagar boleto" --deadline "end of month"`
+    Then the exit code is 0
+    And I remember the issue ID
+    And the file "<vault>/issues/<id>.md" matches "deadline: [0-9]{4}-[0-9]{2}-[0-9]{2}T08:30"
+    When I run `mt defer --vault <vault> <id> segunda`
+    Then the exit code is 0
+    And the file "<vault>/issues/<id>.md" matches "deferred_until: [0-9]{4}-[0-9]{2}-[0-9]{2}T08:30"
+
+  Scenario: defer takes business days and months
+    When I run `mt create --vault <vault> t`
+    Then the exit code is 0
+    And I remember the issue ID
+    When I run `mt defer --vault <vault> <id> +3bd`
+    Then the exit code is 0
+    And stdout matches "<id> deferred until [0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}"
+    When I run `mt defer --vault <vault> <id> +2mo`
+    Then the exit code is 0
+    And stdout matches "<id> deferred until [0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}"
+
+  Scenario: a malformed default_time fails every command that reads the vault config
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      default_time: 9h
+      """
+    When I run `mt create --vault <vault> t`
+    Then the exit code is 1
+    And stderr contains "default_time"
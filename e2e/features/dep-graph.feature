@@ -0,0 +1,110 @@
+Feature: Dependency graph views
+
+  mt dep tree <id> shows an Issue's blockers and dependents,
+  transitively; mt dep impact ranks the Issues by how many others they
+  unblock; mt graph exports the vault's blocked_by graph for Graphviz or
+  Mermaid. The traversals and renderings are pure logic covered at Seam
+  2 (internal/graph); these scenarios cover the wiring.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: gather data
+      status: done
+      labels: []
+      created_at: 2026-01-01T09:00
+      ---
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: write the report
+      status: in_progress
+      labels: []
+      created_at: 2026-01-01T10:00
+      blocked_by: [pkm-001]
+      ---
+      """
+    And the file "<vault>/issues/pkm-003.md" is written with:
+      """
+      ---
+      title: send the report
+      status: open
+      labels: []
+      created_at: 2026-01-01T11:00
+      blocked_by: [pkm-002]
+      ---
+      """
+    And the file "<vault>/issues/pkm-004.md" is written with:
+      """
+      ---
+      title: archive the report
+      status: open
+      labels: []
+      created_at: 2026-01-01T12:00
+      blocked_by: [pkm-003]
+      ---
+      """
+
+  Scenario: dep tree shows blockers and dependents
+    When I run `mt dep tree --vault <vault> pkm-003`
+    Then the exit code is 0
+    And stdout contains "○ pkm-003  send the report"
+    And stdout matches "Blocked by:\n  ◐ pkm-002  write the report\n    ● pkm-001  gather data\n"
+    And stdout matches "Blocks:\n  ○ pkm-004  archive the report\n"
+    When I run `mt dep tree --vault <vault> pkm-001`
+    Then the exit code is 0
+    And stdout contains "Blocked by: none"
+    And stdout matches "Blocks:\n  ◐ pkm-002  write the report\n    ○ pkm-003  send the report\n      ○ pkm-004  archive the report\n"
+
+  Scenario: dep tree marks missing blockers and cycles
+    Given the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: write the report
+      status: in_progress
+      labels: []
+      created_at: 2026-01-01T10:00
+      blocked_by: [pkm-099, pkm-004]
+      ---
+      """
+    When I run `mt dep tree --vault <vault> pkm-002`
+    Then the exit code is 0
+    And stdout contains "  pkm-099  (missing)"
+    And stdout contains "◐ pkm-002  write the report (cycle)"
+
+  Scenario: dep tree needs an existing Issue
+    When I run `mt dep tree --vault <vault> pkm-404`
+    Then the exit code is 1
+    And stderr contains "issue pkm-404 not found"
+    When I run `mt dep tree --vault <vault>`
+    Then the exit code is 2
+
+  Scenario: dep impact ranks the Issues by what they unblock
+    When I run `mt dep impact --vault <vault>`
+    Then the exit code is 0
+    And stdout matches "◐ pkm-002  write the report  \(unblocks 2\)\n○ pkm-003  send the report  \(unblocks 1\)\n$"
+
+  Scenario: graph exports Graphviz DOT by default
+    When I run `mt graph --vault <vault>`
+    Then the exit code is 0
+    And stdout contains "digraph mt {"
+    And stdout contains "style=dashed"
+    And stdout matches ".pkm-002. -> .pkm-003.;"
+
+  Scenario: graph exports a Mermaid flowchart
+    When I run `mt graph --vault <vault> --format mermaid`
+    Then the exit code is 0
+    And stdout contains "flowchart LR"
+    And stdout contains "pkm_002 --> pkm_003"
+    And stdout contains "class pkm_001 done"
+
+  Scenario: graph rejects an unknown format
+    When I run `mt graph --vault <vault> --format png`
+    Then the exit code is 2
+    And stderr contains "unknown graph format"
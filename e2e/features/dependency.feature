@@ -193,7 +193,7 @@ Feature: Issue dependencies (blocked_by)
     When I run `mt dep add --vault <vault> pkm-001`
     Then the exit code is 2
     And stderr contains "dep add needs an issue ID and a blocker ID"
-    When I run `mt dep rm --vault <vault> pkm-001 pkm-002 extra`
+    When I run `mt dep rm --vault <vault> pkm-001`
     Then the exit code is 2
     And stderr contains "dep rm needs an issue ID and a blocker ID"
 
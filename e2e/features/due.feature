@@ -0,0 +1,113 @@
+Feature: Deadline warnings
+
+  warn_before in mt.yaml (and per Issue in its frontmatter) is the lead
+  time before a deadline during which the Issue is due soon: list marks
+  it [due MM-DD], overdue shows it in a third group, pick-next
+  --due-first prefers it, and mt due --within lists the deadlines of a
+  span. The rules are pure logic covered at Seam 2 (internal/list,
+  internal/deferral); these scenarios cover the wiring, with the
+  deadlines relative to now.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      warn_before: 3d
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: file taxes
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 1
+      deadline: 2000-01-01T00:00
+      ---
+      """
+
+  Scenario: list marks the Issues due soon
+    When I run `mt create --vault <vault> "pay the rent" --deadline +1d`
+    Then the exit code is 0
+    When I run `mt create --vault <vault> "renew passport" --deadline +10d`
+    Then the exit code is 0
+    When I run `mt list --vault <vault>`
+    Then the exit code is 0
+    And stdout matches "pay the rent \[due [0-9]{2}-[0-9]{2}\]"
+    And stdout does not contain "renew passport [due"
+
+  Scenario: an Issue's own warn_before overrides the vault's
+    When I run `mt create --vault <vault> "pay the rent" --deadline +2d --warn-before 1d`
+    Then the exit code is 0
+    And I remember the issue ID
+    And the file "<vault>/issues/<id>.md" contains "warn_before: 1d"
+    When I run `mt create --vault <vault> "renew passport" --deadline +10d --warn-before 2w`
+    Then the exit code is 0
+    When I run `mt list --vault <vault>`
+    Then the exit code is 0
+    And stdout does not contain "pay the rent [due"
+    And stdout contains "renew passport [due"
+    When I run `mt show --vault <vault> <id>`
+    Then the exit code is 0
+    And stdout contains "Warn before: 1d"
+    When I run `mt create --vault <vault> "x" --warn-before soon`
+    Then the exit code is 2
+    And stderr contains "--warn-before"
+
+  Scenario: overdue lists the Issues due soon after the passed deadlines
+    When I run `mt create --vault <vault> "pay the rent" --deadline +1d`
+    Then the exit code is 0
+    When I run `mt overdue --vault <vault>`
+    Then the exit code is 0
+    And stdout matches "file taxes \[deadline 01-01\]\n.*pay the rent \[due [0-9]{2}-[0-9]{2}\]\n$"
+
+  Scenario: due lists the deadlines within a span
+    When I run `mt create --vault <vault> "pay the rent" --deadline +1d`
+    Then the exit code is 0
+    When I run `mt create --vault <vault> "renew passport" --deadline +10d`
+    Then the exit code is 0
+    When I run `mt due --vault <vault>`
+    Then the exit code is 0
+    And stdout contains "file taxes [deadline 01-01]"
+    And stdout contains "pay the rent [due"
+    And stdout does not contain "renew passport"
+    When I run `mt due --vault <vault> --within 2w`
+    Then the exit code is 0
+    And stdout contains "renew passport [due"
+
+  Scenario: due rejects a malformed span
+    When I run `mt due --vault <vault> --within soon`
+    Then the exit code is 2
+    And stderr contains "invalid span"
+
+  Scenario: pick-next --due-first prefers the Issues due soon
+    Given the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: top of the queue
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 1
+      ---
+      """
+    When I run `mt create --vault <vault> "pay the rent" --deadline +1d --rank 2`
+    Then the exit code is 0
+    When I run `mt pick-next --vault <vault> --due-first`
+    Then the exit code is 0
+    And stdout contains "pay the rent"
+
+  Scenario: check flags a malformed warn_before
+    Given the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: renew passport
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      warn_before: soon
+      ---
+      """
+    When I run `mt check --vault <vault>`
+    Then the exit code is 1
+    And stderr contains "invalid span for issue pkm-002: warn_before="
@@ -0,0 +1,89 @@
+Feature: Static HTML export
+
+  mt export html <dir> writes a read-only HTML snapshot of the vault:
+  an index in Rank order with status and label facets, one page per
+  Issue and an overdue page. The pages (order, facets, metadata,
+  rendered bodies, permalinks) are pure logic covered at Seam 2
+  (internal/site); these scenarios cover the command and the files.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: open
+      labels: [casa]
+      created_at: 2026-01-01T10:00
+      rank: 1
+      ---
+
+      ## Description
+      Comprar **tinta**.
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: second
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      deadline: 2020-01-01T10:00
+      blocked_by: [pkm-001]
+      ---
+      """
+
+  Scenario: export writes the index, the facets, the Issue pages and the overdue page
+    When I run `mt --vault <vault> export html <base>/site`
+    Then the exit code is 0
+    And stdout contains "Exported 2 issues to"
+    And the file "<base>/site/index.html" matches "pkm-001[\s\S]*pkm-002"
+    And the file "<base>/site/index.html" matches "<a href=.labels/casa.html.>casa</a> \(1\)"
+    And the file "<base>/site/status/open.html" contains "pkm-002"
+    And the file "<base>/site/labels/casa.html" contains "pkm-001"
+    And the file "<base>/site/overdue.html" contains "issues/pkm-002.html"
+    And the file "<base>/site/issues/pkm-001.html" contains "<p>Comprar <strong>tinta</strong>.</p>"
+    And the file "<base>/site/issues/pkm-001.html" contains "<dt>Blocks</dt>"
+    And the file "<base>/site/issues/pkm-002.html" matches "<dt>Blocked by</dt><dd><a href=.pkm-001.html."
+
+  Scenario: comments get permalinks
+    When I run `mt --vault <vault> comment pkm-001 olá`
+    And I run `mt --vault <vault> export html <base>/site`
+    Then the exit code is 0
+    And the file "<base>/site/issues/pkm-001.html" matches "<h3 id=.comment-([0-9a-f]{8}).><a href=.#comment-[0-9a-f]{8}.>"
+    And the file "<base>/site/issues/pkm-001.html" does not contain "<!-- comment:"
+
+  Scenario: a new export removes the stale pages and keeps other files
+    When I run `mt --vault <vault> export html <base>/site`
+    And the file "<base>/site/notes.txt" is written with:
+      """
+      mine
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: open
+      labels: [nova]
+      created_at: 2026-01-01T10:00
+      rank: 1
+      ---
+      """
+    And I run `mt --vault <vault> export html <base>/site`
+    Then the exit code is 0
+    And the directory "<base>/site/labels" contains 1 files
+    And the file "<base>/site/labels/nova.html" contains "pkm-001"
+    And the file "<base>/site/notes.txt" contains "mine"
+
+  Scenario: the export directory cannot be the vault
+    When I run `mt --vault <vault> export html <vault>`
+    Then the exit code is 1
+    And stderr contains "cannot export into the vault itself"
+
+  Scenario: export html needs an output directory
+    When I run `mt --vault <vault> export html`
+    Then the exit code is 2
+    And stderr contains "export html needs an output directory"
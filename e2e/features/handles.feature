@@ -0,0 +1,102 @@
+Feature: Numeric handles from the last listing
+
+  mt list, bare mt and mt ready print a numeric handle before each line
+  and remember the listed ID order per vault, in the user cache dir.
+  Every id-taking command accepts the handle in place of the ID: after a
+  listing, `mt done 3` closes the third line. Mutating Issues keeps the
+  handles valid; creating or deleting an Issue makes them stale. The
+  persistence and resolution rules are pure logic covered at Seam 2
+  (internal/handle); these scenarios cover the process.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      status: [open, in_progress, done]
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 1
+      ---
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: second
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 2
+      ---
+      """
+    And the file "<vault>/issues/pkm-003.md" is written with:
+      """
+      ---
+      title: third
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 3
+      ---
+      """
+
+  Scenario: list prints a handle column in listing order
+    When I run `mt list --vault <vault>`
+    Then the exit code is 0
+    And stdout contains "1 ○ pkm-001  first"
+    And stdout contains "2 ○ pkm-002  second"
+    And stdout contains "3 ○ pkm-003  third"
+
+  Scenario: a handle resolves to the listed ID
+    When I run `mt list --vault <vault>`
+    Then the exit code is 0
+    When I run `mt done --vault <vault> 2`
+    Then the exit code is 0
+    And stdout contains "pkm-002 is now done: second"
+    When I run `mt done --vault <vault> 3`
+    Then the exit code is 0
+    And stdout contains "pkm-003 is now done: third"
+
+  Scenario: handles follow the filtered view they were printed from
+    When I run `mt status --vault <vault> pkm-002 in_progress`
+    Then the exit code is 0
+    When I run `mt --vault <vault>`
+    Then the exit code is 0
+    And stdout contains "1 ◐ pkm-002  second"
+    When I run `mt defer --vault <vault> 1 +2d`
+    Then the exit code is 0
+    And stdout contains "pkm-002 deferred until"
+
+  Scenario: handles work in both positions of dep add
+    When I run `mt ready --vault <vault>`
+    Then the exit code is 0
+    When I run `mt dep add --vault <vault> 3 1`
+    Then the exit code is 0
+    And stdout contains "pkm-003 is now blocked by pkm-001"
+
+  Scenario: creating an Issue makes the handles stale
+    When I run `mt list --vault <vault>`
+    Then the exit code is 0
+    When I run `mt create --vault <vault> fourth`
+    Then the exit code is 0
+    When I run `mt done --vault <vault> 1`
+    Then the exit code is 1
+    And stderr contains "handle 1 is stale"
+    And the file "<vault>/issues/pkm-001.md" contains "status: open"
+
+  Scenario: a handle beyond the listing is a user error
+    When I run `mt list --vault <vault>`
+    Then the exit code is 0
+    When I run `mt show --vault <vault> 4`
+    Then the exit code is 1
+    And stderr contains "handle 4 is not in the last listing"
+
+  Scenario: a handle without a previous listing is a user error
+    When I run `mt done --vault <vault> 1`
+    Then the exit code is 1
+    And stderr contains "run 'mt list' first"
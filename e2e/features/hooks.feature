@@ -0,0 +1,186 @@
+Feature: Hooks
+
+  The hooks: key of mt.yaml and of the global config maps lifecycle
+  events (created, status_changed, done, deferred, ranked, commented) to
+  commands run without a shell, in the vault, with the event as JSON on
+  stdin. An <event> hook runs after the command; a pre_<event> hook runs
+  before the change is written and vetoes it by exiting non-zero. Event
+  detection and the payload are pure logic covered at Seam 2
+  (internal/hook); these scenarios cover the process.
+
+  Background:
+    Given the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 1
+      ---
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: second
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 2
+      ---
+      """
+    And the file "<base>/log.sh" is written with:
+      """
+      printf '%s ' "$MT_HOOK" >> <base>/events.log
+      cat >> <base>/events.log
+      """
+
+  Scenario: done runs the status_changed and done hooks with the JSON payload
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      hooks:
+        status_changed: [[sh, <base>/log.sh]]
+        done: [[sh, <base>/log.sh]]
+      """
+    When I run `mt --vault <vault> done pkm-001`
+    Then the exit code is 0
+    And stdout contains "pkm-001"
+    And the file "<base>/events.log" contains "status_changed {"
+    And the file "<base>/events.log" matches "done \{.event.:.done.,.vault.:.[^,]*vault.,.id.:.pkm-001.,.command.:.mt --vault [^,]* done pkm-001.,"
+    And the file "<base>/events.log" matches ".before.:\{.title.:.first.,.status.:.open.,"
+    And the file "<base>/events.log" matches ".after.:\{.title.:.first.,.status.:.done.,.*.completed_at.:"
+
+  Scenario: a pre-hook that exits non-zero vetoes the change
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      hooks:
+        pre_done:
+          - [sh, -c, exit 1]
+      """
+    When I run `mt --vault <vault> done pkm-001`
+    Then the exit code is 1
+    And stderr contains "pre_done hook"
+    And stderr contains "vetoed the change to pkm-001"
+    And the file "<vault>/issues/pkm-001.md" contains "status: open"
+
+  Scenario: a veto of one Issue aborts the whole batch before any write
+    Given the file "<base>/veto.sh" is written with:
+      """
+      if grep -q '"id":"pkm-002"'; then
+        echo "pkm-002 is not ready" >&2
+        exit 1
+      fi
+      """
+    And the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      hooks:
+        pre_status_changed: [[sh, <base>/veto.sh]]
+      """
+    When I run `mt --vault <vault> done pkm-001 pkm-002`
+    Then the exit code is 1
+    And stderr contains "pkm-002 is not ready"
+    And stderr contains "vetoed the change to pkm-002"
+    And the file "<vault>/issues/pkm-001.md" contains "status: open"
+    And the file "<vault>/issues/pkm-002.md" contains "status: open"
+
+  Scenario: a pre-hook runs once per Issue, also on a hand-edited file
+    Given the file "<vault>/issues/pkm-003.md" is written with:
+      """
+      ---
+      # kept by hand
+      status: open
+      title: "third"
+      created_at: 2026-01-01T10:00
+      labels: []
+      ---
+      """
+    And the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      hooks:
+        pre_done: [[sh, -c, echo vetted >> <base>/runs.log]]
+      """
+    When I run `mt --vault <vault> done pkm-001 pkm-003`
+    Then the exit code is 0
+    And the file "<base>/runs.log" contains 2 occurrences of "vetted"
+    And the file "<vault>/issues/pkm-003.md" contains "# kept by hand"
+
+  Scenario: a pre-hook vetoes a new Issue before its file is written
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      hooks:
+        pre_created: [[sh, -c, exit 3]]
+      """
+    When I run `mt --vault <vault> q nope`
+    Then the exit code is 1
+    And stderr contains "vetoed the change"
+    And the directory "<vault>/issues" contains 2 files
+
+  Scenario: create fires created for the new Issue and ranked for the shifted ones
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      hooks:
+        created: [[sh, <base>/log.sh]]
+        ranked: [[sh, <base>/log.sh]]
+      """
+    When I run `mt --vault <vault> create third --rank top`
+    Then the exit code is 0
+    And the file "<base>/events.log" matches "created \{.event.:.created.,[^\n]*.before.:null,.after.:\{.title.:.third."
+    And the file "<base>/events.log" matches "ranked \{.event.:.ranked.,[^\n]*.id.:.pkm-001.,[^\n]*.rank.:1\}[^\n]*.rank.:2\}"
+    And the file "<base>/events.log" matches "ranked \{.event.:.ranked.,[^\n]*.id.:.pkm-002."
+
+  Scenario: comment fires the commented hook
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      hooks:
+        commented: [[sh, <base>/log.sh]]
+      """
+    When I run `mt --vault <vault> comment pkm-001 olá`
+    Then the exit code is 0
+    And the file "<base>/events.log" matches "commented \{.event.:.commented.,[^\n]*.id.:.pkm-001."
+
+  Scenario: a failing hook warns but the change stands
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      hooks:
+        done: [false]
+      """
+    When I run `mt --vault <vault> done pkm-001`
+    Then the exit code is 0
+    And stderr contains "Warning: done hook"
+    And the file "<vault>/issues/pkm-001.md" contains "status: done"
+
+  Scenario: the global hooks run before the vault's
+    Given the file "<base>/config/mt/config.yaml" is written with:
+      """
+      hooks:
+        deferred: [[sh, -c, echo global >> <base>/order.log]]
+      """
+    And the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      hooks:
+        deferred: [[sh, -c, echo vault >> <base>/order.log]]
+      """
+    When I run `mt --vault <vault> defer pkm-001 +2d`
+    Then the exit code is 0
+    And the file "<base>/order.log" matches "^global\nvault\n$"
+
+  Scenario: a hook under an unknown event is a config error
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      hooks:
+        closed: [[sh, <base>/log.sh]]
+      """
+    When I run `mt --vault <vault> done pkm-001`
+    Then the exit code is 1
+    And stderr contains "unknown hook event"
+    And the file "<vault>/issues/pkm-001.md" contains "status: open"
@@ -0,0 +1,89 @@
+Feature: Persistent Issue index
+
+  list, ready, overdue, pick-next and bare mt read the vault through an
+  index of the Issues' frontmatter cached under .mt/cache, parsing only
+  the files that changed since. When an entry may be trusted is pure
+  logic covered at Seam 2 (internal/index); these scenarios cover that
+  the cache never changes what the commands see.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: open
+      labels: [casa]
+      created_at: 2026-01-01T10:00
+      rank: 1
+      ---
+      """
+
+  Scenario: listing writes the index, ignored by Git
+    When I run `mt --vault <vault> list`
+    Then the exit code is 0
+    And stdout contains "pkm-001  first"
+    And the file "<vault>/.mt/cache/index.json" exists
+    And the file "<vault>/.mt/cache/.gitignore" contains "*"
+
+  Scenario: an Issue edited outside mt is listed as it is now
+    When I run `mt --vault <vault> list`
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: renamed by hand
+      status: in_progress
+      labels: [casa]
+      created_at: 2026-01-01T10:00
+      rank: 1
+      ---
+      """
+    And I run `mt --vault <vault>`
+    Then the exit code is 0
+    And stdout contains "pkm-001  renamed by hand"
+
+  Scenario: created and removed Issues are seen
+    When I run `mt --vault <vault> list`
+    And I run `mt --vault <vault> create second --rank top`
+    And I run `mt --vault <vault> list`
+    Then stdout contains "second"
+    When I run `mt --vault <vault> undo`
+    And I run `mt --vault <vault> list`
+    Then the exit code is 0
+    And stdout does not contain "second"
+
+  Scenario: a corrupt index falls back to parsing every Issue
+    Given the file "<vault>/.mt/cache/index.json" is written with:
+      """
+      not json
+      """
+    When I run `mt --vault <vault> ready`
+    Then the exit code is 0
+    And stdout contains "pkm-001"
+    And the file "<vault>/.mt/cache/index.json" matches "^\{.version.:5,"
+
+  Scenario: a malformed Issue still fails the listing
+    When I run `mt --vault <vault> list`
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: [broken
+      ---
+      """
+    And I run `mt --vault <vault> list`
+    Then the exit code is 1
+    And stderr contains "parsing issue pkm-001"
+
+  Scenario: reindex rebuilds the index
+    When I run `mt --vault <vault> reindex`
+    Then the exit code is 0
+    And stdout contains "Indexed 1 issues"
+    And the file "<vault>/.mt/cache/index.json" matches "^\{.version.:5,"
+
+  Scenario: reindex takes no arguments
+    When I run `mt --vault <vault> reindex now`
+    Then the exit code is 2
+    And stderr contains "reindex takes no arguments"
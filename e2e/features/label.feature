@@ -0,0 +1,84 @@
+Feature: Label add and remove
+
+  mt label add <id...> <label> and mt label rm <id...> <label> edit the
+  labels of Issues. Both are batch commands — several IDs, - for IDs on
+  stdin, or --where — and both are idempotent. The edit is pure logic
+  covered at Seam 2 (internal/issue); these scenarios cover the wiring.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: buy bread
+      status: open
+      labels: [errands]
+      created_at: 2026-01-01T10:00
+      ---
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: buy milk
+      status: open
+      labels: []
+      created_at: 2026-01-01T11:00
+      ---
+      """
+    And the file "<vault>/issues/pkm-003.md" is written with:
+      """
+      ---
+      title: write report
+      status: open
+      labels: [work]
+      created_at: 2026-01-01T12:00
+      ---
+      """
+
+  Scenario: label add labels several Issues at once
+    When I run `mt label add --vault <vault> pkm-001 pkm-002 shop`
+    Then the exit code is 0
+    And stdout contains "label add: 2 issues (pkm-001, pkm-002)"
+    And stdout contains "pkm-001 is now labeled shop"
+    And stdout contains "pkm-002 is now labeled shop"
+    And the file "<vault>/issues/pkm-001.md" contains "labels: [errands, shop]"
+    And the file "<vault>/issues/pkm-002.md" contains "labels: [shop]"
+
+  Scenario: label add is idempotent
+    When I run `mt label add --vault <vault> pkm-001 errands`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "labels: [errands]"
+
+  Scenario: label rm reads the target IDs from stdin
+    When I run `mt label rm --vault <vault> - errands` with stdin:
+      """
+      pkm-001
+      pkm-002
+      """
+    Then the exit code is 0
+    And stdout contains "pkm-001 is no longer labeled errands"
+    And the file "<vault>/issues/pkm-001.md" contains "labels: []"
+    And the file "<vault>/issues/pkm-002.md" contains "labels: []"
+
+  Scenario: --where selects the Issues to label
+    When I run `mt label add --vault <vault> --where "title~buy" shop`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "shop"
+    And the file "<vault>/issues/pkm-002.md" contains "shop"
+    And the file "<vault>/issues/pkm-003.md" does not contain "shop"
+
+  Scenario: an unknown Issue aborts the whole batch
+    When I run `mt label add --vault <vault> pkm-001 pkm-404 shop`
+    Then the exit code is 1
+    And stderr contains "issue pkm-404 not found"
+    And the file "<vault>/issues/pkm-001.md" does not contain "shop"
+
+  Scenario: label needs an ID and a non-empty label
+    When I run `mt label add --vault <vault> pkm-001`
+    Then the exit code is 2
+    When I run `mt label rm --vault <vault> pkm-001 ""`
+    Then the exit code is 2
+    And stderr contains "label rm needs a non-empty label"
@@ -0,0 +1,131 @@
+Feature: MCP server
+
+  mt mcp serves the vault to LLM agents over the Model Context Protocol:
+  JSON-RPC messages, one per line, on stdin and stdout, until stdin ends.
+  The protocol (framing, methods, error objects) is pure logic covered at
+  Seam 2 (internal/mcp); these scenarios cover the tools and resources
+  against a vault on disk.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: open
+      labels: [casa]
+      created_at: 2026-01-01T10:00
+      rank: 1
+      ---
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: second
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 2
+      ---
+      """
+
+  Scenario: the handshake lists the tools
+    When I run `mt --vault <vault> mcp` with stdin:
+      """
+      {"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"agent","version":"1"}}}
+      {"jsonrpc":"2.0","method":"notifications/initialized"}
+      {"jsonrpc":"2.0","id":2,"method":"tools/list"}
+      """
+    Then the exit code is 0
+    And stdout matches "^\{.jsonrpc.:.2.0.,.id.:1,.result.:\{[^\n]*.protocolVersion.:.2025-06-18.[^\n]*\}\n\{.jsonrpc.:.2.0.,.id.:2,"
+    And stdout matches ".name.:.ready."
+    And stdout matches ".name.:.dep."
+    And stdout matches ".name.:.rank."
+
+  Scenario: the tools read and change the vault like the commands
+    When I run `mt --vault <vault> mcp` with stdin:
+      """
+      {"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"dep","arguments":{"id":"pkm-001","blocker":"pkm-002"}}}
+      {"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"ready","arguments":{}}}
+      {"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"create","arguments":{"title":"third","labels":["casa"],"rank":"top"}}}
+      {"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"comment","arguments":{"id":"pkm-002","text":"olá"}}}
+      """
+    Then the exit code is 0
+    And stdout matches ".id.:1,[^\n]*.blocked_by.:\[.pkm-002.\],.blocked.:true"
+    And stdout matches ".id.:2,[^\n]*.structuredContent.:\{.issues.:\[\{.id.:.pkm-002.[^\n]*\}\]\},.isError.:false"
+    And stdout matches ".id.:3,[^\n]*.title.:.third.,[^\n]*.rank.:1,"
+    And the file "<vault>/issues/pkm-001.md" contains "blocked_by: [pkm-002]"
+    And the directory "<vault>/issues" contains 3 files
+    And the file "<vault>/issues/pkm-002.md" contains "rank: 3"
+    And the file "<vault>/issues/pkm-002.md" contains "olá"
+
+  Scenario: each mutating call is one journaled operation
+    When I run `mt --vault <vault> mcp` with stdin:
+      """
+      {"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"done","arguments":{"id":"pkm-001"}}}
+      {"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"rank","arguments":{"id":"pkm-002","rank":"backlog"}}}
+      """
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "status: done"
+    When I run `mt --vault <vault> journal`
+    Then stdout contains "mt mcp: done pkm-001"
+    And stdout contains "mt mcp: rank pkm-002"
+    When I run `mt --vault <vault> undo`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-002.md" contains "rank: 2"
+    And the file "<vault>/issues/pkm-001.md" contains "status: done"
+
+  Scenario: a failed call carries the error and the exit code of the command
+    When I run `mt --vault <vault> mcp` with stdin:
+      """
+      {"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"done","arguments":{"id":"pkm-404"}}}
+      {"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"defer","arguments":{"id":"pkm-001","until":"someday"}}}
+      {"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"show","arguments":{"ident":"pkm-001"}}}
+      {"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"dep","arguments":{"id":"pkm-001","blocker":"pkm-001"}}}
+      """
+    Then the exit code is 0
+    And stdout matches ".id.:1,[^\n]*.error.:.issue pkm-404 not found.,.exit_code.:1,.kind.:.user.\},.isError.:true"
+    And stdout matches ".id.:2,[^\n]*.exit_code.:2,.kind.:.usage."
+    And stdout matches ".id.:3,[^\n]*unknown field [^\n]*.exit_code.:2,"
+    And stdout matches ".id.:4,[^\n]*cannot block itself[^\n]*.exit_code.:1,"
+    And the file "<vault>/issues/pkm-001.md" contains "status: open"
+
+  Scenario: each Issue is a resource
+    When I run `mt --vault <vault> mcp` with stdin:
+      """
+      {"jsonrpc":"2.0","id":1,"method":"resources/list"}
+      {"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"mt://issues/pkm-002"}}
+      {"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"mt://issues/pkm-404"}}
+      """
+    Then the exit code is 0
+    And stdout matches ".id.:1,[^\n]*.uri.:.mt://issues/pkm-001.,.name.:.pkm-001.,.title.:.first.,.mimeType.:.text/markdown."
+    And stdout matches ".id.:2,[^\n]*.mimeType.:.text/markdown.,.text.:.---\\ntitle: second"
+    And stdout matches ".id.:3,.error.:\{.code.:-32002,"
+
+  Scenario: hook output stays off the protocol stream
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      hooks:
+        done: [[sh, -c, echo hook ran]]
+      """
+    When I run `mt --vault <vault> mcp` with stdin:
+      """
+      {"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"done","arguments":{"id":"pkm-001"}}}
+      """
+    Then the exit code is 0
+    And stderr contains "hook ran"
+    And stdout does not contain "hook ran"
+
+  Scenario: mcp refuses a directory that is not a vault
+    When I run `mt --vault <base> mcp`
+    Then the exit code is 1
+    And stderr contains "not a vault"
+
+  Scenario: mcp takes no arguments
+    When I run `mt --vault <vault> mcp now`
+    Then the exit code is 2
+    And stderr contains "mcp takes no arguments"
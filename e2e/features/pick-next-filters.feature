@@ -0,0 +1,123 @@
+Feature: Filtered and dry-run pick-next
+
+  pick-next --label, --where and --max-estimate narrow the candidates;
+  --peek prints the choice without starting it; --explain says why the
+  Issues ranked ahead of the choice were skipped. The rules are pure
+  logic covered at Seam 2 (internal/list); these scenarios cover the
+  wiring.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      fields:
+        - {name: estimate, type: int}
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: write the report
+      status: open
+      labels: [work]
+      created_at: 2026-01-01T10:00
+      rank: 1
+      deferred_until: 2999-01-01T08:00
+      ---
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: send the report
+      status: open
+      labels: [work]
+      created_at: 2026-01-01T11:00
+      rank: 2
+      blocked_by: [pkm-001]
+      ---
+      """
+    And the file "<vault>/issues/pkm-003.md" is written with:
+      """
+      ---
+      title: review the budget
+      status: open
+      labels: [work]
+      created_at: 2026-01-01T12:00
+      rank: 3
+      estimate: 5
+      ---
+      """
+    And the file "<vault>/issues/pkm-004.md" is written with:
+      """
+      ---
+      title: buy stamps
+      status: open
+      labels: [errands]
+      created_at: 2026-01-01T13:00
+      rank: 4
+      estimate: 1
+      ---
+      """
+
+  Scenario: --label and --where narrow the candidates
+    When I run `mt pick-next --vault <vault> --label errands --peek`
+    Then the exit code is 0
+    And stdout contains "pkm-004  buy stamps"
+    When I run `mt pick-next --vault <vault> --where "title~budget" --peek`
+    Then the exit code is 0
+    And stdout contains "pkm-003  review the budget"
+    When I run `mt pick-next --vault <vault> --label home`
+    Then the exit code is 1
+    And stderr contains "no available open issues"
+
+  Scenario: --max-estimate keeps the Issues estimated at most n
+    When I run `mt pick-next --vault <vault> --max-estimate 2`
+    Then the exit code is 0
+    And stdout contains "pkm-004 is now in_progress: buy stamps"
+
+  Scenario: --max-estimate needs the estimate field
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      """
+    When I run `mt pick-next --vault <vault> --max-estimate 2`
+    Then the exit code is 2
+    And stderr contains "--max-estimate needs the int field estimate"
+    When I run `mt pick-next --vault <vault> --max-estimate -1`
+    Then the exit code is 2
+
+  Scenario: --peek prints the choice without starting it
+    When I run `mt pick-next --vault <vault> --peek`
+    Then the exit code is 0
+    And stdout contains "○ pkm-003  review the budget"
+    And stdout does not contain "is now"
+    And the file "<vault>/issues/pkm-003.md" contains "status: open"
+    And the file "<vault>/issues/pkm-003.md" does not contain "started_at"
+
+  Scenario: --explain says why the Issues ahead were skipped
+    Given the file "<vault>/issues/pkm-005.md" is written with:
+      """
+      ---
+      title: file the receipts
+      status: done
+      labels: [work]
+      created_at: 2026-01-01T09:00
+      completed_at: 2026-01-02T09:00
+      rank: 0
+      ---
+      """
+    When I run `mt pick-next --vault <vault> --explain`
+    Then the exit code is 0
+    And stdout contains "skipped: pkm-001 is deferred until 2999-01-01T08:00"
+    And stdout contains "skipped: pkm-002 is blocked by pkm-001"
+    And stdout contains "skipped: 1 done Issue"
+    And stdout does not contain "pkm-005"
+    And stdout contains "pkm-003 is now in_progress: review the budget"
+    And stdout does not contain "pkm-004"
+
+  Scenario: --explain with nothing available explains every skip
+    When I run `mt pick-next --vault <vault> --where "rank<=2" --explain`
+    Then the exit code is 1
+    And stdout contains "skipped: pkm-001 is deferred until"
+    And stdout contains "skipped: pkm-002 is blocked by pkm-001"
+    And stdout does not contain "pkm-003"
+    And stderr contains "no available open issues"
@@ -0,0 +1,109 @@
+Feature: Recurring Issues
+
+  An Issue with recur: <period> is recurring: mt done closes it and
+  spawns its next occurrence, with the deadline and deferred_until
+  advanced by one period. The period arithmetic and the copy are pure
+  logic covered at Seam 2 (internal/deferral, internal/issue); these
+  scenarios cover the wiring.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: water the plants
+      status: open
+      labels: [home]
+      created_at: 2026-01-01T10:00
+      rank: 1
+      deferred_until: 2026-08-14T08:00
+      deadline: 2026-08-15T18:00
+      recur: 1w
+      ---
+
+      ## Description
+      Every pot, the balcony too.
+      ## Notes
+      ## Comments
+      """
+
+  Scenario: done spawns the next occurrence
+    When I run `mt done --vault <vault> pkm-001 --at "26-08-15 17:00"`
+    Then the exit code is 0
+    And stdout contains "pkm-001 is now done: water the plants"
+    And stdout matches "pkm-[a-z0-9]+ spawned from pkm-001: water the plants"
+    When I run `mt list --vault <vault>`
+    Then the exit code is 0
+    And stdout matches "^1 +○ pkm-[a-z0-9]+  water the plants"
+    And stdout does not contain "pkm-001"
+    When I run `mt show --vault <vault> 1`
+    Then the exit code is 0
+    And stdout contains "Every pot, the balcony too."
+    And stdout contains "2026-08-22 18:00"
+
+  Scenario: done keeps the dates of the occurrence it spawns from
+    When I run `mt done --vault <vault> pkm-001 --at "26-08-15 17:00"`
+    Then the exit code is 0
+    When I run `mt list --vault <vault> --where "deadline=2026-08-22T18:00 deferred_until=2026-08-21T08:00 created_at=2026-08-15T17:00" --format ids`
+    Then the exit code is 0
+    And stdout matches "^pkm-[a-z0-9]+\n$"
+
+  Scenario: mt undo reverts the done and removes the occurrence it spawned
+    When I run `mt done --vault <vault> pkm-001`
+    Then the exit code is 0
+    And the directory "<vault>/issues" contains 2 files
+    When I run `mt undo --vault <vault>`
+    Then the exit code is 0
+    And the directory "<vault>/issues" contains 1 files
+    And the file "<vault>/issues/pkm-001.md" contains "status: open"
+
+  Scenario: a pre_created hook that vetoes the occurrence refuses the done
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      hooks:
+        pre_created: [[sh, -c, exit 1]]
+      """
+    When I run `mt done --vault <vault> pkm-001`
+    Then the exit code is 1
+    And stderr contains "pre_created hook"
+    And the file "<vault>/issues/pkm-001.md" contains "status: open"
+    And the directory "<vault>/issues" contains 1 files
+
+  Scenario: closing a done Issue again spawns nothing
+    When I run `mt done --vault <vault> pkm-001`
+    Then the exit code is 0
+    When I run `mt done --vault <vault> pkm-001`
+    Then the exit code is 0
+    And stdout does not contain "spawned"
+
+  Scenario: a malformed recur refuses the done
+    Given the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: feed the cat
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      recur: daily
+      ---
+      """
+    When I run `mt done --vault <vault> pkm-002`
+    Then the exit code is 1
+    And stderr contains "invalid recurrence"
+    And the file "<vault>/issues/pkm-002.md" contains "status: open"
+
+  Scenario: create --recur makes a recurring Issue
+    When I run `mt create --vault <vault> "take the pill" --recur 1d`
+    Then the exit code is 0
+    When I run `mt list --vault <vault>`
+    Then the exit code is 0
+    When I run `mt show --vault <vault> 2`
+    Then the exit code is 0
+    And stdout contains "Recurs every: 1d"
+    When I run `mt create --vault <vault> "take the pill" --recur daily`
+    Then the exit code is 2
+    And stderr contains "--recur: invalid recurrence"
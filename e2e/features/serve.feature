@@ -0,0 +1,43 @@
+Feature: Local HTTP/JSON API
+
+  mt serve exposes the vault over HTTP until interrupted, behind the
+  bearer token of the global config. The API itself (routes, status
+  codes, ETags) is covered by the httptest tests of
+  internal/cli/serve_test.go; these scenarios cover the refusals that
+  happen before the server starts.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      """
+
+  Scenario: serve refuses to start without a token
+    When I run `mt --vault <vault> serve`
+    Then the exit code is 1
+    And stderr contains "mt serve needs a token: set serve.token in"
+
+  Scenario: serve refuses a directory that is not a vault
+    Given the file "<base>/config/mt/config.yaml" is written with:
+      """
+      serve:
+        token: s3cret
+      """
+    When I run `mt --vault <base> serve`
+    Then the exit code is 1
+    And stderr contains "not a vault"
+
+  Scenario: serve takes no arguments
+    When I run `mt --vault <vault> serve now`
+    Then the exit code is 2
+    And stderr contains "serve takes no arguments"
+
+  Scenario: serve reports an address it cannot listen on
+    Given the file "<base>/config/mt/config.yaml" is written with:
+      """
+      serve:
+        token: s3cret
+      """
+    When I run `mt --vault <vault> serve --addr nope`
+    Then the exit code is 1
+    And stderr contains "listening on nope"
@@ -0,0 +1,193 @@
+Feature: Start, pause and the WIP limit
+
+  mt start <id> starts a given Issue when it is available — open, not
+  deferred into the future, not blocked — and mt pause <id> puts an
+  in_progress Issue back to open, keeping its started_at. Every start
+  and pause is logged in work_log. wip_limit in
+  mt.yaml caps the Issues in_progress at once for start and pick-next,
+  and for mt status and create --status in_progress, which follow the
+  rules of start.
+  The rules are pure logic covered at Seam 2 (internal/list,
+  internal/issue); these scenarios cover the wiring.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: write the report
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      ---
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: send the report
+      status: open
+      labels: []
+      created_at: 2026-01-01T11:00
+      blocked_by: [pkm-001]
+      ---
+      """
+
+  Scenario: start starts the given Issue
+    When I run `mt start --vault <vault> pkm-001 --at "26-08-15 09:00"`
+    Then the exit code is 0
+    And stdout contains "pkm-001 is now in_progress: write the report"
+    And the file "<vault>/issues/pkm-001.md" contains "started_at: 2026-08-15T09:00"
+    And the file "<vault>/issues/pkm-001.md" contains "work_log: ['2026-08-15T09:00']"
+
+  Scenario: start refuses an Issue that is not available
+    When I run `mt start --vault <vault> pkm-002`
+    Then the exit code is 1
+    And stderr contains "issue pkm-002 is blocked by pkm-001"
+    When I run `mt defer --vault <vault> pkm-001 "99-01-01 08:00"`
+    Then the exit code is 0
+    When I run `mt start --vault <vault> pkm-001`
+    Then the exit code is 1
+    And stderr contains "issue pkm-001 is deferred until 2099-01-01T08:00"
+    And the file "<vault>/issues/pkm-001.md" does not contain "started_at"
+
+  Scenario: pause keeps started_at, and a second start resumes the work
+    When I run `mt start --vault <vault> pkm-001 --at "26-08-15 09:00"`
+    Then the exit code is 0
+    When I run `mt pause --vault <vault> pkm-001 --at "26-08-15 11:30"`
+    Then the exit code is 0
+    And stdout contains "pkm-001 is now open: write the report"
+    And the file "<vault>/issues/pkm-001.md" contains "status: open"
+    And the file "<vault>/issues/pkm-001.md" contains "started_at: 2026-08-15T09:00"
+    When I run `mt start --vault <vault> pkm-001 --at "26-08-16 14:00"`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "started_at: 2026-08-15T09:00"
+    When I run `mt reopen --vault <vault> pkm-001`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" does not contain "started_at"
+    And the file "<vault>/issues/pkm-001.md" does not contain "work_log"
+
+  Scenario: the work_log accumulates every start and pause
+    When I run `mt start --vault <vault> pkm-001 --at "26-08-15 09:00"`
+    Then the exit code is 0
+    When I run `mt pause --vault <vault> pkm-001 --at "26-08-15 11:30"`
+    Then the exit code is 0
+    When I run `mt pick-next --vault <vault> --at "26-08-16 14:00"`
+    Then the exit code is 0
+    And stdout contains "pkm-001 is now in_progress"
+    When I run `mt pause --vault <vault> pkm-001 --at "26-08-16 15:00"`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "work_log: ['2026-08-15T09:00/2026-08-15T11:30', '2026-08-16T14:00/2026-08-16T15:00']"
+    When I run `mt start --vault <vault> pkm-001 --at "26-08-17 10:00"`
+    Then the exit code is 0
+    When I run `mt show --vault <vault> pkm-001`
+    Then the exit code is 0
+    And stdout contains "Worked: 3h30m over 3 sessions, on since 2026-08-17 10:00"
+    When I run `mt done --vault <vault> pkm-001 --at "26-08-17 12:00"`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "'2026-08-17T10:00/2026-08-17T12:00']"
+    When I run `mt check --vault <vault>`
+    Then the exit code is 0
+
+  Scenario: pause refuses an Issue that is not in_progress
+    When I run `mt pause --vault <vault> pkm-001`
+    Then the exit code is 1
+    And stderr contains "issue pkm-001 is open, not in_progress"
+
+  Scenario: wip_limit caps start and pick-next
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      wip_limit: 1
+      """
+    And the file "<vault>/issues/pkm-003.md" is written with:
+      """
+      ---
+      title: water the plants
+      status: open
+      labels: []
+      created_at: 2026-01-01T12:00
+      ---
+      """
+    When I run `mt start --vault <vault> pkm-003`
+    Then the exit code is 0
+    When I run `mt pick-next --vault <vault>`
+    Then the exit code is 1
+    And stderr contains "WIP limit reached: 1 in progress (wip_limit: 1)"
+    When I run `mt start --vault <vault> pkm-001`
+    Then the exit code is 1
+    And stderr contains "WIP limit reached"
+    When I run `mt pause --vault <vault> pkm-003`
+    Then the exit code is 0
+    When I run `mt pick-next --vault <vault>`
+    Then the exit code is 0
+    And stdout contains "pkm-001 is now in_progress"
+
+  Scenario: --peek and --explain still answer at the WIP limit
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      wip_limit: 1
+      """
+    And the file "<vault>/issues/pkm-003.md" is written with:
+      """
+      ---
+      title: water the plants
+      status: open
+      labels: []
+      created_at: 2026-01-01T12:00
+      ---
+      """
+    When I run `mt start --vault <vault> pkm-001`
+    Then the exit code is 0
+    When I run `mt pick-next --vault <vault> --peek`
+    Then the exit code is 0
+    And stdout contains "pkm-003"
+    And the file "<vault>/issues/pkm-003.md" contains "status: open"
+    When I run `mt pick-next --vault <vault> --explain`
+    Then the exit code is 1
+    And stdout contains "skipped: pkm-001 is in_progress, not open"
+    And stdout contains "skipped: pkm-002 is blocked by pkm-001"
+    And stderr contains "WIP limit reached: 1 in progress (wip_limit: 1)"
+    And the file "<vault>/issues/pkm-003.md" contains "status: open"
+
+  Scenario: mt status and create --status in_progress keep to the rules of start
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      wip_limit: 2
+      """
+    When I run `mt status --vault <vault> pkm-002 in_progress`
+    Then the exit code is 1
+    And stderr contains "issue pkm-002 is blocked by pkm-001"
+    And the file "<vault>/issues/pkm-002.md" contains "status: open"
+    When I run `mt create --vault <vault> --blocked-by pkm-001 --status in_progress "ship the report"`
+    Then the exit code is 1
+    And stderr contains "is blocked by pkm-001"
+    And the directory "<vault>/issues" contains 2 files
+    When I run `mt status --vault <vault> pkm-001 in_progress`
+    Then the exit code is 0
+    When I run `mt create --vault <vault> --status in_progress "file the taxes"`
+    Then the exit code is 0
+    When I run `mt create --vault <vault> --status in_progress "call the bank"`
+    Then the exit code is 1
+    And stderr contains "WIP limit reached: 2 in progress (wip_limit: 2)"
+    And the directory "<vault>/issues" contains 3 files
+
+  Scenario: a negative wip_limit fails every command
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      wip_limit: -1
+      """
+    When I run `mt list --vault <vault>`
+    Then the exit code is 1
+    And stderr contains "wip_limit must not be negative"
+
+  Scenario: start and pause take exactly one ID
+    When I run `mt start --vault <vault>`
+    Then the exit code is 2
+    When I run `mt pause --vault <vault> pkm-001 pkm-002`
+    Then the exit code is 2
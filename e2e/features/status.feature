@@ -139,3 +139,19 @@ Feature: Status transitions
     Then the exit code is 0
     And the file "<vault>/issues/<id>.md" contains "status: done"
     And the file "<vault>/issues/<id>.md" does not contain "completed_at:"
+
+  Scenario: done keeps the hand-written YAML of the frontmatter
+    Given the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      # triagem da semana
+      title: "ligar para o cliente"   # aspas de propósito
+      status: open
+      labels:
+        - trabalho
+      created_at: 2026-08-15T09:30
+      ---
+      """
+    When I run `mt done --vault <vault> pkm-001`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" matches "^---\n# triagem da semana\ntitle: .ligar para o cliente.   # aspas de propósito\nstatus: done\nlabels:\n  - trabalho\ncreated_at: 2026-08-15T09:30\ncompleted_at: [0-9T:-]+\n---\n$"
@@ -0,0 +1,156 @@
+Feature: Issue templates
+
+  mt create --template <name> starts a new Issue from the vault's
+  templates/<name>.md: its partial frontmatter sets labels, deadline and
+  deferred_until offsets, a rank placement and blocked_by; its body
+  replaces the empty sections, with placeholders expanded. mt check
+  validates every template. Parsing and applying a template is pure
+  logic covered at Seam 2 (internal/template); these scenarios cover the
+  process.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      status: [open, in_progress, done]
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 1
+      ---
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: second
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 2
+      ---
+      """
+
+  Scenario: create --template applies the frontmatter and the body
+    Given the file "<vault>/templates/bug.md" is written with:
+      """
+      ---
+      labels: [bug]
+      deadline: +3d
+      deferred_until: +1d
+      blocked_by: [pkm-002]
+      ---
+
+      ## Description
+      Bug {{title}} ({{id}}), reported {{date}}.
+      ## Notes
+      ## Comments
+      """
+    When I run `mt create --vault <vault> --template bug --label urgent app crashes`
+    Then the exit code is 0
+    And stdout matches "^Created pkm-[a-z0-9]+\n$"
+    And I remember the issue ID
+    And the file "<vault>/issues/<id>.md" contains "labels: [bug, urgent]"
+    And the file "<vault>/issues/<id>.md" contains "deadline: "
+    And the file "<vault>/issues/<id>.md" contains "deferred_until: "
+    And the file "<vault>/issues/<id>.md" contains "blocked_by: [pkm-002]"
+    And the file "<vault>/issues/<id>.md" contains "Bug app crashes (<id>), reported 20"
+    And the file "<vault>/issues/<id>.md" does not contain "{{"
+
+  Scenario: a template places the new Issue in the queue
+    Given the file "<vault>/templates/hot.md" is written with:
+      """
+      ---
+      rank: top
+      ---
+      """
+    When I run `mt create --vault <vault> --template hot urgent thing`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "rank: 2"
+    And the file "<vault>/issues/pkm-002.md" contains "rank: 3"
+    When I run `mt list --vault <vault>`
+    Then stdout matches "1 ○ pkm-[a-z0-9]+  urgent thing"
+
+  Scenario: a template without a body keeps the default sections
+    Given the file "<vault>/templates/errand.md" is written with:
+      """
+      ---
+      labels: [errand]
+      rank: 2
+      ---
+      """
+    When I run `mt create --vault <vault> --template errand buy bread`
+    Then the exit code is 0
+    When I run `mt list --vault <vault>`
+    Then stdout matches "2 ○ pkm-[a-z0-9]+  buy bread"
+    And the file "<vault>/issues/pkm-002.md" contains "rank: 3"
+
+  Scenario: an unknown template is a user error and writes nothing
+    Given the file "<vault>/templates/bug.md" is written with:
+      """
+      ## Description
+      """
+    When I run `mt create --vault <vault> --template nope thing`
+    Then the exit code is 1
+    And stderr contains "not found (available: bug)"
+    And the directory "<vault>/issues" contains 2 files
+
+  Scenario: a blocker that does not exist fails before writing
+    Given the file "<vault>/templates/dep.md" is written with:
+      """
+      ---
+      blocked_by: [pkm-404]
+      ---
+      """
+    When I run `mt create --vault <vault> --template dep thing`
+    Then the exit code is 1
+    And stderr contains "template dep: blocked_by: issue pkm-404 not found"
+    And the directory "<vault>/issues" contains 2 files
+
+  Scenario: a template name with a path separator is a usage error
+    When I run `mt create --vault <vault> --template ../x thing`
+    Then the exit code is 2
+    And stderr contains "invalid template name"
+
+  Scenario: check reports an invalid template
+    Given the file "<vault>/templates/bad.md" is written with:
+      """
+      ---
+      status: done
+      ---
+      """
+    When I run `mt check --vault <vault>`
+    Then the exit code is 1
+    And stderr contains "template bad: unknown field"
+    And stderr contains "status"
+
+  Scenario: check reports a template blocker that does not exist
+    Given the file "<vault>/templates/dep.md" is written with:
+      """
+      ---
+      blocked_by: [pkm-404]
+      ---
+      Hello {{title}}
+      """
+    When I run `mt check --vault <vault>`
+    Then the exit code is 1
+    And stderr contains "template dep: blocked_by references unknown issue pkm-404"
+
+  Scenario: check accepts valid templates
+    Given the file "<vault>/templates/bug.md" is written with:
+      """
+      ---
+      labels: [bug]
+      deadline: +3d
+      rank: bottom
+      blocked_by: [pkm-001]
+      ---
+      {{title}} on {{now}}
+      """
+    When I run `mt check --vault <vault>`
+    Then the exit code is 0
+    And stdout contains "OK"
@@ -0,0 +1,110 @@
+Feature: Vault timezone
+
+  timezone: in mt.yaml sets how a vault's datetimes are written and
+  read: unset, naive times of the machine's zone; an IANA name, naive
+  times of that zone wherever mt runs; offset, each datetime with its UTC
+  offset. mt migrate-times rewrites the stored datetimes after the
+  setting changes. The layouts and the conversion are pure logic covered
+  at Seam 2 (internal/issue, internal/check); these scenarios cover the
+  wiring, with the machine's zone pinned to UTC.
+
+  Background:
+    Given the environment variable "TZ" is "UTC"
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: pay the rent
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      deadline: 2026-01-05T12:00
+      ---
+      """
+
+  Scenario: an offset vault stamps new Issues with their offset
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      timezone: offset
+      """
+    When I run `mt create --vault <vault> "buy bread" --deadline "26-08-20 18:00"`
+    Then the exit code is 0
+    And I remember the issue ID
+    And the file "<vault>/issues/<id>.md" matches "created_at: [0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}Z"
+    And the file "<vault>/issues/<id>.md" contains "deadline: 2026-08-20T18:00Z"
+
+  Scenario: check flags naive datetimes in an offset vault and migrate-times converts them
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      timezone: offset
+      """
+    When I run `mt check --vault <vault>`
+    Then the exit code is 1
+    And stderr contains "want 2006-01-02T15:04Z07:00"
+    When I run `mt migrate-times --vault <vault> --from America/Sao_Paulo --dry-run`
+    Then the exit code is 0
+    And stdout contains "pkm-001: created_at: 2026-01-01T10:00 → 2026-01-01T10:00-03:00"
+    And stdout contains "Would migrate 1 Issue (dry run: nothing written)"
+    And the file "<vault>/issues/pkm-001.md" does not contain "-03:00"
+    When I run `mt migrate-times --vault <vault> --from America/Sao_Paulo`
+    Then the exit code is 0
+    And stdout contains "pkm-001: deadline: 2026-01-05T12:00 → 2026-01-05T12:00-03:00"
+    And stdout contains "Migrated 1 Issue"
+    And the file "<vault>/issues/pkm-001.md" contains "deadline: 2026-01-05T12:00-03:00"
+    When I run `mt check --vault <vault>`
+    Then the exit code is 0
+    When I run `mt migrate-times --vault <vault>`
+    Then the exit code is 0
+    And stdout contains "Nothing to migrate"
+
+  Scenario: an IANA zone vault migrates to and resolves in that zone
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      timezone: America/Sao_Paulo
+      """
+    When I run `mt migrate-times --vault <vault>`
+    Then the exit code is 0
+    And stdout contains "pkm-001: created_at: 2026-01-01T10:00 → 2026-01-01T07:00"
+    When I run `mt defer --vault <vault> pkm-001 26-08-20`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "deferred_until: 2026-08-20T09:00"
+
+  Scenario: show renders offset datetimes in the vault zone
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      timezone: America/Sao_Paulo
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: call the bank
+      status: open
+      labels: []
+      created_at: 2026-01-01T12:00Z
+      ---
+      """
+    When I run `mt show --vault <vault> pkm-002`
+    Then the exit code is 0
+    And stdout contains "Created: 2026-01-01 09:00"
+
+  Scenario: an unknown timezone fails every command
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      timezone: Mars/Olympus
+      """
+    When I run `mt list --vault <vault>`
+    Then the exit code is 1
+    And stderr contains "unknown timezone"
+
+  Scenario: an unknown --from zone is a usage error
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      """
+    When I run `mt migrate-times --vault <vault> --from Mars/Olympus`
+    Then the exit code is 2
+    And stderr contains "unknown --from zone"
@@ -2,8 +2,8 @@ Feature: Undefer issues
 
   mt undefer archives the deferral reminder: without an ID it sweeps the
   vault clearing deferred_until from every expired deferral (printing
-  one "Undeferred <id> (was <datetime>)" line per Issue); with an ID it
-  clears one Issue even when its deferral is still in the future. Only
+  one "Undeferred <id> (was <datetime>)" line per Issue); with IDs it
+  clears those Issues even when their deferral is still in the future. Only
   the deferred_until field is touched. These scenarios cover the
   process: the compiled binary against a temporary Vault.
 
@@ -148,7 +148,27 @@ Feature: Undefer issues
     And the file "<vault>/issues/pkm-001.md" contains "deadline: 2999-01-01T00:00"
     And the file "<vault>/issues/pkm-001.md" does not contain "deferred_until:"
 
-  Scenario: undefer takes at most one issue ID
+  Scenario: undefer with several IDs is all-or-nothing
+    Given the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: deferred
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      deferred_until: 2999-01-01T00:00
+      ---
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: not deferred
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      ---
+      """
     When I run `mt undefer --vault <vault> pkm-001 pkm-002`
-    Then the exit code is 2
-    And stderr contains "undefer takes at most one issue ID"
+    Then the exit code is 1
+    And stderr contains "issue pkm-002 has no deferred_until to undefer"
+    And the file "<vault>/issues/pkm-001.md" contains "deferred_until: 2999-01-01T00:00"
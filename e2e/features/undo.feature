@@ -0,0 +1,146 @@
+Feature: Undo journal
+
+  Every command that writes Issue files appends one entry to the vault's
+  journal (.mt/journal.jsonl): the command line, the time and the before
+  and after content of each touched file. mt journal lists the entries,
+  newest first; mt undo [n] reverts the latest ones, refusing — without
+  changing anything — when a file changed since its journaled
+  post-image. The planning rules are pure logic covered at Seam 2
+  (internal/journal); these scenarios cover the process.
+
+  Background:
+    Given the file "<vault>/mt.yaml" is written with:
+      """
+      prefix: pkm
+      status: [open, in_progress, done]
+      """
+    And the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: first
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 1
+      ---
+      """
+    And the file "<vault>/issues/pkm-002.md" is written with:
+      """
+      ---
+      title: second
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      rank: 2
+      ---
+      """
+
+  Scenario: undo reverts the latest operation
+    When I run `mt done --vault <vault> pkm-001`
+    Then the exit code is 0
+    When I run `mt undo --vault <vault>`
+    Then the exit code is 0
+    And stdout contains "Undid #1: mt done --vault"
+    And the file "<vault>/issues/pkm-001.md" contains "status: open"
+    And the file "<vault>/issues/pkm-001.md" does not contain "completed_at"
+
+  Scenario: undo n reverts the n latest operations, newest first
+    When I run `mt done --vault <vault> pkm-001`
+    Then the exit code is 0
+    When I run `mt defer --vault <vault> pkm-002 +2d`
+    Then the exit code is 0
+    When I run `mt undo --vault <vault> 2`
+    Then the exit code is 0
+    And stdout matches "Undid #2: mt defer[^\n]*\nUndid #1: mt done"
+    And the file "<vault>/issues/pkm-001.md" contains "status: open"
+    And the file "<vault>/issues/pkm-002.md" does not contain "deferred_until"
+
+  Scenario: a batch is one operation
+    When I run `mt done --vault <vault> pkm-001 pkm-002`
+    Then the exit code is 0
+    When I run `mt undo --vault <vault>`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "status: open"
+    And the file "<vault>/issues/pkm-002.md" contains "status: open"
+
+  Scenario: undo of create removes the new Issue
+    When I run `mt q --vault <vault> third`
+    Then the exit code is 0
+    And I remember the issue ID
+    When I run `mt undo --vault <vault>`
+    Then the exit code is 0
+    And the directory "<vault>/issues" contains 2 files
+
+  Scenario: undo restores what the editor changed
+    Given the fake editor writes
+      """
+      ---
+      title: rewritten
+      status: open
+      labels: []
+      created_at: 2026-01-01T10:00
+      ---
+      """
+    When I run `mt edit --vault <vault> pkm-001`
+    Then the exit code is 0
+    When I run `mt undo --vault <vault>`
+    Then the exit code is 0
+    And the file "<vault>/issues/pkm-001.md" contains "title: first"
+
+  Scenario: undo refuses when the file changed since
+    When I run `mt done --vault <vault> pkm-001`
+    Then the exit code is 0
+    Given the file "<vault>/issues/pkm-001.md" is written with:
+      """
+      ---
+      title: edited by hand
+      status: done
+      labels: []
+      created_at: 2026-01-01T10:00
+      ---
+      """
+    When I run `mt undo --vault <vault>`
+    Then the exit code is 1
+    And stderr contains "cannot undo #1"
+    And stderr contains "issue pkm-001 changed since"
+    And the file "<vault>/issues/pkm-001.md" contains "title: edited by hand"
+
+  Scenario: journal lists operations newest first and marks undone ones
+    When I run `mt done --vault <vault> pkm-001`
+    Then the exit code is 0
+    When I run `mt status --vault <vault> pkm-002 in_progress`
+    Then the exit code is 0
+    When I run `mt undo --vault <vault>`
+    Then the exit code is 0
+    When I run `mt journal --vault <vault>`
+    Then the exit code is 0
+    And stdout matches "#3  [0-9T:-]+  mt undo --vault [^\n]*\(undid #2\)\n#2 [^\n]*mt status [^\n]*\(pkm-002\) \[undone\]\n#1 [^\n]*mt done [^\n]*\(pkm-001\)\n"
+    And the file "<vault>/.mt/.gitignore" contains "*"
+
+  Scenario: undone operations are skipped by the next undo
+    When I run `mt done --vault <vault> pkm-001`
+    Then the exit code is 0
+    When I run `mt done --vault <vault> pkm-002`
+    Then the exit code is 0
+    When I run `mt undo --vault <vault>`
+    Then the exit code is 0
+    When I run `mt undo --vault <vault>`
+    Then the exit code is 0
+    And stdout contains "Undid #1"
+    When I run `mt undo --vault <vault>`
+    Then the exit code is 1
+    And stderr contains "nothing to undo"
+
+  Scenario: read-only commands journal nothing
+    When I run `mt list --vault <vault>`
+    Then the exit code is 0
+    When I run `mt undo --vault <vault>`
+    Then the exit code is 1
+    And stderr contains "nothing to undo"
+
+  Scenario: a malformed undo count is a usage error
+    When I run `mt undo --vault <vault> zero`
+    Then the exit code is 2
+    And stderr contains "undo count must be a positive integer"
+    When I run `mt undo --vault <vault> 1 2`
+    Then the exit code is 2
@@ -44,12 +44,13 @@ type state struct {
 }
 
 // env is the baseline environment for every mt run in the scenario:
-// fake $EDITOR plus an isolated config home, so the real user config
-// can never leak into a scenario.
+// fake $EDITOR plus isolated config and cache homes, so the real user
+// config (and list handles) can never leak into a scenario.
 func (st *state) env() []string {
 	env := []string{
 		st.editor.EditorVar(),
 		"XDG_CONFIG_HOME=" + filepath.Join(st.base, "config"),
+		"XDG_CACHE_HOME=" + filepath.Join(st.base, "cache"),
 	}
 	return append(env, st.extraEnv...)
 }
@@ -100,6 +101,7 @@ func InitializeScenario(sc *godog.ScenarioContext) {
 	})
 
 	sc.Step(`^I run \x60mt(?: (.*))?\x60$`, iRunMt)
+	sc.Step(`^I run \x60mt(?: (.*))?\x60 with stdin:$`, iRunMtWithStdin)
 	sc.Step(`^the working directory is "([^"]*)"$`, workingDirectoryIs)
 	sc.Step(`^the exit code is (\d+)$`, exitCodeIs)
 	sc.Step(`^stdout contains "([^"]*)"$`, stdoutContains)
@@ -108,6 +110,7 @@ func InitializeScenario(sc *godog.ScenarioContext) {
 	sc.Step(`^the environment variable "([^"]*)" is "([^"]*)"$`, envVarIs)
 	sc.Step(`^stdout matches "([^"]*)"$`, stdoutMatches)
 	sc.Step(`^stderr contains "([^"]*)"$`, stderrContains)
+	sc.Step(`^stderr matches "([^"]*)"$`, stderrMatches)
 	sc.Step(`^a temporary vault exists$`, temporaryVaultExists)
 	sc.Step(`^the vault contains an issues directory$`, vaultHasIssuesDirectory)
 	sc.Step(`^a fake editor is available$`, fakeEditorAvailable)
@@ -132,12 +135,28 @@ func stateFrom(ctx context.Context) (*state, error) {
 }
 
 func iRunMt(ctx context.Context, args string) (context.Context, error) {
+	return runMt(ctx, args, "")
+}
+
+// iRunMtWithStdin runs mt with the doc string (placeholders expanded,
+// plus a trailing newline) as its stdin.
+func iRunMtWithStdin(ctx context.Context, args string, doc *godog.DocString) (context.Context, error) {
+	st, err := stateFrom(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	return runMt(ctx, args, st.expand(doc.Content)+"\n")
+}
+
+// runMt runs mt with args in the scenario's environment and records the
+// result for the following Then steps.
+func runMt(ctx context.Context, args, stdin string) (context.Context, error) {
 	st, err := stateFrom(ctx)
 	if err != nil {
 		return ctx, err
 	}
 	args = st.expand(args)
-	res, err := support.RunCmdIn(support.Binary(), st.dir, splitArgs(args), st.env())
+	res, err := support.RunCmdWithStdin(support.Binary(), st.dir, stdin, splitArgs(args), st.env())
 	if err != nil {
 		return ctx, err
 	}
@@ -399,6 +418,14 @@ func stdoutDoesNotContain(ctx context.Context, want string) (context.Context, er
 }
 
 func stdoutMatches(ctx context.Context, pattern string) (context.Context, error) {
+	return streamMatches(ctx, "stdout", pattern)
+}
+
+func stderrMatches(ctx context.Context, pattern string) (context.Context, error) {
+	return streamMatches(ctx, "stderr", pattern)
+}
+
+func streamMatches(ctx context.Context, stream, pattern string) (context.Context, error) {
 	st, err := stateFrom(ctx)
 	if err != nil {
 		return ctx, err
@@ -411,8 +438,12 @@ func stdoutMatches(ctx context.Context, pattern string) (context.Context, error)
 	if err != nil {
 		return ctx, fmt.Errorf("compiling regexp %q: %w", pattern, err)
 	}
-	if !re.MatchString(st.result.Stdout) {
-		return ctx, fmt.Errorf("stdout does not match %q:\n%s", pattern, st.result.Stdout)
+	got := st.result.Stdout
+	if stream == "stderr" {
+		got = st.result.Stderr
+	}
+	if !re.MatchString(got) {
+		return ctx, fmt.Errorf("%s does not match %q:\n%s", stream, pattern, got)
 	}
 	return ctx, nil
 }
@@ -62,9 +62,16 @@ func RunCmd(bin string, args, env []string) (Result, error) {
 // RunCmdIn runs bin like RunCmd, but with dir as the working directory
 // of the process. An empty dir inherits the caller's cwd.
 func RunCmdIn(bin, dir string, args, env []string) (Result, error) {
+	return RunCmdWithStdin(bin, dir, "", args, env)
+}
+
+// RunCmdWithStdin runs bin like RunCmdIn, feeding stdin to the process.
+// An empty stdin is an immediately closed stdin, like RunCmdIn's.
+func RunCmdWithStdin(bin, dir, stdin string, args, env []string) (Result, error) {
 	cmd := exec.Command(bin, args...)
 	cmd.Dir = dir
 	cmd.Env = mergeEnv(env)
+	cmd.Stdin = strings.NewReader(stdin)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -92,6 +92,16 @@ func TestRunCmdCapturesResult(t *testing.T) {
 	}
 }
 
+func TestRunCmdWithStdinFeedsStdin(t *testing.T) {
+	res, err := support.RunCmdWithStdin("sh", "", "one\ntwo\n", []string{"-c", "wc -l"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(res.Stdout) != "2" {
+		t.Errorf("stdin not fed: stdout = %q, want 2 lines counted", res.Stdout)
+	}
+}
+
 func TestRunCmdExtraEnvOverridesInherited(t *testing.T) {
 	res, err := support.RunCmd("sh", []string{"-c", "printf %s \"$MT_TEST_VAR\""}, []string{"MT_TEST_VAR=overridden"})
 	if err != nil {
@@ -0,0 +1,134 @@
+// Package agenda holds the pure logic of `mt agenda`: the day-by-day view
+// of what is coming — deferrals ending, deadlines due and recurring
+// Issues spawning their next occurrence — over a window
+// of days from an injected now, with the Issues in progress on top. It
+// is decision-dense (calendar windows, zones, ordering), so it lives at
+// Seam 2: black-box unit tested, with the coverage and mutation gates.
+// Loading the vaults is a process concern and stays in internal/cli.
+package agenda
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/Sanmoo/my-tasks2/internal/deferral"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/list"
+)
+
+// Kind is what happens to an Issue at an Event.
+type Kind string
+
+// The kinds of Event, in the order they are listed within one minute.
+const (
+	// Deferral is the end of a deferral: the Issue becomes available.
+	Deferral Kind = "defer"
+	// Deadline is a deadline falling due.
+	Deadline Kind = "deadline"
+	// Spawn is the next occurrence of a recurring Issue falling due: the
+	// Issue mt done spawns when it closes the current one, dated one
+	// recur period after the current deadline (else deferral).
+	Spawn Kind = "spawn"
+)
+
+// Event is one dated happening of an Issue.
+type Event struct {
+	Kind Kind
+	At   time.Time
+	Item list.Item
+}
+
+// Day is the Events of one calendar day, in time order. Date is the start
+// of the day.
+type Day struct {
+	Date   time.Time
+	Events []Event
+}
+
+// Events returns the deferral, deadline and spawn Events of the Issues
+// that are not done, in item order, each datetime read in loc — the
+// vault's zone, where its naive values are wall-clock times (see
+// issue.ParseStamp). Empty and malformed values have no Event, nor does a
+// recurring Issue with neither date; mt check owns them.
+func Events(items []list.Item, loc *time.Location) []Event {
+	var events []Event
+	for _, it := range items {
+		fm := it.Issue.Frontmatter
+		if fm.Status == "done" {
+			continue
+		}
+		if t, ok := issue.ParseStamp(fm.DeferredUntil, loc); ok {
+			events = append(events, Event{Kind: Deferral, At: t, Item: it})
+		}
+		deadline, hasDeadline := issue.ParseStamp(fm.Deadline, loc)
+		if hasDeadline {
+			events = append(events, Event{Kind: Deadline, At: deadline, Item: it})
+		}
+		if fm.Recur == "" {
+			continue
+		}
+		anchor, ok := deadline, hasDeadline
+		if !ok {
+			anchor, ok = issue.ParseStamp(fm.DeferredUntil, loc)
+		}
+		if next, err := deferral.Recur(fm.Recur, anchor); ok && err == nil {
+			events = append(events, Event{Kind: Spawn, At: next, Item: it})
+		}
+	}
+	return events
+}
+
+// Days groups the events within days calendar days, today first, into
+// the Days that have any. The calendar is that of now's location, into
+// which every event is converted; today is whole, so an event earlier
+// today is kept. Within a day, events are ordered by time, then Kind,
+// then Issue ID.
+func Days(events []Event, now time.Time, days int) []Day {
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	end := start.AddDate(0, 0, days)
+	var within []Event
+	for _, e := range events {
+		e.At = e.At.In(now.Location())
+		if !e.At.Before(start) && e.At.Before(end) {
+			within = append(within, e)
+		}
+	}
+	slices.SortStableFunc(within, func(a, b Event) int {
+		return cmp.Or(a.At.Compare(b.At), cmp.Compare(kindOrder(a.Kind), kindOrder(b.Kind)), cmp.Compare(a.Item.ID, b.Item.ID))
+	})
+	var out []Day
+	for _, e := range within {
+		date := time.Date(e.At.Year(), e.At.Month(), e.At.Day(), 0, 0, 0, 0, e.At.Location())
+		if len(out) == 0 || !out[len(out)-1].Date.Equal(date) {
+			out = append(out, Day{Date: date})
+		}
+		out[len(out)-1].Events = append(out[len(out)-1].Events, e)
+	}
+	return out
+}
+
+// kindOrder ranks k within one minute: a deferral ending, then a
+// deadline, then a spawn at the same time.
+func kindOrder(k Kind) int {
+	switch k {
+	case Deferral:
+		return 0
+	case Deadline:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// InProgress returns the in_progress items, in item order: the work of
+// today, on top of the agenda.
+func InProgress(items []list.Item) []list.Item {
+	var out []list.Item
+	for _, it := range items {
+		if it.Issue.Frontmatter.Status == "in_progress" {
+			out = append(out, it)
+		}
+	}
+	return out
+}
@@ -0,0 +1,145 @@
+// Package agenda_test holds the black-box unit tests of the mt agenda
+// pure logic (Seam 2): the deferral, deadline and spawn events, the calendar
+// window of days, the ordering within a day, and the in-progress top.
+package agenda_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Sanmoo/my-tasks2/internal/agenda"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/list"
+)
+
+// now is Sat 2026-08-15 14:30 UTC.
+var now = time.Date(2026, 8, 15, 14, 30, 0, 0, time.UTC)
+
+func item(id, status, deferredUntil, deadline string) list.Item {
+	return list.Item{ID: id, Issue: issue.Issue{Frontmatter: issue.Frontmatter{
+		Status:        status,
+		DeferredUntil: deferredUntil,
+		Deadline:      deadline,
+	}}}
+}
+
+// summary renders days as "MM-DD: HH:MM kind id" lines.
+func summary(days []agenda.Day) []string {
+	var out []string
+	for _, d := range days {
+		for _, e := range d.Events {
+			out = append(out, d.Date.Format("01-02")+": "+e.At.Format("15:04")+" "+string(e.Kind)+" "+e.Item.ID)
+		}
+	}
+	return out
+}
+
+func TestEvents(t *testing.T) {
+	items := []list.Item{
+		item("pkm-001", "open", "2026-08-16T09:00", "2026-08-20T18:00"),
+		item("pkm-002", "done", "2026-08-16T09:00", "2026-08-16T09:00"),
+		item("pkm-003", "in_progress", "", "2026-08-17T12:00Z"),
+		item("pkm-004", "open", "bogus", ""),
+	}
+	sp, _ := time.LoadLocation("America/Sao_Paulo")
+	events := agenda.Events(items, sp)
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Item.ID+" "+string(e.Kind)+" "+e.At.UTC().Format(time.RFC3339))
+	}
+	want := []string{
+		"pkm-001 defer 2026-08-16T12:00:00Z",
+		"pkm-001 deadline 2026-08-20T21:00:00Z",
+		"pkm-003 deadline 2026-08-17T12:00:00Z",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Events() = %q, want %q", got, want)
+	}
+}
+
+func recurring(id, deferredUntil, deadline, every string) list.Item {
+	it := item(id, "open", deferredUntil, deadline)
+	it.Issue.Frontmatter.Recur = every
+	return it
+}
+
+func TestSpawnEvents(t *testing.T) {
+	closed := recurring("pkm-005", "", "2026-08-15T18:00", "1d")
+	closed.Issue.Frontmatter.Status = "done"
+	items := []list.Item{
+		recurring("pkm-001", "2026-08-14T09:00", "2026-08-15T18:00", "1w"),
+		recurring("pkm-002", "2026-08-14T09:00", "", "1d"),
+		recurring("pkm-003", "", "", "1d"),
+		recurring("pkm-004", "", "2026-08-15T18:00", "weekly"),
+		closed,
+	}
+	var got []string
+	for _, e := range agenda.Events(items, time.UTC) {
+		if e.Kind == agenda.Spawn {
+			got = append(got, e.Item.ID+" "+e.At.Format(time.RFC3339))
+		}
+	}
+	// The spawn is one period after the deadline, else the deferral; a
+	// recurring Issue with neither date, a malformed recur or a done
+	// Issue has none.
+	want := []string{"pkm-001 2026-08-22T18:00:00Z", "pkm-002 2026-08-15T09:00:00Z"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("spawn Events() = %q, want %q", got, want)
+	}
+}
+
+func TestDays(t *testing.T) {
+	items := []list.Item{
+		item("pkm-001", "open", "2026-08-15T08:00", ""),
+		item("pkm-002", "open", "", "2026-08-16T09:00"),
+		item("pkm-003", "open", "2026-08-16T09:00", "2026-08-21T23:59"),
+		item("pkm-004", "open", "2026-08-14T23:59", "2026-08-22T00:00"),
+		item("pkm-005", "open", "", "2026-08-16T09:00"),
+		recurring("pkm-006", "", "2026-08-15T09:00", "1d"),
+	}
+	got := summary(agenda.Days(agenda.Events(items, time.UTC), now, 7))
+	want := []string{
+		"08-15: 08:00 defer pkm-001",
+		"08-15: 09:00 deadline pkm-006",
+		"08-16: 09:00 defer pkm-003",
+		"08-16: 09:00 deadline pkm-002",
+		"08-16: 09:00 deadline pkm-005",
+		"08-16: 09:00 spawn pkm-006",
+		"08-21: 23:59 deadline pkm-003",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Days() = %q, want %q", got, want)
+	}
+	if days := agenda.Days(agenda.Events(items, time.UTC), now, 1); len(days) != 1 || !days[0].Date.Equal(time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Days(1) = %+v, want today only", days)
+	}
+	if days := agenda.Days(nil, now, 7); days != nil {
+		t.Errorf("Days(no events) = %+v, want none", days)
+	}
+}
+
+func TestDaysUsesTheCalendarOfNow(t *testing.T) {
+	sp, _ := time.LoadLocation("America/Sao_Paulo")
+	items := []list.Item{item("pkm-001", "open", "2026-08-16T01:00Z", "")}
+	got := summary(agenda.Days(agenda.Events(items, time.UTC), now.In(sp), 1))
+	if want := []string{"08-15: 22:00 defer pkm-001"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Days(Sao Paulo) = %q, want %q", got, want)
+	}
+}
+
+func TestInProgress(t *testing.T) {
+	items := []list.Item{
+		item("pkm-001", "open", "", ""),
+		item("pkm-002", "in_progress", "", ""),
+		item("pkm-003", "done", "", ""),
+		item("pkm-004", "in_progress", "", ""),
+	}
+	var got []string
+	for _, it := range agenda.InProgress(items) {
+		got = append(got, it.ID)
+	}
+	if want := []string{"pkm-002", "pkm-004"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("InProgress() = %q, want %q", got, want)
+	}
+}
@@ -1,5 +1,7 @@
 // Package check holds the pure validation and Rank-integrity rules of
-// `mt check`. Reading and writing Issue files remains a process concern in
+// `mt check`. Every rule yields structured Findings rather than stopping
+// at the first violation, so one run reports everything wrong with a
+// vault. Reading and writing Issue files remains a process concern in
 // internal/cli; these exported APIs are the Seam 2 unit-test boundary.
 package check
 
@@ -8,11 +10,14 @@ import (
 	"fmt"
 	"io"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/Sanmoo/my-tasks2/internal/deferral"
+	"github.com/Sanmoo/my-tasks2/internal/graph"
 	"github.com/Sanmoo/my-tasks2/internal/issue"
 )
 
@@ -22,6 +27,155 @@ type Item struct {
 	Issue issue.Issue
 }
 
+// File is one raw Issue file of a Vault: its ID and its bytes.
+type File struct {
+	ID   string
+	Data []byte
+}
+
+// Severity grades a Finding. Errors fail mt check (exit 1); warnings are
+// reported and do not.
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// The rules a Finding can break, stable identifiers for tools.
+const (
+	RuleMalformed       = "malformed-frontmatter"
+	RuleUnknownField    = "unknown-field"
+	RuleEmptyOptional   = "empty-optional-field"
+	RuleRankNotInteger  = "rank-not-integer"
+	RuleMissingField    = "missing-field"
+	RuleUnknownStatus   = "unknown-status"
+	RuleInvalidDatetime = "invalid-datetime"
+	RuleUnknownBlocker  = "unknown-blocker"
+	RuleSelfBlock       = "self-block"
+	RuleCycle           = "blocked-by-cycle"
+	RuleNonPositiveRank = "non-positive-rank"
+	RuleDuplicateRank   = "duplicate-rank"
+	RuleRankGap         = "rank-gap"
+	RuleInvalidTemplate = "invalid-template"
+	RuleTemplateBlocker = "template-unknown-blocker"
+	RuleFieldOrder      = "field-order"
+	RuleDuplicateLabel  = "duplicate-label"
+	RuleNoCompletedAt   = "missing-completed-at"
+	RuleInvalidField    = "invalid-field"
+	RuleUnknownFieldRef = "unknown-field-ref"
+)
+
+// Finding is one problem mt check found. ID names the Issue it is about
+// and Template the template; both are empty for a vault-wide finding
+// (a duplicate Rank, a Rank gap). Field is the frontmatter field at
+// fault, when there is one. Fixable findings are repaired by --fix.
+type Finding struct {
+	ID       string   `json:"id,omitempty"`
+	Template string   `json:"template,omitempty"`
+	Field    string   `json:"field,omitempty"`
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Fixable  bool     `json:"fixable"`
+}
+
+// Audit parses files and returns every finding about them, with the
+// Issues that parsed (for --fix). Findings come in report order: each
+// file's own findings in file order, then the blocked_by references, then
+// the Rank queue. A file that does not parse yields its frontmatter
+// finding and sits out the value checks, but still counts as an existing
+// Issue for blocked_by references; so does one with a non-integer Rank.
+// fields are the vault's custom field declarations, zone its timezone
+// setting, which sets the datetime layout.
+func Audit(files []File, statuses []string, fields []issue.FieldSpec, zone issue.Zone) ([]Finding, []Item) {
+	var findings []Finding
+	items := make([]Item, 0, len(files))
+	exists := make(map[string]bool, len(files))
+	for _, f := range files {
+		exists[f.ID] = true
+		fileFindings := FrontmatterFindings(f.Data, f.ID, fields)
+		findings = append(findings, fileFindings...)
+		// A file whose Rank is not an integer sits out too: YAML would
+		// read rank: 1.5 as some Rank and mislead the queue checks.
+		if slices.ContainsFunc(fileFindings, func(x Finding) bool {
+			return x.Rule == RuleMalformed || x.Rule == RuleRankNotInteger
+		}) {
+			continue
+		}
+		i, err := issue.Parse(f.Data)
+		if err != nil {
+			// A value of the wrong type (labels: x) only shows here.
+			findings = append(findings, malformed(f.ID, err))
+			continue
+		}
+		item := Item{ID: f.ID, Issue: i}
+		items = append(items, item)
+		findings = append(findings, ItemFindings(item, statuses, fields, zone)...)
+	}
+	findings = append(findings, blockedByFindings(items, exists)...)
+	findings = append(findings, fieldRefFindings(items, exists, fields)...)
+	findings = append(findings, RankFindings(items)...)
+	return findings, items
+}
+
+// Count returns how many findings are errors and how many warnings.
+func Count(findings []Finding) (errors, warnings int) {
+	for _, f := range findings {
+		if f.Severity == Error {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+// Fixable reports whether --fix can repair every error of findings: a
+// vault with an error no fixer handles (a malformed file, an unknown
+// field, a cycle) is left untouched, since rewriting it could lose data.
+func Fixable(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == Error && !f.Fixable {
+			return false
+		}
+	}
+	return true
+}
+
+// Group is the findings about one subject: an Issue ID, "template
+// <name>", or "vault" for the vault-wide ones.
+type Group struct {
+	Subject  string
+	Findings []Finding
+}
+
+// Grouped groups findings by subject, for the text report: Issues in
+// the order they first appear, then templates, then the vault.
+func Grouped(findings []Finding) []Group {
+	var issues, templates, vault []Group
+	add := func(groups *[]Group, subject string, f Finding) {
+		for i := range *groups {
+			if (*groups)[i].Subject == subject {
+				(*groups)[i].Findings = append((*groups)[i].Findings, f)
+				return
+			}
+		}
+		*groups = append(*groups, Group{Subject: subject, Findings: []Finding{f}})
+	}
+	for _, f := range findings {
+		switch {
+		case f.ID != "":
+			add(&issues, f.ID, f)
+		case f.Template != "":
+			add(&templates, "template "+f.Template, f)
+		default:
+			add(&vault, "vault", f)
+		}
+	}
+	return slices.Concat(issues, templates, vault)
+}
+
 // RankGap is one contiguous missing range in the ranked queue. Start and End
 // are inclusive; a single missing Rank has equal Start and End.
 type RankGap struct {
@@ -95,202 +249,343 @@ func NonPositiveRanks(items []Item) []int {
 	return values
 }
 
-var allowedFrontmatterFields = map[string]struct{}{
-	"title": {}, "status": {}, "labels": {}, "created_at": {}, "rank": {},
-	"deferred_until": {}, "deadline": {}, "started_at": {}, "completed_at": {},
-	"blocked_by": {},
-}
+// firstOptionalField is the index in issue.FieldNames of the first
+// optional field: from it on, fields (custom ones included) must be
+// omitted rather than left empty.
+const firstOptionalField = 4
 
-var optionalFrontmatterFields = map[string]struct{}{
-	"rank": {}, "deferred_until": {}, "deadline": {}, "started_at": {}, "completed_at": {},
-	"blocked_by": {},
+// malformed is the finding of a frontmatter that cannot be read at all.
+func malformed(id string, err error) Finding {
+	return Finding{ID: id, Rule: RuleMalformed, Severity: Error,
+		Message: fmt.Sprintf("malformed frontmatter for issue %s: %v", id, err)}
 }
 
-// ValidateFrontmatter validates the YAML mapping and schema-specific keys in
-// an Issue's raw Markdown file. It rejects unknown/forbidden fields, empty
-// optional fields, and additional YAML documents inside the frontmatter.
-func ValidateFrontmatter(data []byte, id string) error {
+// FrontmatterFindings validates the YAML mapping and schema-specific keys
+// in an Issue's raw Markdown file: every unknown/forbidden field, every
+// empty optional field and a non-integer rank. Custom fields are known
+// when fields declares them, and go after the built-in ones in
+// declaration order. A frontmatter that is not a single YAML mapping
+// yields one malformed-frontmatter finding.
+func FrontmatterFindings(data []byte, id string, fields []issue.FieldSpec) []Finding {
 	payload, err := frontmatterPayload(data)
 	if err != nil {
-		return fmt.Errorf("malformed frontmatter for issue %s: %w", id, err)
+		return []Finding{malformed(id, err)}
 	}
 	decoder := yaml.NewDecoder(bytes.NewReader(payload))
 	var document yaml.Node
 	if err := decoder.Decode(&document); err != nil {
-		return fmt.Errorf("malformed frontmatter for issue %s: %w", id, err)
+		return []Finding{malformed(id, err)}
 	}
 	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
-		return fmt.Errorf("malformed frontmatter for issue %s: expected a YAML mapping", id)
+		return []Finding{malformed(id, fmt.Errorf("expected a YAML mapping"))}
 	}
 	var extra yaml.Node
 	if err := decoder.Decode(&extra); err != io.EOF {
 		if err == nil {
-			return fmt.Errorf("malformed frontmatter for issue %s: multiple YAML documents", id)
+			err = fmt.Errorf("multiple YAML documents")
 		}
-		return fmt.Errorf("malformed frontmatter for issue %s: %w", id, err)
+		return []Finding{malformed(id, err)}
+	}
+	var findings []Finding
+	field := func(name, rule string, fixable bool, format string, args ...any) {
+		findings = append(findings, Finding{ID: id, Field: name, Rule: rule, Severity: Error, Fixable: fixable,
+			Message: fmt.Sprintf("malformed frontmatter for issue %s: "+format, append([]any{id}, args...)...)})
 	}
 	mapping := document.Content[0]
+	var order []int
 	for i := 0; i+1 < len(mapping.Content); i += 2 {
 		name := mapping.Content[i].Value
 		value := mapping.Content[i+1]
-		if _, ok := allowedFrontmatterFields[name]; !ok {
-			return fmt.Errorf("malformed frontmatter for issue %s: unknown field %q", id, name)
+		pos := slices.Index(issue.FieldNames, name)
+		if pos < 0 {
+			custom := slices.IndexFunc(fields, func(f issue.FieldSpec) bool { return f.Name == name })
+			if custom < 0 {
+				field(name, RuleUnknownField, false, "unknown field %q", name)
+				continue
+			}
+			pos = len(issue.FieldNames) + custom
 		}
-		_, optional := optionalFrontmatterFields[name]
-		if optional && value.Kind == yaml.ScalarNode &&
+		order = append(order, pos)
+		if pos >= firstOptionalField && value.Kind == yaml.ScalarNode &&
 			(value.Value == "" || value.Tag == "!!null") {
-			return fmt.Errorf("malformed frontmatter for issue %s: empty optional field %q must be omitted", id, name)
+			field(name, RuleEmptyOptional, true, "empty optional field %q must be omitted", name)
+			continue
 		}
 		if name == "rank" && value.Tag != "!!int" {
-			return fmt.Errorf("malformed frontmatter for issue %s: rank must be an integer", id)
+			field(name, RuleRankNotInteger, false, "rank must be an integer")
 		}
 	}
-	return nil
+	if !slices.IsSorted(order) {
+		findings = append(findings, Finding{ID: id, Rule: RuleFieldOrder, Severity: Warning, Fixable: true,
+			Message: fmt.Sprintf("frontmatter fields of issue %s are not in the canonical order", id)})
+	}
+	return findings
 }
 
-// ValidateItem validates the parsed frontmatter values of an Issue against
-// the Vault's configured statuses and the canonical naive datetime layout.
-func ValidateItem(item Item, statuses []string) error {
+// ItemFindings validates the parsed frontmatter values of an Issue: every
+// missing required field, a status outside the Vault's configured
+// statuses, every datetime not in the canonical layout of zone (those of
+// the work_log intervals included), a warn_before that is not a span, a
+// recur that is not a recurrence, and every declared custom field whose
+// value does not fit its type.
+func ItemFindings(item Item, statuses []string, fields []issue.FieldSpec, zone issue.Zone) []Finding {
 	fm := item.Issue.Frontmatter
-	switch {
-	case fm.Title == "":
-		return fmt.Errorf("malformed frontmatter for issue %s: missing title", item.ID)
-	case fm.Status == "":
-		return fmt.Errorf("malformed frontmatter for issue %s: missing status", item.ID)
-	case fm.Labels == nil:
-		return fmt.Errorf("malformed frontmatter for issue %s: missing labels", item.ID)
-	case fm.CreatedAt == "":
-		return fmt.Errorf("malformed frontmatter for issue %s: missing created_at", item.ID)
-	}
-	if !slices.Contains(statuses, fm.Status) {
-		return fmt.Errorf("status %q for issue %s is not configured (valid: %s)",
-			fm.Status, item.ID, strings.Join(statuses, ", "))
+	var findings []Finding
+	for _, req := range []struct {
+		name    string
+		missing bool
+	}{
+		{"title", fm.Title == ""},
+		{"status", fm.Status == ""},
+		{"labels", fm.Labels == nil},
+		{"created_at", fm.CreatedAt == ""},
+	} {
+		if req.missing {
+			findings = append(findings, Finding{ID: item.ID, Field: req.name, Rule: RuleMissingField, Severity: Error,
+				Message: fmt.Sprintf("malformed frontmatter for issue %s: missing %s", item.ID, req.name)})
+		}
+	}
+	if fm.Status != "" && !slices.Contains(statuses, fm.Status) {
+		findings = append(findings, Finding{ID: item.ID, Field: "status", Rule: RuleUnknownStatus, Severity: Error,
+			Message: fmt.Sprintf("status %q for issue %s is not configured (valid: %s)",
+				fm.Status, item.ID, strings.Join(statuses, ", "))})
 	}
 	for _, field := range []struct {
-		name     string
-		value    string
-		required bool
+		name  string
+		value string
 	}{
-		{name: "created_at", value: fm.CreatedAt, required: true},
+		{name: "created_at", value: fm.CreatedAt},
 		{name: "deferred_until", value: fm.DeferredUntil},
 		{name: "deadline", value: fm.Deadline},
 		{name: "started_at", value: fm.StartedAt},
 		{name: "completed_at", value: fm.CompletedAt},
 	} {
 		if field.value == "" {
-			if field.required {
-				return fmt.Errorf("invalid datetime for issue %s: %s is empty", item.ID, field.name)
-			}
 			continue
 		}
-		parsed, err := time.ParseInLocation(issue.NaiveLayout, field.value, time.Local)
-		if err != nil || parsed.Format(issue.NaiveLayout) != field.value {
-			return fmt.Errorf("invalid datetime for issue %s: %s=%q (want %s)",
-				item.ID, field.name, field.value, issue.NaiveLayout)
+		if canonical, ok := CanonicalDatetime(field.value, zone); !ok || canonical != field.value {
+			findings = append(findings, Finding{ID: item.ID, Field: field.name, Rule: RuleInvalidDatetime, Severity: Error, Fixable: ok,
+				Message: fmt.Sprintf("invalid datetime for issue %s: %s=%q (want %s)",
+					item.ID, field.name, field.value, zone.Layout())})
+		}
+	}
+	for n, entry := range fm.WorkLog {
+		start, end, closed := strings.Cut(entry, issue.WorkSep)
+		valid := func(v string) bool { canonical, ok := CanonicalDatetime(v, zone); return ok && canonical == v }
+		if !valid(start) || (closed && !valid(end)) || (!closed && n < len(fm.WorkLog)-1) {
+			findings = append(findings, Finding{ID: item.ID, Field: "work_log", Rule: RuleInvalidDatetime, Severity: Error,
+				Message: fmt.Sprintf("invalid work_log interval for issue %s: %q (want <start>%s<end> in %s; only the last may be open)",
+					item.ID, entry, issue.WorkSep, zone.Layout())})
+		}
+	}
+	if _, err := deferral.ParseSpan(fm.WarnBefore); fm.WarnBefore != "" && err != nil {
+		findings = append(findings, Finding{ID: item.ID, Field: "warn_before", Rule: RuleInvalidField, Severity: Error,
+			Message: fmt.Sprintf("invalid span for issue %s: warn_before=%q (want <n><unit>, e.g. 3d)", item.ID, fm.WarnBefore)})
+	}
+	if _, err := deferral.Recur(fm.Recur, time.Time{}); fm.Recur != "" && err != nil {
+		findings = append(findings, Finding{ID: item.ID, Field: "recur", Rule: RuleInvalidField, Severity: Error,
+			Message: fmt.Sprintf("invalid recurrence for issue %s: recur=%q (want <n><unit>, e.g. 1w)", item.ID, fm.Recur)})
+	}
+	if dups := duplicateLabels(fm.Labels); len(dups) > 0 {
+		findings = append(findings, Finding{ID: item.ID, Field: "labels", Rule: RuleDuplicateLabel, Severity: Warning, Fixable: true,
+			Message: fmt.Sprintf("issue %s repeats label %s", item.ID, strings.Join(dups, ", "))})
+	}
+	if fm.Status == "done" && fm.CompletedAt == "" {
+		findings = append(findings, Finding{ID: item.ID, Field: "completed_at", Rule: RuleNoCompletedAt, Severity: Warning, Fixable: true,
+			Message: fmt.Sprintf("done issue %s has no completed_at", item.ID)})
+	}
+	for _, spec := range fields {
+		if f, ok := customFinding(item.ID, spec, fm.Extra, zone); ok {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// customFinding checks the value of the custom field spec declares
+// against its type. An absent or empty field is fine: custom fields are
+// optional, and an empty key is a frontmatter finding.
+func customFinding(id string, spec issue.FieldSpec, extra issue.Fields, zone issue.Zone) (Finding, bool) {
+	v, ok := extra.Get(spec.Name)
+	if !ok || issue.IsEmpty(v) {
+		return Finding{}, false
+	}
+	invalid := func(want string, fixable bool) (Finding, bool) {
+		return Finding{ID: id, Field: spec.Name, Rule: RuleInvalidField, Severity: Error, Fixable: fixable,
+			Message: fmt.Sprintf("invalid %s for issue %s: %s=%q (want %s)", spec.Type, id, spec.Name, issue.FormatValue(v), want)}, true
+	}
+	text, isText := v.(string)
+	_, isInt := v.(int)
+	switch spec.Type {
+	case issue.TypeString:
+		if !isText && !isInt {
+			return invalid("text", false)
+		}
+	case issue.TypeInt:
+		if !isInt {
+			return invalid("an integer", false)
+		}
+	case issue.TypeEnum:
+		if (!isText && !isInt) || !slices.Contains(spec.Values, issue.FormatValue(v)) {
+			return invalid("one of "+strings.Join(spec.Values, ", "), false)
+		}
+	case issue.TypeDatetime:
+		if canonical, ok := CanonicalDatetime(text, zone); !isText || !ok || canonical != text {
+			return invalid(zone.Layout(), isText && ok)
+		}
+	case issue.TypeList:
+		if _, ok := v.([]string); !ok {
+			return invalid("a list like [a, b]", false)
+		}
+	case issue.TypeIDRef:
+		if !isText {
+			return invalid("an issue ID", false)
 		}
 	}
-	return nil
+	return Finding{}, false
 }
 
-// ValidateBlockedBy validates the blocked_by references of every Issue
-// in the Vault: every referenced ID must exist, no Issue may list
-// itself, and the reference graph must be acyclic. It returns the first
-// violation in a deterministic order — unknown references first (in
-// item order), then self-blocks, then cycles — because mt check
-// reports one problem per run.
-func ValidateBlockedBy(items []Item) error {
-	if err := missingBlockedByRefs(items); err != nil {
-		return err
+// fieldRefFindings reports every id-ref custom field naming an Issue
+// that is not in the vault.
+func fieldRefFindings(items []Item, exists map[string]bool, fields []issue.FieldSpec) []Finding {
+	var findings []Finding
+	for _, item := range items {
+		for _, spec := range fields {
+			if spec.Type != issue.TypeIDRef {
+				continue
+			}
+			v, _ := item.Issue.Frontmatter.Extra.Get(spec.Name)
+			if ref, ok := v.(string); ok && ref != "" && !exists[ref] {
+				findings = append(findings, Finding{ID: item.ID, Field: spec.Name, Rule: RuleUnknownFieldRef, Severity: Error,
+					Message: fmt.Sprintf("%s of issue %s references unknown issue %s", spec.Name, item.ID, ref)})
+			}
+		}
 	}
-	if err := selfBlockers(items); err != nil {
-		return err
+	return findings
+}
+
+// CanonicalDatetime returns value in the canonical layout of zone. ok is
+// false when value is not a datetime at all. Besides the layout itself,
+// it accepts the forms a migrated or hand-edited vault carries: seconds
+// (2026-08-15T09:30:00), an instant with Z or an offset (converted to the
+// zone's time, or kept with its offset under the offset setting), a
+// space instead of T, and a bare date (midnight, T00:00). A naive value
+// is a wall-clock time of the zone's location.
+func CanonicalDatetime(value string, zone issue.Zone) (string, bool) {
+	for _, layout := range []string{time.RFC3339, issue.OffsetLayout} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if zone.Offset() {
+				return zone.Format(t), true
+			}
+			return zone.Format(zone.In(t)), true
+		}
 	}
-	if cycle := blockedByCycle(items); len(cycle) > 0 {
-		return fmt.Errorf("blocked_by cycle: %s", strings.Join(cycle, " -> "))
+	for _, layout := range []string{
+		issue.NaiveLayout, "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02 15:04:05", "2006-01-02",
+	} {
+		if t, err := time.ParseInLocation(layout, value, zone.Location()); err == nil {
+			return zone.Format(t), true
+		}
+	}
+	return "", false
+}
+
+// duplicateLabels returns the labels listed more than once, each once,
+// in order of their first repeat.
+func duplicateLabels(labels []string) []string {
+	seen := make(map[string]bool, len(labels))
+	var dups []string
+	for _, l := range labels {
+		if seen[l] && !slices.Contains(dups, l) {
+			dups = append(dups, l)
+		}
+		seen[l] = true
 	}
-	return nil
+	return dups
 }
 
-// missingBlockedByRefs returns the first blocked_by reference to an ID
-// with no Issue file in the Vault.
-func missingBlockedByRefs(items []Item) error {
-	exists := make(map[string]struct{}, len(items))
+// BlockedByFindings validates the blocked_by references of every Issue
+// in the Vault: every referenced ID must exist, no Issue may list
+// itself, and the reference graph must be acyclic. Findings come in a
+// deterministic order — unknown references (in item order), then
+// self-blocks, then the first cycle of the graph.
+func BlockedByFindings(items []Item) []Finding {
+	exists := make(map[string]bool, len(items))
 	for _, item := range items {
-		exists[item.ID] = struct{}{}
+		exists[item.ID] = true
 	}
+	return blockedByFindings(items, exists)
+}
+
+func blockedByFindings(items []Item, exists map[string]bool) []Finding {
+	var findings []Finding
 	for _, item := range items {
 		for _, ref := range item.Issue.Frontmatter.BlockedBy {
-			if _, ok := exists[ref]; !ok {
-				return fmt.Errorf("blocked_by of issue %s references unknown issue %s", item.ID, ref)
+			if !exists[ref] {
+				findings = append(findings, Finding{ID: item.ID, Field: "blocked_by", Rule: RuleUnknownBlocker, Severity: Error, Fixable: true,
+					Message: fmt.Sprintf("blocked_by of issue %s references unknown issue %s", item.ID, ref)})
 			}
 		}
 	}
-	return nil
-}
-
-// selfBlockers returns an error for the first Issue that lists itself
-// in its own blocked_by.
-func selfBlockers(items []Item) error {
 	for _, item := range items {
 		if slices.Contains(item.Issue.Frontmatter.BlockedBy, item.ID) {
-			return fmt.Errorf("issue %s lists itself in blocked_by", item.ID)
+			findings = append(findings, Finding{ID: item.ID, Field: "blocked_by", Rule: RuleSelfBlock, Severity: Error,
+				Message: fmt.Sprintf("issue %s lists itself in blocked_by", item.ID)})
 		}
 	}
-	return nil
+	if cycle := blockedByCycle(items); len(cycle) > 0 {
+		findings = append(findings, Finding{ID: cycle[0], Field: "blocked_by", Rule: RuleCycle, Severity: Error,
+			Message: fmt.Sprintf("blocked_by cycle: %s", strings.Join(cycle, " -> "))})
+	}
+	return findings
 }
 
-// blockedByCycle returns one cycle of the blocked_by graph as an ordered
-// path (start -> ... -> start), or nil when the graph is acyclic. The
-// walk is deterministic: nodes are visited in item order and each node's
-// references in listed order, so a given Vault always yields the same
-// cycle. References are assumed to exist (missingBlockedByRefs runs
-// first); a self-reference would be caught by selfBlockers.
-func blockedByCycle(items []Item) []string {
-	refs := make(map[string][]string, len(items))
+// RankFindings validates the ranked queue: a non-positive Rank is an
+// error on its Issue, a duplicated Rank a vault-wide error naming the
+// Issues that share it, and a gap in 1..N a vault-wide warning. All are
+// fixable: --fix renormalizes the queue to 1..N.
+func RankFindings(items []Item) []Finding {
+	var findings []Finding
 	for _, item := range items {
-		refs[item.ID] = item.Issue.Frontmatter.BlockedBy
-	}
-	const (
-		unvisited = iota
-		inProgress
-		done
-	)
-	state := make(map[string]int, len(items))
-	var stack []string
-	// visit walks one node of the graph; it returns the first cycle
-	// reachable from id (as a path ending where it started), or nil.
-	var visit func(id string) []string
-	visit = func(id string) []string {
-		state[id] = inProgress
-		stack = append(stack, id)
-		for _, ref := range refs[id] {
-			switch state[ref] {
-			case inProgress:
-				// ref is on the current path: slice it out, close the
-				// loop, and report the cycle.
-				for i, on := range stack {
-					if on == ref {
-						return append(append([]string{}, stack[i:]...), ref)
-					}
-				}
-			case unvisited:
-				if cycle := visit(ref); len(cycle) > 0 {
-					return cycle
-				}
-			}
+		if rank := item.Issue.Frontmatter.Rank; rank != nil && *rank <= 0 {
+			findings = append(findings, Finding{ID: item.ID, Field: "rank", Rule: RuleNonPositiveRank, Severity: Error, Fixable: true,
+				Message: fmt.Sprintf("invalid rank for issue %s: %d (Ranks must be greater than zero)", item.ID, *rank)})
 		}
-		state[id] = done
-		stack = stack[:len(stack)-1]
-		return nil
 	}
+	byRank := make(map[int][]string)
 	for _, item := range items {
-		if state[item.ID] == unvisited {
-			if cycle := visit(item.ID); len(cycle) > 0 {
-				return cycle
+		if rank := item.Issue.Frontmatter.Rank; rank != nil {
+			byRank[*rank] = append(byRank[*rank], item.ID)
+		}
+	}
+	for _, rank := range DuplicateRanks(items) {
+		findings = append(findings, Finding{Field: "rank", Rule: RuleDuplicateRank, Severity: Error, Fixable: true,
+			Message: fmt.Sprintf("duplicate rank: %d (%s)", rank, strings.Join(byRank[rank], ", "))})
+	}
+	if gaps := RankGapRanges(items); len(gaps) > 0 {
+		values := make([]string, len(gaps))
+		for i, gap := range gaps {
+			values[i] = strconv.Itoa(gap.Start)
+			if gap.End != gap.Start {
+				values[i] += "-" + strconv.Itoa(gap.End)
 			}
 		}
+		findings = append(findings, Finding{Field: "rank", Rule: RuleRankGap, Severity: Warning, Fixable: true,
+			Message: "rank gap: " + strings.Join(values, ", ")})
+	}
+	return findings
+}
+
+// blockedByCycle returns one cycle of the blocked_by graph (see
+// graph.Graph.Cycle), or nil when the graph is acyclic. References are
+// assumed to exist (missingBlockedByRefs runs first); a self-reference
+// would be caught by selfBlockers.
+func blockedByCycle(items []Item) []string {
+	nodes := make([]graph.Node, len(items))
+	for i, item := range items {
+		nodes[i] = graph.Node{ID: item.ID, BlockedBy: item.Issue.Frontmatter.BlockedBy}
 	}
-	return nil
+	return graph.New(nodes).Cycle()
 }
 
 func frontmatterPayload(data []byte) ([]byte, error) {
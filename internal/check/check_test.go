@@ -93,7 +93,16 @@ func validFile() []byte {
 	return []byte("---\ntitle: title\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\nrank: 2\n---\nbody\n")
 }
 
-func TestValidateFrontmatter(t *testing.T) {
+// messages joins the messages of findings, one per line.
+func messages(findings []check.Finding) string {
+	var b strings.Builder
+	for _, f := range findings {
+		b.WriteString(f.Message + "\n")
+	}
+	return b.String()
+}
+
+func TestFrontmatterFindings(t *testing.T) {
 	tests := []struct {
 		name string
 		data []byte
@@ -115,29 +124,30 @@ func TestValidateFrontmatter(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := check.ValidateFrontmatter(tt.data, "pkm-001")
+			got := check.FrontmatterFindings(tt.data, "pkm-001", nil)
 			if tt.want == "" {
-				if err != nil {
-					t.Fatalf("ValidateFrontmatter() = %v, want nil", err)
+				if len(got) != 0 {
+					t.Fatalf("FrontmatterFindings() = %v, want none", got)
 				}
 				return
 			}
-			if err == nil || !strings.Contains(err.Error(), tt.want) {
-				t.Errorf("ValidateFrontmatter() error = %v, want substring %q", err, tt.want)
+			if len(got) != 1 || !strings.Contains(got[0].Message, tt.want) || got[0].ID != "pkm-001" || got[0].Severity != check.Error {
+				t.Errorf("FrontmatterFindings() = %v, want one error with substring %q", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestValidateItem(t *testing.T) {
+func TestItemFindings(t *testing.T) {
 	statuses := []string{"open", "in_progress", "done"}
 	base := item("pkm-001", "open", nil, "2026-01-01T10:00")
 	base.Issue.Frontmatter.DeferredUntil = "2026-01-02T10:00"
 	base.Issue.Frontmatter.Deadline = "2026-01-03T10:00"
 	base.Issue.Frontmatter.StartedAt = "2026-01-04T10:00"
 	base.Issue.Frontmatter.CompletedAt = "2026-01-05T10:00"
-	if err := check.ValidateItem(base, statuses); err != nil {
-		t.Fatalf("ValidateItem(valid) = %v, want nil", err)
+	base.Issue.Frontmatter.WarnBefore = "3d"
+	if got := check.ItemFindings(base, statuses, nil, issue.Zone{}); len(got) != 0 {
+		t.Fatalf("ItemFindings(valid) = %v, want none", got)
 	}
 
 	tests := []struct {
@@ -155,16 +165,65 @@ func TestValidateItem(t *testing.T) {
 		{"invalid deadline", func() check.Item { x := base; x.Issue.Frontmatter.Deadline = "bad"; return x }(), "deadline"},
 		{"invalid started_at", func() check.Item { x := base; x.Issue.Frontmatter.StartedAt = "bad"; return x }(), "started_at"},
 		{"invalid completed_at", func() check.Item { x := base; x.Issue.Frontmatter.CompletedAt = "bad"; return x }(), "completed_at"},
+		{"invalid warn_before", func() check.Item { x := base; x.Issue.Frontmatter.WarnBefore = "+3d"; return x }(), "warn_before"},
+		{"invalid work_log start", func() check.Item { x := base; x.Issue.Frontmatter.WorkLog = []string{"bad/2026-01-01T10:00"}; return x }(), "work_log"},
+		{"invalid work_log end", func() check.Item { x := base; x.Issue.Frontmatter.WorkLog = []string{"2026-01-01T10:00/bad"}; return x }(), "work_log"},
+		{"open work_log interval before the last", func() check.Item {
+			x := base
+			x.Issue.Frontmatter.WorkLog = []string{"2026-01-01T10:00", "2026-01-02T10:00"}
+			return x
+		}(), "only the last may be open"},
+		{"invalid recur", func() check.Item { x := base; x.Issue.Frontmatter.Recur = "weekly"; return x }(), "recur"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := check.ValidateItem(tt.item, statuses); err == nil || !strings.Contains(err.Error(), tt.want) {
-				t.Errorf("ValidateItem() error = %v, want substring %q", err, tt.want)
+			if got := check.ItemFindings(tt.item, statuses, nil, issue.Zone{}); len(got) != 1 || !strings.Contains(got[0].Message, tt.want) {
+				t.Errorf("ItemFindings() = %v, want one finding with substring %q", got, tt.want)
 			}
 		})
 	}
-	if err := check.ValidateItem(base, nil); err == nil || !strings.Contains(err.Error(), "valid:") {
-		t.Errorf("ValidateItem(empty statuses) = %v, want status-list error", err)
+	if got := check.ItemFindings(base, nil, nil, issue.Zone{}); len(got) != 1 || !strings.Contains(got[0].Message, "valid:") {
+		t.Errorf("ItemFindings(empty statuses) = %v, want status-list error", got)
+	}
+}
+
+func TestFixableFindings(t *testing.T) {
+	statuses := []string{"open", "done"}
+	tests := []struct {
+		name    string
+		finding func() []check.Finding
+		rule    string
+		sev     check.Severity
+		fixable bool
+	}{
+		{"empty optional field", func() []check.Finding {
+			return check.FrontmatterFindings([]byte("---\ntitle: t\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\ndeadline:\n---\n"), "pkm-001", nil)
+		}, check.RuleEmptyOptional, check.Error, true},
+		{"field order", func() []check.Finding {
+			return check.FrontmatterFindings([]byte("---\nstatus: open\ntitle: t\nlabels: []\ncreated_at: 2026-01-01T10:00\n---\n"), "pkm-001", nil)
+		}, check.RuleFieldOrder, check.Warning, true},
+		{"parseable datetime", func() []check.Finding {
+			return check.ItemFindings(item("pkm-001", "open", nil, "2026-01-01"), statuses, nil, issue.Zone{})
+		}, check.RuleInvalidDatetime, check.Error, true},
+		{"unparseable datetime", func() []check.Finding {
+			return check.ItemFindings(item("pkm-001", "open", nil, "soon"), statuses, nil, issue.Zone{})
+		}, check.RuleInvalidDatetime, check.Error, false},
+		{"duplicate label", func() []check.Finding {
+			x := item("pkm-001", "open", nil, "2026-01-01T10:00")
+			x.Issue.Frontmatter.Labels = []string{"a", "b", "a", "a"}
+			return check.ItemFindings(x, statuses, nil, issue.Zone{})
+		}, check.RuleDuplicateLabel, check.Warning, true},
+		{"done without completed_at", func() []check.Finding {
+			return check.ItemFindings(item("pkm-001", "done", nil, "2026-01-01T10:00"), statuses, nil, issue.Zone{})
+		}, check.RuleNoCompletedAt, check.Warning, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.finding()
+			if len(got) != 1 || got[0].Rule != tt.rule || got[0].Severity != tt.sev || got[0].Fixable != tt.fixable {
+				t.Errorf("findings = %+v, want one %s %s, fixable %v", got, tt.sev, tt.rule, tt.fixable)
+			}
+		})
 	}
 }
 
@@ -175,14 +234,14 @@ func blockedByItem(id string, blockedBy []string) check.Item {
 	return it
 }
 
-func TestValidateBlockedBy(t *testing.T) {
+func TestBlockedByFindings(t *testing.T) {
 	t.Run("valid vault", func(t *testing.T) {
 		items := []check.Item{
 			blockedByItem("pkm-001", nil),
 			blockedByItem("pkm-002", []string{"pkm-001"}),
 		}
-		if err := check.ValidateBlockedBy(items); err != nil {
-			t.Errorf("ValidateBlockedBy() = %v, want nil", err)
+		if got := check.BlockedByFindings(items); len(got) != 0 {
+			t.Errorf("BlockedByFindings() = %v, want none", got)
 		}
 	})
 
@@ -192,8 +251,8 @@ func TestValidateBlockedBy(t *testing.T) {
 			blockedByItem("pkm-002", []string{"pkm-001"}),
 		}
 		items[0].Issue.Frontmatter.Status = "done"
-		if err := check.ValidateBlockedBy(items); err != nil {
-			t.Errorf("ValidateBlockedBy() = %v, want nil", err)
+		if got := check.BlockedByFindings(items); len(got) != 0 {
+			t.Errorf("BlockedByFindings() = %v, want none", got)
 		}
 	})
 
@@ -202,9 +261,9 @@ func TestValidateBlockedBy(t *testing.T) {
 			blockedByItem("pkm-001", nil),
 			blockedByItem("pkm-002", []string{"pkm-999"}),
 		}
-		err := check.ValidateBlockedBy(items)
-		if err == nil || !strings.Contains(err.Error(), "pkm-002") || !strings.Contains(err.Error(), "pkm-999") {
-			t.Errorf("ValidateBlockedBy() error = %v, want unknown-reference error naming both IDs", err)
+		got := messages(check.BlockedByFindings(items))
+		if got == "" || !strings.Contains(got, "pkm-002") || !strings.Contains(got, "pkm-999") {
+			t.Errorf("BlockedByFindings() = %v, want unknown-reference error naming both IDs", got)
 		}
 	})
 
@@ -212,9 +271,9 @@ func TestValidateBlockedBy(t *testing.T) {
 		items := []check.Item{
 			blockedByItem("pkm-001", []string{"pkm-001"}),
 		}
-		err := check.ValidateBlockedBy(items)
-		if err == nil || !strings.Contains(err.Error(), "pkm-001") || !strings.Contains(err.Error(), "itself") {
-			t.Errorf("ValidateBlockedBy() error = %v, want self-block error", err)
+		got := messages(check.BlockedByFindings(items))
+		if got == "" || !strings.Contains(got, "pkm-001") || !strings.Contains(got, "itself") {
+			t.Errorf("BlockedByFindings() = %v, want self-block error", got)
 		}
 	})
 
@@ -223,10 +282,10 @@ func TestValidateBlockedBy(t *testing.T) {
 			blockedByItem("pkm-001", []string{"pkm-002"}),
 			blockedByItem("pkm-002", []string{"pkm-001"}),
 		}
-		err := check.ValidateBlockedBy(items)
-		if err == nil || !strings.Contains(err.Error(), "cycle") ||
-			!strings.Contains(err.Error(), "pkm-001 -> pkm-002 -> pkm-001") {
-			t.Errorf("ValidateBlockedBy() error = %v, want the two-cycle path", err)
+		got := messages(check.BlockedByFindings(items))
+		if got == "" || !strings.Contains(got, "cycle") ||
+			!strings.Contains(got, "pkm-001 -> pkm-002 -> pkm-001") {
+			t.Errorf("BlockedByFindings() = %v, want the two-cycle path", got)
 		}
 	})
 
@@ -236,10 +295,10 @@ func TestValidateBlockedBy(t *testing.T) {
 			blockedByItem("pkm-002", []string{"pkm-003"}),
 			blockedByItem("pkm-003", []string{"pkm-001"}),
 		}
-		err := check.ValidateBlockedBy(items)
-		if err == nil || !strings.Contains(err.Error(), "cycle") ||
-			!strings.Contains(err.Error(), "pkm-001 -> pkm-002 -> pkm-003 -> pkm-001") {
-			t.Errorf("ValidateBlockedBy() error = %v, want the three-cycle path", err)
+		got := messages(check.BlockedByFindings(items))
+		if got == "" || !strings.Contains(got, "cycle") ||
+			!strings.Contains(got, "pkm-001 -> pkm-002 -> pkm-003 -> pkm-001") {
+			t.Errorf("BlockedByFindings() = %v, want the three-cycle path", got)
 		}
 	})
 
@@ -250,10 +309,10 @@ func TestValidateBlockedBy(t *testing.T) {
 			blockedByItem("pkm-003", []string{"pkm-004"}),
 			blockedByItem("pkm-004", []string{"pkm-003"}),
 		}
-		err := check.ValidateBlockedBy(items)
-		if err == nil || !strings.Contains(err.Error(), "cycle") ||
-			!strings.Contains(err.Error(), "pkm-003 -> pkm-004 -> pkm-003") {
-			t.Errorf("ValidateBlockedBy() error = %v, want the inner cycle", err)
+		got := messages(check.BlockedByFindings(items))
+		if got == "" || !strings.Contains(got, "cycle") ||
+			!strings.Contains(got, "pkm-003 -> pkm-004 -> pkm-003") {
+			t.Errorf("BlockedByFindings() = %v, want the inner cycle", got)
 		}
 	})
 
@@ -265,10 +324,10 @@ func TestValidateBlockedBy(t *testing.T) {
 			blockedByItem("pkm-002", []string{"pkm-003"}),
 			blockedByItem("pkm-003", []string{"pkm-002"}),
 		}
-		err := check.ValidateBlockedBy(items)
-		if err == nil || !strings.Contains(err.Error(), "cycle") ||
-			!strings.Contains(err.Error(), "pkm-002 -> pkm-003 -> pkm-002") {
-			t.Errorf("ValidateBlockedBy() error = %v, want the later cycle", err)
+		got := messages(check.BlockedByFindings(items))
+		if got == "" || !strings.Contains(got, "cycle") ||
+			!strings.Contains(got, "pkm-002 -> pkm-003 -> pkm-002") {
+			t.Errorf("BlockedByFindings() = %v, want the later cycle", got)
 		}
 	})
 
@@ -283,8 +342,8 @@ func TestValidateBlockedBy(t *testing.T) {
 			blockedByItem("pkm-003", nil),
 			blockedByItem("pkm-004", []string{"pkm-001"}),
 		}
-		if err := check.ValidateBlockedBy(items); err != nil {
-			t.Errorf("ValidateBlockedBy() = %v, want nil", err)
+		if got := check.BlockedByFindings(items); len(got) != 0 {
+			t.Errorf("BlockedByFindings() = %v, want none", got)
 		}
 	})
 
@@ -294,9 +353,234 @@ func TestValidateBlockedBy(t *testing.T) {
 			blockedByItem("pkm-002", []string{"pkm-001"}),
 			blockedByItem("pkm-003", []string{"pkm-999"}),
 		}
-		err := check.ValidateBlockedBy(items)
-		if err == nil || !strings.Contains(err.Error(), "unknown issue pkm-999") {
-			t.Errorf("ValidateBlockedBy() error = %v, want the unknown reference first", err)
+		got := check.BlockedByFindings(items)
+		if len(got) != 2 || got[0].Rule != check.RuleUnknownBlocker || !strings.Contains(got[0].Message, "unknown issue pkm-999") ||
+			got[1].Rule != check.RuleCycle || got[1].ID != "pkm-001" {
+			t.Errorf("BlockedByFindings() = %v, want the unknown reference, then the cycle", got)
 		}
 	})
 }
+
+func TestBlockedByFindingsReportsEveryViolation(t *testing.T) {
+	items := []check.Item{
+		blockedByItem("pkm-001", []string{"pkm-001", "pkm-998"}),
+		blockedByItem("pkm-002", []string{"pkm-999"}),
+	}
+	got := check.BlockedByFindings(items)
+	var rules []string
+	for _, f := range got {
+		rules = append(rules, f.ID+" "+f.Rule)
+	}
+	want := "pkm-001 unknown-blocker,pkm-002 unknown-blocker,pkm-001 self-block,pkm-001 blocked-by-cycle"
+	if strings.Join(rules, ",") != want {
+		t.Errorf("BlockedByFindings() = %v, want %s", rules, want)
+	}
+}
+
+func TestFrontmatterFindingsReportsEveryField(t *testing.T) {
+	data := []byte("---\ntitle: t\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\nid: x\nupdated_at: y\nrank: 1.5\ndeadline:\n---\n")
+	got := check.FrontmatterFindings(data, "pkm-001", nil)
+	var fields []string
+	for _, f := range got {
+		fields = append(fields, f.Field+" "+f.Rule)
+	}
+	want := "id unknown-field,updated_at unknown-field,rank rank-not-integer,deadline empty-optional-field"
+	if strings.Join(fields, ",") != want {
+		t.Errorf("FrontmatterFindings() = %v, want %s", fields, want)
+	}
+	if !strings.Contains(got[0].Message, `malformed frontmatter for issue pkm-001: unknown field "id"`) {
+		t.Errorf("message = %q", got[0].Message)
+	}
+}
+
+func TestItemFindingsReportsEveryField(t *testing.T) {
+	it := check.Item{ID: "pkm-001", Issue: issue.Issue{Frontmatter: issue.Frontmatter{
+		Status: "blocked", Deadline: "bad", StartedAt: "2026-01-01",
+	}}}
+	got := check.ItemFindings(it, []string{"open"}, nil, issue.Zone{})
+	var fields []string
+	for _, f := range got {
+		fields = append(fields, f.Field+" "+f.Rule)
+	}
+	want := "title missing-field,labels missing-field,created_at missing-field,status unknown-status,deadline invalid-datetime,started_at invalid-datetime"
+	if strings.Join(fields, ",") != want {
+		t.Errorf("ItemFindings() = %v, want %s", fields, want)
+	}
+}
+
+func TestRankFindings(t *testing.T) {
+	items := []check.Item{
+		item("pkm-001", "open", intPtr(1), ""),
+		item("pkm-002", "open", intPtr(1), ""),
+		item("pkm-003", "open", intPtr(0), ""),
+		item("pkm-004", "open", intPtr(4), ""),
+		item("pkm-005", "open", intPtr(7), ""),
+	}
+	got := check.RankFindings(items)
+	want := []check.Finding{
+		{ID: "pkm-003", Field: "rank", Rule: check.RuleNonPositiveRank, Severity: check.Error, Fixable: true,
+			Message: "invalid rank for issue pkm-003: 0 (Ranks must be greater than zero)"},
+		{Field: "rank", Rule: check.RuleDuplicateRank, Severity: check.Error, Fixable: true,
+			Message: "duplicate rank: 1 (pkm-001, pkm-002)"},
+		{Field: "rank", Rule: check.RuleRankGap, Severity: check.Warning, Fixable: true,
+			Message: "rank gap: 2-3, 5-6"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("RankFindings() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("RankFindings()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if got := check.RankFindings([]check.Item{item("a", "open", intPtr(1), ""), item("b", "open", nil, "")}); len(got) != 0 {
+		t.Errorf("RankFindings(valid queue) = %v", got)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	files := []check.File{
+		{ID: "pkm-001", Data: []byte("---\ntitle: a\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\nrank: 1\nblocked_by: [pkm-bad]\n---\n")},
+		{ID: "pkm-bad", Data: []byte("---\ntitle: [unclosed\n---\n")},
+		{ID: "pkm-lab", Data: []byte("---\ntitle: b\nstatus: open\nlabels: x\ncreated_at: 2026-01-01T10:00\n---\n")},
+		{ID: "pkm-frac", Data: []byte("---\ntitle: c\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\nrank: 1.5\n---\n")},
+		{ID: "pkm-003", Data: []byte("---\ntitle: d\nstatus: nope\nlabels: []\ncreated_at: 2026-01-01T10:00\nrank: 3\nblocked_by: [pkm-404]\n---\n")},
+	}
+	findings, items := check.Audit(files, []string{"open", "done"}, nil, issue.Zone{})
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.ID+" "+f.Rule)
+	}
+	want := []string{
+		"pkm-bad malformed-frontmatter",
+		"pkm-lab malformed-frontmatter",
+		"pkm-frac rank-not-integer",
+		"pkm-003 unknown-status",
+		"pkm-003 unknown-blocker",
+		" rank-gap",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Audit() = %v, want %v", got, want)
+	}
+	if len(items) != 2 || items[0].ID != "pkm-001" || items[1].ID != "pkm-003" {
+		t.Errorf("Audit() items = %v, want pkm-001 and pkm-003", items)
+	}
+
+	errs, warns := check.Count(findings)
+	if errs != 5 || warns != 1 {
+		t.Errorf("Count() = %d, %d; want 5, 1", errs, warns)
+	}
+	if check.Fixable(findings) {
+		t.Error("Fixable() = true with schema errors")
+	}
+	if !check.Fixable(check.RankFindings([]check.Item{item("a", "open", intPtr(2), ""), item("b", "open", intPtr(2), "")})) {
+		t.Error("Fixable() = false with only Rank findings")
+	}
+}
+
+func TestGrouped(t *testing.T) {
+	findings := []check.Finding{
+		{ID: "pkm-002", Message: "a"},
+		{Message: "vault one"},
+		{ID: "pkm-001", Message: "b"},
+		{Template: "bug", Message: "t"},
+		{ID: "pkm-002", Message: "c"},
+		{Message: "vault two"},
+	}
+	var got []string
+	for _, g := range check.Grouped(findings) {
+		got = append(got, g.Subject+":"+messages(g.Findings))
+	}
+	want := "pkm-002:a\nc\n|pkm-001:b\n|template bug:t\n|vault:vault one\nvault two\n"
+	if strings.Join(got, "|") != want {
+		t.Errorf("Grouped() = %q, want %q", strings.Join(got, "|"), want)
+	}
+	if len(check.Grouped(nil)) != 0 {
+		t.Error("Grouped(nil) is not empty")
+	}
+}
+
+var customSpecs = []issue.FieldSpec{
+	{Name: "energy", Type: issue.TypeEnum, Values: []string{"low", "high"}},
+	{Name: "estimate", Type: issue.TypeInt},
+	{Name: "url", Type: issue.TypeString},
+	{Name: "call_at", Type: issue.TypeDatetime},
+	{Name: "contexts", Type: issue.TypeList},
+	{Name: "client", Type: issue.TypeIDRef},
+}
+
+func TestFrontmatterFindingsKnowsDeclaredFields(t *testing.T) {
+	data := []byte("---\ntitle: t\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\nenergy: low\nurl:\nsize: 3\n---\n")
+	var got []string
+	for _, f := range check.FrontmatterFindings(data, "pkm-001", customSpecs) {
+		got = append(got, f.Field+" "+f.Rule)
+	}
+	if want := "url empty-optional-field,size unknown-field"; strings.Join(got, ",") != want {
+		t.Errorf("FrontmatterFindings() = %v, want %s", got, want)
+	}
+	data = []byte("---\ntitle: t\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\nurl: u\nenergy: low\n---\n")
+	if got := check.FrontmatterFindings(data, "pkm-001", customSpecs); len(got) != 1 || got[0].Rule != check.RuleFieldOrder {
+		t.Errorf("FrontmatterFindings(out of declaration order) = %v, want a field-order warning", got)
+	}
+	data = []byte("---\ntitle: t\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\nenergy: low\nblocked_by: [a]\n---\n")
+	if got := check.FrontmatterFindings(data, "pkm-001", customSpecs); len(got) != 1 || got[0].Rule != check.RuleFieldOrder {
+		t.Errorf("FrontmatterFindings(custom before built-in) = %v, want a field-order warning", got)
+	}
+}
+
+func TestItemFindingsChecksCustomFieldTypes(t *testing.T) {
+	statuses := []string{"open"}
+	base := item("pkm-001", "open", nil, "2026-01-01T10:00")
+	base.Issue.Frontmatter.Extra = issue.Fields{
+		{Name: "energy", Value: "low"},
+		{Name: "estimate", Value: 3},
+		{Name: "url", Value: 42},
+		{Name: "call_at", Value: "2026-01-02T10:00"},
+		{Name: "contexts", Value: []string{"phone"}},
+		{Name: "client", Value: "pkm-002"},
+		{Name: "undeclared", Value: map[string]any{}},
+		{Name: "empty", Value: nil},
+	}
+	if got := check.ItemFindings(base, statuses, customSpecs, issue.Zone{}); len(got) != 0 {
+		t.Fatalf("ItemFindings(valid custom fields) = %v, want none", got)
+	}
+	tests := []struct {
+		field   string
+		value   any
+		want    string
+		fixable bool
+	}{
+		{"energy", "medium", `invalid enum for issue pkm-001: energy="medium" (want one of low, high)`, false},
+		{"energy", []string{"low"}, "want one of", false},
+		{"estimate", "three", "want an integer", false},
+		{"url", []string{"a"}, "want text", false},
+		{"call_at", "2026-01-02", "invalid datetime for issue pkm-001: call_at=", true},
+		{"call_at", "soon", "want 2006-01-02T15:04", false},
+		{"call_at", 3, "want 2006-01-02T15:04", false},
+		{"contexts", "phone", "want a list like [a, b]", false},
+		{"client", 7, "want an issue ID", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field+"="+issue.FormatValue(tt.value), func(t *testing.T) {
+			x := item("pkm-001", "open", nil, "2026-01-01T10:00")
+			x.Issue.Frontmatter.Extra = issue.Fields{{Name: tt.field, Value: tt.value}}
+			got := check.ItemFindings(x, statuses, customSpecs, issue.Zone{})
+			if len(got) != 1 || got[0].Rule != check.RuleInvalidField || got[0].Field != tt.field ||
+				!strings.Contains(got[0].Message, tt.want) || got[0].Fixable != tt.fixable {
+				t.Errorf("ItemFindings() = %+v, want one invalid-field %q, fixable %v", got, tt.want, tt.fixable)
+			}
+		})
+	}
+}
+
+func TestAuditChecksCustomFieldReferences(t *testing.T) {
+	files := []check.File{
+		{ID: "pkm-001", Data: []byte("---\ntitle: a\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\nclient: pkm-002\n---\n")},
+		{ID: "pkm-002", Data: []byte("---\ntitle: b\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\nclient: pkm-404\n---\n")},
+	}
+	findings, _ := check.Audit(files, []string{"open"}, customSpecs, issue.Zone{})
+	if len(findings) != 1 || findings[0].ID != "pkm-002" || findings[0].Rule != check.RuleUnknownFieldRef ||
+		findings[0].Message != "client of issue pkm-002 references unknown issue pkm-404" {
+		t.Errorf("Audit() = %+v, want the unknown client of pkm-002", findings)
+	}
+}
@@ -0,0 +1,129 @@
+package check
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/priority"
+)
+
+// FileFix is the repair --fix plans for one Issue file: what it changes,
+// in order, and the repaired Issue to write. Writing it renders the
+// frontmatter in the canonical field order, dropping empty optional
+// fields.
+type FileFix struct {
+	ID      string      `json:"id"`
+	Changes []string    `json:"changes"`
+	Issue   issue.Issue `json:"-"`
+}
+
+// PlanFixes plans the repairs of files, in file order, for a vault whose
+// errors are all Fixable: canonical datetimes (custom datetime fields
+// included), empty optional fields omitted, unknown blocked_by references dropped, duplicate labels
+// dropped, completed_at stamped with now on done Issues that lack it,
+// canonical field order (custom fields in the declaration order of
+// fields), and the Ranks renormalized to 1..N. Datetimes take the layout
+// of zone, and now is in its location. Files that need no change are
+// left out; files that do not parse are skipped.
+func PlanFixes(files []File, fields []issue.FieldSpec, zone issue.Zone, now time.Time) []FileFix {
+	exists := make(map[string]bool, len(files))
+	for _, f := range files {
+		exists[f.ID] = true
+	}
+	var fixes []FileFix
+	var ranked []priority.Issue
+	for _, f := range files {
+		i, err := issue.Parse(f.Data)
+		if err != nil {
+			continue
+		}
+		fix := FileFix{ID: f.ID}
+		fm := &i.Frontmatter
+		for _, finding := range FrontmatterFindings(f.Data, f.ID, fields) {
+			switch finding.Rule {
+			case RuleEmptyOptional:
+				fix.Changes = append(fix.Changes, fmt.Sprintf("%s: omit empty field", finding.Field))
+				fm.Extra = slices.DeleteFunc(fm.Extra, func(x issue.Field) bool { return x.Name == finding.Field })
+			case RuleFieldOrder:
+				fix.Changes = append(fix.Changes, "reorder fields canonically")
+				fm.Extra.Order(fields)
+			}
+		}
+		for _, field := range datetimeFields(fm) {
+			if canonical, ok := CanonicalDatetime(*field.value, zone); ok && canonical != *field.value {
+				fix.Changes = append(fix.Changes, fmt.Sprintf("%s: %s → %s", field.name, *field.value, canonical))
+				*field.value = canonical
+			}
+		}
+		for n, x := range fm.Extra {
+			text, isText := x.Value.(string)
+			if spec := slices.IndexFunc(fields, func(s issue.FieldSpec) bool { return s.Name == x.Name }); !isText || spec < 0 || fields[spec].Type != issue.TypeDatetime {
+				continue
+			}
+			if canonical, ok := CanonicalDatetime(text, zone); ok && canonical != text {
+				fix.Changes = append(fix.Changes, fmt.Sprintf("%s: %s → %s", x.Name, text, canonical))
+				fm.Extra[n].Value = canonical
+			}
+		}
+		if refs := slices.DeleteFunc(slices.Clone(fm.BlockedBy), func(ref string) bool { return !exists[ref] }); len(refs) != len(fm.BlockedBy) {
+			for _, ref := range fm.BlockedBy {
+				if !exists[ref] {
+					fix.Changes = append(fix.Changes, fmt.Sprintf("blocked_by: drop unknown %s", ref))
+				}
+			}
+			fm.BlockedBy = refs
+			if len(refs) == 0 {
+				fm.BlockedBy = nil
+			}
+		}
+		if dups := duplicateLabels(fm.Labels); len(dups) > 0 {
+			var labels []string
+			for _, l := range fm.Labels {
+				if !slices.Contains(labels, l) {
+					labels = append(labels, l)
+				}
+			}
+			for _, l := range dups {
+				fix.Changes = append(fix.Changes, fmt.Sprintf("labels: drop duplicate %s", l))
+			}
+			fm.Labels = labels
+		}
+		if fm.Status == "done" && fm.CompletedAt == "" {
+			fm.CompletedAt = zone.Format(now)
+			fix.Changes = append(fix.Changes, fmt.Sprintf("completed_at: set to %s", fm.CompletedAt))
+		}
+		fix.Issue = i
+		fixes = append(fixes, fix)
+		ranked = append(ranked, priority.Issue{ID: f.ID, Title: fm.Title, Status: fm.Status, Rank: fm.Rank, CreatedAt: fm.CreatedAt})
+	}
+	for _, change := range priority.RenormalizeRanks(ranked) {
+		for n := range fixes {
+			if fixes[n].ID == change.ID {
+				fm := &fixes[n].Issue.Frontmatter
+				fixes[n].Changes = append(fixes[n].Changes, fmt.Sprintf("rank: %d → %d", *fm.Rank, *change.Rank))
+				fm.Rank = change.Rank
+			}
+		}
+	}
+	return slices.DeleteFunc(fixes, func(f FileFix) bool { return len(f.Changes) == 0 })
+}
+
+// datetimeField is one datetime field of a frontmatter, by name.
+type datetimeField struct {
+	name  string
+	value *string
+}
+
+// datetimeFields returns the built-in datetime fields of fm in canonical
+// order.
+func datetimeFields(fm *issue.Frontmatter) []datetimeField {
+	return []datetimeField{
+		{"created_at", &fm.CreatedAt},
+		{"deferred_until", &fm.DeferredUntil},
+		{"deadline", &fm.Deadline},
+		{"started_at", &fm.StartedAt},
+		{"completed_at", &fm.CompletedAt},
+	}
+}
@@ -0,0 +1,123 @@
+package check_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sanmoo/my-tasks2/internal/check"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+func TestCanonicalDatetime(t *testing.T) {
+	utc := time.Date(2026, 8, 15, 9, 30, 0, 0, time.UTC).In(time.Local).Format("2006-01-02T15:04")
+	tests := []struct {
+		value string
+		want  string
+		ok    bool
+	}{
+		{"2026-08-15T09:30", "2026-08-15T09:30", true},
+		{"2026-08-15T09:30:00", "2026-08-15T09:30", true},
+		{"2026-08-15 09:30", "2026-08-15T09:30", true},
+		{"2026-08-15 09:30:59", "2026-08-15T09:30", true},
+		{"2026-08-15", "2026-08-15T00:00", true},
+		{"2026-08-15T09:30:00Z", utc, true},
+		{"", "", false},
+		{"tomorrow", "", false},
+		{"2026-13-01", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := check.CanonicalDatetime(tt.value, issue.Zone{}); got != tt.want || ok != tt.ok {
+			t.Errorf("CanonicalDatetime(%q) = %q, %v; want %q, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCanonicalDatetimeInZone(t *testing.T) {
+	sp, _ := issue.LoadZone("America/Sao_Paulo")
+	offset, _ := issue.LoadZone("offset")
+	tests := []struct {
+		value string
+		zone  issue.Zone
+		want  string
+	}{
+		{"2026-08-15T12:00:00Z", sp, "2026-08-15T09:00"},
+		{"2026-08-15T12:00Z", sp, "2026-08-15T09:00"},
+		{"2026-08-15T09:00:00-03:00", offset, "2026-08-15T09:00-03:00"},
+		{"2026-08-15T09:00-03:00", offset, "2026-08-15T09:00-03:00"},
+		{"2026-08-15 09:00", sp, "2026-08-15T09:00"},
+	}
+	for _, tt := range tests {
+		if got, ok := check.CanonicalDatetime(tt.value, tt.zone); got != tt.want || !ok {
+			t.Errorf("CanonicalDatetime(%q, %s) = %q, %v; want %q", tt.value, tt.zone, got, ok, tt.want)
+		}
+	}
+}
+
+func TestPlanFixes(t *testing.T) {
+	now := time.Date(2026, 8, 16, 12, 0, 0, 0, time.Local)
+	files := []check.File{
+		{ID: "pkm-001", Data: []byte("---\ntitle: a\nstatus: open\nlabels: [casa, casa]\ncreated_at: 2026-01-01\nrank: 5\ndeadline:\nblocked_by: [pkm-002, pkm-404]\n---\nbody a\n")},
+		{ID: "pkm-002", Data: []byte("---\nstatus: done\ntitle: b\nlabels: []\ncreated_at: 2026-01-01T10:00\n---\n")},
+		{ID: "pkm-003", Data: []byte("---\ntitle: c\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\n---\n")},
+		{ID: "pkm-bad", Data: []byte("---\ntitle: [unclosed\n---\n")},
+	}
+	fixes := check.PlanFixes(files, nil, issue.Zone{}, now)
+	var got []string
+	for _, f := range fixes {
+		got = append(got, f.ID+": "+strings.Join(f.Changes, "; "))
+	}
+	want := []string{
+		"pkm-001: deadline: omit empty field; created_at: 2026-01-01 → 2026-01-01T00:00; blocked_by: drop unknown pkm-404; labels: drop duplicate casa; rank: 5 → 1",
+		"pkm-002: reorder fields canonically; completed_at: set to 2026-08-16T12:00",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("PlanFixes() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	fm := fixes[0].Issue.Frontmatter
+	if fm.CreatedAt != "2026-01-01T00:00" || *fm.Rank != 1 || len(fm.Labels) != 1 || len(fm.BlockedBy) != 1 || fm.BlockedBy[0] != "pkm-002" {
+		t.Errorf("fixed frontmatter = %+v", fm)
+	}
+	if fixes[0].Issue.Body != "body a\n" {
+		t.Errorf("fixed body = %q, want it kept", fixes[0].Issue.Body)
+	}
+	if fixes[1].Issue.Frontmatter.CompletedAt != "2026-08-16T12:00" {
+		t.Errorf("completed_at = %q", fixes[1].Issue.Frontmatter.CompletedAt)
+	}
+}
+
+func TestPlanFixesDropsEveryUnknownBlocker(t *testing.T) {
+	files := []check.File{
+		{ID: "pkm-001", Data: []byte("---\ntitle: a\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\nblocked_by: [pkm-404, pkm-405]\n---\n")},
+	}
+	fixes := check.PlanFixes(files, nil, issue.Zone{}, time.Now())
+	if len(fixes) != 1 || len(fixes[0].Changes) != 2 || fixes[0].Issue.Frontmatter.BlockedBy != nil {
+		t.Errorf("PlanFixes() = %+v, want both references dropped", fixes)
+	}
+	if fixes := check.PlanFixes([]check.File{{ID: "pkm-001", Data: validFile()}}, nil, issue.Zone{}, time.Now()); len(fixes) != 1 || fixes[0].Changes[0] != "rank: 2 → 1" {
+		t.Errorf("PlanFixes(valid, rank 2) = %+v", fixes)
+	}
+}
+
+func TestPlanFixesRepairsCustomFields(t *testing.T) {
+	specs := []issue.FieldSpec{
+		{Name: "energy", Type: issue.TypeEnum, Values: []string{"low"}},
+		{Name: "call_at", Type: issue.TypeDatetime},
+		{Name: "url", Type: issue.TypeString},
+	}
+	files := []check.File{
+		{ID: "pkm-001", Data: []byte("---\ntitle: a\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\ncall_at: 2026-01-02\nurl:\nenergy: low\n---\n")},
+	}
+	fixes := check.PlanFixes(files, specs, issue.Zone{}, time.Now())
+	if len(fixes) != 1 {
+		t.Fatalf("PlanFixes() = %+v, want one fix", fixes)
+	}
+	if got, want := strings.Join(fixes[0].Changes, "; "), "url: omit empty field; reorder fields canonically; call_at: 2026-01-02 → 2026-01-02T00:00"; got != want {
+		t.Errorf("Changes = %s, want %s", got, want)
+	}
+	want := issue.Fields{{Name: "energy", Value: "low"}, {Name: "call_at", Value: "2026-01-02T00:00"}}
+	if !reflect.DeepEqual(fixes[0].Issue.Frontmatter.Extra, want) {
+		t.Errorf("Extra = %#v, want %#v", fixes[0].Issue.Frontmatter.Extra, want)
+	}
+}
@@ -0,0 +1,91 @@
+package check
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+// PlanMigration plans the conversion of the datetimes of files — the
+// built-in ones, those of the work_log intervals and the custom fields
+// that fields declares datetime — to
+// the timezone setting to, in file order. A naive value is read as a
+// wall-clock time of from, the zone it was written in; a value with a UTC
+// offset is the instant it names. Under the offset setting, a value that
+// already carries an offset is kept as written. Values that are not
+// datetimes are mt check's, and files that need no change or do not
+// parse are left out.
+func PlanMigration(files []File, fields []issue.FieldSpec, from *time.Location, to issue.Zone) []FileFix {
+	var fixes []FileFix
+	for _, f := range files {
+		i, err := issue.Parse(f.Data)
+		if err != nil {
+			continue
+		}
+		fix := FileFix{ID: f.ID}
+		fm := &i.Frontmatter
+		for _, field := range datetimeFields(fm) {
+			if migrated, ok := migrateTime(*field.value, from, to); ok {
+				fix.Changes = append(fix.Changes, fmt.Sprintf("%s: %s → %s", field.name, *field.value, migrated))
+				*field.value = migrated
+			}
+		}
+		for n, entry := range fm.WorkLog {
+			if migrated, ok := migrateInterval(entry, from, to); ok {
+				fix.Changes = append(fix.Changes, fmt.Sprintf("work_log: %s → %s", entry, migrated))
+				fm.WorkLog[n] = migrated
+			}
+		}
+		for n, x := range fm.Extra {
+			text, isText := x.Value.(string)
+			if spec := slices.IndexFunc(fields, func(s issue.FieldSpec) bool { return s.Name == x.Name }); !isText || spec < 0 || fields[spec].Type != issue.TypeDatetime {
+				continue
+			}
+			if migrated, ok := migrateTime(text, from, to); ok {
+				fix.Changes = append(fix.Changes, fmt.Sprintf("%s: %s → %s", x.Name, text, migrated))
+				fm.Extra[n].Value = migrated
+			}
+		}
+		if len(fix.Changes) > 0 {
+			fix.Issue = i
+			fixes = append(fixes, fix)
+		}
+	}
+	return fixes
+}
+
+// migrateTime returns value converted to the setting to; ok is false when
+// it is unchanged or not a stored datetime. Under offset, a naive value
+// keeps its wall-clock time and gains the offset of from.
+func migrateTime(value string, from *time.Location, to issue.Zone) (string, bool) {
+	t, err := time.Parse(issue.OffsetLayout, value)
+	if err == nil && to.Offset() {
+		return "", false
+	}
+	if err != nil {
+		if t, err = time.ParseInLocation(issue.NaiveLayout, value, from); err != nil {
+			return "", false
+		}
+	}
+	if !to.Offset() {
+		t = to.In(t)
+	}
+	migrated := to.Format(t)
+	return migrated, migrated != value
+}
+
+// migrateInterval is migrateTime for a work_log interval: its start and,
+// when closed, its end, each converted on its own.
+func migrateInterval(entry string, from *time.Location, to issue.Zone) (string, bool) {
+	parts := strings.Split(entry, issue.WorkSep)
+	changed := false
+	for n, part := range parts {
+		if migrated, ok := migrateTime(part, from, to); ok {
+			parts[n], changed = migrated, true
+		}
+	}
+	return strings.Join(parts, issue.WorkSep), changed
+}
@@ -0,0 +1,56 @@
+package check_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Sanmoo/my-tasks2/internal/check"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+func TestPlanMigrationToNamedZone(t *testing.T) {
+	from, _ := time.LoadLocation("Europe/Lisbon")
+	to, _ := issue.LoadZone("America/Sao_Paulo")
+	fields := []issue.FieldSpec{{Name: "review_at", Type: issue.TypeDatetime}, {Name: "note", Type: issue.TypeString}}
+	files := []check.File{
+		{ID: "pkm-001", Data: []byte("---\ntitle: a\nstatus: open\ncreated_at: 2026-08-15T13:00\ndeadline: 2026-08-20T12:00Z\nwork_log: [2026-08-15T13:00/2026-08-15T14:00Z, 2026-08-16T13:00]\nreview_at: 2026-08-16T13:00\nnote: 2026-08-16T13:00\n---\n")},
+		{ID: "pkm-002", Data: []byte("---\ntitle: b\nstatus: open\ncreated_at: bogus\n---\n")},
+		{ID: "pkm-003", Data: []byte("not frontmatter")},
+	}
+	fixes := check.PlanMigration(files, fields, from, to)
+	if len(fixes) != 1 || fixes[0].ID != "pkm-001" {
+		t.Fatalf("PlanMigration() = %+v, want pkm-001 only", fixes)
+	}
+	want := []string{
+		"created_at: 2026-08-15T13:00 → 2026-08-15T09:00",
+		"deadline: 2026-08-20T12:00Z → 2026-08-20T09:00",
+		"work_log: 2026-08-15T13:00/2026-08-15T14:00Z → 2026-08-15T09:00/2026-08-15T11:00",
+		"work_log: 2026-08-16T13:00 → 2026-08-16T09:00",
+		"review_at: 2026-08-16T13:00 → 2026-08-16T09:00",
+	}
+	if !reflect.DeepEqual(fixes[0].Changes, want) {
+		t.Errorf("Changes = %q, want %q", fixes[0].Changes, want)
+	}
+	fm := fixes[0].Issue.Frontmatter
+	if fm.CreatedAt != "2026-08-15T09:00" || fm.Deadline != "2026-08-20T09:00" {
+		t.Errorf("migrated frontmatter = %+v", fm)
+	}
+	if got := fm.Extra[0].Value; got != "2026-08-16T09:00" {
+		t.Errorf("review_at = %v, want migrated", got)
+	}
+}
+
+func TestPlanMigrationToOffset(t *testing.T) {
+	from, _ := time.LoadLocation("America/Sao_Paulo")
+	to, _ := issue.LoadZone("offset")
+	files := []check.File{
+		{ID: "pkm-001", Data: []byte("---\ntitle: a\nstatus: open\ncreated_at: 2026-08-15T09:00\ndeadline: 2026-08-20T12:00+01:00\n---\n")},
+		{ID: "pkm-002", Data: []byte("---\ntitle: b\nstatus: open\ncreated_at: 2026-08-15T09:00-03:00\n---\n")},
+	}
+	fixes := check.PlanMigration(files, nil, from, to)
+	want := []string{"created_at: 2026-08-15T09:00 → 2026-08-15T09:00-03:00"}
+	if len(fixes) != 1 || !reflect.DeepEqual(fixes[0].Changes, want) {
+		t.Fatalf("PlanMigration() = %+v, want only the naive created_at given an offset", fixes)
+	}
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sanmoo/my-tasks2/internal/agenda"
+	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/list"
+	"github.com/Sanmoo/my-tasks2/internal/vault"
+)
+
+// newAgendaCmd builds `mt agenda`: the in-progress Issues, then the
+// deferrals ending, deadlines due and recurring spawns over the next
+// --days days, grouped by day. --all-vaults merges every bookmarked vault.
+func newAgendaCmd() *cobra.Command {
+	var days int
+	var allVaults bool
+	cmd := &cobra.Command{
+		Use:   "agenda",
+		Short: "Show the coming days: deferrals ending, deadlines due, recurring spawns",
+		Long:  agendaLong,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return exitcode.Usage(errors.New("agenda takes no arguments"))
+			}
+			if days < 1 {
+				return exitcode.Usage(fmt.Errorf("--days must be positive, got %d", days))
+			}
+			if allVaults && (bookmark != "" || cmd.Flags().Changed("vault")) {
+				return exitcode.Usage(errors.New("--all-vaults takes no @bookmark or --vault"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runAgenda(cmd, days, allVaults)
+		},
+	}
+	cmd.Flags().IntVar(&days, "days", 7, "number of days shown, today included")
+	cmd.Flags().BoolVar(&allVaults, "all-vaults", false, "merge every bookmarked vault")
+	return cmd
+}
+
+// runAgenda loads the Issues of the resolved vault, or of every bookmark,
+// and prints the agenda. A single vault is seen in its own timezone;
+// merged vaults in the machine's, each vault's datetimes read in its own.
+func runAgenda(cmd *cobra.Command, days int, allVaults bool) error {
+	var dirs []string
+	if allVaults {
+		path, home, err := globalConfigPath()
+		if err != nil {
+			return err
+		}
+		g, err := vault.LoadGlobal(path)
+		if err != nil {
+			return fmt.Errorf("loading global config: %w", err)
+		}
+		for _, name := range g.Names() {
+			dirs = append(dirs, vault.ExpandHome(g.Bookmarks[name], home))
+		}
+	} else {
+		vaultDir, err := resolveVault(cmd)
+		if err != nil {
+			return err
+		}
+		dirs = []string{vaultDir}
+	}
+	now, err := clock(vault.Vault{})
+	if err != nil {
+		return err
+	}
+	var inProgress []list.Item
+	var events []agenda.Event
+	for _, dir := range dirs {
+		vaultNow, _, err := vaultClock(dir)
+		if err != nil {
+			return err
+		}
+		if !allVaults {
+			now = vaultNow
+		}
+		items, err := loadSortedItems(dir)
+		if err != nil {
+			return err
+		}
+		inProgress = append(inProgress, agenda.InProgress(items)...)
+		events = append(events, agenda.Events(items, vaultNow.Location())...)
+	}
+
+	out := cmd.OutOrStdout()
+	if len(inProgress) > 0 {
+		fmt.Fprintln(out, "In progress:")
+		for _, it := range inProgress {
+			fmt.Fprintln(out, "  "+formatListLine(it))
+		}
+	}
+	for _, day := range agenda.Days(events, now, days) {
+		heading := day.Date.Format("Mon 01-02")
+		if day.Date.Day() == now.Day() && day.Date.Month() == now.Month() && day.Date.Year() == now.Year() {
+			heading += " (today)"
+		}
+		fmt.Fprintln(out, heading+":")
+		for _, e := range day.Events {
+			fmt.Fprintf(out, "  %s %-8s  %s\n", e.At.Format("15:04"), e.Kind, formatListLine(e.Item))
+		}
+	}
+	return nil
+}
+
+const agendaLong = `agenda shows what is coming: first the in_progress Issues, then, day by
+day over the next --days days (7 by default, today included), the
+deferrals ending (defer), the deadlines due (deadline) and the recurring
+spawns (spawn) of the Issues that are not done, each line with its time
+and the list line of the Issue. A spawn is the next occurrence of a
+recurring Issue (recur: in its frontmatter) falling due: mt done spawns
+it when it closes the current one, one period after the current
+deadline, else the deferral. Days without events are left out; an empty agenda prints nothing.
+Passed deadlines and expired deferrals of earlier days are mt overdue's.
+
+The days are those of the vault's timezone (timezone: in mt.yaml).
+--all-vaults merges every bookmark of the global config into one agenda,
+in the machine's timezone; it takes no @bookmark or --vault.`
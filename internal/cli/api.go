@@ -0,0 +1,353 @@
+// Package cli — the vault API: the reads and mutations the long-running
+// front ends (mt serve over HTTP, mt mcp over stdio) expose. They go
+// through the same paths as the commands (the pure packages,
+// writeIssueFile, the journal and the hooks) and fail with the same
+// errors; each front end only maps its requests onto them.
+package cli
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Sanmoo/my-tasks2/internal/deferral"
+	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/list"
+	"github.com/Sanmoo/my-tasks2/internal/priority"
+)
+
+// rankBacklog is the rank value that returns an Issue to the Backlog
+// (mt unrank).
+const rankBacklog = "backlog"
+
+// errNotFound marks the error of an operation on an Issue the vault does
+// not have, so a front end can answer it as such (404 over HTTP).
+var errNotFound = errors.New("not found")
+
+// vaultAPI is the operations of one vault. The write paths keep the state
+// of the running command in package vars (pending, hooks), so a front end
+// runs one operation at a time, each mutation inside operation.
+type vaultAPI struct {
+	vaultDir string
+}
+
+// operation runs op as one journaled operation, named by command: its
+// changes are journaled and its post-hooks run once it returns, like
+// those of a command in Run. Warnings go to log.
+func operation(command []string, log io.Writer, op func()) {
+	startJournal(command)
+	hooks = hookState{}
+	op()
+	flushJournal(log)
+	runPostHooks(log)
+	startJournal(nil)
+}
+
+// issueSummary is an Issue in a listing: its ID, its frontmatter and
+// whether a blocker is still open.
+type issueSummary struct {
+	ID string `json:"id"`
+	issue.Frontmatter
+	Blocked bool `json:"blocked"`
+}
+
+// issueDetail is one Issue: the summary plus the body and the ETag of its
+// file.
+type issueDetail struct {
+	issueSummary
+	Body string `json:"body"`
+	ETag string `json:"etag"`
+}
+
+// etagOf is the ETag of an Issue file's content.
+func etagOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// summarize builds the listing entries of items.
+func summarize(items []list.Item, statusByID map[string]string) []issueSummary {
+	out := make([]issueSummary, 0, len(items))
+	for _, it := range items {
+		out = append(out, issueSummary{
+			ID:          it.ID,
+			Frontmatter: it.Issue.Frontmatter,
+			Blocked:     list.Blocked(it.Issue.Frontmatter.BlockedBy, statusByID),
+		})
+	}
+	return out
+}
+
+// listFilter is the filters of the list view: the mt list flags.
+type listFilter struct {
+	All    bool     `json:"all"`
+	Status string   `json:"status"`
+	Labels []string `json:"labels"`
+	Where  string   `json:"where"`
+}
+
+// list is the mt list view.
+func (a vaultAPI) list(f listFilter) ([]issueSummary, error) {
+	q, err := parseWhere(a.vaultDir, f.Where)
+	if err != nil {
+		return nil, err
+	}
+	items, err := loadSortedItems(a.vaultDir)
+	if err != nil {
+		return nil, err
+	}
+	opts := list.Options{All: f.All, Status: f.Status, Labels: f.Labels}
+	var listed []list.Item
+	for _, it := range items {
+		if list.Visible(it, opts) && q.Match(it.ID, it.Issue.Frontmatter) {
+			listed = append(listed, it)
+		}
+	}
+	return summarize(listed, list.StatusByID(items)), nil
+}
+
+// ready is the mt ready view.
+func (a vaultAPI) ready() ([]issueSummary, error) {
+	items, err := loadSortedItems(a.vaultDir)
+	if err != nil {
+		return nil, err
+	}
+	statusByID := list.StatusByID(items)
+	now, _, err := vaultClock(a.vaultDir)
+	if err != nil {
+		return nil, err
+	}
+	var ready []list.Item
+	for _, it := range items {
+		if isReady(it, now, statusByID) {
+			ready = append(ready, it)
+		}
+	}
+	return summarize(ready, statusByID), nil
+}
+
+// overdue is the two groups of mt overdue: expired deferrals and passed
+// deadlines.
+func (a vaultAPI) overdue() (map[string][]issueSummary, error) {
+	items, err := loadSortedItems(a.vaultDir)
+	if err != nil {
+		return nil, err
+	}
+	now, _, err := vaultClock(a.vaultDir)
+	if err != nil {
+		return nil, err
+	}
+	statusByID := list.StatusByID(items)
+	expired, late := list.OverdueGroups(items, now)
+	return map[string][]issueSummary{
+		"expired":  summarize(expired, statusByID),
+		"deadline": summarize(late, statusByID),
+	}, nil
+}
+
+// issueData reads the file of the Issue id: a usage error for an invalid
+// ID, errNotFound when there is no such Issue.
+func (a vaultAPI) issueData(id string) ([]byte, error) {
+	if err := checkID(id); err != nil {
+		return nil, err
+	}
+	data, err := readIssueData(a.vaultDir, id)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("issue %s %w", id, errNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading issue %s: %w", id, err)
+	}
+	return data, nil
+}
+
+// show reads the Issue id, with its body and ETag.
+func (a vaultAPI) show(id string) (issueDetail, error) {
+	data, err := a.issueData(id)
+	if err != nil {
+		return issueDetail{}, err
+	}
+	i, err := issue.Parse(data)
+	if err != nil {
+		return issueDetail{}, fmt.Errorf("parsing issue %s: %w", id, err)
+	}
+	items, err := loadIndexedItems(a.vaultDir)
+	if err != nil {
+		return issueDetail{}, err
+	}
+	return issueDetail{
+		issueSummary: issueSummary{
+			ID:          id,
+			Frontmatter: i.Frontmatter,
+			Blocked:     list.Blocked(i.Frontmatter.BlockedBy, list.StatusByID(items)),
+		},
+		Body: i.Body,
+		ETag: etagOf(data),
+	}, nil
+}
+
+// mutate applies mutate to the Issue id through the batch path — the
+// pre-hooks vet it before it is written — and returns the changed Issue.
+func (a vaultAPI) mutate(id string, mutate func(id string, i issue.Issue) (issue.Issue, error)) (issueDetail, error) {
+	if _, err := a.issueData(id); err != nil {
+		return issueDetail{}, err
+	}
+	steps, err := planBatch(a.vaultDir, []string{id}, mutate)
+	if err != nil {
+		return issueDetail{}, err
+	}
+	if err := writeSteps(a.vaultDir, steps); err != nil {
+		return issueDetail{}, err
+	}
+	return a.show(id)
+}
+
+// createRequest is a new Issue: the title and the create flags.
+type createRequest struct {
+	Title       string   `json:"title"`
+	Labels      []string `json:"labels"`
+	Template    string   `json:"template"`
+	Deadline    string   `json:"deadline"`
+	Defer       string   `json:"defer"`
+	Rank        string   `json:"rank"`
+	BlockedBy   []string `json:"blocked_by"`
+	Status      string   `json:"status"`
+	Description string   `json:"description"`
+	Body        string   `json:"body"`
+}
+
+// create is mt create.
+func (a vaultAPI) create(req createRequest) (issueDetail, error) {
+	if strings.TrimSpace(req.Title) == "" {
+		return issueDetail{}, exitcode.Usage(errors.New("create needs a title"))
+	}
+	flags := createFlags{
+		labels:      req.Labels,
+		template:    req.Template,
+		deadline:    req.Deadline,
+		deferUntil:  req.Defer,
+		rank:        req.Rank,
+		blockedBy:   req.BlockedBy,
+		status:      req.Status,
+		description: req.Description,
+	}
+	if req.Body != "" {
+		flags.body = stdinBody
+	}
+	id, err := createIssue(a.vaultDir, req.Title, flags, strings.NewReader(req.Body))
+	if err != nil {
+		return issueDetail{}, err
+	}
+	return a.show(id)
+}
+
+// done is mt done, spawning the next occurrence of a recurring Issue.
+func (a vaultAPI) done(id string) (issueDetail, error) {
+	now, zone, err := vaultClock(a.vaultDir)
+	if err != nil {
+		return issueDetail{}, err
+	}
+	spawns := newSpawner(a.vaultDir, now, zone)
+	detail, err := a.mutate(id, func(id string, i issue.Issue) (issue.Issue, error) {
+		if err := spawns.plan(id, i); err != nil {
+			return issue.Issue{}, err
+		}
+		return i.Done(zone.Format(now)), nil
+	})
+	if err != nil {
+		return issueDetail{}, err
+	}
+	return detail, spawns.spawn(io.Discard)
+}
+
+// deferTo is mt defer, the time as mt defer takes it.
+func (a vaultAPI) deferTo(id, until string) (issueDetail, error) {
+	opts, now, err := timeOptions(a.vaultDir)
+	if err != nil {
+		return issueDetail{}, err
+	}
+	t, err := deferral.Parse(until, now, opts)
+	if err != nil {
+		return issueDetail{}, exitcode.Usage(err)
+	}
+	return a.mutate(id, func(_ string, i issue.Issue) (issue.Issue, error) {
+		return i.Defer(t), nil
+	})
+}
+
+// comment is mt comment, or a reply to the comment anchored replyTo.
+func (a vaultAPI) comment(id, text, replyTo string) (issueDetail, error) {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if err := issue.CheckCommentText(text); err != nil {
+		return issueDetail{}, exitcode.Usage(err)
+	}
+	clock, _, err := vaultClock(a.vaultDir)
+	if err != nil {
+		return issueDetail{}, err
+	}
+	now := clock.Format(issue.NaiveLayout)
+	return a.mutate(id, func(id string, i issue.Issue) (issue.Issue, error) {
+		anchor, err := issue.NextAnchor(rand.Reader, i.Body)
+		if err != nil {
+			return issue.Issue{}, err
+		}
+		if replyTo == "" {
+			i.Body = issue.AppendComment(i.Body, now, text, anchor)
+			return i, nil
+		}
+		if i.Body, err = issue.AppendReply(i.Body, now, text, anchor, replyTo); err != nil {
+			return issue.Issue{}, fmt.Errorf("issue %s: %w", id, err)
+		}
+		return i, nil
+	})
+}
+
+// dep is mt dep add, or mt dep rm with remove, through the edit of
+// depEdit.
+func (a vaultAPI) dep(id, blocker string, remove bool) (issueDetail, error) {
+	if err := checkID(blocker); err != nil {
+		return issueDetail{}, err
+	}
+	edit, err := depEdit(blocker, remove, func(id string) error {
+		_, err := a.issueData(id)
+		return err
+	})
+	if err != nil {
+		return issueDetail{}, err
+	}
+	return a.mutate(id, edit)
+}
+
+// rank is mt top, bottom, rank or — with "backlog" — unrank; placement is
+// "top", "bottom", a position or "backlog". Only the Issues whose rank
+// changes are written.
+func (a vaultAPI) rank(id, placement string) (issueDetail, error) {
+	action, position := priority.RemoveRank, 0
+	if placement != rankBacklog {
+		var err error
+		if action, position, err = priority.ParsePlacement(placement); err != nil {
+			return issueDetail{}, exitcode.Usage(err)
+		}
+	}
+	if _, err := a.issueData(id); err != nil {
+		return issueDetail{}, err
+	}
+	issues, err := loadPriorityIssues(a.vaultDir)
+	if err != nil {
+		return issueDetail{}, err
+	}
+	changes, err := priority.QuickPlan(issues, id, action, position)
+	if err != nil {
+		return issueDetail{}, err
+	}
+	if err := applyRankChanges(a.vaultDir, changes); err != nil {
+		return issueDetail{}, err
+	}
+	return a.show(id)
+}
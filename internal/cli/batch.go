@@ -0,0 +1,246 @@
+// Package cli — batch operations. done, status, defer, undefer, dep,
+// label and comment accept several Issues at once: explicit IDs (or handles), "-"
+// for IDs read from stdin, or a --where query. Every batch runs as one
+// plan: all targets are read and mutated in memory first, and a single
+// validation failure aborts the batch before any file is written. The
+// selector language lives in internal/query.
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+// batchConfirmThreshold is the largest batch applied without asking: a
+// plan touching more Issues than this needs a confirmation (or --yes).
+const batchConfirmThreshold = 5
+
+// batchSummaryIDs is how many IDs a batch summary names before it
+// counts the rest.
+const batchSummaryIDs = 5
+
+// stdinIDs is the argument that reads target IDs from stdin, one per
+// line (the first word of each line), as printed by list --format ids.
+const stdinIDs = "-"
+
+// batchFlags are the selector flags shared by the batch commands.
+type batchFlags struct {
+	where string
+	yes   bool
+}
+
+// register adds --where and --yes to cmd.
+func (f *batchFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.where, "where", "", `select the target Issues by query (e.g. "status=open labels=errands")`)
+	cmd.Flags().BoolVarP(&f.yes, "yes", "y", false, fmt.Sprintf("apply batches of more than %d Issues without asking", batchConfirmThreshold))
+}
+
+// batchStep is one Issue of a batch plan: its ID, the Issue as read and
+// the mutated Issue that will be written.
+type batchStep struct {
+	ID     string
+	Before issue.Issue
+	Issue  issue.Issue
+}
+
+// selectTargets resolves the target Issues of a batch command: with
+// --where, every Issue matching the query in the vault's list order
+// (positional IDs are then a usage error); otherwise ids, where "-"
+// expands to the IDs read from stdin and handles resolve through the last
+// listing. Duplicates collapse to their first occurrence; an empty
+// selection is a user error. fromStdin reports whether stdin was consumed.
+func selectTargets(cmd *cobra.Command, vaultDir string, ids []string, where string) (targets []string, fromStdin bool, err error) {
+	if where != "" {
+		if len(ids) > 0 {
+			return nil, false, exitcode.Usage(errors.New("--where selects the Issues itself: do not also pass issue IDs"))
+		}
+		targets, err = queryTargets(vaultDir, where)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(targets) == 0 {
+			return nil, false, fmt.Errorf("no issues match --where %q", where)
+		}
+		return targets, false, nil
+	}
+	for _, arg := range ids {
+		if arg == stdinIDs {
+			if fromStdin {
+				return nil, false, exitcode.Usage(errors.New(`"-" may appear only once`))
+			}
+			fromStdin = true
+			read, err := readStdinIDs(cmd.InOrStdin())
+			if err != nil {
+				return nil, false, err
+			}
+			targets = append(targets, read...)
+			continue
+		}
+		id, err := resolveIssueArg(vaultDir, arg)
+		if err != nil {
+			return nil, false, err
+		}
+		targets = append(targets, id)
+	}
+	for _, id := range targets {
+		if err := checkID(id); err != nil {
+			return nil, false, err
+		}
+	}
+	targets = dedupe(targets)
+	if len(targets) == 0 {
+		return nil, false, errors.New("no issues selected: stdin held no issue IDs")
+	}
+	return targets, fromStdin, nil
+}
+
+// queryTargets returns the IDs of the vault's Issues matching where, in
+// the vault's list order. A malformed query is a usage error.
+func queryTargets(vaultDir, where string) ([]string, error) {
+	q, err := parseWhere(vaultDir, where)
+	if err != nil {
+		return nil, err
+	}
+	items, err := loadSortedItems(vaultDir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, it := range items {
+		if q.Match(it.ID, it.Issue.Frontmatter) {
+			ids = append(ids, it.ID)
+		}
+	}
+	return ids, nil
+}
+
+// readStdinIDs reads one ID per non-blank line: the first word, so the
+// output of list --format ids (or any ID-first listing) pipes in as is.
+func readStdinIDs(r io.Reader) ([]string, error) {
+	var ids []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if fields := strings.Fields(sc.Text()); len(fields) > 0 {
+			ids = append(ids, fields[0])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading issue IDs from stdin: %w", err)
+	}
+	return ids, nil
+}
+
+// dedupe drops repeated IDs, keeping the first occurrence of each.
+func dedupe(ids []string) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !slices.Contains(out, id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// planBatch reads every target and applies mutate in memory. mutate may
+// reject an Issue (e.g. undefer of an Issue that is not deferred), and a
+// pre-hook may veto a change; the first failure aborts the whole plan, so
+// nothing is written unless every target is valid.
+func planBatch(vaultDir string, targets []string, mutate func(id string, i issue.Issue) (issue.Issue, error)) ([]batchStep, error) {
+	steps := make([]batchStep, 0, len(targets))
+	for _, id := range targets {
+		before, err := readIssue(vaultDir, id)
+		if err != nil {
+			return nil, err
+		}
+		after, err := mutate(id, before)
+		if err != nil {
+			return nil, err
+		}
+		if err := vetChange(vaultDir, id, &before, &after); err != nil {
+			return nil, err
+		}
+		steps = append(steps, batchStep{ID: id, Before: before, Issue: after})
+	}
+	return steps, nil
+}
+
+// runBatch plans a batch over targets and applies it. A batch of more
+// than one Issue prints the plan summary first; one of more than
+// batchConfirmThreshold Issues asks for confirmation unless --yes. Each
+// applied step is reported through report, so a single-Issue batch prints
+// exactly what the single-ID command always printed. A write that fails
+// partway names the Issues already changed: the journal holds them as
+// one entry, so mt undo reverts them together.
+func runBatch(cmd *cobra.Command, vaultDir, verb string, targets []string, fromStdin bool, flags batchFlags,
+	mutate func(id string, i issue.Issue) (issue.Issue, error), report func(out io.Writer, step batchStep)) error {
+	steps, err := planBatch(vaultDir, targets, mutate)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if len(steps) > 1 {
+		fmt.Fprintf(out, "%s: %d issues (%s)\n", verb, len(steps), summarizeIDs(targets))
+	}
+	if len(steps) > batchConfirmThreshold && !flags.yes {
+		if err := confirmBatch(cmd, verb, len(steps), fromStdin); err != nil {
+			return err
+		}
+	}
+	for k, step := range steps {
+		if err := writeIssueFile(vaultDir, step.ID, step.Issue); err != nil {
+			if k == 0 {
+				return err
+			}
+			return fmt.Errorf("%s stopped at %s after changing %s (mt undo reverts them): %w", verb, step.ID, summarizeIDs(targets[:k]), err)
+		}
+		report(out, step)
+	}
+	return nil
+}
+
+// summarizeIDs joins ids for a batch message: the first batchSummaryIDs
+// of them, then how many more there are.
+func summarizeIDs(ids []string) string {
+	if len(ids) <= batchSummaryIDs {
+		return strings.Join(ids, ", ")
+	}
+	return fmt.Sprintf("%s … and %d more", strings.Join(ids[:batchSummaryIDs], ", "), len(ids)-batchSummaryIDs)
+}
+
+// confirmBatch asks on stderr whether to apply a batch of n Issues and
+// reads the answer from stdin — or from the terminal when stdin already
+// carried the IDs. No terminal to ask on means no confirmation: the batch
+// is refused and --yes is the way through.
+func confirmBatch(cmd *cobra.Command, verb string, n int, fromStdin bool) error {
+	in := cmd.InOrStdin()
+	if fromStdin {
+		tty, err := os.Open("/dev/tty")
+		if err != nil || !term.IsTerminal(int(tty.Fd())) {
+			if tty != nil {
+				tty.Close()
+			}
+			return fmt.Errorf("%s would change %d issues and stdin carried the IDs: pass --yes to apply", verb, n)
+		}
+		defer tty.Close()
+		in = tty
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Apply %s to %d issues? [y/N] ", verb, n)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return fmt.Errorf("%s aborted: no issues changed", verb)
+	}
+}
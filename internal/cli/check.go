@@ -1,26 +1,34 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
-	"strconv"
-	"strings"
+	"io"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Sanmoo/my-tasks2/internal/check"
 	"github.com/Sanmoo/my-tasks2/internal/exitcode"
-	"github.com/Sanmoo/my-tasks2/internal/priority"
 	"github.com/Sanmoo/my-tasks2/internal/vault"
 )
 
+// The output formats of mt check.
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
 // newCheckCmd builds `mt check`: audits the vault's Issue files and reports
-// Rank, frontmatter, Status and datetime findings. --fix repairs only the
-// ranked queue; other findings remain errors to be corrected by the user.
+// every Rank, frontmatter, Status, datetime and blocked_by finding, plus
+// invalid templates. --fix repairs the findings marked fixable; --dry-run
+// previews the repairs without writing them.
 func newCheckCmd() *cobra.Command {
-	var fix bool
+	var fix, dryRun bool
+	var format string
 	cmd := &cobra.Command{
 		Use:   "check",
 		Short: "Audit a Vault's Issues",
+		Long:  checkLong,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
 				return exitcode.Usage(fmt.Errorf("check takes no arguments"))
@@ -28,17 +36,39 @@ func newCheckCmd() *cobra.Command {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runCheck(cmd, fix)
+			if dryRun && !fix {
+				return exitcode.Usage(fmt.Errorf("--dry-run needs --fix"))
+			}
+			return runCheck(cmd, fix, dryRun, format)
 		},
 	}
-	cmd.Flags().BoolVar(&fix, "fix", false, "renormalize Ranks to 1..N")
+	cmd.Flags().BoolVar(&fix, "fix", false, "repair the fixable findings")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "with --fix, list the repairs without writing them")
+	cmd.Flags().StringVar(&format, "format", formatText, "output format: text or json")
 	return cmd
 }
 
-// runCheck audits a vault's Issues. Duplicate Ranks are errors because they
-// make the queue ambiguous; Rank gaps are warnings because they do not make
-// the queue ambiguous and can be repaired by --fix.
-func runCheck(cmd *cobra.Command, fix bool) error {
+// checkReport is the --format json output of mt check. Fixes are the
+// repairs --fix planned, per Issue, and Fixed the number of Issues it
+// rewrote (0 with --dry-run); both are absent without --fix.
+type checkReport struct {
+	Findings []check.Finding `json:"findings"`
+	Errors   int             `json:"errors"`
+	Warnings int             `json:"warnings"`
+	Fixes    []check.FileFix `json:"fixes,omitempty"`
+	Fixed    *int            `json:"fixed,omitempty"`
+	DryRun   bool            `json:"dry_run,omitempty"`
+}
+
+// runCheck audits a vault's Issues and templates and reports every
+// finding. Error findings fail the run (exit 1); warnings, such as a Rank
+// gap that does not make the queue ambiguous, do not. --fix repairs the
+// vault only when every error is fixable, lists each change, then audits
+// again; with dryRun it lists the changes and writes nothing.
+func runCheck(cmd *cobra.Command, fix, dryRun bool, format string) error {
+	if format != formatText && format != formatJSON {
+		return exitcode.Usage(fmt.Errorf("unknown check format %q (want text or json)", format))
+	}
 	vaultDir, err := resolveVault(cmd)
 	if err != nil {
 		return err
@@ -47,118 +77,159 @@ func runCheck(cmd *cobra.Command, fix bool) error {
 	if err != nil {
 		return err
 	}
-	items, err := loadCheckItems(vaultDir)
+	files, err := readCheckFiles(vaultDir)
 	if err != nil {
 		return err
 	}
-	if err := validateVault(vcfg, items); err != nil {
-		return err
-	}
+	findings, _ := check.Audit(files, vcfg.StatusList(), vcfg.Fields, vcfg.Zone)
+	var report checkReport
 	if fix {
-		changes := priority.RenormalizeRanks(priorityIssuesFromCheckItems(items))
-		for _, change := range changes {
-			if err := applyCheckRankChange(vaultDir, change); err != nil {
+		if !check.Fixable(findings) {
+			if format == formatText {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Not fixing: correct the other errors first")
+			}
+		} else {
+			now, err := clock(vcfg)
+			if err != nil {
 				return err
 			}
-		}
-		fmt.Fprintf(cmd.OutOrStdout(), "Fixed %d Ranks\n", len(changes))
-		items, err = loadCheckItems(vaultDir)
-		if err != nil {
-			return err
-		}
-		if err := validateVault(vcfg, items); err != nil {
-			return err
+			report.Fixes = check.PlanFixes(files, vcfg.Fields, vcfg.Zone, now)
+			report.DryRun = dryRun
+			if format == formatText {
+				for _, f := range report.Fixes {
+					for _, change := range f.Changes {
+						fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", f.ID, change)
+					}
+				}
+			}
+			fixed := 0
+			if !dryRun {
+				for _, f := range report.Fixes {
+					if err := rewriteIssueFile(vaultDir, f.ID, f.Issue); err != nil {
+						return err
+					}
+					fixed++
+				}
+			}
+			report.Fixed = &fixed
+			if format == formatText {
+				if dryRun {
+					fmt.Fprintf(cmd.OutOrStdout(), "Would fix %s (dry run: nothing written)\n", plural(len(report.Fixes), "Issue"))
+				} else {
+					fmt.Fprintf(cmd.OutOrStdout(), "Fixed %s\n", plural(fixed, "Issue"))
+				}
+			}
+			if fixed > 0 {
+				if files, err = readCheckFiles(vaultDir); err != nil {
+					return err
+				}
+				findings, _ = check.Audit(files, vcfg.StatusList(), vcfg.Fields, vcfg.Zone)
+			}
 		}
 	}
-	if invalid := check.NonPositiveRanks(items); len(invalid) > 0 {
-		return fmt.Errorf("invalid rank: %s (Ranks must be greater than zero)", formatRanks(invalid))
+	ids := make([]string, len(files))
+	for i, file := range files {
+		ids[i] = file.ID
 	}
-	if gaps := check.RankGapRanges(items); len(gaps) > 0 {
-		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: rank gap: %s\n", formatRankGaps(gaps))
+	tplFindings, err := templateFindings(vaultDir, ids)
+	if err != nil {
+		return err
 	}
-	if dups := check.DuplicateRanks(items); len(dups) > 0 {
-		return fmt.Errorf("duplicate rank: %s", duplicateRankDetails(items, dups))
+	report.Findings = append(findings, tplFindings...)
+	if report.Findings == nil {
+		report.Findings = []check.Finding{}
 	}
-	fmt.Fprintln(cmd.OutOrStdout(), "OK")
-	return nil
-}
+	report.Errors, report.Warnings = check.Count(report.Findings)
 
-func loadCheckItems(vaultDir string) ([]check.Item, error) {
-	files, err := readIssueFiles(vaultDir)
-	if err != nil {
-		return nil, fmt.Errorf("malformed frontmatter: %w", err)
-	}
-	items := make([]check.Item, 0, len(files))
-	for _, file := range files {
-		if err := check.ValidateFrontmatter(file.Data, file.ID); err != nil {
-			return nil, err
+	if format == formatJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return fmt.Errorf("encoding check report: %w", err)
+		}
+	} else {
+		writeFindings(cmd.ErrOrStderr(), report.Findings)
+		if report.Errors == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "OK")
 		}
-		items = append(items, check.Item{ID: file.ID, Issue: file.Issue})
 	}
-	return items, nil
+	if report.Errors > 0 {
+		return fmt.Errorf("check found %s and %s", plural(report.Errors, "error"), plural(report.Warnings, "warning"))
+	}
+	return nil
 }
 
-func duplicateRankDetails(items []check.Item, ranks []int) string {
-	byRank := make(map[int][]string)
-	for _, item := range items {
-		if item.Issue.Frontmatter.Rank != nil {
-			byRank[*item.Issue.Frontmatter.Rank] = append(byRank[*item.Issue.Frontmatter.Rank], item.ID)
+// writeFindings prints findings grouped by Issue, then template, then
+// the vault-wide ones.
+func writeFindings(w io.Writer, findings []check.Finding) {
+	for _, group := range check.Grouped(findings) {
+		fmt.Fprintf(w, "%s:\n", group.Subject)
+		for _, f := range group.Findings {
+			fix := ""
+			if f.Fixable {
+				fix = " (--fix)"
+			}
+			fmt.Fprintf(w, "  %s: %s%s\n", f.Severity, f.Message, fix)
 		}
 	}
-	details := make([]string, len(ranks))
-	for i, rank := range ranks {
-		details[i] = fmt.Sprintf("%d (%s)", rank, strings.Join(byRank[rank], ", "))
-	}
-	return strings.Join(details, "; ")
 }
 
-func priorityIssuesFromCheckItems(items []check.Item) []priority.Issue {
-	issues := make([]priority.Issue, 0, len(items))
-	for _, item := range items {
-		issues = append(issues, priorityIssueFrom(item.ID, item.Issue))
+// plural renders n with noun, pluralized by a trailing s.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
 	}
-	return issues
+	return fmt.Sprintf("%d %ss", n, noun)
 }
 
-func applyCheckRankChange(vaultDir string, change priority.Change) error {
-	i, err := readIssue(vaultDir, change.ID)
+// readCheckFiles reads the raw bytes of every Issue file, in parallel and
+// in name order, without parsing them: a malformed file is a finding of
+// mt check, not a failure to read the vault.
+func readCheckFiles(vaultDir string) ([]check.File, error) {
+	entries, err := listIssueFiles(vaultDir)
 	if err != nil {
+		return nil, err
+	}
+	files := make([]check.File, len(entries))
+	err = forEachParallel(len(entries), func(i int) error {
+		data, err := readIssueData(vaultDir, entries[i].ID)
+		files[i] = check.File{ID: entries[i].ID, Data: data}
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	i.Frontmatter.Rank = change.Rank
-	return writeIssueFile(vaultDir, change.ID, i)
+	return files, nil
 }
 
-// validateVault runs the per-Issue schema checks (frontmatter values,
-// status, datetime layout) and the vault-wide blocked_by reference
-// checks (existence, self-block, cycles). It returns the first
-// violation found.
-func validateVault(vcfg vault.Vault, items []check.Item) error {
-	statuses := vcfg.StatusList()
-	for _, item := range items {
-		if err := check.ValidateItem(item, statuses); err != nil {
-			return err
-		}
-	}
-	return check.ValidateBlockedBy(items)
-}
+const checkLong = `check audits the vault and reports every finding in one run, grouped
+by Issue, then template, then the vault-wide ones:
 
-func formatRanks(ranks []int) string {
-	values := make([]string, len(ranks))
-	for i, rank := range ranks {
-		values[i] = strconv.Itoa(rank)
-	}
-	return strings.Join(values, ", ")
-}
+  - malformed frontmatter, unknown or empty optional fields, a rank that
+    is not an integer, fields out of the canonical order (a warning);
+  - missing required fields, a status outside the vault's list, datetimes
+    not in the YYYY-MM-DDTHH:MM layout (with the UTC offset under
+    timezone: offset);
+  - repeated labels and done Issues without completed_at (warnings);
+  - custom fields (declared under fields: in mt.yaml) whose value does
+    not fit the declared type, and id-refs to unknown Issues;
+  - blocked_by references to unknown Issues, self-blocks and cycles;
+  - non-positive and duplicate Ranks (errors) and Rank gaps (a warning);
+  - invalid templates and template blocked_by references.
 
-func formatRankGaps(gaps []check.RankGap) string {
-	values := make([]string, len(gaps))
-	for i, gap := range gaps {
-		if gap.Start == gap.End {
-			values[i] = strconv.Itoa(gap.Start)
-			continue
-		}
-		values[i] = fmt.Sprintf("%d-%d", gap.Start, gap.End)
-	}
-	return strings.Join(values, ", ")
-}
+Any error fails the run (exit 1); warnings alone do not, and a clean
+vault prints OK.
+
+Findings marked (--fix) are repaired by --fix, only once every other
+error is corrected: datetimes that still parse (seconds, Z or an offset,
+a bare date as T00:00) are rewritten canonically for the vault's
+timezone, empty optional fields
+omitted, unknown blocked_by references and repeated labels dropped,
+completed_at set to now on done Issues, the fields put in canonical
+order and the Ranks renormalized to 1..N. Each change is listed as it is
+made; --fix --dry-run lists them and writes nothing.
+
+--format json prints {"findings": [...], "errors": n, "warnings": n},
+plus "fixes", "fixed" and "dry_run" with --fix, on stdout for editors
+and CI; each finding has id (or template), field, rule, severity,
+message and fixable. The exit code is the same as in text.`
@@ -1,13 +1,17 @@
-// Package cli — the comment command. It owns process concerns (files,
-// randomness, stdio); the append-only comment logic lives in
+// Package cli — the comment commands: comment (with replies), comment
+// edit, comment redact and comments. They own process concerns (files,
+// randomness, the editor, stdio); the comment block logic lives in
 // internal/issue.
 package cli
 
 import (
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"slices"
 	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
 
@@ -15,19 +19,50 @@ import (
 	"github.com/Sanmoo/my-tasks2/internal/issue"
 )
 
-// newCommentCmd builds `mt comment <id> <text>`: appends a comment to the
-// Issue's Comments section — a ### timestamp heading, the text, and a
-// stable <!-- comment: … --> anchor. The existing body is preserved
+// stdinText is the comment text argument that reads the text from stdin.
+const stdinText = "-"
+
+// newCommentCmd builds `mt comment <id...> <text>`: appends a comment to
+// each Issue's Comments section — a ### timestamp heading, the text, and
+// a stable <!-- comment: … --> anchor. The existing body is preserved
 // byte-for-byte.
 func newCommentCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "comment <id> <text>",
-		Short: "Append a comment to an Issue",
+	var flags batchFlags
+	var replyTo string
+	var compose bool
+	cmd := &cobra.Command{
+		Use:   "comment <id...> <text>",
+		Short: "Append a comment to Issues",
 		Long: `comment appends a comment to the Issue's Comments section: a ###
 timestamp heading, the text, and a stable <!-- comment: … --> anchor.
-The existing body is preserved byte-for-byte (append-only).`,
+The existing body is preserved byte-for-byte (append-only).
+
+The first argument is always an Issue; the arguments after it that name
+existing Issues (or -, for IDs on stdin) are more targets, and the rest
+is the text — the last argument always belongs to the text. With
+--where, every argument is the text.
+
+Multi-line text (lists, code, several paragraphs) comes from stdin when
+the text is a lone - (mt comment pkm-055 - < notes.md), or from $EDITOR
+with --edit, where every argument is a target and lines starting with #
+are dropped. Text with a line starting with "## " or "### ", or with a
+comment anchor marker, would break the comment block and is refused.
+
+--reply-to <anchor> answers an existing comment of a single Issue: the
+reply's heading names the parent anchor, and mt comments shows it
+threaded under its parent. mt comment edit and mt comment redact
+rewrite one comment's text, keeping its heading and anchor.`,
 		Args: func(cmd *cobra.Command, args []string) error {
-			if len(args) < 2 {
+			switch {
+			case compose && flags.where != "" && len(args) > 0:
+				return exitcode.Usage(fmt.Errorf("comment --edit --where takes no arguments: the text comes from $EDITOR"))
+			case compose && flags.where == "" && len(args) == 0:
+				return exitcode.Usage(fmt.Errorf("comment --edit needs an issue ID"))
+			case compose:
+				return nil
+			case flags.where != "" && len(args) == 0:
+				return exitcode.Usage(fmt.Errorf("comment --where needs a comment text"))
+			case flags.where == "" && len(args) < 2:
 				return exitcode.Usage(fmt.Errorf("comment needs an issue ID and a comment text"))
 			}
 			return nil
@@ -37,27 +72,289 @@ The existing body is preserved byte-for-byte (append-only).`,
 			if err != nil {
 				return err
 			}
-			id := args[0]
-			if err := checkID(id); err != nil {
+			ids, words := splitCommentArgs(vaultDir, args, flags.where != "", compose)
+			textFromStdin := len(words) == 1 && words[0] == stdinText
+			if textFromStdin && slices.Contains(ids, stdinIDs) {
+				return exitcode.Usage(fmt.Errorf("stdin can carry the issue IDs or the comment text, not both"))
+			}
+			targets, fromStdin, err := selectTargets(cmd, vaultDir, ids, flags.where)
+			if err != nil {
+				return err
+			}
+			// An anchor is unique only within its Issue, so a reply has
+			// exactly one target.
+			if replyTo != "" && len(targets) != 1 {
+				return exitcode.Usage(fmt.Errorf("--reply-to answers a comment of a single issue, got %d", len(targets)))
+			}
+			text, err := commentText(cmd, targets, words, compose, textFromStdin)
+			if err != nil {
 				return err
 			}
-			text := strings.Join(args[1:], " ")
-			return appendComment(vaultDir, id, text)
+			// The confirmation prompt cannot read stdin once the text
+			// came from it: it asks on the terminal, as for stdin IDs.
+			return appendComments(cmd, vaultDir, targets, fromStdin || textFromStdin, flags, text, replyTo)
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().StringVar(&replyTo, "reply-to", "", "anchor of the comment this one answers")
+	cmd.Flags().BoolVar(&compose, "edit", false, "compose the comment in $EDITOR")
+	cmd.AddCommand(newCommentEditCmd())
+	cmd.AddCommand(newCommentRedactCmd())
+	return cmd
+}
+
+// splitCommentArgs splits the comment arguments into the targets and the
+// text words. Composing in $EDITOR, every argument is a target. Without
+// --where the first argument is a target (an ID or a handle); each
+// following argument that is "-" or the ID of an existing Issue file is a
+// target too, up to the last argument, which is always text. A handle is
+// only recognized first, since a later number is far more likely part of
+// the text.
+func splitCommentArgs(vaultDir string, args []string, where, compose bool) (ids, words []string) {
+	if compose {
+		return args, nil
+	}
+	if where {
+		return nil, args
+	}
+	n := 1
+	for n < len(args)-1 {
+		if args[n] != stdinIDs && !issueFileExists(vaultDir, args[n]) {
+			break
+		}
+		n++
+	}
+	return args[:n], args[n:]
+}
+
+// commentText returns the comment text: composed in $EDITOR, read from
+// stdin, or the words joined with spaces. Whatever the source, the text
+// must not break the comment block structure.
+func commentText(cmd *cobra.Command, targets, words []string, compose, fromStdin bool) (string, error) {
+	var text string
+	switch {
+	case compose:
+		path, err := writeBuffer("comment", issue.CommentBuffer(targets))
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(path)
+		if err := editFile(path); err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading comment buffer: %w", err)
+		}
+		text = issue.ParseCommentBuffer(string(data))
+		if text == "" {
+			return "", errors.New("empty comment: nothing was added")
+		}
+	case fromStdin:
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return "", fmt.Errorf("reading comment from stdin: %w", err)
+		}
+		text = strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	default:
+		text = strings.Join(words, " ")
+	}
+	if err := issue.CheckCommentText(text); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+// issueFileExists reports whether arg names an existing Issue file.
+func issueFileExists(vaultDir, arg string) bool {
+	if checkID(arg) != nil {
+		return false
+	}
+	_, err := os.Stat(issuePath(vaultDir, arg))
+	return err == nil
+}
+
+// appendComments appends the same timestamped comment, with a fresh
+// stable anchor per Issue, to every target as one batch — as a reply to
+// the comment replyTo, when set.
+func appendComments(cmd *cobra.Command, vaultDir string, targets []string, fromStdin bool, flags batchFlags, text, replyTo string) error {
+	clock, _, err := vaultClock(vaultDir)
+	if err != nil {
+		return err
+	}
+	// Comment headings stay naive: wall-clock times of the vault's zone.
+	now := clock.Format(issue.NaiveLayout)
+	return runBatch(cmd, vaultDir, "comment", targets, fromStdin, flags, func(id string, i issue.Issue) (issue.Issue, error) {
+		anchor, err := issue.NextAnchor(rand.Reader, i.Body)
+		if err != nil {
+			return issue.Issue{}, err
+		}
+		if replyTo == "" {
+			i.Body = issue.AppendComment(i.Body, now, text, anchor)
+			return i, nil
+		}
+		if i.Body, err = issue.AppendReply(i.Body, now, text, anchor, replyTo); err != nil {
+			return issue.Issue{}, fmt.Errorf("issue %s: %w", id, err)
+		}
+		return i, nil
+	}, func(io.Writer, batchStep) {})
+}
+
+// newCommentEditCmd builds `mt comment edit <id> <anchor>`: opens the
+// text of one comment in $EDITOR and writes back what was saved. The
+// heading (timestamp, thread) and the anchor are kept.
+func newCommentEditCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit <id> <anchor>",
+		Short: "Edit a comment's text in $EDITOR",
+		Args:  commentAnchorArgs("edit"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCommentEdit(cmd, args[0], args[1])
 		},
 	}
 }
 
-// appendComment loads the Issue for id, appends a timestamped comment with
-// a fresh stable anchor and writes it back.
-func appendComment(vaultDir, id, text string) error {
+// newCommentRedactCmd builds `mt comment redact <id> <anchor>`: replaces
+// one comment's text with a tombstone, keeping the block and its anchor
+// so replies still resolve.
+func newCommentRedactCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "redact <id> <anchor>",
+		Short: "Replace a comment's text with a tombstone",
+		Args:  commentAnchorArgs("redact"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vaultDir, id, err := resolveCommentIssue(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			i, err := readIssue(vaultDir, id)
+			if err != nil {
+				return err
+			}
+			if i.Body, err = issue.RedactComment(i.Body, args[1]); err != nil {
+				return fmt.Errorf("issue %s: %w", id, err)
+			}
+			if err := writeIssueFile(vaultDir, id, i); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Redacted comment %s of %s\n", args[1], id)
+			return nil
+		},
+	}
+}
+
+// commentAnchorArgs validates the <id> <anchor> arguments of the comment
+// subcommands.
+func commentAnchorArgs(verb string) cobra.PositionalArgs {
+	return func(_ *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return exitcode.Usage(fmt.Errorf("comment %s needs an issue ID and a comment anchor", verb))
+		}
+		return nil
+	}
+}
+
+// resolveCommentIssue resolves the vault and the Issue argument (an ID or
+// a handle) of a comment subcommand.
+func resolveCommentIssue(cmd *cobra.Command, arg string) (string, string, error) {
+	vaultDir, err := resolveVault(cmd)
+	if err != nil {
+		return "", "", err
+	}
+	id, err := resolveIssueArg(vaultDir, arg)
+	if err != nil {
+		return "", "", err
+	}
+	if err := checkID(id); err != nil {
+		return "", "", err
+	}
+	return vaultDir, id, nil
+}
+
+// runCommentEdit writes the comment's text to a buffer, runs $EDITOR on
+// it and, when the text changed, writes it back into the Issue. The
+// edited text is validated like any comment text: it may not break the
+// block structure.
+func runCommentEdit(cmd *cobra.Command, arg, anchor string) error {
+	vaultDir, id, err := resolveCommentIssue(cmd, arg)
+	if err != nil {
+		return err
+	}
 	i, err := readIssue(vaultDir, id)
 	if err != nil {
 		return err
 	}
-	anchor, err := issue.NextAnchor(rand.Reader, i.Body)
+	c, err := issue.FindComment(i.Body, anchor)
+	if err != nil {
+		return fmt.Errorf("issue %s: %w", id, err)
+	}
+	if c.Redacted() {
+		return fmt.Errorf("issue %s: comment %s is redacted", id, anchor)
+	}
+	path, err := writeBuffer("comment", c.Text+"\n")
 	if err != nil {
 		return err
 	}
-	i.Body = issue.AppendComment(i.Body, time.Now().Format(issue.NaiveLayout), text, anchor)
-	return writeIssueFile(vaultDir, id, i)
+	defer os.Remove(path)
+	if err := editFile(path); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading comment buffer: %w", err)
+	}
+	text := strings.TrimRight(string(data), "\n")
+	if text == c.Text {
+		fmt.Fprintf(cmd.OutOrStdout(), "Comment %s of %s unchanged\n", anchor, id)
+		return nil
+	}
+	if i.Body, err = issue.SetCommentText(i.Body, anchor, text); err != nil {
+		return fmt.Errorf("issue %s: %w", id, err)
+	}
+	if err := writeIssueFile(vaultDir, id, i); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Updated comment %s of %s\n", anchor, id)
+	return nil
+}
+
+// newCommentsCmd builds `mt comments <id>`: lists an Issue's comments,
+// replies threaded under their parents, each with its anchor.
+func newCommentsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "comments <id>",
+		Short: "List an Issue's comments with their anchors",
+		Long: `comments lists the Issue's comments in thread order — each comment
+followed by its replies, indented — one per line: the anchor, the
+timestamp and the text (later lines of a multi-line comment aligned
+under the first). The anchor is what comment --reply-to, comment edit
+and comment redact take. Redacted comments show their tombstone.`,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return exitcode.Usage(fmt.Errorf("comments needs exactly one issue ID"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vaultDir, id, err := resolveCommentIssue(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			i, err := readIssue(vaultDir, id)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			for _, c := range issue.Thread(issue.Comments(i.Body)) {
+				indent := strings.Repeat("  ", c.Depth)
+				prefix := fmt.Sprintf("%s%s  %s  ", indent, c.Anchor, c.Timestamp)
+				lines := strings.Split(c.Text, "\n")
+				fmt.Fprintln(out, prefix+lines[0])
+				for _, line := range lines[1:] {
+					fmt.Fprintln(out, strings.TrimRight(strings.Repeat(" ", len(prefix))+line, " "))
+				}
+			}
+			return nil
+		},
+	}
 }
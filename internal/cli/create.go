@@ -0,0 +1,421 @@
+// Package cli — create and q: writing a new Issue. One call can capture a
+// complete Issue — labels, dates, queue placement, blockers, status and
+// body — and everything is validated before the file is written, so a
+// failed create leaves the vault untouched. The Issue schema lives in
+// internal/issue; the time, placement and template rules in
+// internal/deferral, internal/priority and internal/template.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sanmoo/my-tasks2/internal/deferral"
+	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/list"
+	"github.com/Sanmoo/my-tasks2/internal/priority"
+	"github.com/Sanmoo/my-tasks2/internal/template"
+	"github.com/Sanmoo/my-tasks2/internal/vault"
+)
+
+// stdinBody is the --body value that reads the body from stdin.
+const stdinBody = "-"
+
+// createFlags are the flags shared by create and q: everything a new
+// Issue can start with.
+type createFlags struct {
+	labels      []string
+	template    string
+	deadline    string
+	warnBefore  string
+	recur       string
+	deferUntil  string
+	rank        string
+	blockedBy   []string
+	status      string
+	description string
+	body        string
+	edit        bool
+}
+
+// register adds the create flags to cmd.
+func (f *createFlags) register(cmd *cobra.Command) {
+	fl := cmd.Flags()
+	fl.StringArrayVar(&f.labels, "label", nil, "label; repeatable (free-form)")
+	fl.StringVar(&f.template, "template", "", "start from the vault's templates/<name>.md")
+	fl.StringVar(&f.deadline, "deadline", "", "deadline (YY-MM-DD HH:MM, tomorrow 18:00, fri, +2d, +3bd… — as mt defer)")
+	fl.StringVar(&f.warnBefore, "warn-before", "", "this Issue's deadline warning span, over the vault's warn_before (e.g. 3d)")
+	fl.StringVar(&f.recur, "recur", "", "recurrence period: mt done spawns the next occurrence (e.g. 1w, 1mo, 1bd)")
+	fl.StringVar(&f.deferUntil, "defer", "", "defer until (YY-MM-DD HH:MM, tomorrow 9:00, mon, +1w… — as mt defer)")
+	fl.StringVar(&f.rank, "rank", "", "queue placement: top, bottom or a position")
+	fl.StringArrayVar(&f.blockedBy, "blocked-by", nil, "blocking issue ID; repeatable")
+	fl.StringVar(&f.status, "status", "", "initial status (default open); in_progress follows the rules of mt start")
+	fl.StringVar(&f.description, "description", "", "text of the Description section")
+	fl.StringVar(&f.body, "body", "", "whole body from a file, or - for stdin")
+	fl.BoolVar(&f.edit, "edit", false, "open the new Issue in $EDITOR")
+}
+
+// newCreateCmd builds `mt create <título>`: writes a new Issue file with
+// the spec schema. The title is the remaining positional args joined
+// with spaces, so it needs no shell quoting.
+func newCreateCmd() *cobra.Command {
+	var flags createFlags
+	cmd := &cobra.Command{
+		Use:   "create <title>",
+		Short: "Create a new Issue",
+		Long:  createLong,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return exitcode.Usage(fmt.Errorf("create needs a title"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCreate(cmd, strings.Join(args, " "), flags, false)
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+// newQCmd builds `mt q <título>`: like create, but prints only the ID —
+// for capturing ideas without leaving the flow.
+func newQCmd() *cobra.Command {
+	var flags createFlags
+	cmd := &cobra.Command{
+		Use:   "q <title>",
+		Short: "Create an Issue and print only its ID",
+		Long:  "q is the quiet create: it takes the same flags and writes the same Issue file as create, but prints only the new ID.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return exitcode.Usage(fmt.Errorf("q needs a title"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCreate(cmd, strings.Join(args, " "), flags, true)
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+// runCreate writes a new Issue with title, built from the template and
+// the flags, and prints its ID (just the ID when quiet, a confirmation
+// line otherwise).
+func runCreate(cmd *cobra.Command, title string, flags createFlags, quiet bool) error {
+	vaultDir, err := resolveVault(cmd)
+	if err != nil {
+		return err
+	}
+	id, err := createIssue(vaultDir, title, flags, cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+	if flags.edit {
+		editErr := editFile(issuePath(vaultDir, id))
+		if after, err := readIssueImage(vaultDir, id); err == nil {
+			recordChange(vaultDir, id, nil, after)
+		}
+		if editErr != nil {
+			return fmt.Errorf("created %s, but %w", id, editErr)
+		}
+	}
+	if quiet {
+		fmt.Fprintln(cmd.OutOrStdout(), id)
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "Created %s\n", id)
+	}
+	return nil
+}
+
+// createIssue writes a new Issue with title in vaultDir and returns its
+// ID; --body - reads the body from stdin. It is the shared write path of
+// create, q and mt serve. Every flag is checked, the blockers and the
+// queue placement planned, and the pre-hooks run, before the file is
+// written. Its created_at is the command's now (see clock).
+func createIssue(vaultDir, title string, flags createFlags, stdin io.Reader) (string, error) {
+	vcfg, err := vault.LoadVault(vaultDir)
+	if err != nil {
+		return "", err
+	}
+	if vcfg.Prefix == "" {
+		return "", fmt.Errorf("vault %s has no ID prefix in its config — set prefix in mt.yaml", vaultDir)
+	}
+	now, err := clock(vcfg)
+	if err != nil {
+		return "", err
+	}
+	fields, err := parseCreateFlags(flags, now, deferral.Options{DefaultTime: vcfg.DefaultTime, Zone: vcfg.Zone})
+	if err != nil {
+		return "", err
+	}
+	if flags.status != "" && !vcfg.IsStatus(flags.status) {
+		return "", fmt.Errorf("status %q is not in the vault's status list (valid: %s)",
+			flags.status, strings.Join(vcfg.StatusList(), ", "))
+	}
+	body, err := readCreateBody(stdin, flags.body)
+	if err != nil {
+		return "", err
+	}
+	id, err := newIssueID(vcfg.Prefix, vaultDir)
+	if err != nil {
+		return "", err
+	}
+	i := issue.Issue{
+		Frontmatter: issue.Frontmatter{
+			Title:     title,
+			Status:    "open",
+			Labels:    flags.labels,
+			CreatedAt: vcfg.Zone.Format(now),
+		},
+		Body: issue.DefaultBody,
+	}
+	placement := flags.rank
+	if flags.template != "" {
+		var tpl template.Template
+		if i, tpl, err = applyTemplate(vaultDir, flags.template, id, i, flags.labels, now, vcfg.Zone); err != nil {
+			return "", err
+		}
+		if placement == "" {
+			placement = tpl.Rank
+		}
+	}
+	if i, err = applyCreateFlags(vaultDir, i, flags, fields, body, vcfg.Zone.Format(now)); err != nil {
+		return "", err
+	}
+	if i.Frontmatter.Status == "in_progress" {
+		items, err := loadIndexedItems(vaultDir)
+		if err != nil {
+			return "", fmt.Errorf("loading issues: %w", err)
+		}
+		// Checked as the open Issue it is until the start.
+		probe := i
+		probe.Frontmatter.Status = "open"
+		if err := checkStartable(vaultDir, append(items, list.Item{ID: id, Issue: probe}), []string{id}); err != nil {
+			return "", err
+		}
+	}
+	var rankChanges []priority.Change
+	if placement != "" {
+		if i, rankChanges, err = placeNewIssue(vaultDir, id, i, placement); err != nil {
+			return "", err
+		}
+	}
+	if err := vetChange(vaultDir, id, nil, &i); err != nil {
+		return "", err
+	}
+	rankSteps, err := planRankChanges(vaultDir, rankChanges)
+	if err != nil {
+		return "", err
+	}
+	if err := createIssueFile(vaultDir, id, i); err != nil {
+		return "", err
+	}
+	if err := writeSteps(vaultDir, rankSteps); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// createFields are the flag values of create in their canonical form.
+type createFields struct {
+	deadline      string
+	deferredUntil string
+}
+
+// parseCreateFlags checks the flags that need no vault state. A value no
+// parse accepts, or flags that contradict each other, make a malformed
+// invocation: a usage error (exit 2), like a bad time for defer.
+func parseCreateFlags(flags createFlags, now time.Time, opts deferral.Options) (createFields, error) {
+	var fields createFields
+	var err error
+	if flags.description != "" && flags.body != "" {
+		return fields, exitcode.Usage(fmt.Errorf("--description and --body are mutually exclusive"))
+	}
+	if flags.deadline != "" {
+		if fields.deadline, err = deferral.Parse(flags.deadline, now, opts); err != nil {
+			return fields, exitcode.Usage(fmt.Errorf("--deadline: %w", err))
+		}
+	}
+	if flags.warnBefore != "" {
+		if _, err := deferral.ParseSpan(flags.warnBefore); err != nil {
+			return fields, exitcode.Usage(fmt.Errorf("--warn-before: %w", err))
+		}
+	}
+	if flags.recur != "" {
+		if _, err := deferral.Recur(flags.recur, now); err != nil {
+			return fields, exitcode.Usage(fmt.Errorf("--recur: %w", err))
+		}
+	}
+	if flags.deferUntil != "" {
+		if fields.deferredUntil, err = deferral.Parse(flags.deferUntil, now, opts); err != nil {
+			return fields, exitcode.Usage(fmt.Errorf("--defer: %w", err))
+		}
+		// A deferred Issue is always open (see issue.Defer).
+		if flags.status != "" && flags.status != "open" {
+			return fields, exitcode.Usage(fmt.Errorf("--defer keeps the Issue open; it cannot be combined with --status %s", flags.status))
+		}
+	}
+	if flags.rank != "" {
+		if _, _, err := priority.ParsePlacement(flags.rank); err != nil {
+			return fields, exitcode.Usage(fmt.Errorf("--rank: %w", err))
+		}
+	}
+	for _, ref := range flags.blockedBy {
+		if err := checkID(ref); err != nil {
+			return fields, err
+		}
+	}
+	return fields, nil
+}
+
+// readCreateBody reads the --body source: stdin for "-", a file
+// otherwise, nothing when unset. The body keeps the blank line after the
+// frontmatter and ends with a newline, like issue.DefaultBody.
+func readCreateBody(stdin io.Reader, source string) (string, error) {
+	var data []byte
+	var err error
+	switch source {
+	case "":
+		return "", nil
+	case stdinBody:
+		if data, err = io.ReadAll(stdin); err != nil {
+			return "", fmt.Errorf("reading body from stdin: %w", err)
+		}
+	default:
+		if data, err = os.ReadFile(source); err != nil {
+			return "", fmt.Errorf("reading body: %w", err)
+		}
+	}
+	body := string(data)
+	if !strings.HasPrefix(body, "\n") {
+		body = "\n" + body
+	}
+	if !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return body, nil
+}
+
+// applyCreateFlags fills the new Issue from the flags, over whatever the
+// template set: the dates replace the template's offsets, blockers add
+// to its blocked_by, and the body or description replace its body. Every
+// blocker must exist. stamp is the creation time, for the status stamps.
+func applyCreateFlags(vaultDir string, i issue.Issue, flags createFlags, fields createFields, body, stamp string) (issue.Issue, error) {
+	if fields.deadline != "" {
+		i.Frontmatter.Deadline = fields.deadline
+	}
+	if flags.warnBefore != "" {
+		i.Frontmatter.WarnBefore = flags.warnBefore
+	}
+	if flags.recur != "" {
+		i.Frontmatter.Recur = flags.recur
+	}
+	if fields.deferredUntil != "" {
+		i = i.Defer(fields.deferredUntil)
+	}
+	for _, ref := range flags.blockedBy {
+		blocker, err := resolveIssueArg(vaultDir, ref)
+		if err != nil {
+			return issue.Issue{}, err
+		}
+		if _, err := readIssue(vaultDir, blocker); err != nil {
+			return issue.Issue{}, fmt.Errorf("--blocked-by: %w", err)
+		}
+		i = i.AddBlocker(blocker)
+	}
+	switch flags.status {
+	case "", "open":
+	case "in_progress":
+		i = i.Start(stamp)
+	case "done":
+		i = i.Done(stamp)
+	default:
+		i = i.SetStatus(flags.status)
+	}
+	if body != "" {
+		i.Body = body
+	}
+	if flags.description != "" {
+		i = i.SetDescription(flags.description)
+	}
+	return i, nil
+}
+
+// applyTemplate fills the new Issue id from the template tplName, whose
+// blockers must exist. The template's rank placement is left to the
+// caller, which may override it.
+func applyTemplate(vaultDir, tplName, id string, i issue.Issue, labels []string, now time.Time, zone issue.Zone) (issue.Issue, template.Template, error) {
+	tpl, err := loadTemplate(vaultDir, tplName)
+	if err != nil {
+		return issue.Issue{}, template.Template{}, err
+	}
+	i = tpl.Apply(i, labels, template.Values{Title: i.Frontmatter.Title, ID: id, Now: now, Zone: zone})
+	for _, blocker := range i.Frontmatter.BlockedBy {
+		if _, err := readIssue(vaultDir, blocker); err != nil {
+			return issue.Issue{}, template.Template{}, fmt.Errorf("template %s: blocked_by: %w", tplName, err)
+		}
+	}
+	return i, tpl, nil
+}
+
+// placeNewIssue plans the queue placement (top, bottom or a position) of
+// the new Issue id before it is written: the placement comes back as the
+// new Issue's rank plus the rank changes of the Issues it displaces.
+func placeNewIssue(vaultDir, id string, i issue.Issue, placement string) (issue.Issue, []priority.Change, error) {
+	action, position, err := priority.ParsePlacement(placement)
+	if err != nil {
+		return issue.Issue{}, nil, err
+	}
+	issues, err := loadPriorityIssues(vaultDir)
+	if err != nil {
+		return issue.Issue{}, nil, err
+	}
+	issues = append(issues, priorityIssueFrom(id, i))
+	changes, err := priority.QuickPlan(issues, id, action, position)
+	if err != nil {
+		return issue.Issue{}, nil, err
+	}
+	others := make([]priority.Change, 0, len(changes))
+	for _, ch := range changes {
+		if ch.ID == id {
+			i.Frontmatter.Rank = ch.Rank
+			continue
+		}
+		others = append(others, ch)
+	}
+	return i, others, nil
+}
+
+const createLong = `create writes a new Issue file (issues/<id>.md) with the spec schema:
+title, status (open), labels and created_at in the frontmatter, and the
+empty Description/Notes/Comments body. The ID is the vault prefix plus a
+short random suffix; created_at is stamped automatically.
+
+Flags fill in the rest in the same call:
+
+  --deadline, --defer   a time as mt defer takes it (26-08-20 08:00, +2d)
+  --rank                top, bottom or a queue position (as mt rank)
+  --blocked-by          a blocking Issue, which must exist; repeatable
+  --status              an initial status from the vault's list
+                        (in_progress stamps started_at, done completed_at)
+  --description         the text of the Description section
+  --body                the whole body from a file, or - for stdin
+  --edit                open the new file in $EDITOR right away
+
+--template <name> starts from the vault's templates/<name>.md: its
+frontmatter may set labels (merged with --label), deadline and
+deferred_until offsets (+3d), a rank placement (top, bottom or a
+position) and blocked_by; its body replaces the empty sections, with
+{{title}}, {{id}}, {{date}} and {{now}} expanded. Flags override the
+template.
+
+Everything is checked before the file is written: nothing is created
+unless the whole Issue is valid.`
@@ -6,6 +6,7 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -14,51 +15,97 @@ import (
 	"github.com/Sanmoo/my-tasks2/internal/deferral"
 	"github.com/Sanmoo/my-tasks2/internal/exitcode"
 	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/vault"
 )
 
-// newDeferCmd builds `mt defer <id> <when>`: sets deferred_until on the
-// Issue and leaves it open. The Issue simply becomes unavailable until
-// the time arrives; once it does (the Deferral is expired), `mt undefer`
-// archives the reminder.
+// newDeferCmd builds `mt defer <id...> <when>`: sets deferred_until on
+// the Issues and leaves them open. An Issue simply becomes unavailable
+// until the time arrives; once it does (the Deferral is expired),
+// `mt undefer` archives the reminder. The targets are IDs, "-" for IDs on
+// stdin, or a --where query (see batch.go).
 func newDeferCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "defer <id> <when>",
-		Short: "Defer an Issue until a datetime",
+	var flags batchFlags
+	cmd := &cobra.Command{
+		Use:   "defer <id...> <when>",
+		Short: "Defer Issues until a datetime",
 		Long:  deferLong,
 		Args: func(cmd *cobra.Command, args []string) error {
-			if len(args) < 2 {
-				return exitcode.Usage(fmt.Errorf("defer needs an issue ID and a time (YY-MM-DD HH:MM or +2d/+1w/+3h)"))
+			if flags.where != "" && len(args) < 1 {
+				return exitcode.Usage(fmt.Errorf("defer --where needs just a time (YY-MM-DD HH:MM, tomorrow 9:00, +2d, …)"))
+			}
+			if len(args) < 2 && flags.where == "" {
+				return exitcode.Usage(fmt.Errorf("defer needs an issue ID and a time (YY-MM-DD HH:MM, tomorrow 9:00, +2d, …)"))
 			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// The time may be spelled "26-08-20 08:00" as one quoted
-			// argument or two; join the remainder so both forms parse.
-			return runDefer(cmd, args[0], strings.Join(args[1:], " "))
+			return runDefer(cmd, args, flags)
 		},
 	}
+	flags.register(cmd)
+	return cmd
 }
 
 // runDefer resolves the vault, parses the time argument into the
-// canonical deferred_until value, and writes it onto the Issue.
-func runDefer(cmd *cobra.Command, id, when string) error {
+// canonical deferred_until value, and writes it onto the target Issues.
+func runDefer(cmd *cobra.Command, args []string, flags batchFlags) error {
 	vaultDir, err := resolveVault(cmd)
 	if err != nil {
 		return err
 	}
-	until, err := deferral.Parse(when, time.Now())
+	opts, now, err := timeOptions(vaultDir)
+	if err != nil {
+		return err
+	}
+	ids, until, err := splitDeferArgs(args, flags.where != "", now, opts)
 	if err != nil {
 		// A time argument that no parse can accept is a malformed
 		// invocation: a usage error (exit 2), like a bad rank position.
 		return exitcode.Usage(err)
 	}
-	if _, err := mutateIssue(vaultDir, id, func(i issue.Issue) issue.Issue {
-		return i.Defer(until)
-	}); err != nil {
+	targets, fromStdin, err := selectTargets(cmd, vaultDir, ids, flags.where)
+	if err != nil {
 		return err
 	}
-	fmt.Fprintf(cmd.OutOrStdout(), "%s deferred until %s\n", id, until)
-	return nil
+	return runBatch(cmd, vaultDir, "defer", targets, fromStdin, flags, func(_ string, i issue.Issue) (issue.Issue, error) {
+		return i.Defer(until), nil
+	}, func(out io.Writer, step batchStep) {
+		fmt.Fprintf(out, "%s deferred until %s\n", step.ID, until)
+	})
+}
+
+// splitDeferArgs splits the defer arguments into the target IDs and the
+// parsed time, which comes last. The time may be spelled in several
+// words — "26-08-20 08:00", "next friday 18:00" — as one quoted argument
+// or many, so the longest run of trailing arguments that parses wins,
+// down to the last one alone, always leaving an ID (with --where, every
+// argument is the time). Longest first, or "9:00" would parse on its own
+// out of "tomorrow 9:00".
+func splitDeferArgs(args []string, where bool, now time.Time, opts deferral.Options) ([]string, string, error) {
+	if where {
+		until, err := deferral.Parse(strings.Join(args, " "), now, opts)
+		return nil, until, err
+	}
+	for n := 1; n < len(args)-1; n++ {
+		if until, err := deferral.Parse(strings.Join(args[n:], " "), now, opts); err == nil {
+			return args[:n], until, nil
+		}
+	}
+	n := len(args) - 1
+	until, err := deferral.Parse(args[n], now, opts)
+	return args[:n], until, err
+}
+
+// timeOptions reads the vault settings of the time expressions that
+// defer, --deadline and --defer take, with the now they resolve against
+// (see clock).
+func timeOptions(vaultDir string) (deferral.Options, time.Time, error) {
+	vcfg, err := vault.LoadVault(vaultDir)
+	if err != nil {
+		return deferral.Options{}, time.Time{}, err
+	}
+	now, err := clock(vcfg)
+	return deferral.Options{DefaultTime: vcfg.DefaultTime, Zone: vcfg.Zone}, now, err
 }
 
 const deferLong = `defer sets an Issue's deferred_until and leaves it open:
@@ -66,6 +113,15 @@ the Issue simply becomes unavailable until the moment
 arrives (now >= deferred_until), then reappears on its own; once the
 deferral is expired, mt undefer archives the reminder.
 
-The time is either an absolute local datetime in YY-MM-DD HH:MM form
-(e.g. 26-08-20 08:00 — the hour is kept) or a relative duration from
-now (+2d, +1w, +3h).`
+The time is an absolute local datetime in YY-MM-DD HH:MM form (e.g.
+26-08-20 08:00 — the hour is kept), a time of day (18:00: today, or
+tomorrow once it has passed), a day with an optional time (tomorrow 9:00,
+mon, next friday, end of month, amanhã, segunda 14:00), or a relative
+duration from now: +30m, +3h, +2d, +1w, +3bd (business days), +2mo
+(calendar months). A day without a time gets the vault's default_time
+(09:00 unless mt.yaml sets it).
+
+Several Issues defer at once: list their IDs before the time, pass - to
+read the IDs from stdin (mt list --format ids | mt defer - +1w), or
+select them with --where. The batch is all-or-nothing; more than five
+Issues ask for confirmation unless --yes.`
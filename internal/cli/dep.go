@@ -1,20 +1,25 @@
-// Package cli — the mt dep commands. They own the process concerns of
-// editing blocked_by (resolving the vault, reading/writing the Issue
-// file, stdio); the field mutation lives in internal/issue and the
-// blocked computation in internal/list.
+// Package cli — the mt dep commands and mt graph. They own the process
+// concerns of editing and viewing blocked_by (resolving the vault,
+// reading/writing the Issue file, stdio); the field mutation lives in
+// internal/issue, the blocked computation in internal/list and the
+// traversals and renderings of the graph in internal/graph.
 package cli
 
 import (
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/graph"
 	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/list"
 )
 
-// newDepCmd builds `mt dep`: the parent of add and rm. A bare `mt dep`
-// prints the group's help.
+// newDepCmd builds `mt dep`: the parent of add, rm, tree and impact. A
+// bare `mt dep` prints the group's help.
 func newDepCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "dep",
@@ -24,101 +29,327 @@ func newDepCmd() *cobra.Command {
 			return cmd.Help()
 		},
 	}
-	cmd.AddCommand(newDepAddCmd(), newDepRmCmd())
+	cmd.AddCommand(newDepAddCmd(), newDepRmCmd(), newDepTreeCmd(), newDepImpactCmd())
 	return cmd
 }
 
-// newDepAddCmd builds `mt dep add <id> <blocker>`: records that the
-// Issue id is blocked by the Issue blocker, of the same Vault.
+// newDepAddCmd builds `mt dep add <id...> <blocker>`: records that each
+// Issue id is blocked by the Issue blocker, of the same Vault. The
+// blocker is always the last argument; the targets before it are IDs,
+// "-" for IDs on stdin, or a --where query (see batch.go).
 func newDepAddCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "add <id> <blocker>",
-		Short: "Record that an Issue is blocked by another",
+	var flags batchFlags
+	cmd := &cobra.Command{
+		Use:   "add <id...> <blocker>",
+		Short: "Record that Issues are blocked by another",
 		Long: `add appends the blocker ID to the Issue's blocked_by: the Issue is
 blocked — hidden from ready and pick-next, marked [blocked] in list —
 until the blocker is done. The blocker must be an existing Issue of the
-same Vault, and may not be the Issue itself.`,
-		Args: depArgs("dep add"),
+same Vault, and may not be the Issue itself. Several Issues (- reads
+their IDs from stdin, --where selects them) are blocked at once, all or
+nothing.`,
+		Args: depArgs("dep add", &flags),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDepAdd(cmd, args[0], args[1])
+			return runDepAdd(cmd, args[:len(args)-1], args[len(args)-1], flags)
 		},
 	}
+	flags.register(cmd)
+	return cmd
 }
 
-// newDepRmCmd builds `mt dep rm <id> <blocker>`: removes the blocker
-// from the Issue's blocked_by.
+// newDepRmCmd builds `mt dep rm <id...> <blocker>`: removes the blocker
+// from each Issue's blocked_by.
 func newDepRmCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "rm <id> <blocker>",
-		Short: "Remove a blocker from an Issue",
+	var flags batchFlags
+	cmd := &cobra.Command{
+		Use:   "rm <id...> <blocker>",
+		Short: "Remove a blocker from Issues",
 		Long: `rm removes the blocker ID from the Issue's blocked_by. The edit is
 idempotent: removing a blocker that does not block the Issue leaves it
 untouched, so stale references (e.g. to a deleted Issue) can be cleaned
-up.`,
-		Args: depArgs("dep rm"),
+up. Several Issues (- reads their IDs from stdin, --where selects them)
+are edited at once, all or nothing.`,
+		Args: depArgs("dep rm", &flags),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDepRm(cmd, args[0], args[1])
+			return runDepRm(cmd, args[:len(args)-1], args[len(args)-1], flags)
 		},
 	}
+	flags.register(cmd)
+	return cmd
 }
 
-// depArgs validates the two positional arguments of dep add and dep rm:
-// exactly one subject ID and one blocker ID, each a single file name
-// component. A malformed invocation is a usage error (exit 2).
-func depArgs(use string) cobra.PositionalArgs {
+// depArgs validates the positional arguments of dep add and dep rm: at
+// least one subject and the blocker ID last (just the blocker with
+// --where), each a single file name component. A malformed invocation is
+// a usage error (exit 2).
+func depArgs(use string, flags *batchFlags) cobra.PositionalArgs {
 	return func(_ *cobra.Command, args []string) error {
-		if len(args) != 2 {
+		if flags.where != "" && len(args) != 1 {
+			return exitcode.Usage(fmt.Errorf("%s --where needs just a blocker ID", use))
+		}
+		if flags.where == "" && len(args) < 2 {
 			return exitcode.Usage(fmt.Errorf("%s needs an issue ID and a blocker ID", use))
 		}
-		if err := checkID(args[0]); err != nil {
-			return err
+		for _, arg := range args {
+			if arg == stdinIDs {
+				continue
+			}
+			if err := checkID(arg); err != nil {
+				return err
+			}
 		}
-		return checkID(args[1])
+		return nil
 	}
 }
 
-// runDepAdd records blocker in id's blocked_by. The subject and the
-// blocker must both exist in the Vault, and a blocker may not be the
-// Issue itself — well-formed invocations that fail against the current
-// state are user errors (exit 1), like any issue-not-found.
-func runDepAdd(cmd *cobra.Command, id, blocker string) error {
+// runDepAdd records blocker in the blocked_by of each target. The
+// targets and the blocker must all exist in the Vault, and a blocker may
+// not be an Issue itself — well-formed invocations that fail against the
+// current state are user errors (exit 1), like any issue-not-found.
+func runDepAdd(cmd *cobra.Command, ids []string, blocker string, flags batchFlags) error {
 	vaultDir, err := resolveVault(cmd)
 	if err != nil {
 		return err
 	}
-	if _, err := readIssue(vaultDir, id); err != nil {
+	if blocker, err = resolveIssueArg(vaultDir, blocker); err != nil {
 		return err
 	}
-	if _, err := readIssue(vaultDir, blocker); err != nil {
+	targets, fromStdin, err := selectTargets(cmd, vaultDir, ids, flags.where)
+	if err != nil {
 		return err
 	}
-	if blocker == id {
-		return fmt.Errorf("issue %s cannot block itself", id)
-	}
-	if _, err := mutateIssue(vaultDir, id, func(i issue.Issue) issue.Issue {
-		return i.AddBlocker(blocker)
-	}); err != nil {
+	edit, err := depEdit(blocker, false, func(id string) error {
+		_, err := readIssue(vaultDir, id)
+		return err
+	})
+	if err != nil {
 		return err
 	}
-	fmt.Fprintf(cmd.OutOrStdout(), "%s is now blocked by %s\n", id, blocker)
-	return nil
+	return runBatch(cmd, vaultDir, "dep add", targets, fromStdin, flags, edit, func(out io.Writer, step batchStep) {
+		fmt.Fprintf(out, "%s is now blocked by %s\n", step.ID, blocker)
+	})
 }
 
-// runDepRm removes blocker from id's blocked_by. The blocker need not
-// exist in the Vault: removing a stale reference is a legitimate
-// cleanup, and the edit is idempotent when the blocker is not listed.
-func runDepRm(cmd *cobra.Command, id, blocker string) error {
+// runDepRm removes blocker from the blocked_by of each target. The
+// blocker need not exist in the Vault: removing a stale reference is a
+// legitimate cleanup, and the edit is idempotent when the blocker is not
+// listed.
+func runDepRm(cmd *cobra.Command, ids []string, blocker string, flags batchFlags) error {
 	vaultDir, err := resolveVault(cmd)
 	if err != nil {
 		return err
 	}
-	if _, err := mutateIssue(vaultDir, id, func(i issue.Issue) issue.Issue {
-		return i.RemoveBlocker(blocker)
-	}); err != nil {
+	if blocker, err = resolveIssueArg(vaultDir, blocker); err != nil {
 		return err
 	}
-	fmt.Fprintf(cmd.OutOrStdout(), "%s is no longer blocked by %s\n", id, blocker)
-	return nil
+	targets, fromStdin, err := selectTargets(cmd, vaultDir, ids, flags.where)
+	if err != nil {
+		return err
+	}
+	edit, err := depEdit(blocker, true, nil)
+	if err != nil {
+		return err
+	}
+	return runBatch(cmd, vaultDir, "dep rm", targets, fromStdin, flags, edit, func(out io.Writer, step batchStep) {
+		fmt.Fprintf(out, "%s is no longer blocked by %s\n", step.ID, blocker)
+	})
+}
+
+// depEdit returns the blocked_by edit of dep add — dep rm with remove —
+// for blocker, shared by the commands and the API. A blocker is added
+// only when exists finds it in the vault, and never to itself; removing
+// one checks neither, so stale references can be cleaned up.
+func depEdit(blocker string, remove bool, exists func(id string) error) (func(id string, i issue.Issue) (issue.Issue, error), error) {
+	if remove {
+		return func(_ string, i issue.Issue) (issue.Issue, error) {
+			return i.RemoveBlocker(blocker), nil
+		}, nil
+	}
+	if err := exists(blocker); err != nil {
+		return nil, err
+	}
+	return func(id string, i issue.Issue) (issue.Issue, error) {
+		if id == blocker {
+			return issue.Issue{}, fmt.Errorf("issue %s cannot block itself", id)
+		}
+		return i.AddBlocker(blocker), nil
+	}, nil
+}
+
+// newDepTreeCmd builds `mt dep tree <id>`: what blocks the Issue,
+// transitively, and what it blocks.
+func newDepTreeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "tree <id>",
+		Short: "Show an Issue's blockers and dependents, transitively",
+		Long: `tree prints the Issue, then under "Blocked by:" its blockers, their
+blockers and so on, and under "Blocks:" the Issues it blocks, theirs and
+so on — each as its list line, indented one step per level. A blocker
+that does not exist is marked (missing); an Issue met again on its own
+branch, (cycle), and the branch stops there.`,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return exitcode.Usage(fmt.Errorf("dep tree needs exactly one issue ID"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vaultDir, err := resolveVault(cmd)
+			if err != nil {
+				return err
+			}
+			id, err := resolveIssueArg(vaultDir, args[0])
+			if err != nil {
+				return err
+			}
+			if err := checkID(id); err != nil {
+				return err
+			}
+			g, err := vaultGraph(vaultDir)
+			if err != nil {
+				return err
+			}
+			n, ok := g.Node(id)
+			if !ok {
+				return fmt.Errorf("issue %s not found", id)
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintln(out, nodeLine(n))
+			for _, branch := range []struct {
+				heading string
+				tree    graph.Tree
+			}{{"Blocked by:", g.Upstream(id)}, {"Blocks:", g.Downstream(id)}} {
+				if len(branch.tree.Children) == 0 {
+					fmt.Fprintln(out, branch.heading+" none")
+					continue
+				}
+				fmt.Fprintln(out, branch.heading)
+				for _, child := range branch.tree.Children {
+					printTree(out, child, 1)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// printTree prints t and its children, indented two spaces per depth.
+func printTree(out io.Writer, t graph.Tree, depth int) {
+	line := nodeLine(t.Node)
+	switch {
+	case t.Missing:
+		line = t.Node.ID + "  (missing)"
+	case t.Cycle:
+		line += " (cycle)"
+	}
+	fmt.Fprintln(out, strings.Repeat("  ", depth)+line)
+	for _, child := range t.Children {
+		printTree(out, child, depth+1)
+	}
+}
+
+// newDepImpactCmd builds `mt dep impact`: the Issues ranked by how many
+// others they transitively unblock.
+func newDepImpactCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "impact",
+		Short: "Rank Issues by how many others they unblock",
+		Long: `impact ranks the Issues that are not done by how many others they
+transitively unblock — the Issues not done waiting on them, directly or
+through others — most first, ties in list order. Each line is the
+Issue's list line with "(unblocks N)"; Issues that block nothing are
+left out, so an empty output means no Issue waits on another.`,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return exitcode.Usage(fmt.Errorf("dep impact takes no arguments"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			vaultDir, err := resolveVault(cmd)
+			if err != nil {
+				return err
+			}
+			g, err := vaultGraph(vaultDir)
+			if err != nil {
+				return err
+			}
+			for _, im := range g.Impacts() {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s  (unblocks %d)\n", nodeLine(im.Node), im.Count)
+			}
+			return nil
+		},
+	}
+}
+
+// Graph formats of mt graph.
+const (
+	graphDOT     = "dot"
+	graphMermaid = "mermaid"
+)
+
+// newGraphCmd builds `mt graph`: the vault's blocked_by graph, in the
+// Graphviz DOT language or as a Mermaid flowchart.
+func newGraphCmd() *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Export the dependency graph (Graphviz DOT or Mermaid)",
+		Long: `graph prints the vault's blocked_by graph for a diagram tool: one node
+per Issue that is not done — plus the done Issues still listed as a
+blocker, dashed, and the missing ones, in red — labeled with its ID,
+title and status, and an arrow from each blocker to the Issue it blocks.
+
+  mt graph | dot -Tsvg > deps.svg      Graphviz (--format dot, the default)
+  mt graph --format mermaid            a Mermaid flowchart, for Markdown`,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return exitcode.Usage(fmt.Errorf("graph takes no arguments"))
+			}
+			if format != graphDOT && format != graphMermaid {
+				return exitcode.Usage(fmt.Errorf("unknown graph format %q (want dot or mermaid)", format))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			vaultDir, err := resolveVault(cmd)
+			if err != nil {
+				return err
+			}
+			g, err := vaultGraph(vaultDir)
+			if err != nil {
+				return err
+			}
+			if format == graphMermaid {
+				fmt.Fprint(cmd.OutOrStdout(), g.Mermaid())
+				return nil
+			}
+			fmt.Fprint(cmd.OutOrStdout(), g.DOT())
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", graphDOT, "output format: dot or mermaid")
+	return cmd
+}
+
+// vaultGraph builds the blocked_by graph of the vault's Issues, in list
+// order.
+func vaultGraph(vaultDir string) (*graph.Graph, error) {
+	items, err := loadSortedItems(vaultDir)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]graph.Node, len(items))
+	for i, it := range items {
+		fm := it.Issue.Frontmatter
+		nodes[i] = graph.Node{ID: it.ID, Title: fm.Title, Status: fm.Status, BlockedBy: fm.BlockedBy}
+	}
+	return graph.New(nodes), nil
+}
+
+// nodeLine renders n as its list line (see formatListLine).
+func nodeLine(n graph.Node) string {
+	return formatListLine(list.Item{ID: n.ID, Issue: issue.Issue{Frontmatter: issue.Frontmatter{Title: n.Title, Status: n.Status}}})
 }
 
 const depLong = `dep manages Issue dependencies: the blocked_by field of an Issue lists
@@ -126,9 +357,11 @@ the IDs of the Issues that block it, all in the same Vault. An Issue is
 blocked while any of its blockers is not done — computed state, not a
 status: closing the blocker unblocks it on its own.
 
-  mt dep add <id> <blocker>   record that <blocker> blocks <id>
-  mt dep rm <id> <blocker>    remove <blocker> from <id>'s blocked_by
+  mt dep add <id...> <blocker>   record that <blocker> blocks each <id>
+  mt dep rm <id...> <blocker>    remove <blocker> from each blocked_by
+  mt dep tree <id>               what blocks <id> and what it blocks
+  mt dep impact                  Issues ranked by how many they unblock
 
 Blocked Issues are marked [blocked] in list and skipped by ready and
 pick-next. mt check validates the references: existence, no self-block,
-no cycles.`
+no cycles. mt graph exports the whole graph for Graphviz or Mermaid.`
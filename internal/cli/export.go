@@ -0,0 +1,163 @@
+// Package cli — the mt export commands. They own the process concerns of
+// an export (resolving the vault, reading the Issues, writing the output
+// directory); the pages themselves are built by internal/site.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/site"
+	"github.com/Sanmoo/my-tasks2/internal/vault"
+)
+
+// newExportCmd builds `mt export`: the parent of the export formats. A
+// bare `mt export` prints the group's help.
+func newExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the vault to other formats",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newExportHTMLCmd())
+	return cmd
+}
+
+// newExportHTMLCmd builds `mt export html <dir>`: writes the static HTML
+// snapshot of the resolved vault into dir.
+func newExportHTMLCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "html <dir>",
+		Short: "Export the vault as a static, read-only HTML site",
+		Long:  exportHTMLLong,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return exitcode.Usage(errors.New("export html needs an output directory"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExportHTML(cmd, args[0])
+		},
+	}
+}
+
+// runExportHTML builds the pages and writes them under dir, creating it.
+// Pages of a previous export that no longer exist (a deleted Issue, a
+// label no Issue carries) are removed; nothing else in dir is touched.
+func runExportHTML(cmd *cobra.Command, dir string) error {
+	vaultDir, err := resolveVault(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := vault.LoadVault(vaultDir)
+	if err != nil {
+		return err
+	}
+	same, err := samePath(dir, vaultDir)
+	if err != nil {
+		return err
+	}
+	if same {
+		return errors.New("cannot export into the vault itself: choose another directory")
+	}
+	items, err := loadItems(vaultDir)
+	if err != nil {
+		return err
+	}
+	now, err := clock(cfg)
+	if err != nil {
+		return err
+	}
+	pages, err := site.Build(items, site.Options{Title: cfg.Prefix, Now: now})
+	if err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(pages))
+	for path := range pages {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("creating %s: %w", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, pages[path], 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", target, err)
+		}
+	}
+	if err := removeStalePages(dir, pages); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Exported %d issues to %s\n", len(items), filepath.Join(dir, site.IndexPage))
+	return nil
+}
+
+// samePath reports whether a and b name the same directory.
+func samePath(a, b string) (bool, error) {
+	absA, err := filepath.Abs(a)
+	if err != nil {
+		return false, fmt.Errorf("resolving %s: %w", a, err)
+	}
+	absB, err := filepath.Abs(b)
+	if err != nil {
+		return false, fmt.Errorf("resolving %s: %w", b, err)
+	}
+	return absA == absB, nil
+}
+
+// pageDirs are the directories of an export whose pages are generated,
+// and so pruned when stale.
+var pageDirs = []string{"issues", "status", "labels"}
+
+// removeStalePages removes the .html files of the page directories of
+// dir that are not pages of this export.
+func removeStalePages(dir string, pages map[string][]byte) error {
+	for _, sub := range pageDirs {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", filepath.Join(dir, sub), err)
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".html") {
+				continue
+			}
+			if _, ok := pages[sub+"/"+e.Name()]; ok {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, sub, e.Name())); err != nil {
+				return fmt.Errorf("removing stale page: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+const exportHTMLLong = `html writes a static, read-only HTML snapshot of the vault into dir —
+to browse it on a tablet or publish it — creating dir when needed:
+
+  index.html           the open Issues in Rank order, with status and
+                       label facets
+  status/<status>.html one page per status (done included)
+  labels/<label>.html  one page per label
+  issues/<id>.html     one page per Issue: the mt show metadata, the
+                       rendered Markdown body, comments with permalinks,
+                       blocked_by and "blocks" links
+  overdue.html         expired deferrals and passed deadlines
+
+The pages are self-contained: inline style, no scripts, no external
+assets, relative links. Running it again refreshes the pages and removes
+those of deleted Issues; other files in dir are left alone.`
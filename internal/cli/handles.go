@@ -0,0 +1,99 @@
+// Package cli — numeric handles. The listing views remember the ID order
+// they printed, and every id-taking command accepts the handle (the
+// small number in the listing's first column) in place of the ID. The
+// persistence format and resolution rules live in internal/handle.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sanmoo/my-tasks2/internal/handle"
+)
+
+// handlesPath returns the handle file of vaultDir in the user cache dir
+// ($XDG_CACHE_HOME/mt/handles, or ~/.cache/mt/handles), with the vault
+// path made absolute so @bookmark and --vault share one listing.
+func handlesPath(vaultDir string) (path, absVault string, err error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", "", fmt.Errorf("locating cache directory: %w", err)
+	}
+	absVault, err = filepath.Abs(vaultDir)
+	if err != nil {
+		return "", "", fmt.Errorf("locating vault %s: %w", vaultDir, err)
+	}
+	return handle.Path(cacheDir, absVault), absVault, nil
+}
+
+// saveHandles remembers listed (in display order) as the vault's last
+// listing. vaultIDs is the vault's whole Issue set, the fingerprint that
+// later invalidates the handles. The handles are a convenience: failing
+// to save them warns on stderr and never fails the listing.
+func saveHandles(cmd *cobra.Command, vaultDir string, listed, vaultIDs []string) {
+	path, absVault, err := handlesPath(vaultDir)
+	if err == nil {
+		err = handle.Map{Vault: absVault, Fingerprint: handle.Fingerprint(vaultIDs), IDs: listed}.Save(path)
+	}
+	if err != nil {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Warning: could not save list handles:", err)
+	}
+}
+
+// resolveIssueArg maps a handle from the vault's last listing back to its
+// Issue ID; any other argument comes back unchanged. A handle that
+// cannot be resolved (no listing, stale listing, out of range) is a user
+// error: the invocation is well-formed, the listing state is wrong.
+func resolveIssueArg(vaultDir, arg string) (string, error) {
+	if _, ok := handle.Parse(arg); !ok {
+		return arg, nil
+	}
+	path, absVault, err := handlesPath(vaultDir)
+	if err != nil {
+		return "", err
+	}
+	m, err := handle.Load(path)
+	if err != nil {
+		return "", err
+	}
+	ids, err := vaultIssueIDs(vaultDir)
+	if err != nil {
+		return "", err
+	}
+	return handle.Resolve(arg, m, absVault, handle.Fingerprint(ids))
+}
+
+// vaultIssueIDs returns the IDs of every Issue file in the vault, in
+// directory order, without reading the files.
+func vaultIssueIDs(vaultDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(vaultDir, "issues"))
+	if err != nil {
+		return nil, fmt.Errorf("reading issues directory: %w", err)
+	}
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if name := e.Name(); strings.HasSuffix(name, ".md") {
+			ids = append(ids, strings.TrimSuffix(name, ".md"))
+		}
+	}
+	return ids, nil
+}
+
+// withHandles prefixes each listing line with its handle, right-aligned
+// so the glyph column stays straight: " 1 ○ pkm-001  title".
+func withHandles(lines []string) []string {
+	width := len(strconv.Itoa(len(lines)))
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = fmt.Sprintf("%*d %s", width, i+1, line)
+	}
+	return out
+}
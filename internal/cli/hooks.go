@@ -0,0 +1,194 @@
+// Package cli — hooks. The hooks: key of the global config and of mt.yaml
+// maps lifecycle events to commands (see internal/hook). A pre_<event>
+// hook runs before the change is written and vetoes it by exiting
+// non-zero; a batch is vetted as a whole before its first write. An
+// <event> hook runs after the command, from the changes the undo journal
+// recorded, and its failure only warns.
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/Sanmoo/my-tasks2/internal/hook"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/vault"
+)
+
+// hookEnv is set, to the event, in the environment of a hook process. An
+// mt run by a hook fires no hooks, so hooks that change Issues cannot
+// loop.
+const hookEnv = "MT_HOOK"
+
+// hookState is the hooks config of the running command's vault, loaded
+// once, and the content each Issue's pre-hooks already approved.
+type hookState struct {
+	vaultDir string
+	cfg      hook.Config
+	loaded   bool
+	// vetted maps an Issue ID to the canonical rendering of the Issue
+	// vetChange approved, so writeIssueFile does not run the pre-hooks of
+	// a planned batch step a second time. The Issue is compared, not the
+	// file bytes: a patch of a hand-edited file keeps its own layout.
+	vetted map[string]string
+}
+
+// hooks is the hook state of the running command. Run is single-shot per
+// process, so a package var is safe (like pending).
+var hooks hookState
+
+// loadHooks returns the hooks for vaultDir: the global ones, then the
+// vault's. A directory without mt.yaml has only the global hooks; under
+// a hook there are none.
+func loadHooks(vaultDir string) (hook.Config, error) {
+	if os.Getenv(hookEnv) != "" {
+		return nil, nil
+	}
+	if hooks.loaded && hooks.vaultDir == vaultDir {
+		return hooks.cfg, nil
+	}
+	global, _, err := loadGlobal()
+	if err != nil {
+		return nil, fmt.Errorf("loading global config: %w", err)
+	}
+	vcfg, err := vault.LoadVault(vaultDir)
+	if err != nil && !errors.Is(err, vault.ErrNotVault) {
+		return nil, fmt.Errorf("loading vault: %w", err)
+	}
+	hooks.vaultDir, hooks.cfg, hooks.loaded = vaultDir, hook.Merge(global.Hooks, vcfg.Hooks), true
+	return hooks.cfg, nil
+}
+
+// vetChange runs the pre-hooks of the events the change of the Issue id
+// from before to after (nil: no file) fires. The first hook that exits
+// non-zero, or cannot run, vetoes the change.
+func vetChange(vaultDir, id string, before, after *issue.Issue) error {
+	cfg, err := loadHooks(vaultDir)
+	if err != nil {
+		return err
+	}
+	for _, event := range hook.Detect(before, after) {
+		name := hook.PrePrefix + event
+		if len(cfg[name]) == 0 {
+			continue
+		}
+		payload, err := hookPayload(name, vaultDir, id, before, after)
+		if err != nil {
+			return err
+		}
+		for _, command := range cfg[name] {
+			if err := runHook(vaultDir, name, command, payload, os.Stderr); err != nil {
+				return fmt.Errorf("%s hook %q vetoed the change to %s: %w", name, command, id, err)
+			}
+		}
+	}
+	if after != nil {
+		data, err := issue.Render(*after)
+		if err != nil {
+			return err
+		}
+		if hooks.vetted == nil {
+			hooks.vetted = make(map[string]string)
+		}
+		hooks.vetted[id] = string(data)
+	}
+	return nil
+}
+
+// vetWrite vets writing after over the Issue file content before —
+// unless vetChange already approved exactly after.
+func vetWrite(vaultDir, id string, before []byte, after issue.Issue) error {
+	if vetted, ok := hooks.vetted[id]; ok {
+		canonical, err := issue.Render(after)
+		if err != nil {
+			return err
+		}
+		if vetted == string(canonical) {
+			return nil
+		}
+	}
+	prev, err := issue.Parse(before)
+	if err != nil {
+		return fmt.Errorf("parsing issue %s: %w", id, err)
+	}
+	return vetChange(vaultDir, id, &prev, &after)
+}
+
+// runPostHooks runs the hooks of the events the command's journaled
+// changes fire, once the command has returned — also when it failed
+// partway, for what it did write. A failing hook warns on stderr and
+// never fails the command: the Issue files are already written.
+func runPostHooks(stderr io.Writer) {
+	if len(pending.changes) == 0 {
+		return
+	}
+	cfg, err := loadHooks(pending.vaultDir)
+	if err != nil {
+		fmt.Fprintln(stderr, "Warning: could not run hooks:", err)
+		return
+	}
+	for _, c := range pending.changes {
+		before, okBefore := parseImage(c.Before)
+		after, okAfter := parseImage(c.After)
+		if !okBefore || !okAfter {
+			continue
+		}
+		for _, event := range hook.Detect(before, after) {
+			if len(cfg[event]) == 0 {
+				continue
+			}
+			payload, err := hookPayload(event, pending.vaultDir, c.ID, before, after)
+			if err != nil {
+				fmt.Fprintln(stderr, "Warning: could not run hooks:", err)
+				return
+			}
+			for _, command := range cfg[event] {
+				if err := runHook(pending.vaultDir, event, command, payload, stderr); err != nil {
+					fmt.Fprintf(stderr, "Warning: %s hook %q failed for %s: %v\n", event, command, c.ID, err)
+				}
+			}
+		}
+	}
+}
+
+// hookPayload encodes the payload of event for the running command, with
+// the vault as an absolute path: the hook runs inside it.
+func hookPayload(event, vaultDir, id string, before, after *issue.Issue) ([]byte, error) {
+	abs, err := filepath.Abs(vaultDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolving vault path: %w", err)
+	}
+	return hook.NewPayload(event, abs, id, pending.command, before, after).JSON()
+}
+
+// parseImage parses a journal image; nil (no file) is a nil Issue. ok is
+// false when the content is not a valid Issue (hand-edited): its events
+// cannot be told.
+func parseImage(s *string) (i *issue.Issue, ok bool) {
+	if s == nil {
+		return nil, true
+	}
+	parsed, err := issue.Parse([]byte(*s))
+	if err != nil {
+		return nil, false
+	}
+	return &parsed, true
+}
+
+// runHook runs one hook command in the vault directory, without a shell,
+// with the payload on stdin. Its output goes to stderr, keeping mt's
+// stdout for mt's own output.
+func runHook(vaultDir, event string, command hook.Command, payload []byte, stderr io.Writer) error {
+	c := exec.Command(command[0], command[1:]...)
+	c.Dir = vaultDir
+	c.Stdin = bytes.NewReader(payload)
+	c.Stdout = stderr
+	c.Stderr = stderr
+	c.Env = append(os.Environ(), hookEnv+"="+event)
+	return c.Run()
+}
@@ -0,0 +1,11 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !illumos && !linux && !netbsd && !openbsd && !solaris
+
+package cli
+
+import "os"
+
+// inodeOf is unavailable on this platform: the index key falls back to
+// the file's size and modification time alone.
+func inodeOf(os.FileInfo) uint64 {
+	return 0
+}
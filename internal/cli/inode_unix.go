@@ -0,0 +1,18 @@
+//go:build aix || darwin || dragonfly || freebsd || illumos || linux || netbsd || openbsd || solaris
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// inodeOf returns the inode number of info's file, part of the index key:
+// a file replaced by a rename gets a new inode even when its size and
+// modification time match the old one.
+func inodeOf(info os.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Ino)
+	}
+	return 0
+}
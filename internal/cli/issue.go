@@ -1,6 +1,7 @@
-// Package cli — Issue commands: create, q, show and edit. These own
-// process concerns (files, the editor, stdio); the Issue schema itself
-// lives in internal/issue.
+// Package cli — Issue commands: show and edit, plus the Issue file
+// helpers shared with create (create.go). These own process concerns
+// (files, the editor, stdio); the Issue schema itself lives in
+// internal/issue.
 package cli
 
 import (
@@ -10,7 +11,6 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
@@ -18,96 +18,8 @@ import (
 	"github.com/Sanmoo/my-tasks2/internal/exitcode"
 	"github.com/Sanmoo/my-tasks2/internal/issue"
 	"github.com/Sanmoo/my-tasks2/internal/show"
-	"github.com/Sanmoo/my-tasks2/internal/vault"
 )
 
-// newCreateCmd builds `mt create <título>`: writes a new Issue file with
-// the spec schema. The title is the remaining positional args joined
-// with spaces, so it needs no shell quoting.
-func newCreateCmd() *cobra.Command {
-	var labels []string
-	cmd := &cobra.Command{
-		Use:   "create <title>",
-		Short: "Create a new Issue",
-		Long: `create writes a new Issue file (issues/<id>.md) with the spec schema:
-title, status (open), labels and created_at in the frontmatter, and the
-empty Description/Notes/Comments body. The ID is the vault prefix plus a
-short random suffix; created_at is stamped automatically.`,
-		Args: func(cmd *cobra.Command, args []string) error {
-			if len(args) < 1 {
-				return exitcode.Usage(fmt.Errorf("create needs a title"))
-			}
-			return nil
-		},
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return runCreate(cmd, strings.Join(args, " "), labels, false)
-		},
-	}
-	cmd.Flags().StringArrayVar(&labels, "label", nil, "label; repeatable (free-form)")
-	return cmd
-}
-
-// newQCmd builds `mt q <título>`: like create, but prints only the ID —
-// for capturing ideas without leaving the flow.
-func newQCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "q <title>",
-		Short: "Create an Issue and print only its ID",
-		Long:  "q is the quiet create: it writes the same Issue file as create, but prints only the new ID.",
-		Args: func(cmd *cobra.Command, args []string) error {
-			if len(args) < 1 {
-				return exitcode.Usage(fmt.Errorf("q needs a title"))
-			}
-			return nil
-		},
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return runCreate(cmd, strings.Join(args, " "), nil, true)
-		},
-	}
-}
-
-// runCreate writes a new Issue with title and labels and prints its ID
-// (just the ID when quiet, a confirmation line otherwise).
-func runCreate(cmd *cobra.Command, title string, labels []string, quiet bool) error {
-	vaultDir, err := resolveVault(cmd)
-	if err != nil {
-		return err
-	}
-	vcfg, err := vault.LoadVault(vaultDir)
-	if err != nil {
-		return err
-	}
-	if vcfg.Prefix == "" {
-		return fmt.Errorf("vault %s has no ID prefix in its config — set prefix in mt.yaml", vaultDir)
-	}
-	id, err := newIssueID(vcfg.Prefix, vaultDir)
-	if err != nil {
-		return err
-	}
-	i := issue.Issue{
-		Frontmatter: issue.Frontmatter{
-			Title:     title,
-			Status:    "open",
-			Labels:    labels,
-			CreatedAt: time.Now().Format(issue.NaiveLayout),
-		},
-		Body: issue.DefaultBody,
-	}
-	data, err := issue.Render(i)
-	if err != nil {
-		return err
-	}
-	if err := os.WriteFile(issuePath(vaultDir, id), data, 0o644); err != nil {
-		return fmt.Errorf("writing issue %s: %w", id, err)
-	}
-	if quiet {
-		fmt.Fprintln(cmd.OutOrStdout(), id)
-	} else {
-		fmt.Fprintf(cmd.OutOrStdout(), "Created %s\n", id)
-	}
-	return nil
-}
-
 // newShowCmd builds `mt show <id>`: renders the structured, colored
 // Issue view (header, metadata, Markdown body) in the style of nd show.
 // Colors follow the standard convention (NO_COLOR, CLICOLOR, TTY); a
@@ -127,26 +39,35 @@ func newShowCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			if err := checkID(args[0]); err != nil {
+			id, err := resolveIssueArg(vaultDir, args[0])
+			if err != nil {
+				return err
+			}
+			if err := checkID(id); err != nil {
 				return err
 			}
-			data, err := os.ReadFile(issuePath(vaultDir, args[0]))
+			data, err := os.ReadFile(issuePath(vaultDir, id))
 			if err != nil {
 				if os.IsNotExist(err) {
-					return fmt.Errorf("issue %s not found", args[0])
+					return fmt.Errorf("issue %s not found", id)
 				}
-				return fmt.Errorf("reading issue %s: %w", args[0], err)
+				return fmt.Errorf("reading issue %s: %w", id, err)
 			}
 			i, err := issue.Parse(data)
 			if err != nil {
-				return fmt.Errorf("parsing issue %s: %w", args[0], err)
+				return fmt.Errorf("parsing issue %s: %w", id, err)
+			}
+			now, _, err := vaultClock(vaultDir)
+			if err != nil {
+				return err
 			}
 			// TTY detection reads the real stdout, not the injected
 			// writer: the decision is about the terminal the process
 			// is attached to.
-			_, err = fmt.Fprint(cmd.OutOrStdout(), show.Render(i, args[0], show.Options{
-				Color: show.ShouldUseColor(term.IsTerminal(int(os.Stdout.Fd()))),
-				Width: termWidth(),
+			_, err = fmt.Fprint(cmd.OutOrStdout(), show.Render(i, id, show.Options{
+				Color:    show.ShouldUseColor(term.IsTerminal(int(os.Stdout.Fd()))),
+				Width:    termWidth(),
+				Location: now.Location(),
 			}))
 			return err
 		},
@@ -170,17 +91,31 @@ func newEditCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			if err := checkID(args[0]); err != nil {
+			id, err := resolveIssueArg(vaultDir, args[0])
+			if err != nil {
+				return err
+			}
+			if err := checkID(id); err != nil {
 				return err
 			}
-			path := issuePath(vaultDir, args[0])
+			path := issuePath(vaultDir, id)
 			if _, err := os.Stat(path); err != nil {
 				if os.IsNotExist(err) {
-					return fmt.Errorf("issue %s not found", args[0])
+					return fmt.Errorf("issue %s not found", id)
 				}
-				return fmt.Errorf("checking issue %s: %w", args[0], err)
+				return fmt.Errorf("checking issue %s: %w", id, err)
+			}
+			before, err := readIssueData(vaultDir, id)
+			if err != nil {
+				return err
+			}
+			editErr := editFile(path)
+			// Whatever the editor left behind is journaled, even when it
+			// exits non-zero after saving.
+			if after, err := readIssueImage(vaultDir, id); err == nil {
+				recordChange(vaultDir, id, before, after)
 			}
-			return editFile(path)
+			return editErr
 		},
 	}
 }
@@ -213,19 +148,13 @@ func checkID(id string) error {
 // newIssueID allocates an ID for a new Issue: the vault prefix plus a
 // random suffix that does not collide with any existing issue file.
 func newIssueID(prefix, vaultDir string) (string, error) {
-	entries, err := os.ReadDir(filepath.Join(vaultDir, "issues"))
+	ids, err := vaultIssueIDs(vaultDir)
 	if err != nil {
-		return "", fmt.Errorf("reading issues directory: %w", err)
+		return "", err
 	}
-	taken := make(map[string]bool, len(entries))
-	for _, e := range entries {
-		if e.IsDir() {
-			continue
-		}
-		name := e.Name()
-		if strings.HasSuffix(name, ".md") {
-			taken[strings.TrimSuffix(name, ".md")] = true
-		}
+	taken := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		taken[id] = true
 	}
 	return issue.NextID(prefix, taken, rand.Reader)
 }
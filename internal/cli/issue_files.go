@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/Sanmoo/my-tasks2/internal/issue"
 )
@@ -54,13 +55,23 @@ func readIssueData(vaultDir, id string) ([]byte, error) {
 	return data, nil
 }
 
-func readIssueFiles(vaultDir string) ([]parsedIssueFile, error) {
+// issueFile is one Issue file found in the vault's issues/ directory.
+type issueFile struct {
+	ID   string
+	Name string
+	Info os.FileInfo
+}
+
+// listIssueFiles returns the *.md files of the vault's issues/ directory,
+// in name order. A symlink or any other non-regular file named like an
+// Issue fails the whole listing with the offending ID named.
+func listIssueFiles(vaultDir string) ([]issueFile, error) {
 	dir := filepath.Join(vaultDir, "issues")
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("reading issues directory: %w", err)
 	}
-	files := make([]parsedIssueFile, 0, len(entries))
+	files := make([]issueFile, 0, len(entries))
 	for _, entry := range entries {
 		name := entry.Name()
 		if !strings.HasSuffix(name, ".md") {
@@ -80,15 +91,86 @@ func readIssueFiles(vaultDir string) ([]parsedIssueFile, error) {
 		if !info.Mode().IsRegular() {
 			return nil, fmt.Errorf("issue %s is not a regular file", id)
 		}
-		data, err := readIssueData(vaultDir, id)
-		if err != nil {
-			return nil, fmt.Errorf("reading issue %s: %w", id, err)
-		}
-		i, err := issue.Parse(data)
+		files = append(files, issueFile{ID: id, Name: name, Info: info})
+	}
+	return files, nil
+}
+
+// parseIssueFile reads and parses the Issue id.
+func parseIssueFile(vaultDir, id string) (parsedIssueFile, error) {
+	data, err := readIssueData(vaultDir, id)
+	if err != nil {
+		return parsedIssueFile{}, fmt.Errorf("reading issue %s: %w", id, err)
+	}
+	i, err := issue.Parse(data)
+	if err != nil {
+		return parsedIssueFile{}, fmt.Errorf("parsing issue %s: %w", id, err)
+	}
+	return parsedIssueFile{ID: id, Data: data, Issue: i}, nil
+}
+
+// readIssueFrontmatter reads only the frontmatter of the Issue id, never
+// its body: the views that list Issues need nothing more.
+func readIssueFrontmatter(vaultDir, id string) (issue.Frontmatter, error) {
+	f, err := openIssueFile(vaultDir, id, os.O_RDONLY)
+	if err != nil {
+		return issue.Frontmatter{}, fmt.Errorf("reading issue %s: %w", id, err)
+	}
+	fm, parseErr := issue.ParseFrontmatter(f)
+	closeErr := f.Close()
+	if parseErr != nil {
+		return issue.Frontmatter{}, fmt.Errorf("parsing issue %s: %w", id, parseErr)
+	}
+	if closeErr != nil {
+		return issue.Frontmatter{}, fmt.Errorf("closing issue %s: %w", id, closeErr)
+	}
+	return fm, nil
+}
+
+// maxIssueReaders bounds how many Issue files are read at once.
+const maxIssueReaders = 16
+
+// forEachParallel calls fn(0..n-1) on at most maxIssueReaders goroutines.
+// fn writes its result into its own slot, so the caller's order stays the
+// file order; the error returned is that of the lowest failing index, the
+// one a sequential loop would have stopped at.
+func forEachParallel(n int, fn func(i int) error) error {
+	errs := make([]error, n)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(n, maxIssueReaders) {
+		wg.Go(func() {
+			for i := range next {
+				errs[i] = fn(i)
+			}
+		})
+	}
+	for i := range n {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	for _, err := range errs {
 		if err != nil {
-			return nil, fmt.Errorf("parsing issue %s: %w", id, err)
+			return err
 		}
-		files = append(files, parsedIssueFile{ID: id, Data: data, Issue: i})
 	}
-	return files, nil
+	return nil
+}
+
+func readIssueFiles(vaultDir string) ([]parsedIssueFile, error) {
+	files, err := listIssueFiles(vaultDir)
+	if err != nil {
+		return nil, err
+	}
+	parsed := make([]parsedIssueFile, len(files))
+	err = forEachParallel(len(files), func(i int) error {
+		var err error
+		parsed[i], err = parseIssueFile(vaultDir, files[i].ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parsed, nil
 }
@@ -0,0 +1,126 @@
+// Package cli_test — the tests and benchmarks of the vault readers,
+// through cli.Run: the error a malformed vault reports stays that of the
+// first bad file in name order, and the listing of a large synthetic
+// vault is measured with a cold and a warm index and against the full
+// parse of check.
+package cli_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sanmoo/my-tasks2/internal/cli"
+	"github.com/Sanmoo/my-tasks2/internal/index"
+)
+
+// writeVault writes a vault of n Issues, each with a comment thread,
+// dated an hour ago so the index may cache them. bad names Issues
+// written with an unclosed frontmatter.
+func writeVault(tb testing.TB, n int, bad ...int) string {
+	tb.Helper()
+	tb.Setenv("XDG_CONFIG_HOME", tb.TempDir())
+	tb.Setenv("XDG_CACHE_HOME", tb.TempDir())
+	dir := tb.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "issues"), 0o755); err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mt.yaml"), []byte("prefix: pkm\n"), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+	comments := strings.Repeat("### 2026-01-02T10:00\nsome comment text, a line of it\n", 40)
+	old := time.Now().Add(-time.Hour)
+	for i := 1; i <= n; i++ {
+		content := fmt.Sprintf("---\ntitle: issue %d\nstatus: open\nlabels: [casa]\ncreated_at: 2026-01-01T10:00\nrank: %d\n---\n\n## Description\n## Notes\n## Comments\n%s", i, i, comments)
+		for _, b := range bad {
+			if b == i {
+				content = "---\ntitle: broken\n"
+			}
+		}
+		path := filepath.Join(dir, "issues", fmt.Sprintf("pkm-%05d.md", i))
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func run(tb testing.TB, args ...string) (int, string, string) {
+	tb.Helper()
+	var stdout, stderr strings.Builder
+	code := cli.Run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestListReportsTheFirstMalformedIssue(t *testing.T) {
+	dir := writeVault(t, 200, 170, 37, 120)
+	for range 5 {
+		code, _, stderr := run(t, "--vault", dir, "list")
+		if code != 1 || !strings.Contains(stderr, "parsing issue pkm-00037") {
+			t.Fatalf("list = %d, %q; want exit 1 naming pkm-00037", code, stderr)
+		}
+		code, _, stderr = run(t, "--vault", dir, "check")
+		if code != 1 || !strings.Contains(stderr, "pkm-00037") {
+			t.Fatalf("check = %d, %q; want exit 1 naming pkm-00037", code, stderr)
+		}
+	}
+}
+
+func TestListKeepsTheListOrder(t *testing.T) {
+	dir := writeVault(t, 100)
+	var first string
+	for i := range 3 {
+		code, stdout, stderr := run(t, "--vault", dir, "list")
+		if code != 0 {
+			t.Fatalf("list = %d, %q", code, stderr)
+		}
+		if !strings.HasSuffix(strings.SplitN(stdout, "\n", 2)[0], "pkm-00001  issue 1") || strings.Count(stdout, "\n") != 100 {
+			t.Fatalf("list output:\n%s", stdout)
+		}
+		if i == 0 {
+			first = stdout
+		} else if stdout != first {
+			t.Fatalf("list output changed between runs (index cold, then warm)")
+		}
+	}
+}
+
+// benchVault is the synthetic vault size of the benchmarks.
+const benchVault = 10000
+
+func BenchmarkListColdIndex(b *testing.B) {
+	dir := writeVault(b, benchVault)
+	for b.Loop() {
+		os.Remove(index.Path(dir))
+		if code, _, stderr := run(b, "--vault", dir, "list", "--all"); code != 0 {
+			b.Fatal(stderr)
+		}
+	}
+}
+
+func BenchmarkListWarmIndex(b *testing.B) {
+	dir := writeVault(b, benchVault)
+	if code, _, stderr := run(b, "--vault", dir, "reindex"); code != 0 {
+		b.Fatal(stderr)
+	}
+	for b.Loop() {
+		if code, _, stderr := run(b, "--vault", dir, "list", "--all"); code != 0 {
+			b.Fatal(stderr)
+		}
+	}
+}
+
+func BenchmarkCheckFullParse(b *testing.B) {
+	dir := writeVault(b, benchVault)
+	for b.Loop() {
+		if code, _, stderr := run(b, "--vault", dir, "check"); code != 0 {
+			b.Fatal(stderr)
+		}
+	}
+}
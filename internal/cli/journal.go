@@ -0,0 +1,302 @@
+// Package cli — the undo journal. Every Issue file write of a command is
+// recorded (pre- and post-image) as it happens and, once the command
+// returns, appended as one entry to the vault's journal; mt journal lists
+// the entries and mt undo reverts the latest ones. The journal format and
+// the undo planning live in internal/journal.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/journal"
+)
+
+// journalTimeLayout is the local timestamp of a journal entry: the vault's
+// naive datetime, with seconds.
+const journalTimeLayout = "2006-01-02T15:04:05"
+
+// journalLimit is how many entries mt journal shows by default.
+const journalLimit = 20
+
+// pending is the journal entry of the running command, filled by every
+// Issue file write and flushed by Run when the command returns. Run is
+// single-shot per process, so a package var is safe (like bookmark).
+var pending struct {
+	command  string
+	vaultDir string
+	changes  []journal.Change
+	undoes   []int
+}
+
+// startJournal resets the pending entry for the command line args.
+func startJournal(args []string) {
+	words := make([]string, 0, len(args)+1)
+	words = append(words, "mt")
+	for _, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\n\"'") {
+			a = strconv.Quote(a)
+		}
+		words = append(words, a)
+	}
+	pending.command = strings.Join(words, " ")
+	pending.vaultDir = ""
+	pending.changes = nil
+	pending.undoes = nil
+}
+
+// recordChange notes that the command changed the Issue file id of
+// vaultDir from before to after (nil: no file).
+func recordChange(vaultDir, id string, before, after []byte) {
+	if pending.vaultDir == "" {
+		pending.vaultDir = vaultDir
+	}
+	pending.changes = journal.Record(pending.changes, journal.Change{ID: id, Before: image(before), After: image(after)})
+}
+
+// image turns file content into a journal image; nil stays nil (no file).
+func image(data []byte) *string {
+	if data == nil {
+		return nil
+	}
+	s := string(data)
+	return &s
+}
+
+// flushJournal appends the command's entry to its vault's journal, if it
+// changed anything — also when the command failed partway, so whatever
+// it did write stays undoable. Failing to journal warns on stderr and
+// never fails the command: the Issue files are already written.
+func flushJournal(stderr io.Writer) {
+	if len(pending.changes) == 0 && len(pending.undoes) == 0 {
+		return
+	}
+	path := journal.Path(pending.vaultDir)
+	entries, err := journal.Load(path)
+	if err == nil {
+		err = journal.Append(path, journal.Entry{
+			Seq:     journal.NextSeq(entries),
+			Time:    time.Now().Format(journalTimeLayout),
+			Command: pending.command,
+			Changes: pending.changes,
+			Undoes:  pending.undoes,
+		})
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "Warning: could not journal this change (mt undo will not see it):", err)
+	}
+}
+
+// readIssueImage returns the current content of the Issue file id, nil
+// when there is none.
+func readIssueImage(vaultDir, id string) ([]byte, error) {
+	if _, err := os.Lstat(issuePath(vaultDir, id)); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return readIssueData(vaultDir, id)
+}
+
+// newUndoCmd builds `mt undo [n]`: reverts the n (default 1) latest
+// journaled operations that were not undone yet.
+func newUndoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "undo [n]",
+		Short: "Revert the latest journaled operations",
+		Long:  undoLong,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return exitcode.Usage(fmt.Errorf("undo takes at most one count"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n := 1
+			if len(args) == 1 {
+				var err error
+				if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+					return exitcode.Usage(fmt.Errorf("undo count must be a positive integer, got %q", args[0]))
+				}
+			}
+			return runUndo(cmd, n)
+		},
+	}
+}
+
+// runUndo plans the reversal of the n latest operations and, when every
+// touched file still holds its journaled post-image, restores the
+// pre-images. The undo is journaled itself, marking the reverted entries.
+func runUndo(cmd *cobra.Command, n int) error {
+	vaultDir, err := resolveVault(cmd)
+	if err != nil {
+		return err
+	}
+	entries, err := journal.Load(journal.Path(vaultDir))
+	if err != nil {
+		return err
+	}
+	plan, seqs, err := journal.PlanUndo(entries, n, func(id string) (*string, error) {
+		if err := checkID(id); err != nil {
+			return nil, err
+		}
+		data, err := readIssueImage(vaultDir, id)
+		return image(data), err
+	})
+	if err != nil {
+		return err
+	}
+	for _, c := range plan {
+		if err := restoreIssueFile(vaultDir, c); err != nil {
+			return err
+		}
+	}
+	pending.vaultDir = vaultDir
+	pending.undoes = seqs
+	bySeq := make(map[int]journal.Entry, len(entries))
+	for _, e := range entries {
+		bySeq[e.Seq] = e
+	}
+	out := cmd.OutOrStdout()
+	for _, seq := range seqs {
+		fmt.Fprintf(out, "Undid #%d: %s\n", seq, bySeq[seq].Command)
+	}
+	return nil
+}
+
+// restoreIssueFile applies one planned undo change: removes the file,
+// recreates it, or rewrites it with the restored image.
+func restoreIssueFile(vaultDir string, c journal.Change) error {
+	var before []byte
+	if c.Before != nil {
+		before = []byte(*c.Before)
+	}
+	switch {
+	case c.After == nil:
+		if err := os.Remove(issuePath(vaultDir, c.ID)); err != nil {
+			return fmt.Errorf("removing issue %s: %w", c.ID, err)
+		}
+		recordChange(vaultDir, c.ID, before, nil)
+		return nil
+	case c.Before == nil:
+		f, err := os.OpenFile(issuePath(vaultDir, c.ID), os.O_WRONLY|os.O_CREATE|os.O_EXCL|issueOpenNoFollow, 0o644)
+		if err != nil {
+			return fmt.Errorf("restoring issue %s: %w", c.ID, err)
+		}
+		return writeRestored(f, vaultDir, c.ID, before, []byte(*c.After))
+	default:
+		f, err := openIssueFile(vaultDir, c.ID, os.O_WRONLY|os.O_TRUNC)
+		if err != nil {
+			return fmt.Errorf("restoring issue %s: %w", c.ID, err)
+		}
+		return writeRestored(f, vaultDir, c.ID, before, []byte(*c.After))
+	}
+}
+
+// writeRestored writes the restored image to f, closes it and records the
+// change.
+func writeRestored(f *os.File, vaultDir, id string, before, after []byte) error {
+	_, writeErr := f.Write(after)
+	closeErr := f.Close()
+	if writeErr != nil {
+		return fmt.Errorf("restoring issue %s: %w", id, writeErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("closing issue %s: %w", id, closeErr)
+	}
+	recordChange(vaultDir, id, before, after)
+	return nil
+}
+
+// newJournalCmd builds `mt journal`: the journaled operations, newest
+// first.
+func newJournalCmd() *cobra.Command {
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "journal",
+		Short: "List the journaled operations (newest first)",
+		Long:  journalLong,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return exitcode.Usage(fmt.Errorf("journal takes no arguments"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if limit < 0 {
+				return exitcode.Usage(fmt.Errorf("--limit must not be negative"))
+			}
+			return runJournal(cmd, limit)
+		},
+	}
+	cmd.Flags().IntVarP(&limit, "limit", "n", journalLimit, "show at most this many entries (0 = all)")
+	return cmd
+}
+
+// runJournal prints one line per journal entry, newest first: number,
+// time, command line, and what it touched — or which entries it undid.
+// Reverted entries are marked [undone].
+func runJournal(cmd *cobra.Command, limit int) error {
+	vaultDir, err := resolveVault(cmd)
+	if err != nil {
+		return err
+	}
+	entries, err := journal.Load(journal.Path(vaultDir))
+	if err != nil {
+		return err
+	}
+	undone := journal.Undone(entries)
+	out := cmd.OutOrStdout()
+	for i, shown := len(entries)-1, 0; i >= 0 && (limit == 0 || shown < limit); i, shown = i-1, shown+1 {
+		e := entries[i]
+		line := fmt.Sprintf("#%d  %s  %s  (%s)", e.Seq, e.Time, e.Command, journalSummary(e))
+		if undone[e.Seq] {
+			line += " [undone]"
+		}
+		fmt.Fprintln(out, line)
+	}
+	return nil
+}
+
+// journalSummary describes an entry's effect: the entries an undo
+// reverted, or the Issues an operation touched.
+func journalSummary(e journal.Entry) string {
+	if len(e.Undoes) > 0 {
+		seqs := make([]string, len(e.Undoes))
+		for i, s := range e.Undoes {
+			seqs[i] = "#" + strconv.Itoa(s)
+		}
+		return "undid " + strings.Join(seqs, ", ")
+	}
+	ids := make([]string, len(e.Changes))
+	for i, c := range e.Changes {
+		ids[i] = c.ID
+	}
+	return strings.Join(ids, ", ")
+}
+
+const undoLong = `undo reverts the latest journaled operation — or the n latest with
+mt undo n — restoring every Issue file it touched to its pre-image: a
+created Issue is removed, an edited one rewritten.
+
+Every command that writes Issue files (create, done, status, defer,
+prioritize, check --fix, edit, ...) appends one entry to the vault's
+journal, .mt/journal.jsonl, with the command line, the time and the
+before/after content of each file. mt journal lists it.
+
+undo refuses — changing nothing — when a file no longer holds the
+content the journal recorded after the operation (edited by hand or by
+a later operation): it never overwrites changes it does not know about.
+Undo operations are journaled too, but are not themselves undoable.`
+
+const journalLong = `journal lists the vault's journaled operations, newest first: the
+entry number, the time, the command line and the Issues it touched (an
+undo shows the entries it reverted). Entries reverted by mt undo are
+marked [undone]. The journal lives in .mt/journal.jsonl inside the
+vault, which keeps itself out of Git.`
@@ -0,0 +1,83 @@
+// Package cli — the mt label commands. They own the process concerns of
+// editing labels (resolving the vault, reading/writing the Issue files,
+// stdio); the field mutation lives in internal/issue.
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+// newLabelCmd builds `mt label`: the parent of add and rm. A bare `mt
+// label` prints the group's help.
+func newLabelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "label",
+		Short: "Add or remove Issue labels",
+		Long: `label edits the labels of Issues: free-form tags, filtered on by
+list --label and --where labels=<label>.
+
+  mt label add <id...> <label>   add <label> to each <id>
+  mt label rm <id...> <label>    remove <label> from each <id>
+
+Both are batch commands: several IDs, - to read them from stdin, or
+--where to select them, all or nothing. Both are idempotent.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newLabelEditCmd("add", "Add a label to Issues", "is now labeled",
+		func(i issue.Issue, label string) issue.Issue { return i.AddLabel(label) }))
+	cmd.AddCommand(newLabelEditCmd("rm", "Remove a label from Issues", "is no longer labeled",
+		func(i issue.Issue, label string) issue.Issue { return i.RemoveLabel(label) }))
+	return cmd
+}
+
+// newLabelEditCmd builds `mt label add|rm <id...> <label>`: edit applies
+// the label to each target, and each applied step is confirmed as "<id>
+// <done> <label>". The label is always the last argument; the targets
+// before it are IDs, "-" for IDs on stdin, or a --where query (see
+// batch.go).
+func newLabelEditCmd(verb, short, done string, edit func(issue.Issue, string) issue.Issue) *cobra.Command {
+	var flags batchFlags
+	use := "label " + verb
+	cmd := &cobra.Command{
+		Use:   verb + " <id...> <label>",
+		Short: short,
+		Args: func(_ *cobra.Command, args []string) error {
+			if flags.where != "" && len(args) != 1 {
+				return exitcode.Usage(fmt.Errorf("%s --where needs just a label", use))
+			}
+			if flags.where == "" && len(args) < 2 {
+				return exitcode.Usage(fmt.Errorf("%s needs an issue ID and a label", use))
+			}
+			if args[len(args)-1] == "" {
+				return exitcode.Usage(fmt.Errorf("%s needs a non-empty label", use))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, label := args[:len(args)-1], args[len(args)-1]
+			vaultDir, err := resolveVault(cmd)
+			if err != nil {
+				return err
+			}
+			targets, fromStdin, err := selectTargets(cmd, vaultDir, ids, flags.where)
+			if err != nil {
+				return err
+			}
+			return runBatch(cmd, vaultDir, use, targets, fromStdin, flags, func(_ string, i issue.Issue) (issue.Issue, error) {
+				return edit(i, label), nil
+			}, func(out io.Writer, step batchStep) {
+				fmt.Fprintf(out, "%s %s %s\n", step.ID, done, label)
+			})
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
@@ -14,6 +14,8 @@ import (
 
 	"github.com/Sanmoo/my-tasks2/internal/exitcode"
 	"github.com/Sanmoo/my-tasks2/internal/list"
+	"github.com/Sanmoo/my-tasks2/internal/query"
+	"github.com/Sanmoo/my-tasks2/internal/vault"
 )
 
 // statusInProgress is the literal status bare `mt` lists. The bare view
@@ -21,14 +23,28 @@ import (
 // appear in it, even ones a vault defines as semantically similar.
 const statusInProgress = "in_progress"
 
+// listFlags are the filters and output format of a listing.
+type listFlags struct {
+	all    bool
+	status string
+	labels []string
+	where  string
+	format string
+}
+
+// List output formats: the handle-numbered lines, or bare IDs one per
+// line for piping into the batch commands.
+const (
+	formatLines = "lines"
+	formatIDs   = "ids"
+)
+
 // newListCmd builds `mt list`: issues in priority order (rank → backlog
 // by created_at → id), one glyph per status, done hidden by default
 // (--all reveals them), future-deferred always shown and marked with a
-// [defer ...] suffix, filterable by --status and --label.
+// [defer ...] suffix, filterable by --status, --label and --where.
 func newListCmd() *cobra.Command {
-	var all bool
-	var statusFilter string
-	var labelFilters []string
+	var flags listFlags
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List issues in priority order",
@@ -40,12 +56,14 @@ func newListCmd() *cobra.Command {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runList(cmd, all, statusFilter, labelFilters)
+			return runList(cmd, flags)
 		},
 	}
-	cmd.Flags().BoolVar(&all, "all", false, "also show done issues (future-deferred are always shown, marked with a suffix)")
-	cmd.Flags().StringVar(&statusFilter, "status", "", "only issues with this status")
-	cmd.Flags().StringArrayVar(&labelFilters, "label", nil, "only issues with this label; repeatable")
+	cmd.Flags().BoolVar(&flags.all, "all", false, "also show done issues (future-deferred are always shown, marked with a suffix)")
+	cmd.Flags().StringVar(&flags.status, "status", "", "only issues with this status")
+	cmd.Flags().StringArrayVar(&flags.labels, "label", nil, "only issues with this label; repeatable")
+	cmd.Flags().StringVar(&flags.where, "where", "", `only issues matching this query (e.g. "labels=errands rank<=3")`)
+	cmd.Flags().StringVar(&flags.format, "format", formatLines, "output format: lines or ids (one ID per line, for piping)")
 	return cmd
 }
 
@@ -53,11 +71,18 @@ func newListCmd() *cobra.Command {
 // given filters. The duplicate-rank warning goes to stderr and is
 // computed over the whole vault, before any filter, so a filtered view
 // still reports vault integrity.
-func runList(cmd *cobra.Command, all bool, statusFilter string, labelFilters []string) error {
+func runList(cmd *cobra.Command, flags listFlags) error {
+	if flags.format != "" && flags.format != formatLines && flags.format != formatIDs {
+		return exitcode.Usage(fmt.Errorf("unknown list format %q (want lines or ids)", flags.format))
+	}
 	vaultDir, err := resolveVault(cmd)
 	if err != nil {
 		return err
 	}
+	q, err := parseWhere(vaultDir, flags.where)
+	if err != nil {
+		return err
+	}
 	items, err := loadSortedItems(vaultDir)
 	if err != nil {
 		return err
@@ -67,30 +92,74 @@ func runList(cmd *cobra.Command, all bool, statusFilter string, labelFilters []s
 		fmt.Fprintln(cmd.ErrOrStderr(), duplicateRanksWarning(dups))
 	}
 
-	now := time.Now()
-	out := cmd.OutOrStdout()
-	opts := list.Options{All: all, Status: statusFilter, Labels: labelFilters}
+	now, _, err := vaultClock(vaultDir)
+	if err != nil {
+		return err
+	}
+	warn, err := vaultWarn(vaultDir)
+	if err != nil {
+		return err
+	}
+	opts := list.Options{All: flags.all, Status: flags.status, Labels: flags.labels}
 	statusByID := list.StatusByID(items)
+	var listed, lines []string
 	for _, it := range items {
-		if !list.Visible(it, opts) {
+		if !list.Visible(it, opts) || !q.Match(it.ID, it.Issue.Frontmatter) {
 			continue
 		}
 		line := formatListLine(it)
 		if suffix := list.DeferSuffix(it.Issue.Frontmatter.DeferredUntil, now); suffix != "" {
 			line += " " + suffix
 		}
+		if suffix := dueSuffix(it, now, warn); suffix != "" {
+			line += " " + suffix
+		}
 		if list.Blocked(it.Issue.Frontmatter.BlockedBy, statusByID) {
 			line += " [blocked]"
 		}
-		fmt.Fprintln(out, line)
+		listed = append(listed, it.ID)
+		lines = append(lines, line)
 	}
+	if flags.format == formatIDs {
+		out := cmd.OutOrStdout()
+		for _, id := range listed {
+			fmt.Fprintln(out, id)
+		}
+		return nil
+	}
+	printListing(cmd, vaultDir, items, listed, lines)
 	return nil
 }
 
-// loadSortedItems reads every Issue in the vault and orders the result
-// according to the shared list order.
+// dueSuffix returns the [due MM-DD] marker of an Issue due soon, "" for
+// the others.
+func dueSuffix(it list.Item, now time.Time, warn time.Duration) string {
+	if !list.DueSoon(it, now, warn) {
+		return ""
+	}
+	return list.DueSuffix(it.Issue.Frontmatter.Deadline, now, list.WarnSpan(it, warn))
+}
+
+// printListing prints the lines of a listing view, each prefixed with its
+// numeric handle, and remembers the listed IDs so the handles resolve in
+// the following commands. items is the whole vault, the handles'
+// fingerprint.
+func printListing(cmd *cobra.Command, vaultDir string, items []list.Item, listed, lines []string) {
+	out := cmd.OutOrStdout()
+	for _, line := range withHandles(lines) {
+		fmt.Fprintln(out, line)
+	}
+	vaultIDs := make([]string, len(items))
+	for i, it := range items {
+		vaultIDs[i] = it.ID
+	}
+	saveHandles(cmd, vaultDir, listed, vaultIDs)
+}
+
+// loadSortedItems reads every Issue's frontmatter in the vault, through
+// the index, and orders the result according to the shared list order.
 func loadSortedItems(vaultDir string) ([]list.Item, error) {
-	items, err := loadItems(vaultDir)
+	items, err := loadIndexedItems(vaultDir)
 	if err != nil {
 		return nil, err
 	}
@@ -138,9 +207,33 @@ const listLong = `list prints the vault's issues in priority order:
   ranked issues first, lowest rank first; then the Backlog (issues
   without a rank) ordered by created_at; then ID as the final tiebreak.
 
-Each line is a status glyph (○ open, ◐ in_progress, ● done, ? for a
-custom status), the ID, and the title. Issues blocked by another
+Each line is a numeric handle, a status glyph (○ open, ◐ in_progress,
+● done, ? for a custom status), the ID, and the title. The handle names
+the Issue in the next commands: after a listing, 'mt done 3' closes the
+third line. Creating or deleting an Issue makes the handles stale; list
+again to refresh them. Issues blocked by another
 non-done Issue carry a [blocked] suffix. Only done issues are hidden
 by default; --all shows them too. Issues deferred to the future are
-always shown, marked with a [defer MM-DD HH:MM] suffix. Use --status
-and --label to narrow the view.`
+always shown, marked with a [defer MM-DD HH:MM] suffix. Use --status,
+--label and --where to narrow the view; --where takes the query of the
+batch commands (status=open labels=errands rank<=3 deadline<2026-09-01
+title~"material", terms ANDed). --format ids prints only the IDs, one
+per line, to pipe into a batch: mt list --format ids | mt defer - +1w.`
+
+// parseWhere parses a --where selector, knowing the vault's custom
+// fields. An empty selector matches everything; a malformed one is a
+// usage error.
+func parseWhere(vaultDir, where string) (query.Query, error) {
+	if where == "" {
+		return query.Query{}, nil
+	}
+	vcfg, err := vault.LoadVault(vaultDir)
+	if err != nil {
+		return query.Query{}, err
+	}
+	q, err := query.Parse(where, vcfg.Fields...)
+	if err != nil {
+		return query.Query{}, exitcode.Usage(err)
+	}
+	return q, nil
+}
@@ -0,0 +1,304 @@
+// Package cli — mt mcp: the vault as a Model Context Protocol server over
+// stdio, so an LLM agent can drive it. The protocol lives in
+// internal/mcp; the tools here map onto the vault API (api.go), so they
+// validate and fail as the commands do, and each mutating call is one
+// journaled operation that mt undo reverts and the hooks see.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/mcp"
+	"github.com/Sanmoo/my-tasks2/internal/vault"
+)
+
+// issueURIPrefix prefixes the Issue ID in the URI of an Issue resource.
+const issueURIPrefix = "mt://issues/"
+
+// newMCPCmd builds `mt mcp`: serves the resolved vault over MCP on
+// stdin/stdout until stdin ends.
+func newMCPCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "mcp",
+		Short: "Serve the vault to LLM agents over MCP (stdio)",
+		Long:  mcpLong,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return exitcode.Usage(fmt.Errorf("mcp takes no arguments"))
+			}
+			return nil
+		},
+		RunE: runMCP,
+	}
+}
+
+// runMCP checks the vault, then answers MCP messages on stdin. stdout
+// carries only the protocol; warnings go to stderr.
+func runMCP(cmd *cobra.Command, _ []string) error {
+	vaultDir, err := resolveVault(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err := vault.LoadVault(vaultDir); err != nil {
+		return err
+	}
+	s := newMCPServer(vaultAPI{vaultDir}, cmd.ErrOrStderr())
+	return s.Serve(cmd.InOrStdin(), cmd.OutOrStdout())
+}
+
+// buildVersion is the version of the mt module, as the Go toolchain
+// stamped it ("(devel)" for a local build).
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// newMCPServer returns the MCP server of the vault api: its tools and
+// the Issues as resources. Warnings go to log.
+func newMCPServer(api vaultAPI, log io.Writer) *mcp.Server {
+	s := mcp.NewServer("mt", buildVersion())
+	s.Instructions = mcpInstructions
+	s.SetResources(issueResources{api})
+
+	s.AddTool(readTool("list", "List the Issues of the vault, as mt list: open ones in rank order unless all or status say otherwise.",
+		object(map[string]any{
+			"all":    prop("boolean", "include done Issues"),
+			"status": prop("string", "only Issues with this status"),
+			"labels": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "only Issues with all these labels"},
+			"where":  prop("string", `a query, as mt list --where (e.g. "label:casa and deadline<+7d")`),
+		}),
+		func(f listFilter) (any, error) {
+			issues, err := api.list(f)
+			return map[string]any{"issues": issues}, err
+		}))
+	s.AddTool(readTool("ready", "List the Issues ready to work on, as mt ready: open, not deferred and not blocked, in rank order.",
+		object(nil),
+		func(struct{}) (any, error) {
+			issues, err := api.ready()
+			return map[string]any{"issues": issues}, err
+		}))
+	s.AddTool(readTool("show", "Show one Issue: its frontmatter, body (description, notes, comments) and ETag.",
+		object(map[string]any{"id": prop("string", "the Issue ID, e.g. pkm-001")}, "id"),
+		func(a issueArgs) (any, error) { return api.show(a.ID) }))
+
+	s.AddTool(writeTool("create", "Create an Issue, as mt create; returns it with its new ID.",
+		object(map[string]any{
+			"title":       prop("string", "the title"),
+			"labels":      map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"template":    prop("string", "a template of the vault's templates/ directory"),
+			"deadline":    prop("string", `the deadline, as mt create --deadline (e.g. "+7d" or "26-09-01 18:00")`),
+			"defer":       prop("string", "defer it until this time, as mt defer takes it"),
+			"rank":        prop("string", `"top", "bottom" or a position; unranked (Backlog) without`),
+			"blocked_by":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "IDs of the Issues blocking it"},
+			"status":      prop("string", "the initial status"),
+			"description": prop("string", "the Description section"),
+			"body":        prop("string", "the whole Markdown body, instead of the template's"),
+		}, "title"),
+		log, func(r createRequest) string { return r.Title },
+		func(r createRequest) (any, error) { return api.create(r) }))
+	s.AddTool(writeTool("comment", "Add a timestamped comment to an Issue, or a reply to the comment anchored reply_to.",
+		object(map[string]any{
+			"id":       prop("string", "the Issue ID"),
+			"text":     prop("string", "the comment, Markdown"),
+			"reply_to": prop("string", "the anchor of the comment to reply to"),
+		}, "id", "text"),
+		log, commentArgs.subject,
+		func(a commentArgs) (any, error) { return api.comment(a.ID, a.Text, a.ReplyTo) }))
+	s.AddTool(writeTool("done", "Mark an Issue done, as mt done.",
+		object(map[string]any{"id": prop("string", "the Issue ID")}, "id"),
+		log, issueArgs.subject,
+		func(a issueArgs) (any, error) { return api.done(a.ID) }))
+	s.AddTool(writeTool("defer", "Defer an Issue until a time, as mt defer: it leaves ready until then.",
+		object(map[string]any{
+			"id":    prop("string", "the Issue ID"),
+			"until": prop("string", `"+2d", "+3h" or "YY-MM-DD HH:MM"`),
+		}, "id", "until"),
+		log, deferArgs.subject,
+		func(a deferArgs) (any, error) { return api.deferTo(a.ID, a.Until) }))
+	s.AddTool(writeTool("dep", "Record that an Issue is blocked by another (mt dep add), or with remove stop it (mt dep rm).",
+		object(map[string]any{
+			"id":      prop("string", "the blocked Issue ID"),
+			"blocker": prop("string", "the blocking Issue ID"),
+			"remove":  prop("boolean", "remove the blocker instead of adding it"),
+		}, "id", "blocker"),
+		log, blockerArgs.subject,
+		func(a blockerArgs) (any, error) { return api.dep(a.ID, a.Blocker, a.Remove) }))
+	s.AddTool(writeTool("rank", `Move an Issue in the priority order: "top", "bottom", a position, or "backlog" to unrank it.`,
+		object(map[string]any{
+			"id":   prop("string", "the Issue ID"),
+			"rank": map[string]any{"type": []string{"string", "integer"}, "description": `"top", "bottom", a position (1 is first) or "backlog"`},
+		}, "id", "rank"),
+		log, rankArgs.subject,
+		func(a rankArgs) (any, error) {
+			if a.Rank == nil {
+				return nil, exitcode.Usage(errors.New(`rank needs "rank": top, bottom, a position or backlog`))
+			}
+			return api.rank(a.ID, fmt.Sprint(a.Rank))
+		}))
+	return s
+}
+
+// The arguments of the tools on one Issue. subject names the call in the
+// journal.
+type (
+	issueArgs struct {
+		ID string `json:"id"`
+	}
+	commentArgs struct {
+		ID      string `json:"id"`
+		Text    string `json:"text"`
+		ReplyTo string `json:"reply_to"`
+	}
+	deferArgs struct {
+		ID    string `json:"id"`
+		Until string `json:"until"`
+	}
+	blockerArgs struct {
+		ID      string `json:"id"`
+		Blocker string `json:"blocker"`
+		Remove  bool   `json:"remove"`
+	}
+	rankArgs struct {
+		ID   string `json:"id"`
+		Rank any    `json:"rank"`
+	}
+)
+
+func (a issueArgs) subject() string   { return a.ID }
+func (a commentArgs) subject() string { return a.ID }
+func (a deferArgs) subject() string   { return a.ID }
+func (a blockerArgs) subject() string { return a.ID }
+func (a rankArgs) subject() string    { return a.ID }
+
+// readTool is a tool that only reads the vault.
+func readTool[A any](name, description string, schema map[string]any, run func(A) (any, error)) mcp.Tool {
+	return mcp.Tool{
+		Name:        name,
+		Description: description,
+		InputSchema: schema,
+		Call: func(raw json.RawMessage) (any, error) {
+			var args A
+			if err := mcp.DecodeArguments(raw, &args); err != nil {
+				return nil, err
+			}
+			return run(args)
+		},
+	}
+}
+
+// writeTool is a tool that changes the vault: each call is one journaled
+// operation, named "mt mcp: <tool> <subject>".
+func writeTool[A any](name, description string, schema map[string]any, log io.Writer, subject func(A) string, run func(A) (any, error)) mcp.Tool {
+	return mcp.Tool{
+		Name:        name,
+		Description: description,
+		InputSchema: schema,
+		Call: func(raw json.RawMessage) (any, error) {
+			var args A
+			if err := mcp.DecodeArguments(raw, &args); err != nil {
+				return nil, err
+			}
+			var (
+				v   any
+				err error
+			)
+			operation([]string{"mcp:", name, subject(args)}, log, func() {
+				v, err = run(args)
+			})
+			return v, err
+		},
+	}
+}
+
+// object is the JSON Schema of an arguments object with the given
+// properties, no others.
+func object(properties map[string]any, required ...string) map[string]any {
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	schema := map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// prop is the JSON Schema of a scalar property.
+func prop(typ, description string) map[string]any {
+	return map[string]any{"type": typ, "description": description}
+}
+
+// issueResources offers each Issue of the vault as a resource: its
+// Markdown file, at mt://issues/<id>.
+type issueResources struct {
+	api vaultAPI
+}
+
+func (r issueResources) List() ([]mcp.Resource, error) {
+	items, err := loadSortedItems(r.api.vaultDir)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]mcp.Resource, 0, len(items))
+	for _, it := range items {
+		out = append(out, mcp.Resource{
+			URI:      issueURIPrefix + it.ID,
+			Name:     it.ID,
+			Title:    it.Issue.Frontmatter.Title,
+			MIMEType: "text/markdown",
+		})
+	}
+	return out, nil
+}
+
+func (r issueResources) Read(uri string) (mcp.Contents, error) {
+	id, ok := strings.CutPrefix(uri, issueURIPrefix)
+	if !ok || checkID(id) != nil {
+		return mcp.Contents{}, fmt.Errorf("%w: %s", mcp.ErrResourceNotFound, uri)
+	}
+	data, err := r.api.issueData(id)
+	if errors.Is(err, errNotFound) {
+		return mcp.Contents{}, fmt.Errorf("%w: %w", mcp.ErrResourceNotFound, err)
+	}
+	if err != nil {
+		return mcp.Contents{}, err
+	}
+	return mcp.Contents{URI: uri, MIMEType: "text/markdown", Text: string(data)}, nil
+}
+
+const mcpInstructions = `mt is a personal issue tracker: each Issue is a Markdown file of the vault, with an ID like pkm-001, a status, labels, an optional rank (its place in the priority order; unranked Issues are the Backlog), deadline, deferral and blockers. Use ready to find what to work on next, show to read an Issue, and the other tools to change it. A failed call reports the error and the exit code mt would end with: 2 for a malformed call (fix the arguments), 1 when the vault refused it.`
+
+const mcpLong = `mcp serves the vault to LLM agents over the Model Context Protocol:
+JSON-RPC messages, one per line, on stdin and stdout, until stdin ends.
+Register it with the agent as a stdio server running
+
+  mt --vault <path> mcp
+
+Tools:
+  list      the list view (all, status, labels, where)
+  ready     the ready view
+  show      one Issue: frontmatter, body and ETag
+  create    a new Issue (title and the create flags)
+  comment   a comment, or a reply with reply_to
+  done      mark an Issue done
+  defer     defer an Issue (until, as mt defer takes it)
+  dep       add a blocker, or remove it with remove
+  rank      "top", "bottom", a position or "backlog"
+
+Each Issue is also a resource, its Markdown file at mt://issues/<id>.
+
+The tools validate as the commands do. A failed call carries the error
+and the exit code the command would end with (2 for a malformed call, 1
+when the vault refused it). Changes are journaled (mt undo reverts them)
+and run the hooks, as if each call were one mt command; the hooks'
+output and warnings go to stderr, never to stdout.`
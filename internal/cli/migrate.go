@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sanmoo/my-tasks2/internal/check"
+	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/vault"
+)
+
+// newMigrateTimesCmd builds `mt migrate-times`: rewrites the vault's
+// stored datetimes for its timezone setting, after the setting changed.
+// --from names the zone the naive values were written in; --dry-run
+// lists the changes without writing them.
+func newMigrateTimesCmd() *cobra.Command {
+	var from string
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "migrate-times",
+		Short: "Rewrite the vault's datetimes for its timezone setting",
+		Long:  migrateTimesLong,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return exitcode.Usage(errors.New("migrate-times takes no arguments"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			loc := time.Local
+			if from != "" {
+				var err error
+				if loc, err = time.LoadLocation(from); err != nil || from == "Local" {
+					return exitcode.Usage(fmt.Errorf("unknown --from zone %q: want an IANA zone name (e.g. America/Sao_Paulo)", from))
+				}
+			}
+			return runMigrateTimes(cmd, loc, dryRun)
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "IANA zone the naive datetimes were written in (default: this machine's)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the changes without writing them")
+	return cmd
+}
+
+// runMigrateTimes converts the datetimes of every Issue that parses to
+// the vault's timezone setting, reading naive values as wall-clock times
+// of from, and lists each change. Files that do not parse are left for
+// mt check.
+func runMigrateTimes(cmd *cobra.Command, from *time.Location, dryRun bool) error {
+	vaultDir, err := resolveVault(cmd)
+	if err != nil {
+		return err
+	}
+	vcfg, err := vault.LoadVault(vaultDir)
+	if err != nil {
+		return err
+	}
+	files, err := readCheckFiles(vaultDir)
+	if err != nil {
+		return err
+	}
+	fixes := check.PlanMigration(files, vcfg.Fields, from, vcfg.Zone)
+	out := cmd.OutOrStdout()
+	for _, f := range fixes {
+		for _, change := range f.Changes {
+			fmt.Fprintf(out, "%s: %s\n", f.ID, change)
+		}
+	}
+	switch {
+	case len(fixes) == 0:
+		fmt.Fprintln(out, "Nothing to migrate")
+	case dryRun:
+		fmt.Fprintf(out, "Would migrate %s (dry run: nothing written)\n", plural(len(fixes), "Issue"))
+	default:
+		for _, f := range fixes {
+			if err := writeIssueFile(vaultDir, f.ID, f.Issue); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(out, "Migrated %s\n", plural(len(fixes), "Issue"))
+	}
+	return nil
+}
+
+const migrateTimesLong = `migrate-times rewrites the datetimes of every Issue — created_at,
+started_at, the work_log intervals, completed_at, deferred_until,
+deadline and the custom datetime fields — for the vault's timezone setting (timezone: in
+mt.yaml), after it is set or changed:
+
+  - a naive value (YYYY-MM-DDTHH:MM) is read as a wall-clock time of
+    --from, the zone it was written in: this machine's by default;
+  - a value with a UTC offset is the instant it names;
+  - under an IANA zone, or with timezone unset (this machine's zone),
+    each is written as the naive wall-clock time of that zone;
+  - under offset, each is written with its UTC offset, in --from; a
+    value that already has one is kept.
+
+Each change is listed as "<id>: <field>: <old> → <new>"; --dry-run lists
+them and writes nothing. Files that do not parse are left for mt check.`
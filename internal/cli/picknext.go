@@ -4,21 +4,42 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
-	"time"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Sanmoo/my-tasks2/internal/exitcode"
 	"github.com/Sanmoo/my-tasks2/internal/issue"
 	"github.com/Sanmoo/my-tasks2/internal/list"
+	"github.com/Sanmoo/my-tasks2/internal/query"
+	"github.com/Sanmoo/my-tasks2/internal/vault"
 )
 
+// pickNextFlags are the flags of `mt pick-next`.
+type pickNextFlags struct {
+	dueFirst    bool
+	labels      []string
+	where       string
+	maxEstimate int
+	peek        bool
+	explain     bool
+}
+
+// estimateField is the custom int field --max-estimate compares.
+const estimateField = "estimate"
+
 // newPickNextCmd builds `mt pick-next`: starts the available open Issue with
 // the lowest Rank, or the oldest available Backlog Issue when no ranked
-// candidate exists. It allows multiple Issues to remain in_progress simultaneously.
+// candidate exists. Several Issues may be in_progress at once, up to the
+// vault's wip_limit. --due-first prefers the Issues due soon; --label,
+// --where and --max-estimate narrow the candidates; --peek prints the
+// choice without starting it; --explain says why the Issues ahead of it
+// were skipped.
 func newPickNextCmd() *cobra.Command {
-	return &cobra.Command{
+	var flags pickNextFlags
+	cmd := &cobra.Command{
 		Use:   "pick-next",
 		Short: "Start the next available Issue",
 		Long:  pickNextLong,
@@ -26,38 +47,102 @@ func newPickNextCmd() *cobra.Command {
 			if len(args) > 0 {
 				return exitcode.Usage(fmt.Errorf("pick-next takes no arguments"))
 			}
+			if flags.maxEstimate < 0 {
+				return exitcode.Usage(fmt.Errorf("--max-estimate must not be negative, got %d", flags.maxEstimate))
+			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runPickNext(cmd)
+			return runPickNext(cmd, flags)
 		},
 	}
+	cmd.Flags().BoolVar(&flags.dueFirst, "due-first", false, "prefer the Issues due soon (warn_before) over the Rank order")
+	cmd.Flags().StringArrayVar(&flags.labels, "label", nil, "only Issues with this label; repeatable")
+	cmd.Flags().StringVar(&flags.where, "where", "", `only Issues matching this query (e.g. "labels=errands rank<=3")`)
+	cmd.Flags().IntVar(&flags.maxEstimate, "max-estimate", 0, "only Issues whose estimate field is at most this")
+	cmd.Flags().BoolVar(&flags.peek, "peek", false, "print the choice without starting it")
+	cmd.Flags().BoolVar(&flags.explain, "explain", false, "say why the Issues ahead of the choice were skipped")
+	return cmd
 }
 
 // runPickNext resolves the Vault, validates its ranks, selects an available
 // open Issue and starts it with one timestamp shared by selection and write.
-func runPickNext(cmd *cobra.Command) error {
+func runPickNext(cmd *cobra.Command, flags pickNextFlags) error {
 	vaultDir, err := resolveVault(cmd)
 	if err != nil {
 		return err
 	}
-	items, err := loadItems(vaultDir)
+	where, err := pickWhere(cmd, vaultDir, flags)
+	if err != nil {
+		return err
+	}
+	items, err := loadIndexedItems(vaultDir)
 	if err != nil {
 		return fmt.Errorf("loading issues: %w", err)
 	}
-	now := time.Now()
-	next, err := list.PickNext(items, now)
+	now, zone, err := vaultClock(vaultDir)
 	if err != nil {
+		return err
+	}
+	warn, err := vaultWarn(vaultDir)
+	if err != nil {
+		return err
+	}
+	opts := list.PickOptions{DueFirst: flags.dueFirst, Warn: warn, Labels: flags.labels, Where: where}
+	next, err := list.PickNextWith(items, now, opts)
+	if err != nil && !errors.Is(err, list.ErrNoneAvailable) {
+		return fmt.Errorf("selecting next issue: %w", err)
+	}
+	out := cmd.OutOrStdout()
+	if flags.explain {
+		skips, done := list.ExplainPick(items, now, opts, next.ID)
+		for _, skip := range skips {
+			fmt.Fprintf(out, "skipped: %s %s\n", skip.ID, skip.Reason)
+		}
+		if done > 0 {
+			fmt.Fprintf(out, "skipped: %s\n", plural(done, "done Issue"))
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("selecting next issue: %w", err)
+	}
+	if flags.peek {
+		fmt.Fprintln(out, formatListLine(next))
+		return nil
+	}
+	limit, err := vaultWIPLimit(vaultDir)
+	if err != nil {
+		return err
+	}
+	if err := list.CheckWIP(items, limit); err != nil {
 		return fmt.Errorf("selecting next issue: %w", err)
 	}
 	if err := applyMutation(cmd, vaultDir, next.ID, func(i issue.Issue) issue.Issue {
-		return i.Start(now.Format(issue.NaiveLayout))
+		return i.Start(zone.Format(now))
 	}); err != nil {
 		return fmt.Errorf("starting issue: %w", err)
 	}
 	return nil
 }
 
+// pickWhere parses the --where query of pick-next, with --max-estimate as
+// one more term on the vault's int field estimate. --max-estimate in a
+// vault that declares no such field is a usage error.
+func pickWhere(cmd *cobra.Command, vaultDir string, flags pickNextFlags) (query.Query, error) {
+	where := flags.where
+	if cmd.Flags().Changed("max-estimate") {
+		vcfg, err := vault.LoadVault(vaultDir)
+		if err != nil {
+			return query.Query{}, err
+		}
+		if spec, ok := vcfg.Field(estimateField); !ok || spec.Type != issue.TypeInt {
+			return query.Query{}, exitcode.Usage(fmt.Errorf("--max-estimate needs the int field %s declared in mt.yaml (fields:)", estimateField))
+		}
+		where = strings.TrimSpace(fmt.Sprintf("%s %s<=%d", where, estimateField, flags.maxEstimate))
+	}
+	return parseWhere(vaultDir, where)
+}
+
 const pickNextLong = `pick-next starts the next available open Issue:
 
   ranked Issues are chosen by the lowest Rank; when no ranked Issue is
@@ -65,5 +150,25 @@ const pickNextLong = `pick-next starts the next available open Issue:
   final tie-break. Issues deferred into the future and Issues blocked by
   a non-done Issue are skipped.
 
-The chosen Issue becomes in_progress and receives a started_at timestamp.
-Duplicate ranks are rejected, and multiple Issues may be in_progress at once.`
+The chosen Issue becomes in_progress, receives a started_at timestamp
+(a paused Issue keeps its first one) and opens an interval in its
+work_log, as mt start does. Duplicate ranks are rejected.
+Multiple Issues may be in_progress at once; with wip_limit: N in mt.yaml,
+pick-next refuses to start one more while N are; --peek, and the
+skips --explain prints, still come out. mt start <id> starts a given
+Issue.
+
+--due-first chooses among the available Issues due soon — a deadline
+within the Issue's warn_before, else the vault's — first, in the same
+order, and falls back to the rest when none is.
+
+--label (repeatable: any of them), --where <query> (the query of mt list)
+and --max-estimate <n> (the Issues whose int field estimate, declared in
+mt.yaml, is at most n; those without one are left out) narrow the
+candidates. --peek prints the choice as a list line without starting it.
+--explain first prints, in Rank order, each Issue ahead of the choice
+that was skipped and why: "skipped: pkm-003 is deferred until
+2026-08-20T08:00", "is blocked by pkm-001", "is in_progress, not open" or,
+under --due-first, "is not due soon". The done Issues ahead of the
+choice are only counted, on one last line — "skipped: 42 done Issues" —
+so a long history does not bury the reasons.`
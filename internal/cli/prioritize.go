@@ -58,7 +58,7 @@ func runPrioritize(cmd *cobra.Command) error {
 		}
 	}
 
-	path, err := writeBuffer(priority.Buffer(prioritizable))
+	path, err := writeBuffer("prioritize", priority.Buffer(prioritizable))
 	if err != nil {
 		return err
 	}
@@ -92,13 +92,13 @@ func runPrioritize(cmd *cobra.Command) error {
 	return nil
 }
 
-// writeBuffer writes the editor buffer to a fresh temp file and returns
-// its path. The temp file is the $EDITOR target: the editor overwrites it
-// and the saved contents are read back.
-func writeBuffer(buffer string) (string, error) {
-	f, err := os.CreateTemp("", "mt-prioritize-*.md")
+// writeBuffer writes the editor buffer of the command name to a fresh
+// temp file and returns its path. The temp file is the $EDITOR target:
+// the editor overwrites it and the saved contents are read back.
+func writeBuffer(name, buffer string) (string, error) {
+	f, err := os.CreateTemp("", "mt-"+name+"-*.md")
 	if err != nil {
-		return "", fmt.Errorf("creating prioritize buffer: %w", err)
+		return "", fmt.Errorf("creating %s buffer: %w", name, err)
 	}
 	path := f.Name()
 	_, writeErr := f.WriteString(buffer)
@@ -106,18 +106,18 @@ func writeBuffer(buffer string) (string, error) {
 	switch {
 	case writeErr != nil:
 		os.Remove(path)
-		return "", fmt.Errorf("writing prioritize buffer: %w", writeErr)
+		return "", fmt.Errorf("writing %s buffer: %w", name, writeErr)
 	case closeErr != nil:
 		os.Remove(path)
-		return "", fmt.Errorf("closing prioritize buffer: %w", closeErr)
+		return "", fmt.Errorf("closing %s buffer: %w", name, closeErr)
 	}
 	return path, nil
 }
 
-// loadPriorityIssues reuses the CLI's shared issue-file loader and
-// projects each parsed Issue into the minimal priority.Issue view.
+// loadPriorityIssues reads the vault's frontmatter through the index and
+// projects each Issue into the minimal priority.Issue view.
 func loadPriorityIssues(vaultDir string) ([]priority.Issue, error) {
-	items, err := loadItems(vaultDir)
+	items, err := loadIndexedItems(vaultDir)
 	if err != nil {
 		return nil, err
 	}
@@ -146,26 +146,40 @@ func priorityIssuesFromItems(items []list.Item) []priority.Issue {
 // applyRankChanges applies each rank change in-process, without spawning a
 // subprocess per issue.
 func applyRankChanges(vaultDir string, changes []priority.Change) error {
+	steps, err := planRankChanges(vaultDir, changes)
+	if err != nil {
+		return err
+	}
+	return writeSteps(vaultDir, steps)
+}
+
+// planRankChanges plans writing each new rank (nil = Backlog) into its
+// issue file, preserving everything else. The plan is vetted by the
+// pre-hooks as a whole, like a batch.
+func planRankChanges(vaultDir string, changes []priority.Change) ([]batchStep, error) {
+	ids := make([]string, 0, len(changes))
+	ranks := make(map[string]*int, len(changes))
 	for _, ch := range changes {
-		if err := applyRankChange(vaultDir, ch); err != nil {
-			return err
+		if err := checkID(ch.ID); err != nil {
+			return nil, err
 		}
+		ids = append(ids, ch.ID)
+		ranks[ch.ID] = ch.Rank
 	}
-	return nil
+	return planBatch(vaultDir, ids, func(id string, i issue.Issue) (issue.Issue, error) {
+		i.Frontmatter.Rank = ranks[id]
+		return i, nil
+	})
 }
 
-// applyRankChange writes the new rank (nil = Backlog) into the issue file,
-// preserving everything else.
-func applyRankChange(vaultDir string, ch priority.Change) error {
-	if err := checkID(ch.ID); err != nil {
-		return err
-	}
-	i, err := readIssue(vaultDir, ch.ID)
-	if err != nil {
-		return err
+// writeSteps writes every planned step, in order.
+func writeSteps(vaultDir string, steps []batchStep) error {
+	for _, step := range steps {
+		if err := writeIssueFile(vaultDir, step.ID, step.Issue); err != nil {
+			return err
+		}
 	}
-	i.Frontmatter.Rank = ch.Rank
-	return writeIssueFile(vaultDir, ch.ID, i)
+	return nil
 }
 
 const prioritizeLong = `prioritize opens $EDITOR on a buffer of the vault's open and in_progress
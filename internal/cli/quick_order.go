@@ -72,6 +72,10 @@ func runQuickOrder(cmd *cobra.Command, id string, action priority.QuickAction, p
 	if err != nil {
 		return err
 	}
+	id, err = resolveIssueArg(vaultDir, id)
+	if err != nil {
+		return err
+	}
 	issues, err := loadPriorityIssues(vaultDir)
 	if err != nil {
 		return err
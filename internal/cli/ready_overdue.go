@@ -1,30 +1,38 @@
-// Package cli implements the ready and overdue Issue queries.
+// Package cli implements the ready, overdue and due Issue queries.
 package cli
 
 import (
+	"cmp"
 	"fmt"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/Sanmoo/my-tasks2/internal/deferral"
 	"github.com/Sanmoo/my-tasks2/internal/exitcode"
 	"github.com/Sanmoo/my-tasks2/internal/list"
+	"github.com/Sanmoo/my-tasks2/internal/vault"
 )
 
 // newReadyCmd builds `mt ready`, which lists open Issues that are
 // available now — not future-deferred and not blocked — in the vault's
 // established priority order.
 func newReadyCmd() *cobra.Command {
-	return newIssueQueryCmd("ready", "List open Issues available now", func(item list.Item, now time.Time, statusByID map[string]string) bool {
-		return list.Ready(item, now) && !list.Blocked(item.Issue.Frontmatter.BlockedBy, statusByID)
-	})
+	return newIssueQueryCmd("ready", "List open Issues available now", isReady)
+}
+
+// isReady reports whether item is ready at now: open, not future-deferred
+// and not blocked by an Issue of statusByID.
+func isReady(item list.Item, now time.Time, statusByID map[string]string) bool {
+	return list.Ready(item, now) && !list.Blocked(item.Issue.Frontmatter.BlockedBy, statusByID)
 }
 
 // newOverdueCmd builds `mt overdue`, the vault's temporal-attention
 // command: expired deferrals first (marked [expirada MM-DD]), then
-// passed deadlines (marked [deadline MM-DD]), each group in the vault's
-// priority order. The two-group output needs its own runner, unlike the
-// single-predicate queries of newIssueQueryCmd.
+// passed deadlines (marked [deadline MM-DD]), then deadlines due soon
+// (marked [due MM-DD]), each group in the vault's priority order. The
+// grouped output needs its own runner, unlike the single-predicate
+// queries of newIssueQueryCmd.
 func newOverdueCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "overdue",
@@ -41,10 +49,11 @@ func newOverdueCmd() *cobra.Command {
 	}
 }
 
-// runOverdue loads and orders all Issues, then prints the two temporal
-// groups: expired deferrals first, then passed deadlines, each line
-// marked with the reason it is there. An Issue with both signals appears
-// only in the expired group; done Issues appear in neither. It
+// runOverdue loads and orders all Issues, then prints the temporal
+// groups: expired deferrals first, then passed deadlines, then the
+// deadlines due soon under warn_before, each line marked with the reason
+// it is there. An Issue with several signals appears only in the first
+// of its groups; done Issues appear in none. It
 // intentionally does not warn about duplicate ranks, like the other
 // focused query views.
 func runOverdue(cmd *cobra.Command) error {
@@ -57,7 +66,14 @@ func runOverdue(cmd *cobra.Command) error {
 		return err
 	}
 
-	now := time.Now()
+	now, _, err := vaultClock(vaultDir)
+	if err != nil {
+		return err
+	}
+	warn, err := vaultWarn(vaultDir)
+	if err != nil {
+		return err
+	}
 	expired, late := list.OverdueGroups(items, now)
 	out := cmd.OutOrStdout()
 	for _, it := range expired {
@@ -68,6 +84,76 @@ func runOverdue(cmd *cobra.Command) error {
 		line := formatListLine(it) + " " + list.DeadlineSuffix(it.Issue.Frontmatter.Deadline, now)
 		fmt.Fprintln(out, line)
 	}
+	for _, it := range list.DueSoonGroup(items, now, warn) {
+		fmt.Fprintln(out, formatListLine(it)+" "+dueSuffix(it, now, warn))
+	}
+	return nil
+}
+
+// defaultDueWithin is the window of mt due in a vault without
+// warn_before.
+const defaultDueWithin = "7d"
+
+// newDueCmd builds `mt due`: the Issues whose deadline falls within
+// --within from now, passed ones included, in the vault's priority order.
+func newDueCmd() *cobra.Command {
+	var within string
+	cmd := &cobra.Command{
+		Use:   "due",
+		Short: "List Issues with a deadline within a span (default: warn_before, else 7d)",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return exitcode.Usage(fmt.Errorf("due takes no arguments"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runDue(cmd, within)
+		},
+	}
+	cmd.Flags().StringVar(&within, "within", "", "span from now, <n><unit> with unit m, h, d or w (e.g. 7d)")
+	return cmd
+}
+
+// runDue prints every Issue that is not done with a deadline at most
+// within from now, marked [deadline MM-DD] when passed and [due MM-DD]
+// otherwise, with numeric handles like list. An empty within is the
+// vault's warn_before, else defaultDueWithin.
+func runDue(cmd *cobra.Command, within string) error {
+	vaultDir, err := resolveVault(cmd)
+	if err != nil {
+		return err
+	}
+	vcfg, err := vault.LoadVault(vaultDir)
+	if err != nil {
+		return err
+	}
+	if within == "" {
+		within = cmp.Or(vcfg.WarnBefore, defaultDueWithin)
+	}
+	span, err := deferral.ParseSpan(within)
+	if err != nil {
+		return exitcode.Usage(fmt.Errorf("--within: %w", err))
+	}
+	items, err := loadSortedItems(vaultDir)
+	if err != nil {
+		return err
+	}
+	now, err := clock(vcfg)
+	if err != nil {
+		return err
+	}
+	var listed, lines []string
+	for _, it := range items {
+		if !list.DueWithin(it, now, span) {
+			continue
+		}
+		deadline := it.Issue.Frontmatter.Deadline
+		suffix := cmp.Or(list.DeadlineSuffix(deadline, now), list.DueSuffix(deadline, now, span))
+		listed = append(listed, it.ID)
+		lines = append(lines, formatListLine(it)+" "+suffix)
+	}
+	printListing(cmd, vaultDir, items, listed, lines)
 	return nil
 }
 
@@ -91,7 +177,7 @@ func newIssueQueryCmd(use, short string, matches func(list.Item, time.Time, map[
 }
 
 // runIssueQuery loads and orders all Issues, then prints those matched by the
-// query. It intentionally does not warn about duplicate ranks: unlike list,
+// query, with numeric handles like list. It intentionally does not warn about duplicate ranks: unlike list,
 // these focused views do not serve as vault-integrity reporting.
 func runIssueQuery(cmd *cobra.Command, matches func(list.Item, time.Time, map[string]string) bool) error {
 	vaultDir, err := resolveVault(cmd)
@@ -104,11 +190,17 @@ func runIssueQuery(cmd *cobra.Command, matches func(list.Item, time.Time, map[st
 	}
 
 	statusByID := list.StatusByID(items)
-	now := time.Now()
+	now, _, err := vaultClock(vaultDir)
+	if err != nil {
+		return err
+	}
+	var listed, lines []string
 	for _, item := range items {
 		if matches(item, now, statusByID) {
-			fmt.Fprintln(cmd.OutOrStdout(), formatListLine(item))
+			listed = append(listed, item.ID)
+			lines = append(lines, formatListLine(item))
 		}
 	}
+	printListing(cmd, vaultDir, items, listed, lines)
 	return nil
 }
@@ -0,0 +1,128 @@
+// Package cli — recurring Issues. mt done closes a recurring Issue
+// (recur: in its frontmatter) and spawns its next occurrence as a new
+// Issue. The period arithmetic lives in internal/deferral and the copy
+// in internal/issue; this file owns the files.
+package cli
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Sanmoo/my-tasks2/internal/deferral"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/vault"
+)
+
+// occurrence is the next occurrence of a recurring Issue being closed:
+// the ID it spawns from, the ID it will have and the new Issue, not
+// written yet.
+type occurrence struct {
+	from string
+	id   string
+	next issue.Issue
+}
+
+// planOccurrence returns the next occurrence of the Issue id, about to
+// be closed at now; ok is false when i does not recur, or is done
+// already (closing it again spawns nothing). A recur or date the period
+// cannot advance is a user error, so the done is refused rather than
+// losing the occurrence.
+func planOccurrence(id string, i issue.Issue, now time.Time, zone issue.Zone) (occ occurrence, ok bool, err error) {
+	fm := i.Frontmatter
+	if fm.Recur == "" || fm.Status == "done" {
+		return occurrence{}, false, nil
+	}
+	if _, err := deferral.Recur(fm.Recur, now); err != nil {
+		return occurrence{}, false, fmt.Errorf("issue %s: %w", id, err)
+	}
+	advance := func(field, stamp string) (string, error) {
+		if stamp == "" {
+			return "", nil
+		}
+		t, ok := issue.ParseStamp(stamp, zone.Location())
+		if !ok {
+			return "", fmt.Errorf("issue %s recurs but its %s %q is not a datetime — run mt check", id, field, stamp)
+		}
+		next, err := deferral.Recur(fm.Recur, t)
+		if err != nil {
+			return "", fmt.Errorf("issue %s: %w", id, err)
+		}
+		return zone.Format(zone.In(next)), nil
+	}
+	deadline, err := advance("deadline", fm.Deadline)
+	if err != nil {
+		return occurrence{}, false, err
+	}
+	deferredUntil, err := advance("deferred_until", fm.DeferredUntil)
+	if err != nil {
+		return occurrence{}, false, err
+	}
+	return occurrence{from: id, next: i.Occurrence(zone.Format(now), deadline, deferredUntil)}, true, nil
+}
+
+// spawner plans and writes the occurrences a done spawns. plan runs in
+// the done's batch plan, so an occurrence gets its ID and passes its
+// pre_created hooks before any file is written, and a veto refuses the
+// whole done; spawn writes them once the done is written.
+type spawner struct {
+	vaultDir string
+	now      time.Time
+	zone     issue.Zone
+	prefix   string
+	// taken holds the vault's IDs and those planned, loaded on the first
+	// recurring Issue.
+	taken map[string]bool
+	occs  []occurrence
+}
+
+// newSpawner returns the spawner of a done at now in vaultDir.
+func newSpawner(vaultDir string, now time.Time, zone issue.Zone) *spawner {
+	return &spawner{vaultDir: vaultDir, now: now, zone: zone}
+}
+
+// plan plans the next occurrence of the Issue id, about to be closed,
+// when it recurs (see planOccurrence).
+func (s *spawner) plan(id string, i issue.Issue) error {
+	occ, ok, err := planOccurrence(id, i, s.now, s.zone)
+	if err != nil || !ok {
+		return err
+	}
+	if s.taken == nil {
+		vcfg, err := vault.LoadVault(s.vaultDir)
+		if err != nil {
+			return err
+		}
+		ids, err := vaultIssueIDs(s.vaultDir)
+		if err != nil {
+			return err
+		}
+		s.prefix, s.taken = vcfg.Prefix, make(map[string]bool, len(ids))
+		for _, id := range ids {
+			s.taken[id] = true
+		}
+	}
+	if occ.id, err = issue.NextID(s.prefix, s.taken, rand.Reader); err != nil {
+		return err
+	}
+	s.taken[occ.id] = true
+	if err := vetChange(s.vaultDir, occ.id, nil, &occ.next); err != nil {
+		return err
+	}
+	s.occs = append(s.occs, occ)
+	return nil
+}
+
+// spawn creates each planned occurrence, as mt create does, and reports
+// each as "<id> spawned from <from>: <title>". A failed write names the
+// occurrence lost: its Issue is done already.
+func (s *spawner) spawn(out io.Writer) error {
+	for _, occ := range s.occs {
+		if err := createIssueFile(s.vaultDir, occ.id, occ.next); err != nil {
+			return fmt.Errorf("%s is done but its next occurrence was not spawned (mt undo reverts the done): %w", occ.from, err)
+		}
+		fmt.Fprintf(out, "%s spawned from %s: %s\n", occ.id, occ.from, occ.next.Frontmatter.Title)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// Package cli — the persistent Issue index and the mt reindex command.
+// They own the process concerns of the index (walking issues/, parsing
+// the stale files, saving the cache); when an entry may be trusted lives
+// in internal/index.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/index"
+	"github.com/Sanmoo/my-tasks2/internal/list"
+)
+
+// newReindexCmd builds `mt reindex`: rebuilds the vault's index from a
+// full parse of every Issue file.
+func newReindexCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reindex",
+		Short: "Rebuild the vault's Issue index from the Issue files",
+		Long:  reindexLong,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return exitcode.Usage(errors.New("reindex takes no arguments"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			vaultDir, err := resolveVault(cmd)
+			if err != nil {
+				return err
+			}
+			ix := index.New()
+			items, err := refreshIndex(vaultDir, ix)
+			if err != nil {
+				return err
+			}
+			if err := ix.Save(index.Path(vaultDir)); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Indexed %d issues\n", len(items))
+			return nil
+		},
+	}
+}
+
+// loadIndexedItems returns every Issue of the vault with its frontmatter
+// only — the Body is left empty — reading it from the index and parsing
+// just the files changed since they were indexed. Saving the refreshed
+// index is best effort: a read-only vault still lists, at the cost of a
+// full parse each time. Commands that need bodies use loadItems.
+func loadIndexedItems(vaultDir string) ([]list.Item, error) {
+	path := index.Path(vaultDir)
+	ix := index.Load(path)
+	items, err := refreshIndex(vaultDir, ix)
+	if err != nil {
+		return nil, err
+	}
+	if ix.Dirty() {
+		_ = ix.Save(path)
+	}
+	return items, nil
+}
+
+// refreshIndex brings ix up to date with the vault's issues/ directory and
+// returns the vault's Issues as frontmatter-only items. A file whose stat
+// signature matches its entry is not read; the others have their
+// frontmatter read in parallel, with the same errors as a full load.
+func refreshIndex(vaultDir string, ix *index.Index) ([]list.Item, error) {
+	files, err := listIssueFiles(vaultDir)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]list.Item, len(files))
+	keys := make([]index.Key, len(files))
+	names := make([]string, len(files))
+	var stale []int
+	for i, f := range files {
+		names[i] = f.Name
+		keys[i] = index.Key{MTime: f.Info.ModTime().UnixNano(), Size: f.Info.Size(), Inode: inodeOf(f.Info)}
+		items[i].ID = f.ID
+		fm, ok := ix.Lookup(f.Name, keys[i])
+		if !ok {
+			stale = append(stale, i)
+			continue
+		}
+		items[i].Issue.Frontmatter = fm
+	}
+	err = forEachParallel(len(stale), func(n int) error {
+		i := stale[n]
+		fm, err := readIssueFrontmatter(vaultDir, files[i].ID)
+		items[i].Issue.Frontmatter = fm
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, i := range stale {
+		ix.Put(names[i], keys[i], items[i].Issue.Frontmatter, now)
+	}
+	ix.Retain(names)
+	return items, nil
+}
+
+const reindexLong = `reindex rebuilds the vault's Issue index from scratch and prints how
+many Issues it holds.
+
+The index (.mt/cache/index.json, ignored by Git) caches the frontmatter
+of every Issue file, keyed by file name and validated by the file's
+modification time, size and inode. list, ready, overdue, pick-next,
+prioritize and bare mt read it and parse only the frontmatter of the
+Issue files that changed since, so a large vault lists quickly. It is
+refreshed on every such run; a missing, corrupt or outdated index is
+simply rebuilt, and a vault whose index cannot be written is read in
+full. reindex is never required — use it
+to rebuild the cache eagerly, e.g. after restoring the vault from a
+backup that kept the old modification times.`
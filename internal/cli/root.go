@@ -5,14 +5,18 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/Sanmoo/my-tasks2/internal/deferral"
 	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
 	"github.com/Sanmoo/my-tasks2/internal/vault"
 )
 
@@ -22,6 +26,14 @@ import (
 // process, so a package var is safe.
 var bookmark string
 
+// at is the --at clock override of this process: a time expression (see
+// deferral.Parse) every command takes as now instead of the wall clock.
+// Unset, the MT_NOW environment variable is the override.
+var at string
+
+// envNow is the environment variable of the clock override.
+const envNow = "MT_NOW"
+
 // Execute runs mt with the process's args and streams, returning the
 // process exit code under the project convention.
 func Execute() int {
@@ -31,6 +43,12 @@ func Execute() int {
 // Run runs mt with the given args, writing to stdout and stderr, and
 // returns the exit code under the project convention.
 func Run(args []string, stdout, stderr io.Writer) int {
+	startJournal(args)
+	hooks = hookState{}
+	// Deferred calls run last-in first-out: the journal is flushed
+	// before the post-hooks run.
+	defer runPostHooks(stderr)
+	defer flushJournal(stderr)
 	// The @bookmark token may appear anywhere among the args; strip it
 	// before cobra parses, so command argument validators never see it.
 	var err error
@@ -94,7 +112,7 @@ func NewRootCmd() *cobra.Command {
 			// Bare `mt` (no command after extracting @bookmark) lists the
 			// resolved vault's in_progress Issues — strictly `mt list
 			// --status in_progress` (see rootLong for the full behavior).
-			return runList(cmd, false, statusInProgress, nil)
+			return runList(cmd, listFlags{status: statusInProgress})
 		},
 		SilenceErrors: true,
 		SilenceUsage:  true,
@@ -107,6 +125,7 @@ func NewRootCmd() *cobra.Command {
 	// usage error, so help topics go through the same classification.
 	cmd.SetHelpCommand(newHelpCmd())
 	cmd.PersistentFlags().String("vault", "", "vault path (takes precedence over the default bookmark)")
+	cmd.PersistentFlags().StringVar(&at, "at", "", "run as if now were this time (as mt defer takes it; default $MT_NOW, else the clock)")
 	cmd.AddCommand(newInitCmd())
 	cmd.AddCommand(newCreateCmd())
 	cmd.AddCommand(newQCmd())
@@ -115,9 +134,13 @@ func NewRootCmd() *cobra.Command {
 	cmd.AddCommand(newDoneCmd())
 	cmd.AddCommand(newReopenCmd())
 	cmd.AddCommand(newStatusCmd())
+	cmd.AddCommand(newStartCmd())
+	cmd.AddCommand(newPauseCmd())
 	cmd.AddCommand(newDeferCmd())
 	cmd.AddCommand(newUndeferCmd())
 	cmd.AddCommand(newDepCmd())
+	cmd.AddCommand(newLabelCmd())
+	cmd.AddCommand(newGraphCmd())
 	cmd.AddCommand(newPickNextCmd())
 	cmd.AddCommand(newPrioritizeCmd())
 	cmd.AddCommand(newTopCmd())
@@ -125,11 +148,21 @@ func NewRootCmd() *cobra.Command {
 	cmd.AddCommand(newRankCmd())
 	cmd.AddCommand(newUnrankCmd())
 	cmd.AddCommand(newCommentCmd())
+	cmd.AddCommand(newCommentsCmd())
 	cmd.AddCommand(newBookmarkCmd())
 	cmd.AddCommand(newListCmd())
 	cmd.AddCommand(newCheckCmd())
+	cmd.AddCommand(newMigrateTimesCmd())
+	cmd.AddCommand(newReindexCmd())
 	cmd.AddCommand(newReadyCmd())
 	cmd.AddCommand(newOverdueCmd())
+	cmd.AddCommand(newDueCmd())
+	cmd.AddCommand(newAgendaCmd())
+	cmd.AddCommand(newUndoCmd())
+	cmd.AddCommand(newJournalCmd())
+	cmd.AddCommand(newServeCmd())
+	cmd.AddCommand(newMCPCmd())
+	cmd.AddCommand(newExportCmd())
 	return cmd
 }
 
@@ -189,6 +222,63 @@ func resolveVault(cmd *cobra.Command) (string, error) {
 	return resolved, nil
 }
 
+// vaultClock returns now in the timezone of the vault at vaultDir, with
+// that timezone setting, which writes its stamps (see issue.Zone). A
+// directory without mt.yaml has no setting: naive local time.
+func vaultClock(vaultDir string) (time.Time, issue.Zone, error) {
+	vcfg, err := vault.LoadVault(vaultDir)
+	if err != nil && !errors.Is(err, vault.ErrNotVault) {
+		return time.Time{}, issue.Zone{}, err
+	}
+	now, err := clock(vcfg)
+	return now, vcfg.Zone, err
+}
+
+// clock returns the now of a command against the vault config vcfg: the
+// --at or MT_NOW override, resolved against the wall clock under the
+// vault's default time and timezone, else the wall clock itself — in the
+// vault's timezone either way. A malformed --at or MT_NOW is a usage
+// error: both are part of how mt was invoked.
+func clock(vcfg vault.Vault) (time.Time, error) {
+	now := vcfg.Zone.In(time.Now())
+	expr, source := at, "--at"
+	if expr == "" {
+		expr, source = os.Getenv(envNow), envNow
+	}
+	if expr == "" {
+		return now, nil
+	}
+	stamp, err := deferral.Parse(expr, now, deferral.Options{DefaultTime: vcfg.DefaultTime, Zone: vcfg.Zone})
+	if err != nil {
+		return time.Time{}, exitcode.Usage(fmt.Errorf("%s: %w", source, err))
+	}
+	t, _ := issue.ParseStamp(stamp, vcfg.Zone.Location())
+	return t, nil
+}
+
+// vaultWarn returns the lead time of the deadline warnings of the vault
+// at vaultDir, its warn_before: 0, no warning, when it sets none.
+func vaultWarn(vaultDir string) (time.Duration, error) {
+	vcfg, err := vault.LoadVault(vaultDir)
+	if err != nil && !errors.Is(err, vault.ErrNotVault) {
+		return 0, err
+	}
+	if vcfg.WarnBefore == "" {
+		return 0, nil
+	}
+	return deferral.ParseSpan(vcfg.WarnBefore)
+}
+
+// vaultWIPLimit returns the wip_limit of the vault at vaultDir: 0, no
+// limit, when it sets none.
+func vaultWIPLimit(vaultDir string) (int, error) {
+	vcfg, err := vault.LoadVault(vaultDir)
+	if err != nil && !errors.Is(err, vault.ErrNotVault) {
+		return 0, err
+	}
+	return vcfg.WIPLimit, nil
+}
+
 const rootLong = `mt is a personal, git-friendly issue tracker: one Markdown file per Issue,
 one Vault per domain, everything versioned in Git.
 
@@ -196,6 +286,12 @@ Vaults are addressed by @bookmark, --vault <path>, or the default
 bookmark in the global config. With none of them, vault-requiring
 commands fail with instructions.
 
+--at <when> (or the MT_NOW environment variable) runs any command as if
+now were that time, in the expressions mt defer takes: mt ready --at
+"mon 9:00" shows what will be ready Monday morning; writes stamp that
+time instead of the wall clock. A malformed --at or MT_NOW is a usage
+error (exit 2).
+
 Bare mt (no command) shows the resolved vault's in_progress Issues,
 like 'mt list --status in_progress'. 'mt help' and 'mt --help' show
 this help.
@@ -0,0 +1,417 @@
+// Package cli — mt serve: the vault over a local HTTP/JSON API, for
+// dashboards and small web pages. The handlers go through the same read
+// and write paths as the commands (the pure packages, writeIssueFile,
+// the journal and the hooks), one request at a time: each mutation is
+// one journaled operation, as if it were one mt command.
+package cli
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/vault"
+)
+
+// defaultServeAddr is where mt serve listens without --addr: loopback
+// only, so the vault is not exposed to the network by default.
+const defaultServeAddr = "127.0.0.1:7390"
+
+// maxRequestBody caps the JSON body of a request.
+const maxRequestBody = 1 << 20
+
+// newServeCmd builds `mt serve`: serves the resolved vault over HTTP
+// until interrupted.
+func newServeCmd() *cobra.Command {
+	var addr string
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the vault over a local HTTP/JSON API",
+		Long:  serveLong,
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return exitcode.Usage(fmt.Errorf("serve takes no arguments"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runServe(cmd, addr)
+		},
+	}
+	cmd.Flags().StringVar(&addr, "addr", defaultServeAddr, "listen address (host:port)")
+	return cmd
+}
+
+// runServe checks the vault and the token, then serves until SIGINT or
+// SIGTERM, letting in-flight requests finish.
+func runServe(cmd *cobra.Command, addr string) error {
+	vaultDir, err := resolveVault(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err := vault.LoadVault(vaultDir); err != nil {
+		return err
+	}
+	global, path, err := loadGlobal()
+	if err != nil {
+		return fmt.Errorf("loading global config: %w", err)
+	}
+	if global.ServeToken == "" {
+		return fmt.Errorf("mt serve needs a token: set serve.token in %s", path)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", addr, err)
+	}
+	srv := &http.Server{
+		Handler:           NewServeHandler(vaultDir, global.ServeToken, cmd.ErrOrStderr()),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+	fmt.Fprintf(cmd.OutOrStdout(), "Serving %s on http://%s\n", vaultDir, ln.Addr())
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving: %w", err)
+	}
+	return nil
+}
+
+// server is the HTTP API of one vault. mu serializes the requests: the
+// vault API runs one operation at a time.
+type server struct {
+	api   vaultAPI
+	token string
+	// log receives the journal and hook warnings, like stderr does for a
+	// command.
+	log io.Writer
+	mu  sync.Mutex
+}
+
+// NewServeHandler returns the HTTP/JSON API of the vault at vaultDir.
+// Every request must carry "Authorization: Bearer <token>". Reads:
+//
+//	GET  /issues[?all=1&status=&label=&where=]  the list view
+//	GET  /ready, GET /overdue                   the query views
+//	GET  /issues/{id}                           one Issue, with its ETag
+//
+// Mutations, answered with the changed Issue and its new ETag:
+//
+//	POST /issues                   create: {"title": ..., "labels": [...], ...}
+//	POST /issues/{id}/done
+//	POST /issues/{id}/defer        {"until": "+2d"}
+//	POST /issues/{id}/comments     {"text": ..., "reply_to": anchor}
+//	POST /issues/{id}/rank         {"rank": "top" | "bottom" | 3 | "backlog"}
+//
+// A mutation of an Issue with If-Match is refused with 412 unless the
+// file still has that ETag. Warnings go to log.
+func NewServeHandler(vaultDir, token string, log io.Writer) http.Handler {
+	s := &server{api: vaultAPI{vaultDir}, token: token, log: log}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /issues", s.read(s.listIssues))
+	mux.HandleFunc("GET /ready", s.read(s.readyIssues))
+	mux.HandleFunc("GET /overdue", s.read(s.overdueIssues))
+	mux.HandleFunc("GET /issues/{id}", s.read(s.showIssue))
+	mux.HandleFunc("POST /issues", s.write(s.createIssue))
+	mux.HandleFunc("POST /issues/{id}/done", s.write(s.doneIssue))
+	mux.HandleFunc("POST /issues/{id}/defer", s.write(s.deferIssue))
+	mux.HandleFunc("POST /issues/{id}/comments", s.write(s.commentIssue))
+	mux.HandleFunc("POST /issues/{id}/rank", s.write(s.rankIssue))
+	return s.authorize(mux)
+}
+
+// httpError is an error with the HTTP status it answers with.
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e httpError) Error() string { return e.err.Error() }
+func (e httpError) Unwrap() error { return e.err }
+
+// statusOf maps a handler error to its HTTP status: the status of an
+// httpError, 404 for an unknown Issue, 400 for what the CLI calls a usage
+// error, and 422 for a well-formed request the vault refused (a hook
+// veto, a missing blocker).
+func statusOf(err error) int {
+	var he httpError
+	if errors.As(err, &he) {
+		return he.status
+	}
+	if errors.Is(err, errNotFound) {
+		return http.StatusNotFound
+	}
+	if exitcode.For(err) == exitcode.UsageError {
+		return http.StatusBadRequest
+	}
+	return http.StatusUnprocessableEntity
+}
+
+// handlerFunc is an API endpoint: the response status and JSON value, or
+// an error.
+type handlerFunc func(r *http.Request) (int, any, error)
+
+// authorize rejects requests without the bearer token.
+func (s *server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mt"`)
+			respond(w, http.StatusUnauthorized, errorBody("missing or wrong token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// read serves a read-only endpoint.
+func (s *server) read(h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		status, v, err := h(r)
+		serveResult(w, status, v, err)
+	}
+}
+
+// write serves a mutating endpoint as one journaled operation, named
+// after the request: its changes are journaled and its post-hooks run
+// once it returns (see operation).
+func (s *server) write(h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+		var (
+			status int
+			v      any
+			err    error
+		)
+		operation([]string{"serve:", r.Method, r.URL.Path}, s.log, func() {
+			status, v, err = h(r)
+		})
+		serveResult(w, status, v, err)
+	}
+}
+
+// serveResult writes a handler's result: the value, with its ETag header
+// when it is one Issue, or the error.
+func serveResult(w http.ResponseWriter, status int, v any, err error) {
+	if err != nil {
+		respond(w, statusOf(err), errorBody(err.Error()))
+		return
+	}
+	if d, ok := v.(issueDetail); ok {
+		w.Header().Set("ETag", d.ETag)
+		if status == http.StatusCreated {
+			w.Header().Set("Location", "/issues/"+d.ID)
+		}
+	}
+	respond(w, status, v)
+}
+
+// respond writes v as the JSON response body.
+func respond(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// errorBody is the JSON body of an error response.
+func errorBody(msg string) map[string]string {
+	return map[string]string{"error": msg}
+}
+
+// listIssues is GET /issues: the mt list view, with its filters as query
+// parameters (all, status, label — repeatable — and where).
+func (s *server) listIssues(r *http.Request) (int, any, error) {
+	params := r.URL.Query()
+	issues, err := s.api.list(listFilter{
+		All:    params.Get("all") != "",
+		Status: params.Get("status"),
+		Labels: params["label"],
+		Where:  params.Get("where"),
+	})
+	return http.StatusOK, issues, err
+}
+
+// readyIssues is GET /ready: the mt ready view.
+func (s *server) readyIssues(*http.Request) (int, any, error) {
+	issues, err := s.api.ready()
+	return http.StatusOK, issues, err
+}
+
+// overdueIssues is GET /overdue: the two groups of mt overdue.
+func (s *server) overdueIssues(*http.Request) (int, any, error) {
+	groups, err := s.api.overdue()
+	return http.StatusOK, groups, err
+}
+
+// showIssue is GET /issues/{id}.
+func (s *server) showIssue(r *http.Request) (int, any, error) {
+	return detailResult(http.StatusOK)(s.api.show(r.PathValue("id")))
+}
+
+// detailResult adapts the result of an API operation on one Issue to a
+// handler's, answered with status.
+func detailResult(status int) func(issueDetail, error) (int, any, error) {
+	return func(d issueDetail, err error) (int, any, error) {
+		if err != nil {
+			return 0, nil, err
+		}
+		return status, d, nil
+	}
+}
+
+// target returns the ID of the Issue a mutation request addresses, after
+// the optimistic concurrency check: with If-Match, the file must still
+// have one of the listed ETags (or any, for *).
+func (s *server) target(r *http.Request) (string, error) {
+	id := r.PathValue("id")
+	data, err := s.api.issueData(id)
+	if err != nil {
+		return "", err
+	}
+	match := r.Header.Get("If-Match")
+	if match == "" {
+		return id, nil
+	}
+	etag := etagOf(data)
+	for _, tag := range strings.Split(match, ",") {
+		if tag = strings.TrimSpace(tag); tag == "*" || tag == etag {
+			return id, nil
+		}
+	}
+	return "", httpError{http.StatusPreconditionFailed, fmt.Errorf("issue %s changed since it was read (ETag is now %s)", id, etag)}
+}
+
+// decode reads the JSON request body into v. Unknown fields are
+// rejected, so a typo does not pass silently.
+func decode(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return exitcode.Usage(fmt.Errorf("reading request body: %w", err))
+	}
+	return nil
+}
+
+// createIssue is POST /issues: mt create, the body a createRequest.
+func (s *server) createIssue(r *http.Request) (int, any, error) {
+	var req createRequest
+	if err := decode(r, &req); err != nil {
+		return 0, nil, err
+	}
+	return detailResult(http.StatusCreated)(s.api.create(req))
+}
+
+// doneIssue is POST /issues/{id}/done: mt done.
+func (s *server) doneIssue(r *http.Request) (int, any, error) {
+	id, err := s.target(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	return detailResult(http.StatusOK)(s.api.done(id))
+}
+
+// deferIssue is POST /issues/{id}/defer: mt defer, the time as mt defer
+// takes it.
+func (s *server) deferIssue(r *http.Request) (int, any, error) {
+	var req struct {
+		Until string `json:"until"`
+	}
+	if err := decode(r, &req); err != nil {
+		return 0, nil, err
+	}
+	id, err := s.target(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	return detailResult(http.StatusOK)(s.api.deferTo(id, req.Until))
+}
+
+// commentIssue is POST /issues/{id}/comments: mt comment, or a reply with
+// reply_to.
+func (s *server) commentIssue(r *http.Request) (int, any, error) {
+	var req struct {
+		Text    string `json:"text"`
+		ReplyTo string `json:"reply_to"`
+	}
+	if err := decode(r, &req); err != nil {
+		return 0, nil, err
+	}
+	id, err := s.target(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	return detailResult(http.StatusOK)(s.api.comment(id, req.Text, req.ReplyTo))
+}
+
+// rankIssue is POST /issues/{id}/rank: mt top, bottom, rank or — with
+// "backlog" — unrank.
+func (s *server) rankIssue(r *http.Request) (int, any, error) {
+	var req struct {
+		Rank any `json:"rank"`
+	}
+	if err := decode(r, &req); err != nil {
+		return 0, nil, err
+	}
+	if req.Rank == nil {
+		return 0, nil, exitcode.Usage(errors.New(`rank needs "rank": top, bottom, a position or backlog`))
+	}
+	id, err := s.target(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	return detailResult(http.StatusOK)(s.api.rank(id, fmt.Sprint(req.Rank)))
+}
+
+const serveLong = `serve exposes the vault over a local HTTP/JSON API, for a dashboard or
+a small web page, until interrupted. It listens on 127.0.0.1:7390 by
+default (--addr to change), and every request must carry the token set
+as serve.token in the global config:
+
+  Authorization: Bearer <token>
+
+Reads:
+  GET  /issues          the list view (?all=1, ?status=, ?label=, ?where=)
+  GET  /ready           the ready view
+  GET  /overdue         {"expired": [...], "deadline": [...]}
+  GET  /issues/{id}     one Issue: frontmatter, body and ETag
+
+Mutations, answered with the changed Issue:
+  POST /issues                 {"title": "...", "labels": [...], "rank": "top", ...}
+                               (the create flags: template, deadline, defer,
+                               rank, blocked_by, status, description, body)
+  POST /issues/{id}/done
+  POST /issues/{id}/defer      {"until": "+2d"}
+  POST /issues/{id}/comments   {"text": "...", "reply_to": "<anchor>"}
+  POST /issues/{id}/rank       {"rank": "top" | "bottom" | 3 | "backlog"}
+
+Each Issue has an ETag (also in the ETag header). Send it back as
+If-Match on a mutation and the server refuses the change with 412 when
+the file changed since it was read. Mutations go through the same paths
+as the commands: they are journaled (mt undo reverts them) and run the
+hooks. Errors are {"error": "..."} with 400 for a malformed request, 401
+without the token, 404 for an unknown Issue and 422 when the vault
+refuses the change.`
@@ -0,0 +1,243 @@
+// Package cli_test holds the httptest tests of the mt serve API, through
+// its exported handler: the HTTP contract (routes, status codes, ETags)
+// against a temporary vault on disk.
+package cli_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sanmoo/my-tasks2/internal/cli"
+)
+
+const token = "s3cret"
+
+// newVault writes a vault with two ranked Issues, pkm-001 blocking
+// pkm-002, and a Backlog Issue past its deadline, under an isolated
+// global config.
+func newVault(t *testing.T) string {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	files := map[string]string{
+		"mt.yaml":           "prefix: pkm\nstatus: [open, in_progress, done]\n",
+		"issues/pkm-001.md": "---\ntitle: first\nstatus: open\nlabels: [casa]\ncreated_at: 2026-01-01T10:00\nrank: 1\n---\n\n## Description\n\n## Notes\n\n## Comments\n",
+		"issues/pkm-002.md": "---\ntitle: second\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\nrank: 2\nblocked_by: [pkm-001]\n---\n",
+		"issues/pkm-003.md": "---\ntitle: late\nstatus: open\nlabels: []\ncreated_at: 2026-01-01T10:00\ndeadline: 2020-01-01T10:00\n---\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// call sends one request to the API of dir and decodes the JSON answer.
+func call(t *testing.T, dir, method, path, body string, header map[string]string) (*http.Response, map[string]any, []any) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	cli.NewServeHandler(dir, token, io.Discard).ServeHTTP(rec, req)
+	res := rec.Result()
+	data, _ := io.ReadAll(res.Body)
+	var obj map[string]any
+	var arr []any
+	if strings.HasPrefix(string(data), "[") {
+		if err := json.Unmarshal(data, &arr); err != nil {
+			t.Fatalf("%s %s: decoding %s: %v", method, path, data, err)
+		}
+	} else if err := json.Unmarshal(data, &obj); err != nil {
+		t.Fatalf("%s %s: decoding %s: %v", method, path, data, err)
+	}
+	return res, obj, arr
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func ids(arr []any) []string {
+	var out []string
+	for _, v := range arr {
+		out = append(out, v.(map[string]any)["id"].(string))
+	}
+	return out
+}
+
+func TestServeRequiresTheToken(t *testing.T) {
+	dir := newVault(t)
+	for name, auth := range map[string]string{"none": "", "wrong": "Bearer nope", "scheme": "Basic " + token} {
+		req := httptest.NewRequest("GET", "/issues", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		cli.NewServeHandler(dir, token, io.Discard).ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: status %d, want 401 with a challenge", name, rec.Code)
+		}
+	}
+}
+
+func TestServeListViews(t *testing.T) {
+	dir := newVault(t)
+	res, _, arr := call(t, dir, "GET", "/issues", "", nil)
+	if res.StatusCode != http.StatusOK || strings.Join(ids(arr), ",") != "pkm-001,pkm-002,pkm-003" {
+		t.Fatalf("GET /issues = %d %v", res.StatusCode, ids(arr))
+	}
+	second := arr[1].(map[string]any)
+	if second["title"] != "second" || second["blocked"] != true || second["rank"] != 2.0 {
+		t.Errorf("pkm-002 = %v, want its frontmatter and blocked", second)
+	}
+	if _, _, arr := call(t, dir, "GET", "/issues?label=casa", "", nil); strings.Join(ids(arr), ",") != "pkm-001" {
+		t.Errorf("GET /issues?label=casa = %v", ids(arr))
+	}
+	if _, _, arr := call(t, dir, "GET", "/issues?where=rank%3E1", "", nil); strings.Join(ids(arr), ",") != "pkm-002" {
+		t.Errorf("GET /issues?where=rank>1 = %v", ids(arr))
+	}
+	if res, obj, _ := call(t, dir, "GET", "/issues?where=nope~~", "", nil); res.StatusCode != http.StatusBadRequest || obj["error"] == nil {
+		t.Errorf("bad where = %d %v, want 400 with an error", res.StatusCode, obj)
+	}
+	if _, _, arr := call(t, dir, "GET", "/ready", "", nil); strings.Join(ids(arr), ",") != "pkm-001,pkm-003" {
+		t.Errorf("GET /ready = %v, want the unblocked Issues", ids(arr))
+	}
+	_, obj, _ := call(t, dir, "GET", "/overdue", "", nil)
+	if late := obj["deadline"].([]any); len(late) != 1 || late[0].(map[string]any)["id"] != "pkm-003" {
+		t.Errorf("GET /overdue = %v", obj)
+	}
+}
+
+func TestServeShowIssue(t *testing.T) {
+	dir := newVault(t)
+	res, obj, _ := call(t, dir, "GET", "/issues/pkm-001", "", nil)
+	if res.StatusCode != http.StatusOK || obj["title"] != "first" || !strings.Contains(obj["body"].(string), "## Notes") {
+		t.Fatalf("GET /issues/pkm-001 = %d %v", res.StatusCode, obj)
+	}
+	if etag := res.Header.Get("ETag"); etag == "" || etag != obj["etag"] {
+		t.Errorf("ETag header %q, etag field %v", etag, obj["etag"])
+	}
+	if res, _, _ := call(t, dir, "GET", "/issues/pkm-404", "", nil); res.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown Issue: status %d, want 404", res.StatusCode)
+	}
+	if res, _, _ := call(t, dir, "GET", "/issues/a%5Cb", "", nil); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid ID: status %d, want 400", res.StatusCode)
+	}
+}
+
+func TestServeDoneWithIfMatch(t *testing.T) {
+	dir := newVault(t)
+	path := filepath.Join(dir, "issues", "pkm-001.md")
+	res, _, _ := call(t, dir, "GET", "/issues/pkm-001", "", nil)
+	etag := res.Header.Get("ETag")
+
+	res, obj, _ := call(t, dir, "POST", "/issues/pkm-001/done", "", map[string]string{"If-Match": `"stale"`})
+	if res.StatusCode != http.StatusPreconditionFailed || !strings.Contains(readFile(t, path), "status: open") {
+		t.Fatalf("stale If-Match = %d %v, want 412 and the file unchanged", res.StatusCode, obj)
+	}
+	res, obj, _ = call(t, dir, "POST", "/issues/pkm-001/done", "", map[string]string{"If-Match": etag})
+	if res.StatusCode != http.StatusOK || obj["status"] != "done" || obj["completed_at"] == nil {
+		t.Fatalf("done = %d %v", res.StatusCode, obj)
+	}
+	if newTag := res.Header.Get("ETag"); newTag == etag || newTag != obj["etag"] {
+		t.Errorf("ETag after done = %q (was %q), body %v", newTag, etag, obj["etag"])
+	}
+	if !strings.Contains(readFile(t, path), "status: done") {
+		t.Error("done did not write the file")
+	}
+	if journal := readFile(t, filepath.Join(dir, ".mt", "journal.jsonl")); !strings.Contains(journal, "mt serve: POST /issues/pkm-001/done") {
+		t.Errorf("journal = %s, want the request as the command", journal)
+	}
+	if res, _, _ := call(t, dir, "POST", "/issues/pkm-404/done", "", nil); res.StatusCode != http.StatusNotFound {
+		t.Errorf("done of an unknown Issue: status %d, want 404", res.StatusCode)
+	}
+}
+
+func TestServeDeferAndComment(t *testing.T) {
+	dir := newVault(t)
+	if res, _, _ := call(t, dir, "POST", "/issues/pkm-001/defer", `{"until": "someday"}`, nil); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("bad time: status %d, want 400", res.StatusCode)
+	}
+	if res, _, _ := call(t, dir, "POST", "/issues/pkm-001/defer", `{"when": "+2d"}`, nil); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown field: status %d, want 400", res.StatusCode)
+	}
+	if res, obj, _ := call(t, dir, "POST", "/issues/pkm-001/defer", `{"until": "+2d"}`, nil); res.StatusCode != http.StatusOK || obj["deferred_until"] == nil {
+		t.Errorf("defer = %d %v", res.StatusCode, obj)
+	}
+
+	res, obj, _ := call(t, dir, "POST", "/issues/pkm-001/comments", `{"text": "comprei\nmetade"}`, nil)
+	if res.StatusCode != http.StatusOK || !strings.Contains(obj["body"].(string), "comprei\nmetade\n<!-- comment: ") {
+		t.Fatalf("comment = %d %v", res.StatusCode, obj)
+	}
+	if res, _, _ := call(t, dir, "POST", "/issues/pkm-001/comments", `{"text": "## heading"}`, nil); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("heading in comment: status %d, want 400", res.StatusCode)
+	}
+	if res, _, _ := call(t, dir, "POST", "/issues/pkm-001/comments", `{"text": "x", "reply_to": "deadbeef"}`, nil); res.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("reply to an unknown comment: status %d, want 422", res.StatusCode)
+	}
+}
+
+func TestServeRank(t *testing.T) {
+	dir := newVault(t)
+	res, obj, _ := call(t, dir, "POST", "/issues/pkm-003/rank", `{"rank": "top"}`, nil)
+	if res.StatusCode != http.StatusOK || obj["rank"] != 1.0 {
+		t.Fatalf("rank top = %d %v", res.StatusCode, obj)
+	}
+	if !strings.Contains(readFile(t, filepath.Join(dir, "issues", "pkm-002.md")), "rank: 3") {
+		t.Error("rank top did not shift the queue")
+	}
+	if _, obj, _ := call(t, dir, "POST", "/issues/pkm-003/rank", `{"rank": 2}`, nil); obj["rank"] != 2.0 {
+		t.Errorf("rank 2 = %v", obj)
+	}
+	if _, obj, _ := call(t, dir, "POST", "/issues/pkm-003/rank", `{"rank": "backlog"}`, nil); obj["rank"] != nil {
+		t.Errorf("rank backlog = %v, want no rank", obj)
+	}
+	for _, body := range []string{`{"rank": "middle"}`, `{}`} {
+		if res, _, _ := call(t, dir, "POST", "/issues/pkm-003/rank", body, nil); res.StatusCode != http.StatusBadRequest {
+			t.Errorf("rank %s: status %d, want 400", body, res.StatusCode)
+		}
+	}
+}
+
+func TestServeCreate(t *testing.T) {
+	dir := newVault(t)
+	res, obj, _ := call(t, dir, "POST", "/issues", `{"title": "from the web", "labels": ["casa"], "rank": "top", "blocked_by": ["pkm-001"], "description": "via API"}`, nil)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create = %d %v", res.StatusCode, obj)
+	}
+	id := obj["id"].(string)
+	if res.Header.Get("Location") != "/issues/"+id || obj["rank"] != 1.0 || obj["blocked"] != true {
+		t.Errorf("create = %v, Location %q", obj, res.Header.Get("Location"))
+	}
+	if content := readFile(t, filepath.Join(dir, "issues", id+".md")); !strings.Contains(content, "title: from the web") || !strings.Contains(content, "via API") {
+		t.Errorf("created file =\n%s", content)
+	}
+	if res, _, _ := call(t, dir, "POST", "/issues", `{"labels": ["x"]}`, nil); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("create without title: status %d, want 400", res.StatusCode)
+	}
+	if res, _, _ := call(t, dir, "POST", "/issues", `{"title": "x", "blocked_by": ["pkm-404"]}`, nil); res.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("create with a missing blocker: status %d, want 422", res.StatusCode)
+	}
+	if res, obj, _ := call(t, dir, "POST", "/issues", `{"title": "raw", "body": "\n## Description\nfrom body"}`, nil); res.StatusCode != http.StatusCreated || !strings.Contains(obj["body"].(string), "from body") {
+		t.Errorf("create with body = %d %v", res.StatusCode, obj)
+	}
+}
@@ -1,5 +1,5 @@
-// Package cli — Status commands: done (with close as alias), reopen and
-// status. These own process concerns (files, stdio); the transition
+// Package cli — Status commands: done (with close as alias), reopen,
+// status, start and pause. These own process concerns (files, stdio); the transition
 // rules themselves live in internal/issue, and status validation in
 // internal/vault.
 package cli
@@ -7,44 +7,69 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Sanmoo/my-tasks2/internal/exitcode"
 	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/list"
 	"github.com/Sanmoo/my-tasks2/internal/vault"
 )
 
-// newDoneCmd builds `mt done <id>` (alias `close`): closes the Issue —
-// status done, completed_at stamped now.
+// newDoneCmd builds `mt done <id...>` (alias `close`): closes the
+// Issues — status done, completed_at stamped now — and spawns the next
+// occurrence of each recurring one (see recur.go). The targets are IDs,
+// "-" for IDs on stdin, or a --where query (see batch.go).
 func newDoneCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:     "done <id>",
+	var flags batchFlags
+	cmd := &cobra.Command{
+		Use:     "done <id...>",
 		Aliases: []string{"close"},
-		Short:   "Close an Issue (stamp completed_at)",
+		Short:   "Close Issues (stamp completed_at)",
 		Args: func(cmd *cobra.Command, args []string) error {
-			if len(args) != 1 {
-				return exitcode.Usage(fmt.Errorf("done needs exactly one issue ID"))
+			if flags.where == "" && len(args) == 0 {
+				return exitcode.Usage(fmt.Errorf("done needs an issue ID (or several, - for stdin, or --where)"))
 			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runMutation(cmd, args[0], func(i issue.Issue) issue.Issue {
-				return i.Done(time.Now().Format(issue.NaiveLayout))
-			})
+			vaultDir, err := resolveVault(cmd)
+			if err != nil {
+				return err
+			}
+			targets, fromStdin, err := selectTargets(cmd, vaultDir, args, flags.where)
+			if err != nil {
+				return err
+			}
+			now, zone, err := vaultClock(vaultDir)
+			if err != nil {
+				return err
+			}
+			spawns := newSpawner(vaultDir, now, zone)
+			if err := runBatch(cmd, vaultDir, "done", targets, fromStdin, flags, func(id string, i issue.Issue) (issue.Issue, error) {
+				if err := spawns.plan(id, i); err != nil {
+					return issue.Issue{}, err
+				}
+				return i.Done(zone.Format(now)), nil
+			}, reportTransition); err != nil {
+				return fmt.Errorf("mutating issue: %w", err)
+			}
+			return spawns.spawn(cmd.OutOrStdout())
 		},
 	}
+	flags.register(cmd)
+	return cmd
 }
 
 // newReopenCmd builds `mt reopen <id>`: back to open, clearing
-// completed_at and started_at.
+// completed_at, started_at and the work_log.
 func newReopenCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "reopen <id>",
-		Short: "Reopen an Issue (clear completed_at and started_at)",
+		Short: "Reopen an Issue (clear completed_at, started_at and work_log)",
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
 				return exitcode.Usage(fmt.Errorf("reopen needs exactly one issue ID"))
@@ -59,14 +84,21 @@ func newReopenCmd() *cobra.Command {
 	}
 }
 
-// newStatusCmd builds `mt status <id> <status>`: the free transition,
-// validated against the vault's configured status list.
+// newStatusCmd builds `mt status <id...> <status>`: the free transition,
+// validated against the vault's configured status list — into
+// in_progress, also against the rules of mt start (see checkStartable).
+// The status is always the last argument; the targets before it are IDs,
+// "-" for IDs on stdin, or a --where query (see batch.go).
 func newStatusCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "status <id> <status>",
-		Short: "Set an Issue's status (free transition)",
+	var flags batchFlags
+	cmd := &cobra.Command{
+		Use:   "status <id...> <status>",
+		Short: "Set Issues' status (free transition)",
 		Args: func(cmd *cobra.Command, args []string) error {
-			if len(args) != 2 {
+			if flags.where != "" && len(args) != 1 {
+				return exitcode.Usage(fmt.Errorf("status --where needs exactly one status"))
+			}
+			if len(args) < 2 && flags.where == "" {
 				return exitcode.Usage(fmt.Errorf("status needs an issue ID and a status"))
 			}
 			return nil
@@ -76,29 +108,178 @@ func newStatusCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			ids, status := args[:len(args)-1], args[len(args)-1]
 			vcfg, err := vault.LoadVault(vaultDir)
 			if err != nil {
 				return fmt.Errorf("loading vault: %w", err)
 			}
-			if !vcfg.IsStatus(args[1]) {
+			if !vcfg.IsStatus(status) {
 				return fmt.Errorf("status %q is not in the vault's status list (valid: %s)",
-					args[1], strings.Join(vcfg.StatusList(), ", "))
+					status, strings.Join(vcfg.StatusList(), ", "))
 			}
-			return applyMutation(cmd, vaultDir, args[0], func(i issue.Issue) issue.Issue {
-				return i.SetStatus(args[1])
-			})
+			targets, fromStdin, err := selectTargets(cmd, vaultDir, ids, flags.where)
+			if err != nil {
+				return err
+			}
+			if status == "in_progress" {
+				items, err := loadIndexedItems(vaultDir)
+				if err != nil {
+					return fmt.Errorf("loading issues: %w", err)
+				}
+				if err := checkStartable(vaultDir, items, targets); err != nil {
+					return err
+				}
+			}
+			return runBatch(cmd, vaultDir, "status", targets, fromStdin, flags, func(_ string, i issue.Issue) (issue.Issue, error) {
+				return i.SetStatus(status), nil
+			}, reportTransition)
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+// newStartCmd builds `mt start <id>`: starts the given Issue as
+// pick-next starts its choice — in_progress, started_at stamped — when
+// it is available and the vault's wip_limit leaves room.
+func newStartCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "start <id>",
+		Short: "Start an Issue (stamp started_at)",
+		Long:  startLong,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return exitcode.Usage(fmt.Errorf("start needs exactly one issue ID"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStart(cmd, args[0])
 		},
 	}
 }
 
-// runMutation is the shared body of done and reopen: resolve the vault,
-// then apply the mutation to the Issue. resolveVault's errors already
-// name the failing step, so they propagate unwrapped.
+// runStart checks the Issue against the rules of list.Startable, then
+// starts it with one timestamp shared by the check and the write.
+func runStart(cmd *cobra.Command, arg string) error {
+	vaultDir, err := resolveVault(cmd)
+	if err != nil {
+		return err
+	}
+	id, err := resolveIssueArg(vaultDir, arg)
+	if err != nil {
+		return err
+	}
+	if err := checkID(id); err != nil {
+		return err
+	}
+	items, err := loadIndexedItems(vaultDir)
+	if err != nil {
+		return fmt.Errorf("loading issues: %w", err)
+	}
+	now, zone, err := vaultClock(vaultDir)
+	if err != nil {
+		return err
+	}
+	limit, err := vaultWIPLimit(vaultDir)
+	if err != nil {
+		return err
+	}
+	if err := list.Startable(items, id, now, limit); err != nil {
+		return err
+	}
+	if err := applyMutation(cmd, vaultDir, id, func(i issue.Issue) issue.Issue {
+		return i.Start(zone.Format(now))
+	}); err != nil {
+		return fmt.Errorf("starting issue: %w", err)
+	}
+	return nil
+}
+
+// checkStartable holds putting the Issues ids in_progress other than by
+// mt start — mt status, create --status — to the same rules: available,
+// and within the vault's wip_limit (see list.StartableAll).
+func checkStartable(vaultDir string, items []list.Item, ids []string) error {
+	now, _, err := vaultClock(vaultDir)
+	if err != nil {
+		return err
+	}
+	limit, err := vaultWIPLimit(vaultDir)
+	if err != nil {
+		return err
+	}
+	return list.StartableAll(items, ids, now, limit)
+}
+
+// newPauseCmd builds `mt pause <id>`: puts an in_progress Issue back to
+// open, keeping its started_at and closing its work_log interval.
+func newPauseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pause <id>",
+		Short: "Put an in_progress Issue back to open (keep started_at)",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return exitcode.Usage(fmt.Errorf("pause needs exactly one issue ID"))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vaultDir, err := resolveVault(cmd)
+			if err != nil {
+				return err
+			}
+			id, err := resolveIssueArg(vaultDir, args[0])
+			if err != nil {
+				return err
+			}
+			if err := checkID(id); err != nil {
+				return err
+			}
+			i, err := readIssue(vaultDir, id)
+			if err != nil {
+				return err
+			}
+			if s := i.Frontmatter.Status; s != "in_progress" {
+				return fmt.Errorf("issue %s is %s, not in_progress", id, s)
+			}
+			now, zone, err := vaultClock(vaultDir)
+			if err != nil {
+				return err
+			}
+			if err := applyMutation(cmd, vaultDir, id, func(i issue.Issue) issue.Issue {
+				return i.Pause(zone.Format(now))
+			}); err != nil {
+				return fmt.Errorf("pausing issue: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+const startLong = `start starts the given Issue: it becomes in_progress, receives a
+started_at timestamp — or keeps the one it has, when it was paused — and
+opens an interval in its work_log.
+
+The Issue must be available, as for pick-next: open, not deferred into
+the future and not blocked by a non-done Issue; otherwise start says why
+and fails. With wip_limit: N in mt.yaml, start refuses while N Issues are
+in_progress. mt pause <id> puts an Issue back to open, keeping its
+started_at and closing the interval, so the work_log accumulates every
+start and pause ("2026-08-15T09:00/2026-08-15T11:30, ..."); mt done
+closes the last interval and mt reopen clears them all.`
+
+// runMutation is the body of reopen: resolve the vault, then apply the
+// mutation to the Issue. resolveVault's errors already name the failing
+// step, so they propagate unwrapped.
 func runMutation(cmd *cobra.Command, id string, mutate func(issue.Issue) issue.Issue) error {
 	vaultDir, err := resolveVault(cmd)
 	if err != nil {
 		return err
 	}
+	id, err = resolveIssueArg(vaultDir, id)
+	if err != nil {
+		return err
+	}
 	if err := applyMutation(cmd, vaultDir, id, mutate); err != nil {
 		return fmt.Errorf("mutating issue: %w", err)
 	}
@@ -107,7 +288,7 @@ func runMutation(cmd *cobra.Command, id string, mutate func(issue.Issue) issue.I
 
 // applyMutation applies and persists a mutation, then prints the new
 // status — with the Issue's title when it has one. It is the shared
-// tail of done, reopen, status and pick-next.
+// tail of reopen, start, pause and pick-next.
 func applyMutation(cmd *cobra.Command, vaultDir, id string, mutate func(issue.Issue) issue.Issue) error {
 	i, err := mutateIssue(vaultDir, id, mutate)
 	if err != nil {
@@ -117,6 +298,12 @@ func applyMutation(cmd *cobra.Command, vaultDir, id string, mutate func(issue.Is
 	return nil
 }
 
+// reportTransition prints the transition confirmation of one applied
+// batch step.
+func reportTransition(out io.Writer, step batchStep) {
+	fmt.Fprintln(out, transitionLine(step.ID, step.Issue))
+}
+
 // transitionLine renders the transition confirmation for id: "id is now
 // status", plus ": title" when the Issue has a title. Whitespace runs in
 // the title become single spaces so the confirmation stays a single line,
@@ -164,15 +351,41 @@ func readIssue(vaultDir, id string) (issue.Issue, error) {
 	return i, nil
 }
 
-// writeIssueFile renders i and writes it back to its file in the vault.
-// It is the shared render-and-persist tail of the mutating commands; the
+// writeIssueFile writes i back to its file in the vault as a patch of
+// the file's current text, so only the frontmatter keys that changed are
+// rewritten and the user's comments and formatting survive. It is the
+// shared render-and-persist tail of the mutating commands; the
 // O_NOFOLLOW flag prevents a symlink from redirecting the write outside the
-// Vault. The confirmation line is the caller's concern.
+// Vault. The change goes through the pre-hooks first, and the write is
+// recorded for the undo journal. The confirmation line is the caller's
+// concern.
 func writeIssueFile(vaultDir, id string, i issue.Issue) error {
-	data, err := issue.Render(i)
+	return persistIssue(vaultDir, id, i, issue.Patch)
+}
+
+// rewriteIssueFile is writeIssueFile with the whole file rendered anew, in
+// the canonical form: for mt check --fix, whose repairs are the layout
+// itself.
+func rewriteIssueFile(vaultDir, id string, i issue.Issue) error {
+	return persistIssue(vaultDir, id, i, func(_ []byte, i issue.Issue) ([]byte, error) {
+		return issue.Render(i)
+	})
+}
+
+// persistIssue writes i to its file, rendered from the file's current
+// bytes by render.
+func persistIssue(vaultDir, id string, i issue.Issue, render func([]byte, issue.Issue) ([]byte, error)) error {
+	before, err := readIssueData(vaultDir, id)
+	if err != nil {
+		return fmt.Errorf("writing issue %s: %w", id, err)
+	}
+	data, err := render(before, i)
 	if err != nil {
 		return err
 	}
+	if err := vetWrite(vaultDir, id, before, i); err != nil {
+		return err
+	}
 	f, err := openIssueFile(vaultDir, id, os.O_WRONLY|os.O_TRUNC)
 	if err != nil {
 		return fmt.Errorf("writing issue %s: %w", id, err)
@@ -185,5 +398,31 @@ func writeIssueFile(vaultDir, id string, i issue.Issue) error {
 	if closeErr != nil {
 		return fmt.Errorf("closing issue %s: %w", id, closeErr)
 	}
+	recordChange(vaultDir, id, before, data)
+	return nil
+}
+
+// createIssueFile writes the new Issue i to the file id, which must not
+// exist yet: O_EXCL refuses to overwrite one, O_NOFOLLOW to follow a
+// symlink. The write is recorded for the undo journal; the pre-hooks are
+// the caller's, before anything of its command is written.
+func createIssueFile(vaultDir, id string, i issue.Issue) error {
+	data, err := issue.Render(i)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(issuePath(vaultDir, id), os.O_WRONLY|os.O_CREATE|os.O_EXCL|issueOpenNoFollow, 0o644)
+	if err != nil {
+		return fmt.Errorf("writing issue %s: %w", id, err)
+	}
+	_, writeErr := f.Write(data)
+	closeErr := f.Close()
+	if writeErr != nil {
+		return fmt.Errorf("writing issue %s: %w", id, writeErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("closing issue %s: %w", id, closeErr)
+	}
+	recordChange(vaultDir, id, nil, data)
 	return nil
 }
@@ -0,0 +1,83 @@
+// Package cli — Issue templates. mt create --template starts a new Issue
+// from a file in the vault's templates/ directory, and mt check
+// validates every template. Parsing, placeholders and the
+// frontmatter rules live in internal/template.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Sanmoo/my-tasks2/internal/check"
+	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/template"
+)
+
+// loadTemplate reads and parses the template name of vaultDir. A name
+// that cannot be a file name is a usage error; a missing template is a
+// user error naming the available ones.
+func loadTemplate(vaultDir, name string) (template.Template, error) {
+	if !template.ValidName(name) {
+		return template.Template{}, exitcode.Usage(fmt.Errorf("invalid template name %q", name))
+	}
+	data, err := os.ReadFile(template.Path(vaultDir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		names, _ := templateNames(vaultDir)
+		if len(names) == 0 {
+			return template.Template{}, fmt.Errorf("template %q not found: the vault has no templates (add %s/%s.md)", name, template.DirName, name)
+		}
+		return template.Template{}, fmt.Errorf("template %q not found (available: %s)", name, strings.Join(names, ", "))
+	}
+	if err != nil {
+		return template.Template{}, fmt.Errorf("reading template %s: %w", name, err)
+	}
+	return template.Parse(name, data)
+}
+
+// templateNames lists the templates of vaultDir, sorted. A vault without
+// a templates/ directory simply has none.
+func templateNames(vaultDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(vaultDir, template.DirName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading templates directory: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".md"); ok && e.Type().IsRegular() {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// templateFindings parses every template of vaultDir and checks that
+// their blocked_by references name existing Issues, returning one finding
+// per broken template.
+func templateFindings(vaultDir string, ids []string) ([]check.Finding, error) {
+	names, err := templateNames(vaultDir)
+	if err != nil {
+		return nil, err
+	}
+	var findings []check.Finding
+	for _, name := range names {
+		tpl, err := loadTemplate(vaultDir, name)
+		if err != nil {
+			findings = append(findings, check.Finding{Template: name, Rule: check.RuleInvalidTemplate,
+				Severity: check.Error, Message: err.Error()})
+			continue
+		}
+		if missing := tpl.MissingRefs(ids); len(missing) > 0 {
+			findings = append(findings, check.Finding{Template: name, Field: "blocked_by", Rule: check.RuleTemplateBlocker,
+				Severity: check.Error, Message: fmt.Sprintf("template %s: blocked_by references unknown issue %s", name, strings.Join(missing, ", "))})
+		}
+	}
+	return findings, nil
+}
@@ -6,67 +6,57 @@ package cli
 
 import (
 	"fmt"
-	"time"
+	"io"
 
 	"github.com/spf13/cobra"
 
-	"github.com/Sanmoo/my-tasks2/internal/exitcode"
 	"github.com/Sanmoo/my-tasks2/internal/issue"
 	"github.com/Sanmoo/my-tasks2/internal/list"
 )
 
-// newUndeferCmd builds `mt undefer [id]`: without an ID, it clears
-// deferred_until from every expired deferral in the vault (the
-// "archive the reminder" step of the daily overdue flow); with an ID,
-// it clears that one Issue, even a still-future deferral — the user
-// changed their mind. Only the deferred_until field is touched: Status
-// and Rank stay exactly as they are.
+// newUndeferCmd builds `mt undefer [id...]`: without an ID, it clears
+// deferred_until from every expired deferral in the vault (the "archive
+// the reminder" step of the daily overdue flow); with IDs ("-" for IDs on
+// stdin) or a --where query, it clears those Issues, even a still-future
+// deferral — the user changed their mind. Only the deferred_until field
+// is touched: Status and Rank stay exactly as they are.
 func newUndeferCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "undefer [id]",
-		Short: "Clear deferred_until (all expired deferrals, or one Issue)",
+	var flags batchFlags
+	cmd := &cobra.Command{
+		Use:   "undefer [id...]",
+		Short: "Clear deferred_until (all expired deferrals, or the given Issues)",
 		Long:  undeferLong,
-		Args: func(_ *cobra.Command, args []string) error {
-			if len(args) > 1 {
-				return exitcode.Usage(fmt.Errorf("undefer takes at most one issue ID"))
-			}
-			return nil
-		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 1 {
-				return runUndeferOne(cmd, args[0])
+			if len(args) > 0 || flags.where != "" {
+				return runUndeferSome(cmd, args, flags)
 			}
 			return runUndeferAll(cmd)
 		},
 	}
+	flags.register(cmd)
+	return cmd
 }
 
-// runUndeferOne clears deferred_until from one specific Issue, even
-// when the deferral is still in the future. An Issue without the field
-// is a user error (exit 1): the target is wrong.
-func runUndeferOne(cmd *cobra.Command, id string) error {
+// runUndeferSome clears deferred_until from specific Issues, even when
+// the deferral is still in the future. A target without the field is a
+// user error (exit 1) that aborts the whole batch: the target is wrong.
+func runUndeferSome(cmd *cobra.Command, args []string, flags batchFlags) error {
 	vaultDir, err := resolveVault(cmd)
 	if err != nil {
 		return err
 	}
-	if err := checkID(id); err != nil {
-		return err
-	}
-	i, err := readIssue(vaultDir, id)
+	targets, fromStdin, err := selectTargets(cmd, vaultDir, args, flags.where)
 	if err != nil {
 		return err
 	}
-	if i.Frontmatter.DeferredUntil == "" {
-		return fmt.Errorf("issue %s has no deferred_until to undefer", id)
-	}
-	was := i.Frontmatter.DeferredUntil
-	if _, err := mutateIssue(vaultDir, id, func(i issue.Issue) issue.Issue {
-		return i.Undefer()
-	}); err != nil {
-		return err
-	}
-	fmt.Fprintf(cmd.OutOrStdout(), "Undeferred %s (was %s)\n", id, was)
-	return nil
+	return runBatch(cmd, vaultDir, "undefer", targets, fromStdin, flags, func(id string, i issue.Issue) (issue.Issue, error) {
+		if i.Frontmatter.DeferredUntil == "" {
+			return issue.Issue{}, fmt.Errorf("issue %s has no deferred_until to undefer", id)
+		}
+		return i.Undefer(), nil
+	}, func(out io.Writer, step batchStep) {
+		fmt.Fprintf(out, "Undeferred %s (was %s)\n", step.ID, step.Before.Frontmatter.DeferredUntil)
+	})
 }
 
 // runUndeferAll sweeps the vault's Issues in their priority order,
@@ -84,7 +74,10 @@ func runUndeferAll(cmd *cobra.Command) error {
 	if err != nil {
 		return err
 	}
-	now := time.Now()
+	now, _, err := vaultClock(vaultDir)
+	if err != nil {
+		return err
+	}
 	out := cmd.OutOrStdout()
 	for _, it := range items {
 		until := it.Issue.Frontmatter.DeferredUntil
@@ -104,7 +97,9 @@ func runUndeferAll(cmd *cobra.Command) error {
 const undeferLong = `undefer clears an Issue's deferred_until. Without an
 ID, it sweeps the vault: every expired deferral is cleared, printing one
 "Undeferred <id> (was <datetime>)" line per Issue — with nothing
-expired, it prints nothing and succeeds. With an ID, it clears that one
-Issue even when its deferral is still in the future (you changed your
-mind). Only the deferred_until field is touched: Status and Rank stay as
-they are, and availability (mt ready, mt pick-next) is unaffected.`
+expired, it prints nothing and succeeds. With IDs (- reads them from
+stdin) or --where, it clears those Issues even when their deferral is
+still in the future (you changed your mind); a target without
+deferred_until fails the whole batch before anything is written. Only
+the deferred_until field is touched: Status and Rank stay as they are,
+and availability (mt ready, mt pick-next) is unaffected.`
@@ -1,6 +1,9 @@
-// Package deferral holds the pure logic of `mt defer`: parsing the time
-// argument — an absolute "YY-MM-DD HH:MM" or a relative "+<n><unit>" —
-// into the canonical stored deferred_until value (issue.NaiveLayout). It
+// Package deferral holds the pure logic of the time expressions mt takes
+// for defer, --deadline and --defer: an absolute "YY-MM-DD HH:MM", a
+// relative "+<n><unit>", a time of day, or a named day (tomorrow, fri,
+// next friday, end of month, amanhã, segunda) — all resolved against an
+// injected now into the canonical stored value of the vault's timezone
+// setting (issue.Zone). It
 // is decision-dense, so it lives at Seam 2: black-box unit tested, with
 // the coverage and mutation gates.
 package deferral
@@ -14,96 +17,310 @@ import (
 	"github.com/Sanmoo/my-tasks2/internal/issue"
 )
 
-// absoluteLayout is the input layout of `mt defer`'s absolute form: a
-// two-digit year with zero-padded month, day, hour and minute.
-const absoluteLayout = "06-01-02 15:04"
+// dateLayout is the day of the absolute form: a two-digit year with
+// zero-padded month and day. Its time of day follows as a separate word.
+const dateLayout = "06-01-02"
+
+// clockLayout is a time of day: the hour (one or two digits) and the
+// zero-padded minute.
+const clockLayout = "15:04"
+
+// DefaultTime is the time of day of an expression that names a day but
+// no time, when the vault sets none.
+const DefaultTime = "09:00"
 
 // maxDuration is the largest duration time.Time.Add can represent without
 // overflowing its nanosecond count. Relative counts are checked against it
 // before multiplying by their unit duration.
 const maxDuration = time.Duration(1<<63 - 1)
 
-// Parse converts a `mt defer` time argument to the canonical
-// deferred_until value (issue.NaiveLayout, YYYY-MM-DDTHH:MM naive local
-// time). It accepts:
+// day is the length of the d unit; calendar arithmetic (months, business
+// days) is bounded by the same day count as +<n>d.
+const day = 24 * time.Hour
+
+// maxYear is the last year the stored form can hold.
+const maxYear = 9999
+
+// Options are the vault settings a time expression depends on.
+type Options struct {
+	// DefaultTime is the time of day, H:MM or HH:MM, given to an
+	// expression that names a day but no time (tomorrow, fri, 26-08-20);
+	// empty means the package DefaultTime.
+	DefaultTime string
+	// Zone is the vault's timezone setting: the result is written in its
+	// layout. The calendar is that of now's location, so the caller
+	// passes now in the zone (issue.Zone.In).
+	Zone issue.Zone
+}
+
+// Parse converts a time expression to the canonical stored value
+// (issue.NaiveLayout, YYYY-MM-DDTHH:MM naive local time, or the layout
+// of opts.Zone). It accepts:
 //
 //   - an absolute "YY-MM-DD HH:MM"; the two-digit year is expanded to
 //     20YY, so any year this century is reachable and the hour is kept;
-//   - a relative "+<n><unit>" computed from now, where unit is d (days),
-//     w (weeks) or h (hours) and n is a positive integer.
+//     without the time, the day at the default time;
+//   - a time of day "HH:MM": today at that time, or tomorrow when it has
+//     already passed;
+//   - a named day with an optional "HH:MM" (the default time without
+//     one): today/hoje, tomorrow/amanhã, a weekday in English or
+//     Portuguese (mon, friday, seg, sexta-feira, …) — the next one after
+//     today, also spelled "next friday" or "próxima sexta" — and
+//     "end of month"/"fim do mês", the last day of this month;
+//   - a relative "+<n><unit>" computed from now, where n is a positive
+//     integer and unit is m (minutes), h (hours), d (days), w (weeks),
+//     bd (business days, skipping weekends) or mo (calendar months, the
+//     day clamped to the month's last).
 //
-// now is used only for relative forms. Anything else returns an error.
-func Parse(s string, now time.Time) (string, error) {
+// Words are case-insensitive. now is the clock every relative form and
+// named day resolves against. Anything else returns an error.
+func Parse(s string, now time.Time, opts Options) (string, error) {
 	if strings.HasPrefix(s, "+") {
-		return parseRelative(s, now)
+		t, err := parseRelative(s, now)
+		if err != nil {
+			return "", err
+		}
+		return opts.Zone.Format(t), nil
+	}
+	clock := opts.DefaultTime
+	if clock == "" {
+		clock = DefaultTime
+	}
+	hour, minute, err := ParseTimeOfDay(clock)
+	if err != nil {
+		return "", fmt.Errorf("invalid default time: %w", err)
+	}
+	t, ok := parseExpression(strings.Fields(strings.ToLower(s)), now, hour, minute)
+	if !ok {
+		return "", fmt.Errorf("invalid time %q: want YY-MM-DD HH:MM (e.g. 26-08-20 08:00, the time optional), HH:MM, "+
+			"a day (tomorrow 9:00, mon, next friday, end of month, amanhã, segunda) "+
+			"or a relative duration (+30m, +3h, +2d, +3bd, +1w, +2mo)", s)
+	}
+	return opts.Zone.Format(t), nil
+}
+
+// ParseTimeOfDay parses an "H:MM" or "HH:MM" time of day (00:00 to
+// 23:59) into its hour and minute.
+func ParseTimeOfDay(s string) (hour, minute int, err error) {
+	t, err := time.Parse(clockLayout, s)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time of day %q: want HH:MM (e.g. 09:00)", s)
 	}
-	return parseAbsolute(s)
+	return t.Hour(), t.Minute(), nil
 }
 
-// parseAbsolute parses "YY-MM-DD HH:MM" into the canonical stored form.
-// time.Parse validates the ranges (month, day, hour, minute); the
-// century is then forced to 20YY, because Go's "06" maps years 69-99
-// into the 1900s — useless for a defer target, which is always in this
-// century.
-func parseAbsolute(s string) (string, error) {
-	t, err := time.ParseInLocation(absoluteLayout, s, time.Local)
+// ParseSpan parses a warning span "<n><unit>" — n a positive integer,
+// unit m (minutes), h (hours), d (days) or w (weeks) — into its duration:
+// the lead time of warn_before and mt due --within.
+func ParseSpan(s string) (time.Duration, error) {
+	digits := 0
+	for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+		digits++
+	}
+	n, err := strconv.Atoi(s[:digits])
+	per, ok := unitDuration(s[digits:])
+	if err != nil || n <= 0 || !ok || time.Duration(n) > maxDuration/per {
+		return 0, fmt.Errorf("invalid span %q: want <n><unit> with a positive count and unit m, h, d or w (e.g. 3d)", s)
+	}
+	return time.Duration(n) * per, nil
+}
+
+// Recur returns t advanced by one period of the recurrence every: the
+// relative form without its "+" — "<n><unit>" with the units of
+// "+<n><unit>", calendar months and business days included (e.g. 1w,
+// 2mo, 1bd). It dates the next occurrence of a recurring Issue.
+func Recur(every string, t time.Time) (time.Time, error) {
+	next, err := parseRelative("+"+every, t)
 	if err != nil {
-		return "", fmt.Errorf("invalid defer time %q: want YY-MM-DD HH:MM (e.g. 26-08-20 08:00) or a relative duration (+2d, +1w, +3h)", s)
+		return time.Time{}, fmt.Errorf("invalid recurrence %q: want <n><unit> with a positive count and unit m, h, d, w, bd or mo (e.g. 1w)", every)
+	}
+	return next, nil
+}
+
+// parseExpression resolves the lowercase words of an absolute, clock or
+// named-day expression; hour and minute are the default time of day.
+func parseExpression(words []string, now time.Time, hour, minute int) (time.Time, bool) {
+	if len(words) == 0 {
+		return time.Time{}, false
 	}
-	if t.Year() < 2000 {
-		t = t.AddDate(100, 0, 0)
+	h, m, err := ParseTimeOfDay(words[len(words)-1])
+	explicit := err == nil
+	if explicit {
+		words = words[:len(words)-1]
+		hour, minute = h, m
+	}
+	if explicit && len(words) == 0 {
+		t := at(now, 0, hour, minute)
+		if !t.After(now) {
+			t = at(now, 1, hour, minute)
+		}
+		return t, true
 	}
-	return t.Format(issue.NaiveLayout), nil
+	date, ok := parseDay(words, now)
+	if !ok {
+		return time.Time{}, false
+	}
+	return at(date, 0, hour, minute), true
 }
 
-// parseRelative parses "+<n><unit>" (n positive, unit d/w/h) and returns
-// now plus that duration, formatted to the canonical stored form.
-func parseRelative(s string, now time.Time) (string, error) {
-	body := s[1:] // drop the "+" (Parse guarantees the prefix)
-	if len(body) < 2 {
-		return "", invalidDuration(s)
-	}
-	unit := body[len(body)-1]
-	numStr := body[:len(body)-1]
-	// The count must be a plain positive integer: reject signs and stray
-	// characters that Atoi would silently accept (e.g. "+2", "-2").
-	for i := 0; i < len(numStr); i++ {
-		if numStr[i] < '0' || numStr[i] > '9' {
-			return "", invalidDuration(s)
+// parseDay resolves the words naming a day: an absolute YY-MM-DD or one
+// of the named days. The time of day of the result is irrelevant.
+func parseDay(words []string, now time.Time) (time.Time, bool) {
+	phrase := strings.Join(words, " ")
+	if t, err := time.ParseInLocation(dateLayout, phrase, now.Location()); err == nil {
+		if t.Year() < 2000 {
+			// Go's "06" maps 69-99 into the 1900s; a target is always
+			// in this century.
+			t = t.AddDate(100, 0, 0)
 		}
+		return t, true
+	}
+	switch phrase {
+	case "today", "hoje":
+		return now, true
+	case "tomorrow", "amanhã", "amanha":
+		return at(now, 1, 0, 0), true
+	case "end of month", "fim do mês", "fim do mes":
+		// Day 0 of the next month is the last day of this one.
+		return time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location()), true
+	}
+	if len(words) == 2 && nextWords[words[0]] {
+		words = words[1:]
+	}
+	if len(words) != 1 {
+		return time.Time{}, false
+	}
+	wd, ok := weekdays[words[0]]
+	if !ok {
+		return time.Time{}, false
+	}
+	ahead := (int(wd) - int(now.Weekday()) + 7) % 7
+	if ahead == 0 {
+		ahead = 7
+	}
+	return at(now, ahead, 0, 0), true
+}
+
+// at returns the day of t days later, at hour:minute.
+func at(t time.Time, days, hour, minute int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+days, hour, minute, 0, 0, t.Location())
+}
+
+// nextWords are the words that may precede a weekday without changing
+// it: "next friday" and "fri" are the same day.
+var nextWords = map[string]bool{
+	"next": true, "próxima": true, "proxima": true, "próximo": true, "proximo": true,
+}
+
+// weekdays maps the English and Portuguese weekday names, full and
+// abbreviated, to their day.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday, "dom": time.Sunday, "domingo": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"seg": time.Monday, "segunda": time.Monday, "segunda-feira": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"ter": time.Tuesday, "terça": time.Tuesday, "terca": time.Tuesday, "terça-feira": time.Tuesday, "terca-feira": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"qua": time.Wednesday, "quarta": time.Wednesday, "quarta-feira": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"qui": time.Thursday, "quinta": time.Thursday, "quinta-feira": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sex": time.Friday, "sexta": time.Friday, "sexta-feira": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+	"sáb": time.Saturday, "sab": time.Saturday, "sábado": time.Saturday, "sabado": time.Saturday,
+}
+
+// parseRelative parses "+<n><unit>" (n positive) and returns now plus
+// that span.
+func parseRelative(s string, now time.Time) (time.Time, error) {
+	body := s[1:] // drop the "+" (Parse guarantees the prefix)
+	// The count must be a plain positive integer: signs and stray
+	// characters end it, leaving an empty count or an unknown unit.
+	digits := 0
+	for digits < len(body) && body[digits] >= '0' && body[digits] <= '9' {
+		digits++
+	}
+	numStr, unit := body[:digits], body[digits:]
+	if numStr == "" || unit == "" {
+		return time.Time{}, invalidDuration(s)
 	}
 	n, err := strconv.Atoi(numStr)
 	if err != nil || n <= 0 {
-		return "", invalidDuration(s)
+		return time.Time{}, invalidDuration(s)
+	}
+	switch unit {
+	case "bd":
+		if n/5*7+7 > int(maxDuration/day) {
+			return time.Time{}, invalidDuration(s)
+		}
+		return addBusinessDays(now, n), nil
+	case "mo":
+		if n > (maxYear-now.Year())*12 {
+			return time.Time{}, invalidDuration(s)
+		}
+		return addMonths(now, n), nil
 	}
 	per, ok := unitDuration(unit)
 	if !ok {
-		return "", fmt.Errorf("invalid defer duration %q: unit must be d (days), w (weeks) or h (hours)", s)
+		return time.Time{}, fmt.Errorf("invalid duration %q: unit must be m (minutes), h (hours), d (days), w (weeks), bd (business days) or mo (months)", s)
 	}
 	count := time.Duration(n)
 	if count > maxDuration/per {
-		return "", invalidDuration(s)
+		return time.Time{}, invalidDuration(s)
 	}
-	return now.Add(count * per).Format(issue.NaiveLayout), nil
+	return now.Add(count * per), nil
 }
 
 // invalidDuration renders the shared error for a malformed relative
 // duration.
 func invalidDuration(s string) error {
-	return fmt.Errorf("invalid defer duration %q: want +<n><unit> with a positive count and unit d (days), w (weeks) or h (hours) (e.g. +2d)", s)
+	return fmt.Errorf("invalid duration %q: want +<n><unit> with a positive count and unit m, h, d, w, bd or mo (e.g. +2d)", s)
 }
 
-// unitDuration returns the duration of one unit of the relative form,
-// and whether u is a known unit (d = days, w = weeks, h = hours).
-func unitDuration(u byte) (time.Duration, bool) {
+// unitDuration returns the duration of one fixed-length unit of the
+// relative form, and whether u is one (m = minutes, h = hours, d = days,
+// w = weeks).
+func unitDuration(u string) (time.Duration, bool) {
 	switch u {
-	case 'd':
-		return 24 * time.Hour, true
-	case 'w':
-		return 7 * 24 * time.Hour, true
-	case 'h':
+	case "m":
+		return time.Minute, true
+	case "h":
 		return time.Hour, true
+	case "d":
+		return day, true
+	case "w":
+		return 7 * day, true
 	default:
 		return 0, false
 	}
 }
+
+// addBusinessDays returns t plus n weekdays, at the same time of day. A
+// weekend start counts from the Friday before, so +1bd from a Saturday is
+// Monday and every whole week of five is seven calendar days.
+func addBusinessDays(t time.Time, n int) time.Time {
+	switch t.Weekday() {
+	case time.Saturday:
+		t = t.AddDate(0, 0, -1)
+	case time.Sunday:
+		t = t.AddDate(0, 0, -2)
+	}
+	t = t.AddDate(0, 0, n/5*7)
+	for left := n % 5; left > 0; {
+		t = t.AddDate(0, 0, 1)
+		if wd := t.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			left--
+		}
+	}
+	return t
+}
+
+// addMonths returns t plus n calendar months, at the same time of day.
+// The day is clamped to the target month's last, so a month after Jan 31
+// is Feb 28 (or 29), not the AddDate overflow into March.
+func addMonths(t time.Time, n int) time.Time {
+	months := int(t.Month()) - 1 + n
+	year, month := t.Year()+months/12, time.Month(months%12+1)
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	return time.Date(year, month, min(t.Day(), last), t.Hour(), t.Minute(), 0, 0, t.Location())
+}
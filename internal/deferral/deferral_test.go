@@ -1,7 +1,8 @@
-// Package deferral_test holds the black-box unit tests of the mt defer
-// datetime parsing (Seam 2): absolute YY-MM-DD HH:MM (the year expanded
-// to 20YY, the hour preserved), relative +<n><unit> durations computed
-// from now, and the error edges. Only the exported Parse is exercised.
+// Package deferral_test holds the black-box unit tests of the time
+// expressions (Seam 2): absolute YY-MM-DD HH:MM (the year expanded to
+// 20YY, the hour preserved), times of day, named days in English and
+// Portuguese, the default time, relative +<n><unit> spans computed from
+// now (fixed and calendar units), the offset layout, and the error edges.
 package deferral_test
 
 import (
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Sanmoo/my-tasks2/internal/deferral"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
 )
 
 // now is a fixed instant for the relative-form tests, in UTC so that
@@ -32,7 +34,7 @@ func TestParseAbsolute(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			got, err := deferral.Parse(c.in, now)
+			got, err := deferral.Parse(c.in, now, deferral.Options{})
 			if err != nil {
 				t.Fatalf("Parse(%q) error: %v", c.in, err)
 			}
@@ -63,7 +65,7 @@ func TestParseRelative(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			got, err := deferral.Parse(c.in, now)
+			got, err := deferral.Parse(c.in, now, deferral.Options{})
 			if err != nil {
 				t.Fatalf("Parse(%q) error: %v", c.in, err)
 			}
@@ -78,7 +80,7 @@ func TestParseRelativeTruncatesSubMinute(t *testing.T) {
 	// The stored value has minute granularity; seconds and nanos of now
 	// are dropped, not rounded.
 	withSeconds := time.Date(2026, 8, 15, 14, 30, 45, 123456789, time.UTC)
-	got, err := deferral.Parse("+3h", withSeconds)
+	got, err := deferral.Parse("+3h", withSeconds, deferral.Options{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -94,8 +96,13 @@ func TestParseRejectsMalformed(t *testing.T) {
 	}{
 		{"empty", ""},
 		{"garbage", "garbage"},
-		{"missing time", "26-08-20"},
-		{"missing date", "08:00"},
+		{"hour 24", "24:00"},
+		{"time before a day", "08:00 tomorrow"},
+		{"unknown day", "someday"},
+		{"next without a weekday", "next"},
+		{"next before a non-weekday", "next month"},
+		{"two weekdays", "mon tue"},
+		{"seconds", "26-08-20 08:00:00"},
 		{"four-digit year", "2026-08-20 08:00"},
 		{"single-digit month", "26-8-20 08:00"},
 		{"month 13", "26-13-20 08:00"},
@@ -108,6 +115,9 @@ func TestParseRejectsMalformed(t *testing.T) {
 		{"negative duration", "+-2d"},
 		{"unknown unit", "+2x"},
 		{"uppercase unit", "+2D"},
+		{"unit in words", "+2days"},
+		{"business days overflow", "+76250bd"},
+		{"months past year 9999", "+95677mo"},
 		{"missing unit", "+2"},
 		{"missing number", "+d"},
 		{"only plus", "+"},
@@ -118,7 +128,7 @@ func TestParseRejectsMalformed(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			if got, err := deferral.Parse(c.in, now); err == nil {
+			if got, err := deferral.Parse(c.in, now, deferral.Options{}); err == nil {
 				t.Errorf("Parse(%q) = %q, want an error", c.in, got)
 			}
 		})
@@ -126,10 +136,180 @@ func TestParseRejectsMalformed(t *testing.T) {
 }
 
 func TestParseErrorMessagesHintAtTheFormat(t *testing.T) {
-	if _, err := deferral.Parse("bogus", now); err == nil || !strings.Contains(err.Error(), "YY-MM-DD HH:MM") {
-		t.Errorf("absolute error = %v, want it to hint at YY-MM-DD HH:MM", err)
+	if _, err := deferral.Parse("bogus", now, deferral.Options{}); err == nil || !strings.Contains(err.Error(), "YY-MM-DD HH:MM") ||
+		!strings.Contains(err.Error(), "next friday") {
+		t.Errorf("absolute error = %v, want it to hint at YY-MM-DD HH:MM and the named days", err)
 	}
-	if _, err := deferral.Parse("+2x", now); err == nil || !strings.Contains(err.Error(), "d (days), w (weeks) or h (hours)") {
-		t.Errorf("relative error = %v, want it to hint at d/w/h", err)
+	if _, err := deferral.Parse("+2x", now, deferral.Options{}); err == nil ||
+		!strings.Contains(err.Error(), "m (minutes), h (hours), d (days), w (weeks), bd (business days) or mo (months)") {
+		t.Errorf("relative error = %v, want it to hint at the units", err)
+	}
+	if _, err := deferral.Parse("tomorrow", now, deferral.Options{DefaultTime: "9h"}); err == nil || !strings.Contains(err.Error(), "default time") {
+		t.Errorf("bad default time error = %v, want it to name the default time", err)
+	}
+}
+
+func TestParseNamedDaysAndTimes(t *testing.T) {
+	// now is Saturday 2026-08-15 14:30.
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"date without time gets the default", "26-08-20", "2026-08-20T09:00"},
+		{"single-digit hour", "26-08-20 8:00", "2026-08-20T08:00"},
+		{"time still ahead is today", "18:00", "2026-08-15T18:00"},
+		{"time already passed is tomorrow", "9:00", "2026-08-16T09:00"},
+		{"time equal to now is tomorrow", "14:30", "2026-08-16T14:30"},
+		{"today", "today", "2026-08-15T09:00"},
+		{"hoje with time", "hoje 20:00", "2026-08-15T20:00"},
+		{"tomorrow", "tomorrow", "2026-08-16T09:00"},
+		{"tomorrow with time", "tomorrow 9:00", "2026-08-16T09:00"},
+		{"amanhã capitalized", "Amanhã 7:15", "2026-08-16T07:15"},
+		{"amanha without accent", "amanha", "2026-08-16T09:00"},
+		{"mon", "mon", "2026-08-17T09:00"},
+		{"monday with time", "monday 14:00", "2026-08-17T14:00"},
+		{"segunda", "segunda", "2026-08-17T09:00"},
+		{"segunda-feira", "Segunda-Feira", "2026-08-17T09:00"},
+		{"ter", "ter", "2026-08-18T09:00"},
+		{"qua", "qua", "2026-08-19T09:00"},
+		{"qui", "qui", "2026-08-20T09:00"},
+		{"fri", "fri", "2026-08-21T09:00"},
+		{"today's weekday is a week ahead", "sat", "2026-08-22T09:00"},
+		{"sábado", "sábado", "2026-08-22T09:00"},
+		{"sun is tomorrow", "sun", "2026-08-16T09:00"},
+		{"next friday", "next friday", "2026-08-21T09:00"},
+		{"próxima sexta with time", "próxima sexta 18:00", "2026-08-21T18:00"},
+		{"proximo domingo", "proximo domingo", "2026-08-16T09:00"},
+		{"end of month", "end of month", "2026-08-31T09:00"},
+		{"fim do mês with time", "fim do mês 18:00", "2026-08-31T18:00"},
+		{"extra spaces", "  next   friday  ", "2026-08-21T09:00"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := deferral.Parse(c.in, now, deferral.Options{})
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", c.in, err)
+			}
+			if got != c.want {
+				t.Errorf("Parse(%q) = %q, want %q", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseDefaultTime(t *testing.T) {
+	opts := deferral.Options{DefaultTime: "7:30"}
+	for in, want := range map[string]string{
+		"tomorrow":       "2026-08-16T07:30",
+		"26-08-20":       "2026-08-20T07:30",
+		"tomorrow 18:00": "2026-08-16T18:00",
+		"+1h":            "2026-08-15T15:30",
+	} {
+		if got, err := deferral.Parse(in, now, opts); err != nil || got != want {
+			t.Errorf("Parse(%q, default 7:30) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+}
+
+func TestParseInOffsetZone(t *testing.T) {
+	zone, err := issue.LoadZone("offset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp, _ := time.LoadLocation("America/Sao_Paulo")
+	local := now.In(sp)
+	for in, want := range map[string]string{
+		"tomorrow":       "2026-08-16T09:00-03:00",
+		"26-08-20 18:00": "2026-08-20T18:00-03:00",
+		"+1h":            "2026-08-15T12:30-03:00",
+	} {
+		if got, err := deferral.Parse(in, local, deferral.Options{Zone: zone}); err != nil || got != want {
+			t.Errorf("Parse(%q, offset) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+}
+
+func TestParseCalendarUnits(t *testing.T) {
+	wednesday := time.Date(2026, 8, 19, 10, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name string
+		in   string
+		from time.Time
+		want string
+	}{
+		{"minutes", "+30m", now, "2026-08-15T15:00"},
+		{"minutes past the hour", "+90m", now, "2026-08-15T16:00"},
+		{"one business day from saturday", "+1bd", now, "2026-08-17T14:30"},
+		{"business days from saturday", "+3bd", now, "2026-08-19T14:30"},
+		{"a business week from saturday", "+5bd", now, "2026-08-21T14:30"},
+		{"business days within the week", "+2bd", wednesday, "2026-08-21T10:00"},
+		{"business days across a weekend", "+3bd", wednesday, "2026-08-24T10:00"},
+		{"business days past a whole week", "+7bd", wednesday, "2026-08-28T10:00"},
+		{"months", "+2mo", now, "2026-10-15T14:30"},
+		{"month across a year", "+1mo", time.Date(2026, 12, 10, 8, 0, 0, 0, time.UTC), "2027-01-10T08:00"},
+		{"month clamped to february", "+1mo", time.Date(2026, 1, 31, 10, 0, 0, 0, time.UTC), "2026-02-28T10:00"},
+		{"month clamped to a leap day", "+1mo", time.Date(2028, 1, 31, 10, 0, 0, 0, time.UTC), "2028-02-29T10:00"},
+		{"thirteen months", "+13mo", time.Date(2026, 1, 31, 10, 0, 0, 0, time.UTC), "2027-02-28T10:00"},
+		{"end of december", "end of month", time.Date(2026, 12, 10, 8, 0, 0, 0, time.UTC), "2026-12-31T09:00"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := deferral.Parse(c.in, c.from, deferral.Options{})
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", c.in, err)
+			}
+			if got != c.want {
+				t.Errorf("Parse(%q) = %q, want %q", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseSpan(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"30m": 30 * time.Minute,
+		"12h": 12 * time.Hour,
+		"3d":  72 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+	} {
+		if got, err := deferral.ParseSpan(in); err != nil || got != want {
+			t.Errorf("ParseSpan(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "d", "3", "0d", "+3d", "-3d", "3bd", "3mo", "3 d", "99999999999w"} {
+		if _, err := deferral.ParseSpan(in); err == nil || !strings.Contains(err.Error(), "invalid span") {
+			t.Errorf("ParseSpan(%q) = %v, want an invalid span error", in, err)
+		}
+	}
+}
+
+func TestRecur(t *testing.T) {
+	from := time.Date(2026, 1, 31, 18, 0, 0, 0, time.UTC) // a Saturday
+	for in, want := range map[string]time.Time{
+		"1d":  time.Date(2026, 2, 1, 18, 0, 0, 0, time.UTC),
+		"2w":  time.Date(2026, 2, 14, 18, 0, 0, 0, time.UTC),
+		"1mo": time.Date(2026, 2, 28, 18, 0, 0, 0, time.UTC),
+		"1bd": time.Date(2026, 2, 2, 18, 0, 0, 0, time.UTC),
+	} {
+		if got, err := deferral.Recur(in, from); err != nil || !got.Equal(want) {
+			t.Errorf("Recur(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "w", "0d", "+1w", "-1w", "1y"} {
+		if _, err := deferral.Recur(in, from); err == nil || !strings.Contains(err.Error(), "invalid recurrence") {
+			t.Errorf("Recur(%q) = %v, want an invalid recurrence error", in, err)
+		}
+	}
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	if h, m, err := deferral.ParseTimeOfDay("8:05"); err != nil || h != 8 || m != 5 {
+		t.Errorf("ParseTimeOfDay(8:05) = %d, %d, %v", h, m, err)
+	}
+	for _, in := range []string{"", "9", "9h", "24:00", "09:60", "09:5"} {
+		if _, _, err := deferral.ParseTimeOfDay(in); err == nil {
+			t.Errorf("ParseTimeOfDay(%q) succeeded, want an error", in)
+		}
 	}
 }
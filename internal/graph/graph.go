@@ -0,0 +1,292 @@
+// Package graph holds the pure logic of the blocked_by dependency graph:
+// the cycle mt check reports, the upstream and downstream trees of mt
+// dep tree, the impact ranking of mt dep impact and the Graphviz and
+// Mermaid renderings of mt graph. An edge runs from a blocker to the
+// Issue it blocks. It is decision-dense (traversals, cycles, dangling
+// references), so it lives at Seam 2: black-box unit tested, with the
+// coverage and mutation gates. Reading the Issues stays in internal/cli.
+package graph
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Node is one Issue of the graph: its ID, the title and status shown,
+// and the IDs of its blocked_by.
+type Node struct {
+	ID        string
+	Title     string
+	Status    string
+	BlockedBy []string
+}
+
+// Graph is the blocked_by graph of a Vault, both ways.
+type Graph struct {
+	nodes      []Node
+	byID       map[string]Node
+	dependents map[string][]string
+}
+
+// New builds the graph of nodes. The order of nodes is the order every
+// traversal visits them in, and that of each node's dependents.
+func New(nodes []Node) *Graph {
+	g := &Graph{nodes: nodes, byID: make(map[string]Node, len(nodes)), dependents: make(map[string][]string)}
+	for _, n := range nodes {
+		g.byID[n.ID] = n
+	}
+	for _, n := range nodes {
+		for _, ref := range n.BlockedBy {
+			if !slices.Contains(g.dependents[ref], n.ID) {
+				g.dependents[ref] = append(g.dependents[ref], n.ID)
+			}
+		}
+	}
+	return g
+}
+
+// Node returns the node id, ok false when no Issue has that ID (a
+// dangling reference).
+func (g *Graph) Node(id string) (Node, bool) {
+	n, ok := g.byID[id]
+	return n, ok
+}
+
+// Cycle returns one cycle of the graph as an ordered path (start -> ...
+// -> start), or nil when the graph is acyclic. The walk is deterministic:
+// nodes are visited in order and each node's references in listed order,
+// so a given Vault always yields the same cycle. Dangling references end
+// the walk.
+func (g *Graph) Cycle() []string {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int, len(g.nodes))
+	var stack []string
+	// visit walks one node of the graph; it returns the first cycle
+	// reachable from id (as a path ending where it started), or nil.
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		state[id] = inProgress
+		stack = append(stack, id)
+		for _, ref := range g.byID[id].BlockedBy {
+			switch state[ref] {
+			case inProgress:
+				// ref is on the current path: slice it out, close the
+				// loop, and report the cycle.
+				i := slices.Index(stack, ref)
+				return append(slices.Clone(stack[i:]), ref)
+			case unvisited:
+				if cycle := visit(ref); len(cycle) > 0 {
+					return cycle
+				}
+			}
+		}
+		state[id] = done
+		stack = stack[:len(stack)-1]
+		return nil
+	}
+	for _, n := range g.nodes {
+		if state[n.ID] == unvisited {
+			if cycle := visit(n.ID); len(cycle) > 0 {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// Tree is a node with the nodes one step further along a traversal.
+// Missing marks a dangling reference; Cycle a node already on the path
+// from the root, whose branch is cut there.
+type Tree struct {
+	Node     Node
+	Missing  bool
+	Cycle    bool
+	Children []Tree
+}
+
+// Upstream returns the tree of what blocks id: its blockers, theirs, and
+// so on, in blocked_by order.
+func (g *Graph) Upstream(id string) Tree {
+	return g.tree(id, func(n Node) []string { return n.BlockedBy }, nil)
+}
+
+// Downstream returns the tree of what id blocks: its dependents, theirs,
+// and so on, in node order.
+func (g *Graph) Downstream(id string) Tree {
+	return g.tree(id, func(n Node) []string { return g.dependents[n.ID] }, nil)
+}
+
+// tree expands id along next, cutting every branch that comes back to a
+// node on its own path.
+func (g *Graph) tree(id string, next func(Node) []string, path []string) Tree {
+	n, ok := g.byID[id]
+	if !ok {
+		return Tree{Node: Node{ID: id}, Missing: true}
+	}
+	t := Tree{Node: n}
+	if slices.Contains(path, id) {
+		t.Cycle = true
+		return t
+	}
+	path = append(path, id)
+	for _, ref := range next(n) {
+		t.Children = append(t.Children, g.tree(ref, next, path))
+	}
+	return t
+}
+
+// Impact is how many Issues one Issue holds up.
+type Impact struct {
+	Node  Node
+	Count int
+}
+
+// Impacts ranks the Issues that are not done by how many others they
+// transitively unblock: the Issues not done that wait on them, directly
+// or through others that are not done either — a done Issue blocks
+// nothing. Issues that unblock none are left out; the ranking is by
+// Count, highest first, then in node order.
+func (g *Graph) Impacts() []Impact {
+	var out []Impact
+	for _, n := range g.nodes {
+		if n.Status == "done" {
+			continue
+		}
+		seen := map[string]bool{n.ID: true}
+		queue := []string{n.ID}
+		count := 0
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, dep := range g.dependents[id] {
+				if seen[dep] || g.byID[dep].Status == "done" {
+					continue
+				}
+				seen[dep] = true
+				count++
+				queue = append(queue, dep)
+			}
+		}
+		if count > 0 {
+			out = append(out, Impact{Node: n, Count: count})
+		}
+	}
+	slices.SortStableFunc(out, func(a, b Impact) int { return cmp.Compare(b.Count, a.Count) })
+	return out
+}
+
+// exported returns the nodes a rendering of the whole graph shows: every
+// Issue that is not done, the done Issues still listed as a blocker, and
+// a missing node per dangling reference, in node order.
+func (g *Graph) exported() (nodes []Node, missing []string) {
+	for _, n := range g.nodes {
+		if n.Status != "done" || len(g.dependents[n.ID]) > 0 {
+			nodes = append(nodes, n)
+		}
+		for _, ref := range n.BlockedBy {
+			if _, ok := g.byID[ref]; !ok && !slices.Contains(missing, ref) {
+				missing = append(missing, ref)
+			}
+		}
+	}
+	return nodes, missing
+}
+
+// edges returns every blocker -> blocked pair of nodes, in node order.
+func edges(nodes []Node) [][2]string {
+	var out [][2]string
+	for _, n := range nodes {
+		for _, ref := range n.BlockedBy {
+			out = append(out, [2]string{ref, n.ID})
+		}
+	}
+	return out
+}
+
+// DOT renders the graph in the Graphviz DOT language: one box per Issue
+// labeled with its ID, title and status (dashed when done, red and
+// marked missing for a dangling reference) and an arrow from each
+// blocker to the Issue it blocks.
+func (g *Graph) DOT() string {
+	nodes, missing := g.exported()
+	var b strings.Builder
+	b.WriteString("digraph mt {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, n := range nodes {
+		style := ""
+		if n.Status == "done" {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %s [label=%s%s];\n", dotQuote(n.ID), dotQuote(n.ID+"\n"+n.Title+"\n("+n.Status+")"), style)
+	}
+	for _, id := range missing {
+		fmt.Fprintf(&b, "  %s [label=%s, color=red];\n", dotQuote(id), dotQuote(id+"\n(missing)"))
+	}
+	for _, e := range edges(nodes) {
+		fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(e[0]), dotQuote(e[1]))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote renders s as a DOT quoted string.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// Mermaid renders the graph as a Mermaid flowchart: the same nodes and
+// arrows as DOT, done Issues in the done class and dangling references
+// in the missing class.
+func (g *Graph) Mermaid() string {
+	nodes, missing := g.exported()
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, n := range nodes {
+		fmt.Fprintf(&b, "  %s[\"%s<br>%s<br>(%s)\"]\n", mermaidID(n.ID), mermaidText(n.ID), mermaidText(n.Title), mermaidText(n.Status))
+	}
+	for _, id := range missing {
+		fmt.Fprintf(&b, "  %s[\"%s<br>(missing)\"]\n", mermaidID(id), mermaidText(id))
+	}
+	for _, e := range edges(nodes) {
+		fmt.Fprintf(&b, "  %s --> %s\n", mermaidID(e[0]), mermaidID(e[1]))
+	}
+	var done []string
+	for _, n := range nodes {
+		if n.Status == "done" {
+			done = append(done, mermaidID(n.ID))
+		}
+	}
+	if len(done) > 0 {
+		fmt.Fprintf(&b, "  classDef done stroke-dasharray: 4 4\n  class %s done\n", strings.Join(done, ","))
+	}
+	if len(missing) > 0 {
+		ids := make([]string, len(missing))
+		for i, id := range missing {
+			ids[i] = mermaidID(id)
+		}
+		fmt.Fprintf(&b, "  classDef missing stroke:red\n  class %s missing\n", strings.Join(ids, ","))
+	}
+	return b.String()
+}
+
+// mermaidID turns an Issue ID into a Mermaid node ID: every character
+// other than a letter or a digit becomes an underscore, so pkm-001 does
+// not read as the start of an arrow.
+func mermaidID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, id)
+}
+
+// mermaidText escapes s for a quoted Mermaid label.
+func mermaidText(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
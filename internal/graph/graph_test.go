@@ -0,0 +1,175 @@
+// Package graph_test holds the black-box unit tests of the blocked_by
+// graph pure logic (Seam 2): the cycle, the upstream and downstream
+// trees, the impact ranking, and the DOT and Mermaid renderings.
+package graph_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sanmoo/my-tasks2/internal/graph"
+)
+
+func node(id, status string, blockedBy ...string) graph.Node {
+	return graph.Node{ID: id, Title: "title of " + id, Status: status, BlockedBy: blockedBy}
+}
+
+// vault is a small graph: a blocks b and c, b blocks d, c (done) blocks
+// e, and d waits on a missing ghost too.
+func vault() *graph.Graph {
+	return graph.New([]graph.Node{
+		node("a", "open"),
+		node("b", "open", "a"),
+		node("c", "done", "a"),
+		node("d", "in_progress", "b", "ghost"),
+		node("e", "open", "c"),
+		node("f", "open"),
+	})
+}
+
+// outline renders t as indented "id" lines, with the markers.
+func outline(t graph.Tree, depth int) []string {
+	line := strings.Repeat("  ", depth) + t.Node.ID
+	if t.Missing {
+		line += " missing"
+	}
+	if t.Cycle {
+		line += " cycle"
+	}
+	out := []string{line}
+	for _, c := range t.Children {
+		out = append(out, outline(c, depth+1)...)
+	}
+	return out
+}
+
+func TestCycle(t *testing.T) {
+	if got := vault().Cycle(); got != nil {
+		t.Errorf("Cycle(acyclic) = %v, want nil", got)
+	}
+	g := graph.New([]graph.Node{
+		node("x", "open"),
+		node("a", "open", "x", "b"),
+		node("b", "open", "c"),
+		node("c", "open", "a"),
+	})
+	if got, want := g.Cycle(), []string{"a", "b", "c", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Cycle() = %v, want %v", got, want)
+	}
+}
+
+func TestUpstream(t *testing.T) {
+	got := outline(vault().Upstream("d"), 0)
+	want := []string{"d", "  b", "    a", "  ghost missing"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Upstream(d) = %q, want %q", got, want)
+	}
+	if got := vault().Upstream("nope"); !got.Missing {
+		t.Errorf("Upstream(nope) = %+v, want missing", got)
+	}
+}
+
+func TestDownstream(t *testing.T) {
+	got := outline(vault().Downstream("a"), 0)
+	want := []string{"a", "  b", "    d", "  c", "    e"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Downstream(a) = %q, want %q", got, want)
+	}
+}
+
+func TestTreesCutCycles(t *testing.T) {
+	g := graph.New([]graph.Node{node("a", "open", "b"), node("b", "open", "a")})
+	if got, want := outline(g.Upstream("a"), 0), []string{"a", "  b", "    a cycle"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Upstream(cycle) = %q, want %q", got, want)
+	}
+}
+
+func TestNode(t *testing.T) {
+	if n, ok := vault().Node("b"); !ok || n.Title != "title of b" {
+		t.Errorf("Node(b) = %+v, %v", n, ok)
+	}
+	if _, ok := vault().Node("ghost"); ok {
+		t.Error("Node(ghost) found, want missing")
+	}
+}
+
+func TestImpacts(t *testing.T) {
+	var got []string
+	for _, im := range vault().Impacts() {
+		got = append(got, fmt.Sprintf("%s:%d", im.Node.ID, im.Count))
+	}
+	// a unblocks b and d, not c (done) nor e (behind done c); b
+	// unblocks d; d, e and f unblock none; c is done.
+	if want := []string{"a:2", "b:1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Impacts() = %v, want %v", got, want)
+	}
+	diamond := graph.New([]graph.Node{
+		node("z", "open"),
+		node("top", "open"),
+		node("l", "open", "top"),
+		node("r", "open", "top"),
+		node("bottom", "open", "l", "r"),
+		node("y", "open", "z"),
+	})
+	got = nil
+	for _, im := range diamond.Impacts() {
+		got = append(got, fmt.Sprintf("%s:%d", im.Node.ID, im.Count))
+	}
+	if want := []string{"top:3", "z:1", "l:1", "r:1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Impacts(diamond) = %v, want %v", got, want)
+	}
+}
+
+func TestDOT(t *testing.T) {
+	g := graph.New([]graph.Node{
+		node("a", "open"),
+		{ID: "b", Title: `say "hi"`, Status: "open", BlockedBy: []string{"a", "ghost"}},
+		node("old", "done"),
+	})
+	want := `digraph mt {
+  rankdir=LR;
+  node [shape=box];
+  "a" [label="a\ntitle of a\n(open)"];
+  "b" [label="b\nsay \"hi\"\n(open)"];
+  "ghost" [label="ghost\n(missing)", color=red];
+  "a" -> "b";
+  "ghost" -> "b";
+}
+`
+	if got := g.DOT(); got != want {
+		t.Errorf("DOT() =\n%s\nwant\n%s", got, want)
+	}
+	if got := vault().DOT(); !strings.Contains(got, `"c" [label="c\ntitle of c\n(done)", style=dashed];`) {
+		t.Errorf("DOT() misses the done blocker:\n%s", got)
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	want := `flowchart LR
+  a["a<br>title of a<br>(open)"]
+  b["b<br>title of b<br>(open)"]
+  c["c<br>title of c<br>(done)"]
+  d["d<br>title of d<br>(in_progress)"]
+  e["e<br>title of e<br>(open)"]
+  f["f<br>title of f<br>(open)"]
+  ghost["ghost<br>(missing)"]
+  a --> b
+  a --> c
+  b --> d
+  ghost --> d
+  c --> e
+  classDef done stroke-dasharray: 4 4
+  class c done
+  classDef missing stroke:red
+  class ghost missing
+`
+	if got := vault().Mermaid(); got != want {
+		t.Errorf("Mermaid() =\n%s\nwant\n%s", got, want)
+	}
+	g := graph.New([]graph.Node{{ID: "pkm-001", Title: `a "b" <c>`, Status: "open"}})
+	if got := g.Mermaid(); !strings.Contains(got, `pkm_001["pkm-001<br>a #quot;b#quot; #lt;c#gt;<br>(open)"]`) {
+		t.Errorf("Mermaid() escaping:\n%s", got)
+	}
+}
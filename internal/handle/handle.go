@@ -0,0 +1,133 @@
+// Package handle holds the pure logic of session-relative numeric
+// handles: the ID order of the last rendered listing (list, bare mt,
+// ready), persisted per vault in the user cache dir, and the resolution
+// of a small integer argument back to the Issue ID it named. It is
+// decision-dense, so it lives at Seam 2: black-box unit tested, with the
+// coverage and mutation gates. Deciding when to save and which
+// arguments to resolve is a process concern and stays in internal/cli.
+package handle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxDigits bounds the length of a handle argument: a listing never
+// holds a million lines, and the bound keeps Atoi far from overflow.
+const maxDigits = 6
+
+// Map is the persisted ID order of the last listing of one vault.
+// Handle n names IDs[n-1]. Fingerprint identifies the vault's Issue set
+// at listing time (see Fingerprint): when it no longer matches, the
+// handles are stale and resolve to nothing.
+type Map struct {
+	// Vault is the absolute path of the listed vault.
+	Vault string `yaml:"vault"`
+	// Fingerprint is the vault's Issue-set fingerprint at listing time.
+	Fingerprint string `yaml:"fingerprint"`
+	// IDs are the listed Issue IDs, in display order.
+	IDs []string `yaml:"ids,flow"`
+}
+
+// Path returns the handle file of vaultDir inside the user cache dir:
+// <cacheDir>/mt/handles/<hash>.yaml, one file per vault. vaultDir should
+// be absolute, so every way of addressing a vault shares one file.
+func Path(cacheDir, vaultDir string) string {
+	sum := sha256.Sum256([]byte(vaultDir))
+	return filepath.Join(cacheDir, "mt", "handles", hex.EncodeToString(sum[:8])+".yaml")
+}
+
+// Fingerprint summarizes a vault's Issue set: the sorted IDs, hashed. It
+// changes when an Issue is created, deleted or renamed, and only then —
+// mutating an Issue (done, defer, rank) leaves the listed handles valid,
+// so `mt done 3` followed by `mt done 4` works. ids is not modified.
+func Fingerprint(ids []string) string {
+	sorted := slices.Clone(ids)
+	slices.Sort(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Load reads the handle map at path. A missing file yields an empty Map
+// without error: there simply was no listing yet.
+func Load(path string) (Map, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Map{}, nil
+	}
+	if err != nil {
+		return Map{}, fmt.Errorf("reading handles %s: %w", path, err)
+	}
+	var m Map
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return Map{}, fmt.Errorf("parsing handles %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Save writes m to path, creating parent directories as needed. It is the
+// write counterpart of Load.
+func (m Map) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating handles directory: %w", err)
+	}
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("encoding handles: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing handles %s: %w", path, err)
+	}
+	return nil
+}
+
+// Parse reports whether arg is a handle — a positive decimal integer of
+// at most maxDigits digits, without sign or leading zero — and returns
+// its value. Issue IDs always carry the vault prefix, so they never
+// parse as a handle.
+func Parse(arg string) (int, bool) {
+	if arg == "" || len(arg) > maxDigits || arg[0] == '0' {
+		return 0, false
+	}
+	for i := 0; i < len(arg); i++ {
+		if arg[i] < '0' || arg[i] > '9' {
+			return 0, false
+		}
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// Resolve maps arg to an Issue ID. An argument that is not a handle
+// comes back unchanged. A handle resolves through m, the last listing of
+// vaultDir, and fails when there is no listing of that vault, when the
+// vault's Issue set no longer matches fingerprint (the listing is stale),
+// or when the handle is beyond the listing.
+func Resolve(arg string, m Map, vaultDir, fingerprint string) (string, error) {
+	n, ok := Parse(arg)
+	if !ok {
+		return arg, nil
+	}
+	if m.Vault != vaultDir {
+		return "", fmt.Errorf("handle %d: no listing of this vault yet — run 'mt list' first", n)
+	}
+	if m.Fingerprint != fingerprint {
+		return "", fmt.Errorf("handle %d is stale: the vault changed since the last listing — run 'mt list' again", n)
+	}
+	if n > len(m.IDs) {
+		return "", fmt.Errorf("handle %d is not in the last listing (%d issues listed)", n, len(m.IDs))
+	}
+	return m.IDs[n-1], nil
+}
@@ -0,0 +1,177 @@
+// Package handle_test holds the black-box unit tests of the numeric
+// handles (Seam 2): handle parsing, the per-vault cache path, the
+// Issue-set fingerprint, the load/save round-trip and the resolution
+// rules (pass-through, stale listing, out of range).
+package handle_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Sanmoo/my-tasks2/internal/handle"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"1", 1, true},
+		{"3", 3, true},
+		{"42", 42, true},
+		{"999999", 999999, true},
+		{"1000000", 0, false},
+		{"0", 0, false},
+		{"03", 0, false},
+		{"", 0, false},
+		{"-1", 0, false},
+		{"+1", 0, false},
+		{"1a", 0, false},
+		{"a1", 0, false},
+		{"pkm-001", 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			got, ok := handle.Parse(c.in)
+			if got != c.want || ok != c.ok {
+				t.Errorf("Parse(%q) = (%d, %v), want (%d, %v)", c.in, got, ok, c.want, c.ok)
+			}
+		})
+	}
+}
+
+func TestPathIsPerVaultUnderCacheDir(t *testing.T) {
+	a := handle.Path("/cache", "/v/a")
+	b := handle.Path("/cache", "/v/b")
+	if a == b {
+		t.Fatalf("Path is the same for two vaults: %q", a)
+	}
+	if !strings.HasPrefix(a, filepath.Join("/cache", "mt", "handles")+string(filepath.Separator)) {
+		t.Errorf("Path = %q, want it under /cache/mt/handles", a)
+	}
+	if !strings.HasSuffix(a, ".yaml") {
+		t.Errorf("Path = %q, want a .yaml file", a)
+	}
+	if again := handle.Path("/cache", "/v/a"); again != a {
+		t.Errorf("Path is not stable: %q then %q", a, again)
+	}
+}
+
+func TestFingerprintIgnoresOrderButNotMembership(t *testing.T) {
+	ids := []string{"pkm-002", "pkm-001"}
+	a := handle.Fingerprint(ids)
+	if b := handle.Fingerprint([]string{"pkm-001", "pkm-002"}); a != b {
+		t.Errorf("Fingerprint depends on order: %q vs %q", a, b)
+	}
+	if c := handle.Fingerprint([]string{"pkm-001", "pkm-002", "pkm-003"}); a == c {
+		t.Error("Fingerprint ignores an added Issue")
+	}
+	if d := handle.Fingerprint([]string{"pkm-001"}); a == d {
+		t.Error("Fingerprint ignores a removed Issue")
+	}
+	if !slices.Equal(ids, []string{"pkm-002", "pkm-001"}) {
+		t.Errorf("Fingerprint mutated its input: %v", ids)
+	}
+}
+
+func TestLoadMissingIsEmpty(t *testing.T) {
+	m, err := handle.Load(filepath.Join(t.TempDir(), "nope.yaml"))
+	if err != nil {
+		t.Fatalf("Load(missing) = %v, want no error", err)
+	}
+	if m.Vault != "" || len(m.IDs) != 0 {
+		t.Errorf("Load(missing) = %+v, want empty", m)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mt", "handles", "x.yaml")
+	want := handle.Map{Vault: "/v", Fingerprint: "abc", IDs: []string{"pkm-002", "pkm-001"}}
+	if err := want.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := handle.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Vault != want.Vault || got.Fingerprint != want.Fingerprint || !slices.Equal(got.IDs, want.IDs) {
+		t.Errorf("round-trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadMalformedFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.yaml")
+	if err := os.WriteFile(path, []byte("ids: [unclosed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := handle.Load(path); err == nil {
+		t.Fatal("Load(malformed) = nil error, want failure")
+	}
+}
+
+func TestLoadUnreadableFails(t *testing.T) {
+	if _, err := handle.Load(t.TempDir()); err == nil {
+		t.Fatal("Load(directory) = nil error, want failure")
+	}
+}
+
+func TestSaveFailsWhenParentIsAFile(t *testing.T) {
+	parent := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(parent, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := (handle.Map{}).Save(filepath.Join(parent, "x.yaml")); err == nil {
+		t.Fatal("Save under a file = nil error, want failure")
+	}
+}
+
+func TestSaveFailsWhenPathIsADirectory(t *testing.T) {
+	if err := (handle.Map{}).Save(t.TempDir()); err == nil {
+		t.Fatal("Save onto a directory = nil error, want failure")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	ids := []string{"pkm-003", "pkm-001", "pkm-002"}
+	fp := handle.Fingerprint(ids)
+	m := handle.Map{Vault: "/v", Fingerprint: fp, IDs: ids}
+	cases := []struct {
+		name    string
+		arg     string
+		m       handle.Map
+		vault   string
+		fp      string
+		want    string
+		wantErr string
+	}{
+		{"id passes through", "pkm-009", m, "/v", fp, "pkm-009", ""},
+		{"id passes through without a listing", "pkm-009", handle.Map{}, "/v", fp, "pkm-009", ""},
+		{"first handle", "1", m, "/v", fp, "pkm-003", ""},
+		{"last handle", "3", m, "/v", fp, "pkm-002", ""},
+		{"beyond the listing", "4", m, "/v", fp, "", "handle 4 is not in the last listing (3 issues listed)"},
+		{"no listing yet", "1", handle.Map{}, "/v", fp, "", "no listing of this vault"},
+		{"listing of another vault", "1", m, "/w", fp, "", "no listing of this vault"},
+		{"stale listing", "1", m, "/v", "other", "", "handle 1 is stale"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := handle.Resolve(c.arg, c.m, c.vault, c.fp)
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("Resolve(%q) error = %v, want it to contain %q", c.arg, err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q) error: %v", c.arg, err)
+			}
+			if got != c.want {
+				t.Errorf("Resolve(%q) = %q, want %q", c.arg, got, c.want)
+			}
+		})
+	}
+}
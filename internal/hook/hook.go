@@ -0,0 +1,178 @@
+// Package hook holds the pure logic of hooks: user commands that mt runs
+// when Issues change. The hooks: key of mt.yaml and of the global config
+// maps lifecycle events to commands, run without a shell:
+//
+//	hooks:
+//	  done:
+//	    - [notify-send, "Issue done"]
+//	  pre_status_changed:
+//	    - /home/me/bin/check-wip
+//
+// Deciding which events a change fires and building the JSON payload a
+// hook reads on stdin is decision-dense, so it lives at Seam 2:
+// black-box unit tested, with the coverage and mutation gates. Running
+// the commands is a process concern and stays in internal/cli.
+package hook
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+// The lifecycle events, in the order Detect reports them.
+const (
+	Created       = "created"
+	StatusChanged = "status_changed"
+	Done          = "done"
+	Deferred      = "deferred"
+	Ranked        = "ranked"
+	Commented     = "commented"
+)
+
+// Events lists every lifecycle event.
+var Events = []string{Created, StatusChanged, Done, Deferred, Ranked, Commented}
+
+// PrePrefix marks a pre-hook: pre_<event> runs before the change is
+// written, and a non-zero exit vetoes it.
+const PrePrefix = "pre_"
+
+// Command is the argv of a hook command. In YAML it is either a list
+// (the exact argv) or a string, split on whitespace like $EDITOR.
+type Command []string
+
+// UnmarshalYAML reads a command from a string or a list of strings.
+func (c *Command) UnmarshalYAML(node *yaml.Node) error {
+	var argv []string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		argv = strings.Fields(node.Value)
+	case yaml.SequenceNode:
+		if err := node.Decode(&argv); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("line %d: a hook command is a string or a list of arguments", node.Line)
+	}
+	if len(argv) == 0 || argv[0] == "" {
+		return fmt.Errorf("line %d: empty hook command", node.Line)
+	}
+	*c = argv
+	return nil
+}
+
+// String renders the command for messages.
+func (c Command) String() string {
+	return strings.Join(c, " ")
+}
+
+// Config maps event names (an event, or pre_<event>) to the commands to
+// run, in order.
+type Config map[string][]Command
+
+// UnmarshalYAML reads a hooks mapping, rejecting unknown event names.
+func (c *Config) UnmarshalYAML(node *yaml.Node) error {
+	var raw map[string][]Command
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	for name := range raw {
+		if !slices.Contains(Events, strings.TrimPrefix(name, PrePrefix)) {
+			return fmt.Errorf("unknown hook event %q (events: %s, each also as %s<event>)",
+				name, strings.Join(Events, ", "), PrePrefix)
+		}
+	}
+	*c = raw
+	return nil
+}
+
+// Merge returns the hooks of global followed by those of vault, per
+// event: the user's hooks run first, then the vault's.
+func Merge(global, vault Config) Config {
+	merged := make(Config, len(global)+len(vault))
+	for _, cfg := range []Config{global, vault} {
+		for name, cmds := range cfg {
+			merged[name] = append(merged[name], cmds...)
+		}
+	}
+	return merged
+}
+
+// Detect returns the events a change of one Issue fires, from before to
+// after (nil: no file). A new file fires only created; a removed one
+// fires nothing. Otherwise: status_changed when the status changes, and
+// done too when it becomes done; deferred when deferred_until gets a new
+// value; ranked when the rank changes; commented when a comment is
+// added.
+func Detect(before, after *issue.Issue) []string {
+	switch {
+	case after == nil:
+		return nil
+	case before == nil:
+		return []string{Created}
+	}
+	var events []string
+	b, a := before.Frontmatter, after.Frontmatter
+	if a.Status != b.Status {
+		events = append(events, StatusChanged)
+		if a.Status == "done" {
+			events = append(events, Done)
+		}
+	}
+	if a.DeferredUntil != "" && a.DeferredUntil != b.DeferredUntil {
+		events = append(events, Deferred)
+	}
+	if !sameRank(a.Rank, b.Rank) {
+		events = append(events, Ranked)
+	}
+	if len(issue.Comments(after.Body)) > len(issue.Comments(before.Body)) {
+		events = append(events, Commented)
+	}
+	return events
+}
+
+// sameRank reports whether two ranks are equal, nil (Backlog) being
+// equal only to nil.
+func sameRank(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// Payload is the event a hook reads as JSON on stdin.
+type Payload struct {
+	Event   string             `json:"event"`
+	Vault   string             `json:"vault"`
+	ID      string             `json:"id"`
+	Command string             `json:"command"`
+	Before  *issue.Frontmatter `json:"before"`
+	After   *issue.Frontmatter `json:"after"`
+}
+
+// NewPayload builds the payload of event for the Issue id, changed from
+// before to after (nil: no file) by the mt command line command.
+func NewPayload(event, vaultDir, id, command string, before, after *issue.Issue) Payload {
+	p := Payload{Event: event, Vault: vaultDir, ID: id, Command: command}
+	if before != nil {
+		p.Before = &before.Frontmatter
+	}
+	if after != nil {
+		p.After = &after.Frontmatter
+	}
+	return p
+}
+
+// JSON encodes the payload as one line of JSON.
+func (p Payload) JSON() ([]byte, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("encoding hook payload: %w", err)
+	}
+	return append(data, '\n'), nil
+}
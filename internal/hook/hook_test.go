@@ -0,0 +1,144 @@
+// Package hook_test holds the black-box unit tests of hooks (Seam 2):
+// the hooks config, event detection and the JSON payload.
+package hook_test
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Sanmoo/my-tasks2/internal/hook"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+func ptr(n int) *int { return &n }
+
+func TestConfigParsesStringAndListCommands(t *testing.T) {
+	var cfg hook.Config
+	err := yaml.Unmarshal([]byte(`
+done:
+  - [notify-send, "Issue done"]
+  - /bin/sync-calendar --all
+pre_status_changed:
+  - [check-wip]
+`), &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg[hook.Done]; len(got) != 2 || !slices.Equal(got[0], hook.Command{"notify-send", "Issue done"}) ||
+		!slices.Equal(got[1], hook.Command{"/bin/sync-calendar", "--all"}) {
+		t.Errorf("done hooks = %v", got)
+	}
+	if got := cfg["pre_status_changed"]; len(got) != 1 || got[0].String() != "check-wip" {
+		t.Errorf("pre_status_changed hooks = %v", got)
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	for name, tc := range map[string]struct{ yaml, want string }{
+		"unknown event":     {"closed: [x]", `unknown hook event "closed"`},
+		"unknown pre event": {"pre_closed: [x]", `unknown hook event "pre_closed"`},
+		"empty string":      {"done: ['  ']", "empty hook command"},
+		"empty list":        {"done: [[]]", "empty hook command"},
+		"mapping command":   {"done: [{a: b}]", "a hook command is a string or a list"},
+		"not a mapping":     {"[done]", "cannot unmarshal"},
+		"bad argv":          {"done: [[{a: b}]]", "cannot unmarshal"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var cfg hook.Config
+			if err := yaml.Unmarshal([]byte(tc.yaml), &cfg); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Unmarshal error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestMergeRunsGlobalHooksFirst(t *testing.T) {
+	global := hook.Config{hook.Done: {{"global"}}, hook.Created: {{"g-created"}}}
+	vault := hook.Config{hook.Done: {{"vault"}}}
+	merged := hook.Merge(global, vault)
+	if got := merged[hook.Done]; len(got) != 2 || got[0][0] != "global" || got[1][0] != "vault" {
+		t.Errorf("merged done = %v, want global then vault", got)
+	}
+	if len(merged[hook.Created]) != 1 || len(global[hook.Done]) != 1 {
+		t.Errorf("Merge = %v, or it modified its input", merged)
+	}
+	if got := hook.Merge(nil, nil); len(got) != 0 {
+		t.Errorf("Merge(nil, nil) = %v", got)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	base := issue.Issue{
+		Frontmatter: issue.Frontmatter{Title: "t", Status: "open", Rank: ptr(1)},
+		Body:        issue.DefaultBody,
+	}
+	with := func(f func(*issue.Issue)) *issue.Issue {
+		i := base
+		f(&i)
+		return &i
+	}
+	tests := []struct {
+		name          string
+		before, after *issue.Issue
+		want          []string
+	}{
+		{"created", nil, &base, []string{hook.Created}},
+		{"removed", &base, nil, nil},
+		{"unchanged", &base, &base, nil},
+		{"status", &base, with(func(i *issue.Issue) { i.Frontmatter.Status = "in_progress" }), []string{hook.StatusChanged}},
+		{"done", &base, with(func(i *issue.Issue) { i.Frontmatter.Status = "done" }), []string{hook.StatusChanged, hook.Done}},
+		{"deferred", &base, with(func(i *issue.Issue) { i.Frontmatter.DeferredUntil = "2026-09-01T08:00" }), []string{hook.Deferred}},
+		{"undeferred", with(func(i *issue.Issue) { i.Frontmatter.DeferredUntil = "2026-09-01T08:00" }), &base, nil},
+		{"reranked", &base, with(func(i *issue.Issue) { i.Frontmatter.Rank = ptr(2) }), []string{hook.Ranked}},
+		{"unranked", &base, with(func(i *issue.Issue) { i.Frontmatter.Rank = nil }), []string{hook.Ranked}},
+		{"ranked from backlog", with(func(i *issue.Issue) { i.Frontmatter.Rank = nil }), &base, []string{hook.Ranked}},
+		{"commented", &base, with(func(i *issue.Issue) {
+			i.Body = issue.AppendComment(i.Body, "2026-08-16T14:05", "oi", "4f2b9c1a")
+		}), []string{hook.Commented}},
+		{"body edit", &base, with(func(i *issue.Issue) { i.Body = "\n## Description\nx\n" }), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hook.Detect(tt.before, tt.after); !slices.Equal(got, tt.want) {
+				t.Errorf("Detect = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayloadJSON(t *testing.T) {
+	before := issue.Issue{Frontmatter: issue.Frontmatter{Title: "t", Status: "open", Labels: []string{}, CreatedAt: "2026-08-15T09:30"}}
+	after := before
+	after.Frontmatter.Status = "done"
+	after.Frontmatter.CompletedAt = "2026-08-16T10:00"
+	data, err := hook.NewPayload(hook.Done, "/v", "pkm-001", "mt done pkm-001", &before, &after).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(data), "}\n") {
+		t.Errorf("JSON = %q, want one line", data)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["event"] != "done" || got["vault"] != "/v" || got["id"] != "pkm-001" || got["command"] != "mt done pkm-001" {
+		t.Errorf("payload = %v", got)
+	}
+	b, a := got["before"].(map[string]any), got["after"].(map[string]any)
+	if b["status"] != "open" || a["status"] != "done" || a["completed_at"] != "2026-08-16T10:00" {
+		t.Errorf("before, after = %v, %v", b, a)
+	}
+	if _, ok := b["completed_at"]; ok {
+		t.Errorf("before has an unset field: %v", b)
+	}
+
+	created, err := hook.NewPayload(hook.Created, "/v", "pkm-002", "mt q x", nil, &after).JSON()
+	if err != nil || !strings.Contains(string(created), `"before":null`) {
+		t.Errorf("created payload = %s, %v; want before null", created, err)
+	}
+}
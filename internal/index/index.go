@@ -0,0 +1,173 @@
+// Package index holds the pure logic of the persistent Issue index: a
+// frontmatter projection of every Issue file, keyed by file name and
+// validated by the file's stat signature (modification time, size,
+// inode), cached under the vault's state directory so list-style commands
+// on a large vault parse only the files that changed since the last run.
+// Deciding when an entry may be trusted, and when it must not be stored
+// at all, is the decision-dense part and lives at Seam 2: black-box unit
+// tested, with the coverage and mutation gates. Walking the issues
+// directory and parsing the stale files stay in internal/cli.
+//
+// The index is a cache, never a source of truth: a missing, corrupt or
+// older-format file loads as an empty index, so the caller falls back to
+// a full parse and rewrites it.
+package index
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/journal"
+)
+
+// Version is the on-disk format of the index. A file of any other
+// version is ignored and rebuilt.
+const Version = 5
+
+// RacyWindow is how close to "now" a file's modification time may be for
+// its entry to still be stored. A file written within the filesystem's
+// timestamp granularity of the index being saved could change again
+// without its stat signature changing; such files are left out and
+// parsed again next time, as Git does for its racily clean index entries.
+const RacyWindow = 2 * time.Second
+
+// Key is the stat signature of one Issue file. An entry is trusted only
+// while the file's current signature equals the stored one.
+type Key struct {
+	MTime int64  `json:"mtime"`
+	Size  int64  `json:"size"`
+	Inode uint64 `json:"inode,omitempty"`
+}
+
+// Entry is the cached projection of one Issue file.
+type Entry struct {
+	Key         Key               `json:"key"`
+	Frontmatter issue.Frontmatter `json:"frontmatter"`
+}
+
+// Index maps Issue file names (pkm-001.md) to their cached entries.
+type Index struct {
+	entries map[string]Entry
+	dirty   bool
+}
+
+type file struct {
+	Version int              `json:"version"`
+	Entries map[string]Entry `json:"entries"`
+}
+
+// Path returns the index file of vaultDir: <vault>/.mt/cache/index.json.
+func Path(vaultDir string) string {
+	return filepath.Join(vaultDir, journal.Dir, "cache", "index.json")
+}
+
+// New returns an empty index, the start of a full rebuild.
+func New() *Index {
+	return &Index{entries: map[string]Entry{}, dirty: true}
+}
+
+// Load reads the index at path. Any file that cannot be used — missing,
+// unreadable, malformed or of another Version — yields an empty index,
+// never an error: the caller then parses every Issue.
+func Load(path string) *Index {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return New()
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil || f.Version != Version || f.Entries == nil {
+		return New()
+	}
+	return &Index{entries: f.Entries}
+}
+
+// Len returns the number of cached entries.
+func (ix *Index) Len() int {
+	return len(ix.entries)
+}
+
+// Lookup returns the cached frontmatter of name when its stored key
+// equals key — the file is unchanged since it was indexed.
+func (ix *Index) Lookup(name string, key Key) (issue.Frontmatter, bool) {
+	e, ok := ix.entries[name]
+	if !ok || e.Key != key {
+		return issue.Frontmatter{}, false
+	}
+	return e.Frontmatter, true
+}
+
+// Put records the freshly parsed frontmatter of name. A file modified
+// within RacyWindow of now is not stored (and any older entry of it is
+// dropped): its signature cannot yet tell a later edit apart.
+func (ix *Index) Put(name string, key Key, fm issue.Frontmatter, now time.Time) {
+	if now.Sub(time.Unix(0, key.MTime)).Abs() < RacyWindow {
+		if _, ok := ix.entries[name]; ok {
+			delete(ix.entries, name)
+			ix.dirty = true
+		}
+		return
+	}
+	ix.entries[name] = Entry{Key: key, Frontmatter: fm}
+	ix.dirty = true
+}
+
+// Retain drops the entries of every file not in names — Issues deleted
+// or renamed since they were indexed.
+func (ix *Index) Retain(names []string) {
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+	}
+	for name := range ix.entries {
+		if !keep[name] {
+			delete(ix.entries, name)
+			ix.dirty = true
+		}
+	}
+}
+
+// Dirty reports whether the index changed since it was loaded, so it is
+// worth saving.
+func (ix *Index) Dirty() bool {
+	return ix.dirty
+}
+
+// Save writes the index to path atomically (a temporary file renamed into
+// place), creating its directory with a .gitignore that ignores it whole.
+func (ix *Index) Save(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", dir, err)
+	}
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(ignore, []byte("*\n"), 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", ignore, err)
+		}
+	}
+	data, err := json.Marshal(file{Version: Version, Entries: ix.entries})
+	if err != nil {
+		return fmt.Errorf("encoding index: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "index-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating index: %w", err)
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if err := errors.Join(writeErr, closeErr); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing index %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("replacing index %s: %w", path, err)
+	}
+	ix.dirty = false
+	return nil
+}
@@ -0,0 +1,176 @@
+// Package index_test holds the black-box unit tests of the persistent
+// Issue index (Seam 2): the save/load round-trip, the fallback to an
+// empty index, key validation, the racy-entry guard and pruning.
+package index_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Sanmoo/my-tasks2/internal/index"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+var now = time.Date(2026, 8, 16, 12, 0, 0, 0, time.UTC)
+
+// old is a key of a file written well before now.
+var old = index.Key{MTime: now.Add(-time.Hour).UnixNano(), Size: 120, Inode: 7}
+
+var fm = issue.Frontmatter{Title: "first", Status: "open", Labels: []string{"casa"}, CreatedAt: "2026-01-01T10:00"}
+
+func TestPathIsInsideTheVaultStateDir(t *testing.T) {
+	got := index.Path("/v")
+	if want := filepath.Join("/v", ".mt", "cache", "index.json"); got != want {
+		t.Errorf("Path = %q, want %q", got, want)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "index.json")
+	ix := index.New()
+	ix.Put("pkm-001.md", old, fm, now)
+	if err := ix.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if ix.Dirty() {
+		t.Error("a saved index is still dirty")
+	}
+	if data, err := os.ReadFile(filepath.Join(filepath.Dir(path), ".gitignore")); err != nil || string(data) != "*\n" {
+		t.Errorf(".gitignore = %q, %v", data, err)
+	}
+
+	loaded := index.Load(path)
+	if loaded.Dirty() || loaded.Len() != 1 {
+		t.Fatalf("Load = dirty %v, %d entries; want clean, 1", loaded.Dirty(), loaded.Len())
+	}
+	got, ok := loaded.Lookup("pkm-001.md", old)
+	if !ok || got.Title != "first" || len(got.Labels) != 1 || got.Labels[0] != "casa" {
+		t.Errorf("Lookup = %+v, %v", got, ok)
+	}
+	if err := loaded.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 2 {
+		t.Errorf("cache dir holds %d files, want index.json and .gitignore only", len(entries))
+	}
+}
+
+func TestLoadFallsBackToAnEmptyIndex(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"corrupt.json": "{not json",
+		"version.json": `{"version":999,"entries":{"pkm-001.md":{"key":{"mtime":1,"size":1}}}}`,
+		"null.json":    `{"version":5}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if ix := index.Load(path); ix.Len() != 0 || !ix.Dirty() {
+			t.Errorf("Load(%s) = %d entries, dirty %v; want empty and dirty", name, ix.Len(), ix.Dirty())
+		}
+	}
+	if ix := index.Load(filepath.Join(dir, "missing.json")); ix.Len() != 0 || !ix.Dirty() {
+		t.Errorf("Load(missing) = %d entries, dirty %v", ix.Len(), ix.Dirty())
+	}
+}
+
+func TestLookupNeedsTheSameKey(t *testing.T) {
+	ix := index.New()
+	ix.Put("pkm-001.md", old, fm, now)
+	for name, key := range map[string]index.Key{
+		"mtime": {MTime: old.MTime + 1, Size: old.Size, Inode: old.Inode},
+		"size":  {MTime: old.MTime, Size: old.Size + 1, Inode: old.Inode},
+		"inode": {MTime: old.MTime, Size: old.Size, Inode: old.Inode + 1},
+	} {
+		if _, ok := ix.Lookup("pkm-001.md", key); ok {
+			t.Errorf("Lookup trusted an entry whose %s changed", name)
+		}
+	}
+	if _, ok := ix.Lookup("pkm-002.md", old); ok {
+		t.Error("Lookup found a file never indexed")
+	}
+}
+
+func TestPutSkipsRacyFiles(t *testing.T) {
+	ix := index.Load(filepath.Join(t.TempDir(), "missing.json"))
+	ix.Put("pkm-001.md", old, fm, now)
+	if err := ix.Save(filepath.Join(t.TempDir(), "index.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	racy := index.Key{MTime: now.Add(-index.RacyWindow + time.Millisecond).UnixNano(), Size: 130}
+	ix.Put("pkm-001.md", racy, fm, now)
+	if _, ok := ix.Lookup("pkm-001.md", racy); ok {
+		t.Error("a racy file was indexed")
+	}
+	if _, ok := ix.Lookup("pkm-001.md", old); ok || ix.Len() != 0 || !ix.Dirty() {
+		t.Error("a racy rewrite kept the file's previous entry")
+	}
+
+	ix.Put("pkm-002.md", racy, fm, now)
+	if ix.Len() != 0 {
+		t.Error("a racy new file was indexed")
+	}
+	future := index.Key{MTime: now.Add(time.Second).UnixNano()}
+	ix.Put("pkm-003.md", future, fm, now)
+	if ix.Len() != 0 {
+		t.Error("a file modified in the future was indexed")
+	}
+	edge := index.Key{MTime: now.Add(-index.RacyWindow).UnixNano()}
+	ix.Put("pkm-004.md", edge, fm, now)
+	if _, ok := ix.Lookup("pkm-004.md", edge); !ok {
+		t.Error("a file modified RacyWindow ago was not indexed")
+	}
+}
+
+func TestRetainPrunesGoneFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+	ix := index.New()
+	ix.Put("pkm-001.md", old, fm, now)
+	ix.Put("pkm-002.md", old, fm, now)
+	if err := ix.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	ix = index.Load(path)
+	ix.Retain([]string{"pkm-001.md", "pkm-002.md"})
+	if ix.Dirty() {
+		t.Error("Retain of every file dirtied the index")
+	}
+	ix.Retain([]string{"pkm-002.md", "pkm-003.md"})
+	if !ix.Dirty() || ix.Len() != 1 {
+		t.Errorf("Retain = %d entries, dirty %v; want 1, dirty", ix.Len(), ix.Dirty())
+	}
+	if _, ok := ix.Lookup("pkm-001.md", old); ok {
+		t.Error("Retain kept a gone file")
+	}
+}
+
+func TestSaveReportsAnUnwritableDir(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.New().Save(filepath.Join(blocker, "cache", "index.json")); err == nil {
+		t.Error("Save under a regular file succeeded")
+	}
+}
+
+func TestSaveLeavesNoTempFileOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "index.json")
+	if err := os.MkdirAll(filepath.Join(path, "taken"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.New().Save(path); err == nil {
+		t.Fatal("Save over a directory succeeded")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("dir holds %d entries, want index.json and .gitignore only", len(entries))
+	}
+}
@@ -0,0 +1,245 @@
+package issue
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Comment management: reading the comment blocks AppendComment writes
+// back out of a body, threading replies, and rewriting one comment's
+// text in place. A reply is an ordinary comment block whose heading
+// names its parent's anchor:
+//
+//	### 2026-08-16T15:00 (reply to 4f2b9c1a)
+//	Achei o resto no atacado.
+//	<!-- comment: 9e0d7c2b -->
+//
+// Rewriting (edit, redact) touches only the text between a block's
+// heading and its anchor marker: the timestamp, the thread and the
+// anchor itself are stable.
+
+// Tombstone is the text a redacted comment keeps: the block, its heading
+// and its anchor stay, so replies and references still resolve.
+const Tombstone = "*[redacted]*"
+
+// markerRe matches a comment anchor marker line.
+var markerRe = regexp.MustCompile(`^<!-- comment: ([0-9a-z]+) -->$`)
+
+// headingRe matches a comment heading: the timestamp and, for a reply,
+// the parent's anchor.
+var headingRe = regexp.MustCompile(`^### (\S+)(?: \(reply to ([0-9a-z]+)\))?$`)
+
+// Comment is one anchored comment block of a body.
+type Comment struct {
+	Anchor    string
+	Timestamp string
+	// ReplyTo is the anchor of the comment this one answers, empty for a
+	// top-level comment.
+	ReplyTo string
+	// Text is the comment text, without the final newline.
+	Text string
+	// textStart and textEnd delimit the text lines in the body: from
+	// after the heading line to the start of the marker line.
+	textStart, textEnd int
+}
+
+// Redacted reports whether c was redacted.
+func (c Comment) Redacted() bool {
+	return c.Text == Tombstone
+}
+
+// Comments returns the anchored comment blocks of body, in file order.
+// Only the Comments section is read (the whole body when it has no
+// Comments heading). A ### block without an anchor marker — written by
+// hand — cannot be addressed and is skipped.
+func Comments(body string) []Comment {
+	var comments []Comment
+	offset := 0
+	if i := sectionStart(body, "## Comments"); i >= 0 {
+		offset = i
+	}
+	var open *Comment
+	for offset < len(body) {
+		end := strings.IndexByte(body[offset:], '\n')
+		next := len(body)
+		if end >= 0 {
+			next = offset + end + 1
+		}
+		line := strings.TrimSuffix(body[offset:next], "\n")
+		switch m := headingRe.FindStringSubmatch(line); {
+		case m != nil:
+			open = &Comment{Timestamp: m[1], ReplyTo: m[2], textStart: next}
+		case open != nil && markerRe.MatchString(line):
+			open.Anchor = markerRe.FindStringSubmatch(line)[1]
+			open.textEnd = offset
+			open.Text = strings.TrimSuffix(body[open.textStart:offset], "\n")
+			comments = append(comments, *open)
+			open = nil
+		case strings.HasPrefix(line, "## ") || strings.HasPrefix(line, "### "):
+			open = nil
+		}
+		offset = next
+	}
+	return comments
+}
+
+// sectionStart returns the offset of the line after the heading line,
+// or -1 when body has no such line.
+func sectionStart(body, heading string) int {
+	offset := 0
+	for offset < len(body) {
+		end := strings.IndexByte(body[offset:], '\n')
+		if end < 0 {
+			if body[offset:] == heading {
+				return len(body)
+			}
+			return -1
+		}
+		if body[offset:offset+end] == heading {
+			return offset + end + 1
+		}
+		offset += end + 1
+	}
+	return -1
+}
+
+// FindComment returns the comment of body with anchor.
+func FindComment(body, anchor string) (Comment, error) {
+	for _, c := range Comments(body) {
+		if c.Anchor == anchor {
+			return c, nil
+		}
+	}
+	return Comment{}, fmt.Errorf("comment %s not found", anchor)
+}
+
+// AppendReply is AppendComment for a reply: the heading names the parent
+// anchor. The parent must be a comment of body.
+func AppendReply(body, timestamp, text, anchor, parent string) (string, error) {
+	if _, err := FindComment(body, parent); err != nil {
+		return "", err
+	}
+	return AppendComment(body, fmt.Sprintf("%s (reply to %s)", timestamp, parent), text, anchor), nil
+}
+
+// SetCommentText returns body with the text of the comment anchor
+// replaced; everything else is preserved byte-for-byte. A redacted
+// comment cannot be rewritten: its tombstone is final.
+func SetCommentText(body, anchor, text string) (string, error) {
+	c, err := FindComment(body, anchor)
+	if err != nil {
+		return "", err
+	}
+	if c.Redacted() {
+		return "", fmt.Errorf("comment %s is redacted", anchor)
+	}
+	if err := CheckCommentText(text); err != nil {
+		return "", err
+	}
+	return body[:c.textStart] + text + "\n" + body[c.textEnd:], nil
+}
+
+// RedactComment returns body with the text of the comment anchor replaced
+// by the Tombstone. Redacting a redacted comment changes nothing.
+func RedactComment(body, anchor string) (string, error) {
+	c, err := FindComment(body, anchor)
+	if err != nil {
+		return "", err
+	}
+	return body[:c.textStart] + Tombstone + "\n" + body[c.textEnd:], nil
+}
+
+// CheckCommentText rejects comment text that would break the block
+// structure: empty text, a line that reads as a section or comment
+// heading, or an anchor marker.
+func CheckCommentText(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return errors.New("comment text is empty")
+	}
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "## ") || strings.HasPrefix(line, "### "):
+			return fmt.Errorf("comment text must not contain a heading line (%q): it would split the comment", line)
+		case strings.Contains(line, "<!-- comment:"):
+			return fmt.Errorf("comment text must not contain an anchor marker (%q)", line)
+		}
+	}
+	return nil
+}
+
+// commentBufferHeader is the instruction block at the top of the editor
+// buffer a comment is composed in.
+const commentBufferHeader = `# Write the comment for %s below.
+# Lines starting with # are ignored; an empty comment aborts.
+`
+
+// CommentBuffer builds the $EDITOR buffer to compose a comment on the
+// Issues ids: the instruction header, then a blank line to write on.
+func CommentBuffer(ids []string) string {
+	return fmt.Sprintf(commentBufferHeader, strings.Join(ids, ", ")) + "\n"
+}
+
+// ParseCommentBuffer reads the comment text back out of a saved editor
+// buffer: lines starting with # (the header, like the prioritize buffer's
+// comments) are dropped, and so are the blank lines around the text. The
+// result still goes through CheckCommentText.
+func ParseCommentBuffer(buffer string) string {
+	var kept []string
+	for _, line := range strings.Split(strings.ReplaceAll(buffer, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimLeft(line, " \t"), "#") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Trim(strings.Join(kept, "\n"), "\n")
+}
+
+// Threaded is a comment in thread order, with its reply depth (0 for a
+// top-level comment).
+type Threaded struct {
+	Comment
+	Depth int
+}
+
+// Thread orders comments for display: each comment followed by its
+// replies, recursively, in file order. A reply whose parent is missing
+// is shown at the top level, and every comment is shown exactly once.
+func Thread(comments []Comment) []Threaded {
+	known := make(map[string]bool, len(comments))
+	for _, c := range comments {
+		known[c.Anchor] = true
+	}
+	children := make(map[string][]Comment)
+	var roots []Comment
+	for _, c := range comments {
+		if c.ReplyTo != "" && known[c.ReplyTo] && c.ReplyTo != c.Anchor {
+			children[c.ReplyTo] = append(children[c.ReplyTo], c)
+			continue
+		}
+		roots = append(roots, c)
+	}
+	out := make([]Threaded, 0, len(comments))
+	seen := make(map[string]bool, len(comments))
+	var walk func(c Comment, depth int)
+	walk = func(c Comment, depth int) {
+		if seen[c.Anchor] {
+			return
+		}
+		seen[c.Anchor] = true
+		out = append(out, Threaded{Comment: c, Depth: depth})
+		for _, child := range children[c.Anchor] {
+			walk(child, depth+1)
+		}
+	}
+	for _, c := range roots {
+		walk(c, 0)
+	}
+	// Replies in a cycle (hand-edited anchors) have no root; they still
+	// show, at the top level.
+	for _, c := range comments {
+		walk(c, 0)
+	}
+	return out
+}
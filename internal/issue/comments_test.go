@@ -0,0 +1,185 @@
+package issue_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+const threadBody = `
+## Description
+### 2026-01-01T00:00
+not a comment: outside the Comments section
+<!-- comment: 00000000 -->
+## Notes
+## Comments
+### 2026-08-16T14:05
+Comprei metade da lista.
+<!-- comment: 4f2b9c1a -->
+### handwritten, no anchor
+free text
+### 2026-08-16T14:30
+segunda
+linha
+<!-- comment: 11111111 -->
+### 2026-08-16T15:00 (reply to 4f2b9c1a)
+Achei o resto.
+<!-- comment: 9e0d7c2b -->
+### 2026-08-16T16:00 (reply to 9e0d7c2b)
+Ótimo.
+<!-- comment: 22222222 -->
+`
+
+func TestCommentsParsesAnchoredBlocks(t *testing.T) {
+	got := issue.Comments(threadBody)
+	want := []issue.Comment{
+		{Anchor: "4f2b9c1a", Timestamp: "2026-08-16T14:05", Text: "Comprei metade da lista."},
+		{Anchor: "11111111", Timestamp: "2026-08-16T14:30", Text: "segunda\nlinha"},
+		{Anchor: "9e0d7c2b", Timestamp: "2026-08-16T15:00", ReplyTo: "4f2b9c1a", Text: "Achei o resto."},
+		{Anchor: "22222222", Timestamp: "2026-08-16T16:00", ReplyTo: "9e0d7c2b", Text: "Ótimo."},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Comments = %d comments, want %d: %+v", len(got), len(want), got)
+	}
+	for n := range want {
+		g, w := got[n], want[n]
+		if g.Anchor != w.Anchor || g.Timestamp != w.Timestamp || g.ReplyTo != w.ReplyTo || g.Text != w.Text {
+			t.Errorf("Comments[%d] = %+v, want %+v", n, g, w)
+		}
+	}
+}
+
+func TestCommentsWithoutCommentsHeadingReadsTheWholeBody(t *testing.T) {
+	got := issue.Comments("### 2026-08-16T14:05\nx\n<!-- comment: 4f2b9c1a -->")
+	if len(got) != 1 || got[0].Text != "x" || got[0].Anchor != "4f2b9c1a" {
+		t.Errorf("Comments = %+v", got)
+	}
+	if got := issue.Comments(issue.DefaultBody); len(got) != 0 {
+		t.Errorf("Comments(DefaultBody) = %+v, want none", got)
+	}
+	if got := issue.Comments("\n## Comments"); len(got) != 0 {
+		t.Errorf("Comments(heading at EOF) = %+v, want none", got)
+	}
+}
+
+func TestCommentsRoundTripAppendComment(t *testing.T) {
+	body := issue.AppendComment(issue.DefaultBody, "2026-08-16T14:05", "a\n\nb", "4f2b9c1a")
+	got := issue.Comments(body)
+	if len(got) != 1 || got[0].Text != "a\n\nb" || got[0].Timestamp != "2026-08-16T14:05" {
+		t.Errorf("Comments(AppendComment) = %+v", got)
+	}
+}
+
+func TestAppendReply(t *testing.T) {
+	body, err := issue.AppendReply(threadBody, "2026-08-17T09:00", "resposta", "33333333", "11111111")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(body, threadBody) {
+		t.Error("AppendReply rewrote the existing body")
+	}
+	c, err := issue.FindComment(body, "33333333")
+	if err != nil || c.ReplyTo != "11111111" || c.Timestamp != "2026-08-17T09:00" || c.Text != "resposta" {
+		t.Errorf("reply = %+v, %v", c, err)
+	}
+	if _, err := issue.AppendReply(threadBody, "2026-08-17T09:00", "x", "33333333", "00000000"); err == nil || !strings.Contains(err.Error(), "comment 00000000 not found") {
+		t.Errorf("AppendReply to a block outside Comments: err = %v", err)
+	}
+}
+
+func TestSetCommentText(t *testing.T) {
+	body, err := issue.SetCommentText(threadBody, "11111111", "reescrito\nem duas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(threadBody, "segunda\nlinha\n", "reescrito\nem duas\n", 1)
+	if body != want {
+		t.Errorf("SetCommentText =\n%s\nwant\n%s", body, want)
+	}
+	for name, tc := range map[string]struct{ body, anchor, text, want string }{
+		"unknown anchor":  {threadBody, "deadbeef", "x", "comment deadbeef not found"},
+		"empty text":      {threadBody, "11111111", " \n", "comment text is empty"},
+		"heading line":    {threadBody, "11111111", "a\n### b", "heading line"},
+		"section heading": {threadBody, "11111111", "## Notes", "heading line"},
+		"fake marker":     {threadBody, "11111111", "<!-- comment: 12345678 -->", "anchor marker"},
+		"redacted":        {strings.Replace(threadBody, "Ótimo.", issue.Tombstone, 1), "22222222", "x", "comment 22222222 is redacted"},
+	} {
+		if _, err := issue.SetCommentText(tc.body, tc.anchor, tc.text); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", name, err, tc.want)
+		}
+	}
+}
+
+func TestRedactComment(t *testing.T) {
+	body, err := issue.RedactComment(threadBody, "9e0d7c2b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.Replace(threadBody, "Achei o resto.\n", issue.Tombstone+"\n", 1); body != want {
+		t.Errorf("RedactComment =\n%s\nwant\n%s", body, want)
+	}
+	c, _ := issue.FindComment(body, "9e0d7c2b")
+	if !c.Redacted() || c.ReplyTo != "4f2b9c1a" {
+		t.Errorf("redacted comment = %+v, want the tombstone with its thread kept", c)
+	}
+	again, err := issue.RedactComment(body, "9e0d7c2b")
+	if err != nil || again != body {
+		t.Errorf("redacting twice changed the body (%v)", err)
+	}
+	if _, err := issue.RedactComment(threadBody, "deadbeef"); err == nil {
+		t.Error("RedactComment of an unknown anchor succeeded")
+	}
+}
+
+func TestThread(t *testing.T) {
+	got := issue.Thread(issue.Comments(threadBody))
+	want := []struct {
+		anchor string
+		depth  int
+	}{{"4f2b9c1a", 0}, {"9e0d7c2b", 1}, {"22222222", 2}, {"11111111", 0}}
+	if len(got) != len(want) {
+		t.Fatalf("Thread = %+v", got)
+	}
+	for n, w := range want {
+		if got[n].Anchor != w.anchor || got[n].Depth != w.depth {
+			t.Errorf("Thread[%d] = %s at %d, want %s at %d", n, got[n].Anchor, got[n].Depth, w.anchor, w.depth)
+		}
+	}
+}
+
+func TestThreadOrphansAndCycles(t *testing.T) {
+	got := issue.Thread([]issue.Comment{
+		{Anchor: "a", ReplyTo: "b"},
+		{Anchor: "b", ReplyTo: "a"},
+		{Anchor: "c", ReplyTo: "gone"},
+		{Anchor: "d", ReplyTo: "d"},
+	})
+	var anchors []string
+	for _, c := range got {
+		anchors = append(anchors, c.Anchor)
+		if c.Anchor == "c" && c.Depth != 0 {
+			t.Errorf("orphan reply at depth %d, want 0", c.Depth)
+		}
+	}
+	if strings.Join(anchors, ",") != "c,d,a,b" {
+		t.Errorf("Thread anchors = %v, want every comment once: c,d,a,b", anchors)
+	}
+}
+
+func TestCommentBufferRoundTrip(t *testing.T) {
+	buffer := issue.CommentBuffer([]string{"pkm-055", "pkm-056"})
+	if !strings.HasPrefix(buffer, "# Write the comment for pkm-055, pkm-056 below.\n") {
+		t.Errorf("CommentBuffer = %q", buffer)
+	}
+	if got := issue.ParseCommentBuffer(buffer); got != "" {
+		t.Errorf("ParseCommentBuffer(untouched buffer) = %q, want empty", got)
+	}
+	saved := buffer + "\n- item\n\n```go\nx := 1   \n```\n  # indented note\n\n"
+	if got, want := issue.ParseCommentBuffer(saved), "- item\n\n```go\nx := 1   \n```"; got != want {
+		t.Errorf("ParseCommentBuffer = %q, want %q", got, want)
+	}
+	if got := issue.ParseCommentBuffer("# h\r\nline\r\n"); got != "line" {
+		t.Errorf("ParseCommentBuffer(CRLF) = %q, want %q", got, "line")
+	}
+}
@@ -0,0 +1,61 @@
+package issue
+
+import "strings"
+
+// descriptionHeading is the heading of the body's first section.
+const descriptionHeading = "## Description"
+
+// SetDescription returns i with the Description section of its body
+// holding text: everything between the "## Description" heading and the
+// next "## " heading is replaced, and the rest of the body is kept
+// byte-for-byte. A body without the heading gets the section prepended.
+// Trailing newlines of text collapse into the single one that ends the
+// section.
+func (i Issue) SetDescription(text string) Issue {
+	section := descriptionHeading + "\n"
+	if text = strings.TrimRight(text, "\n"); text != "" {
+		section += text + "\n"
+	}
+	lines := strings.SplitAfter(i.Body, "\n")
+	start, end := descriptionSection(lines)
+	if start < 0 {
+		i.Body = "\n" + section + strings.TrimPrefix(i.Body, "\n")
+		return i
+	}
+	i.Body = strings.Join(lines[:start], "") + section + strings.Join(lines[end:], "")
+	return i
+}
+
+// Description returns the text of the Description section of i's body,
+// without its heading and trailing newlines: "" when the section is
+// empty or missing.
+func (i Issue) Description() string {
+	lines := strings.SplitAfter(i.Body, "\n")
+	start, end := descriptionSection(lines)
+	if start < 0 {
+		return ""
+	}
+	return strings.TrimRight(strings.Join(lines[start+1:end], ""), "\n")
+}
+
+// descriptionSection returns the line span of the Description section:
+// the index of its heading and that of the next "## " heading (or
+// len(lines)). start is -1 when the body has no such heading.
+func descriptionSection(lines []string) (start, end int) {
+	start = -1
+	for n, line := range lines {
+		if strings.TrimRight(line, "\n") == descriptionHeading {
+			start = n
+			break
+		}
+	}
+	if start < 0 {
+		return -1, len(lines)
+	}
+	for n := start + 1; n < len(lines); n++ {
+		if strings.HasPrefix(lines[n], "## ") {
+			return start, n
+		}
+	}
+	return start, len(lines)
+}
@@ -0,0 +1,52 @@
+package issue_test
+
+import (
+	"testing"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+func TestSetDescription(t *testing.T) {
+	tests := []struct {
+		name, body, text, want string
+	}{
+		{"default body", issue.DefaultBody, "Buy at the market.", "\n## Description\nBuy at the market.\n## Notes\n## Comments\n"},
+		{"replaces the old text", "\n## Description\nold\nlines\n\n## Notes\nkeep\n## Comments\n", "new\n\n", "\n## Description\nnew\n## Notes\nkeep\n## Comments\n"},
+		{"multi-line text", issue.DefaultBody, "a\n\nb", "\n## Description\na\n\nb\n## Notes\n## Comments\n"},
+		{"empty text clears", "\n## Description\nold\n## Notes\n", "", "\n## Description\n## Notes\n"},
+		{"subheadings belong to the section", "\n## Description\n### step\nold\n## Notes\n", "new", "\n## Description\nnew\n## Notes\n"},
+		{"last section", "\n## Notes\n## Description\nold", "new", "\n## Notes\n## Description\nnew\n"},
+		{"no heading", "\n## Notes\nn\n", "d", "\n## Description\nd\n## Notes\nn\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := issue.Issue{Frontmatter: issue.Frontmatter{Title: "t"}, Body: tt.body}
+			got := i.SetDescription(tt.text)
+			if got.Body != tt.want {
+				t.Errorf("Body = %q, want %q", got.Body, tt.want)
+			}
+			if got.Frontmatter.Title != "t" || i.Body != tt.body {
+				t.Errorf("SetDescription touched the frontmatter or the receiver")
+			}
+		})
+	}
+}
+
+func TestDescription(t *testing.T) {
+	tests := []struct {
+		name, body, want string
+	}{
+		{"default body", issue.DefaultBody, ""},
+		{"text", "\n## Description\na\n\nb\n\n## Notes\nn\n", "a\n\nb"},
+		{"last section", "\n## Notes\n## Description\nd", "d"},
+		{"no heading", "\n## Notes\nn\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := issue.Issue{Body: tt.body}
+			if got := i.Description(); got != tt.want {
+				t.Errorf("Description() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package issue
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldNames are the built-in frontmatter keys, in canonical order. Any
+// other key is a custom field: kept in Frontmatter.Extra, and valid only
+// when the vault declares it.
+var FieldNames = []string{
+	"title", "status", "labels", "created_at",
+	"rank", "deferred_until", "deadline", "warn_before", "recur", "started_at", "work_log", "completed_at", "blocked_by",
+}
+
+// FieldType is the declared type of a custom frontmatter field.
+type FieldType string
+
+// The custom field types. An enum takes one of its declared values, a
+// datetime the NaiveLayout, a list a flow sequence of strings and an
+// id-ref the ID of another Issue of the vault.
+const (
+	TypeString   FieldType = "string"
+	TypeInt      FieldType = "int"
+	TypeEnum     FieldType = "enum"
+	TypeDatetime FieldType = "datetime"
+	TypeList     FieldType = "list"
+	TypeIDRef    FieldType = "id-ref"
+)
+
+// FieldTypes lists every custom field type.
+var FieldTypes = []FieldType{TypeString, TypeInt, TypeEnum, TypeDatetime, TypeList, TypeIDRef}
+
+// FieldSpec declares one custom frontmatter field of a vault, in mt.yaml:
+//
+//	fields:
+//	  - {name: energy, type: enum, values: [low, medium, high]}
+//	  - {name: url, type: string}
+//
+// The declaration order is the field order on disk, after the built-in
+// fields.
+type FieldSpec struct {
+	Name   string    `yaml:"name"`
+	Type   FieldType `yaml:"type"`
+	Values []string  `yaml:"values,flow,omitempty"`
+}
+
+// fieldName is the shape of a custom field name: a lowercase YAML key
+// that needs no quoting and reads as one --where field.
+var fieldName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ValidateFieldSpecs checks the custom field declarations of a vault: a
+// lowercase name (letters, digits, _) that is neither built-in nor
+// declared twice, a known type, and values for (and only for) an enum.
+func ValidateFieldSpecs(specs []FieldSpec) error {
+	seen := make(map[string]bool, len(specs))
+	for _, s := range specs {
+		switch {
+		case !fieldName.MatchString(s.Name):
+			return fmt.Errorf("invalid custom field name %q: want lowercase letters, digits and _", s.Name)
+		case slices.Contains(FieldNames, s.Name) || s.Name == "id":
+			return fmt.Errorf("custom field %q is a built-in field", s.Name)
+		case seen[s.Name]:
+			return fmt.Errorf("custom field %q declared twice", s.Name)
+		case !slices.Contains(FieldTypes, s.Type):
+			return fmt.Errorf("custom field %q has unknown type %q (want one of %s)", s.Name, s.Type, joinTypes())
+		case s.Type == TypeEnum && len(s.Values) == 0:
+			return fmt.Errorf("custom field %q is an enum without values", s.Name)
+		case s.Type != TypeEnum && len(s.Values) > 0:
+			return fmt.Errorf("custom field %q declares values but is not an enum", s.Name)
+		}
+		seen[s.Name] = true
+	}
+	return nil
+}
+
+// joinTypes lists FieldTypes for an error message.
+func joinTypes() string {
+	names := make([]string, len(FieldTypes))
+	for i, t := range FieldTypes {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Field is the value of one custom field of an Issue. Value is a string,
+// an int, a []string (a sequence of scalars) or nil (an empty key); any
+// other YAML scalar is kept as its text, and a nested mapping as decoded.
+// Checking the value against the field's type is mt check's job.
+type Field struct {
+	Name  string
+	Value any
+}
+
+// Fields are the custom fields of an Issue, in order.
+type Fields []Field
+
+// Get returns the value of the custom field name.
+func (fs Fields) Get(name string) (any, bool) {
+	for _, f := range fs {
+		if f.Name == name {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Order sorts fs in the declaration order of specs; undeclared fields go
+// last, in their current order.
+func (fs Fields) Order(specs []FieldSpec) {
+	rank := func(name string) int {
+		if at := slices.IndexFunc(specs, func(s FieldSpec) bool { return s.Name == name }); at >= 0 {
+			return at
+		}
+		return len(specs)
+	}
+	slices.SortStableFunc(fs, func(a, b Field) int { return rank(a.Name) - rank(b.Name) })
+}
+
+// IsEmpty reports whether v is an empty custom field value: nil, an
+// empty string or an empty list. Render omits such fields, as it does
+// the unset optional built-in ones.
+func IsEmpty(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []string:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// FormatValue renders a custom field value as text: a list is joined
+// with ", ", nil is empty.
+func FormatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// MarshalJSON encodes fs as one JSON object, keys in order.
+func (fs Fields) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range fs {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.Name)
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, fmt.Errorf("encoding custom field %s: %w", f.Name, err)
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// UnmarshalJSON decodes the object MarshalJSON writes, keeping the key
+// order and the value kinds of Field: integers as int, arrays of strings
+// as []string.
+func (fs *Fields) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return errors.New("custom fields must be a JSON object")
+	}
+	var out Fields
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		out = append(out, Field{Name: tok.(string), Value: jsonValue(v)})
+	}
+	*fs = out
+	return nil
+}
+
+// jsonValue maps a decoded JSON value to the value kinds of Field.
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := strconv.Atoi(v.String()); err == nil {
+			return n
+		}
+		return v.String()
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return v
+			}
+			list = append(list, s)
+		}
+		return list
+	default:
+		return v
+	}
+}
+
+// customFields returns the custom fields of a decoded frontmatter
+// mapping, in file order.
+func customFields(m *yaml.Node) (Fields, error) {
+	var fs Fields
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		name := m.Content[i].Value
+		if slices.Contains(FieldNames, name) {
+			continue
+		}
+		v, err := fieldValue(m.Content[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("parsing frontmatter field %s: %w", name, err)
+		}
+		fs = append(fs, Field{Name: name, Value: v})
+	}
+	return fs, nil
+}
+
+// fieldValue decodes one custom field value. Scalars other than integers
+// and nulls keep their text, so a date is not turned into a time.Time.
+func fieldValue(n *yaml.Node) (any, error) {
+	switch {
+	case n.Kind == yaml.ScalarNode && n.Tag == "!!null":
+		return nil, nil
+	case n.Kind == yaml.ScalarNode && n.Tag == "!!int":
+		if v, err := strconv.Atoi(n.Value); err == nil {
+			return v, nil
+		}
+		return n.Value, nil
+	case n.Kind == yaml.ScalarNode:
+		return n.Value, nil
+	case n.Kind == yaml.SequenceNode && !slices.ContainsFunc(n.Content, func(e *yaml.Node) bool { return e.Kind != yaml.ScalarNode }):
+		list := make([]string, len(n.Content))
+		for i, e := range n.Content {
+			list[i] = e.Value
+		}
+		return list, nil
+	}
+	var v any
+	err := n.Decode(&v)
+	return v, err
+}
+
+// appendFields appends the non-empty custom fields of fs to the
+// frontmatter mapping m, lists in flow style like labels.
+func appendFields(m *yaml.Node, fs Fields) error {
+	for _, f := range fs {
+		if IsEmpty(f.Value) {
+			continue
+		}
+		var v yaml.Node
+		if err := v.Encode(f.Value); err != nil {
+			return fmt.Errorf("encoding custom field %s: %w", f.Name, err)
+		}
+		if v.Kind == yaml.SequenceNode {
+			v.Style = yaml.FlowStyle
+		}
+		m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.Name}, &v)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package issue_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+const customExample = `---
+title: call the client
+status: open
+labels: []
+created_at: 2026-08-15T09:30
+energy: low
+estimate: 3
+contexts: [phone, desk]
+due_call: 2026-08-16T10:00
+---
+body
+`
+
+func TestCustomFieldsRoundTrip(t *testing.T) {
+	i, err := issue.Parse([]byte(customExample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := issue.Fields{
+		{Name: "energy", Value: "low"},
+		{Name: "estimate", Value: 3},
+		{Name: "contexts", Value: []string{"phone", "desk"}},
+		{Name: "due_call", Value: "2026-08-16T10:00"},
+	}
+	if !reflect.DeepEqual(i.Frontmatter.Extra, want) {
+		t.Errorf("Extra = %#v, want %#v", i.Frontmatter.Extra, want)
+	}
+	got, err := issue.Render(i)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != customExample {
+		t.Errorf("Render(Parse()) =\n%s\nwant\n%s", got, customExample)
+	}
+	fm, err := issue.ParseFrontmatter(strings.NewReader(customExample))
+	if err != nil || !reflect.DeepEqual(fm.Extra, want) {
+		t.Errorf("ParseFrontmatter().Extra = %#v, %v", fm.Extra, err)
+	}
+}
+
+func TestCustomFieldValueKinds(t *testing.T) {
+	data := "---\ntitle: t\nstatus: open\nlabels: []\ncreated_at: 2026-08-15T09:30\n" +
+		"empty:\nday: 2026-08-16\nflag: true\nbig: 99999999999999999999\nnested: [[a]]\nmap: {a: 1}\n---\n"
+	i, err := issue.Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := issue.Fields{
+		{Name: "empty", Value: nil},
+		{Name: "day", Value: "2026-08-16"},
+		{Name: "flag", Value: "true"},
+		{Name: "big", Value: "99999999999999999999"},
+		{Name: "nested", Value: []any{[]any{"a"}}},
+		{Name: "map", Value: map[string]any{"a": 1}},
+	}
+	if !reflect.DeepEqual(i.Frontmatter.Extra, want) {
+		t.Errorf("Extra = %#v, want %#v", i.Frontmatter.Extra, want)
+	}
+	out, err := issue.Render(i)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "empty") {
+		t.Errorf("Render kept an empty custom field:\n%s", out)
+	}
+}
+
+func TestRenderOmitsEmptyCustomFields(t *testing.T) {
+	i := issue.Issue{Frontmatter: issue.Frontmatter{
+		Title: "t", Status: "open", Labels: []string{}, CreatedAt: "2026-08-15T09:30",
+		Extra: issue.Fields{{Name: "a", Value: ""}, {Name: "b", Value: []string{}}, {Name: "c", Value: "x"}},
+	}}
+	out, err := issue.Render(i)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(out), "created_at: 2026-08-15T09:30\nc: x\n---\n") {
+		t.Errorf("Render =\n%s", out)
+	}
+}
+
+func TestFieldsJSONKeepsOrderAndKinds(t *testing.T) {
+	fm := issue.Frontmatter{Title: "t", Extra: issue.Fields{
+		{Name: "zeta", Value: "z"},
+		{Name: "estimate", Value: 3},
+		{Name: "contexts", Value: []string{"phone"}},
+		{Name: "ratio", Value: 1.5},
+		{Name: "mixed", Value: []any{"a", 1}},
+	}}
+	data, err := json.Marshal(fm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"extra":{"zeta":"z","estimate":3,"contexts":["phone"],"ratio":1.5,"mixed":["a",1]}`) {
+		t.Errorf("json = %s", data)
+	}
+	var back issue.Frontmatter
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	want := issue.Fields{
+		{Name: "zeta", Value: "z"},
+		{Name: "estimate", Value: 3},
+		{Name: "contexts", Value: []string{"phone"}},
+		{Name: "ratio", Value: "1.5"},
+		{Name: "mixed", Value: []any{"a", json.Number("1")}},
+	}
+	if !reflect.DeepEqual(back.Extra, want) {
+		t.Errorf("round-trip Extra = %#v, want %#v", back.Extra, want)
+	}
+	if data, _ := json.Marshal(issue.Frontmatter{}); strings.Contains(string(data), "extra") {
+		t.Errorf("json without custom fields = %s", data)
+	}
+	if err := json.Unmarshal([]byte(`{"extra":[1]}`), &back); err == nil {
+		t.Error("Unmarshal of a non-object extra succeeded")
+	}
+	if _, err := json.Marshal(issue.Fields{{Name: "f", Value: func() {}}}); err == nil {
+		t.Error("Marshal of an unencodable value succeeded")
+	}
+}
+
+func TestFieldsGetAndOrder(t *testing.T) {
+	fs := issue.Fields{{Name: "x", Value: "1"}, {Name: "url", Value: "u"}, {Name: "energy", Value: "low"}, {Name: "y", Value: "2"}}
+	if v, ok := fs.Get("url"); !ok || v != "u" {
+		t.Errorf("Get(url) = %v, %v", v, ok)
+	}
+	if _, ok := fs.Get("nope"); ok {
+		t.Error("Get(nope) found a value")
+	}
+	fs.Order([]issue.FieldSpec{{Name: "energy"}, {Name: "url"}})
+	var names []string
+	for _, f := range fs {
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, ","); got != "energy,url,x,y" {
+		t.Errorf("Order = %s, want energy,url,x,y", got)
+	}
+}
+
+func TestFormatValueAndIsEmpty(t *testing.T) {
+	for _, tt := range []struct {
+		v     any
+		text  string
+		empty bool
+	}{
+		{nil, "", true},
+		{"", "", true},
+		{[]string{}, "", true},
+		{"low", "low", false},
+		{3, "3", false},
+		{[]string{"a", "b"}, "a, b", false},
+	} {
+		if got := issue.FormatValue(tt.v); got != tt.text {
+			t.Errorf("FormatValue(%#v) = %q, want %q", tt.v, got, tt.text)
+		}
+		if got := issue.IsEmpty(tt.v); got != tt.empty {
+			t.Errorf("IsEmpty(%#v) = %v, want %v", tt.v, got, tt.empty)
+		}
+	}
+}
+
+func TestValidateFieldSpecs(t *testing.T) {
+	valid := []issue.FieldSpec{
+		{Name: "energy", Type: issue.TypeEnum, Values: []string{"low"}},
+		{Name: "client_2", Type: issue.TypeIDRef},
+	}
+	if err := issue.ValidateFieldSpecs(valid); err != nil {
+		t.Errorf("ValidateFieldSpecs(valid) = %v", err)
+	}
+	for _, tt := range []struct {
+		spec issue.FieldSpec
+		want string
+	}{
+		{issue.FieldSpec{Name: "Energy", Type: issue.TypeString}, "invalid custom field name"},
+		{issue.FieldSpec{Name: "", Type: issue.TypeString}, "invalid custom field name"},
+		{issue.FieldSpec{Name: "deadline", Type: issue.TypeDatetime}, "built-in"},
+		{issue.FieldSpec{Name: "id", Type: issue.TypeString}, "built-in"},
+		{issue.FieldSpec{Name: "energy", Type: issue.TypeString}, "declared twice"},
+		{issue.FieldSpec{Name: "size", Type: "float"}, `unknown type "float" (want one of string, int, enum, datetime, list, id-ref)`},
+		{issue.FieldSpec{Name: "size", Type: issue.TypeEnum}, "without values"},
+		{issue.FieldSpec{Name: "size", Type: issue.TypeInt, Values: []string{"1"}}, "not an enum"},
+	} {
+		err := issue.ValidateFieldSpecs(append(valid[:1:1], tt.spec))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ValidateFieldSpecs(%+v) = %v, want %q", tt.spec, err, tt.want)
+		}
+	}
+}
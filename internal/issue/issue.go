@@ -5,8 +5,10 @@
 package issue
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -14,28 +16,42 @@ import (
 
 // NaiveLayout is the canonical datetime layout of every Issue datetime
 // field (created_at, deferred_until, deadline, started_at, completed_at):
-// local time, naive (no timezone), no seconds — YYYY-MM-DDTHH:MM.
+// local time, naive (no timezone), no seconds — YYYY-MM-DDTHH:MM. A
+// vault's timezone setting (see Zone) may name the zone it is local to,
+// or switch to OffsetLayout.
 const NaiveLayout = "2006-01-02T15:04"
 
 // Frontmatter is the YAML header of an Issue file. The field order here
 // is the canonical order on disk; keep it in sync with the spec schema.
 //
 // Always present: title, status, labels, created_at. Present only when
-// they have a value: rank, deferred_until, deadline, started_at,
-// completed_at, blocked_by. There is no id (the file name is the
-// authority) and no updated_at (Git and mtime track that).
+// they have a value: rank, deferred_until, deadline, warn_before (the
+// Issue's own lead time for its deadline), recur (the period of a
+// recurring Issue), started_at, work_log (the start/end intervals of
+// the work, see Start and Pause), completed_at, blocked_by. There is no
+// id (the file name is the authority) and no updated_at (Git and mtime
+// track that). The JSON names, used by hook payloads, are the YAML keys.
+//
+// Extra holds the custom fields — any other key — in file order, after
+// the built-in ones on disk; a vault declares them in mt.yaml. In JSON
+// they are one "extra" object.
 type Frontmatter struct {
-	Title     string   `yaml:"title"`
-	Status    string   `yaml:"status"`
-	Labels    []string `yaml:"labels,flow"`
-	CreatedAt string   `yaml:"created_at"`
+	Title     string   `yaml:"title" json:"title"`
+	Status    string   `yaml:"status" json:"status"`
+	Labels    []string `yaml:"labels,flow" json:"labels"`
+	CreatedAt string   `yaml:"created_at" json:"created_at"`
+
+	Rank          *int     `yaml:"rank,omitempty" json:"rank,omitempty"`
+	DeferredUntil string   `yaml:"deferred_until,omitempty" json:"deferred_until,omitempty"`
+	Deadline      string   `yaml:"deadline,omitempty" json:"deadline,omitempty"`
+	WarnBefore    string   `yaml:"warn_before,omitempty" json:"warn_before,omitempty"`
+	Recur         string   `yaml:"recur,omitempty" json:"recur,omitempty"`
+	StartedAt     string   `yaml:"started_at,omitempty" json:"started_at,omitempty"`
+	WorkLog       []string `yaml:"work_log,flow,omitempty" json:"work_log,omitempty"`
+	CompletedAt   string   `yaml:"completed_at,omitempty" json:"completed_at,omitempty"`
+	BlockedBy     []string `yaml:"blocked_by,flow,omitempty" json:"blocked_by,omitempty"`
 
-	Rank          *int     `yaml:"rank,omitempty"`
-	DeferredUntil string   `yaml:"deferred_until,omitempty"`
-	Deadline      string   `yaml:"deadline,omitempty"`
-	StartedAt     string   `yaml:"started_at,omitempty"`
-	CompletedAt   string   `yaml:"completed_at,omitempty"`
-	BlockedBy     []string `yaml:"blocked_by,flow,omitempty"`
+	Extra Fields `yaml:"-" json:"extra,omitempty"`
 }
 
 // Issue is one unit of work: the frontmatter plus the Markdown body
@@ -53,8 +69,14 @@ const DefaultBody = "\n## Description\n## Notes\n## Comments\n"
 
 // Render serializes an Issue to the on-disk form: a --- delimiter, the
 // frontmatter mapping, a closing --- delimiter, then the body verbatim.
+// Custom fields follow the built-in ones, in Extra order; empty ones are
+// omitted.
 func Render(i Issue) ([]byte, error) {
-	fm, err := yaml.Marshal(i.Frontmatter)
+	m, err := frontmatterNode(i.Frontmatter)
+	if err != nil {
+		return nil, err
+	}
+	fm, err := yaml.Marshal(m)
 	if err != nil {
 		return nil, fmt.Errorf("encoding frontmatter: %w", err)
 	}
@@ -66,6 +88,18 @@ func Render(i Issue) ([]byte, error) {
 	return []byte(b.String()), nil
 }
 
+// frontmatterNode encodes fm as the YAML mapping Render writes.
+func frontmatterNode(fm Frontmatter) (*yaml.Node, error) {
+	var m yaml.Node
+	if err := m.Encode(fm); err != nil {
+		return nil, fmt.Errorf("encoding frontmatter: %w", err)
+	}
+	if err := appendFields(&m, fm.Extra); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
 // Parse reads an Issue from its on-disk form: a leading --- line, the
 // frontmatter mapping, a closing --- line, then the body (returned
 // verbatim, including any trailing newline).
@@ -74,7 +108,7 @@ func Parse(data []byte) (Issue, error) {
 	// for empty input), so lines[0] is safe and covers the empty case.
 	lines := strings.Split(string(data), "\n")
 	if lines[0] != "---" {
-		return Issue{}, errors.New("issue file must start with a --- frontmatter delimiter")
+		return Issue{}, errNoOpening
 	}
 	// Find the closing --- delimiter. ok disambiguates "found at index
 	// end" from "not found": a valid end index is always >= 1, so a
@@ -89,11 +123,69 @@ func Parse(data []byte) (Issue, error) {
 		}
 	}
 	if !ok {
-		return Issue{}, errors.New("frontmatter is not closed with a --- delimiter")
+		return Issue{}, errNotClosed
 	}
-	var fm Frontmatter
-	if err := yaml.Unmarshal([]byte(strings.Join(lines[1:end], "\n")), &fm); err != nil {
-		return Issue{}, fmt.Errorf("parsing frontmatter: %w", err)
+	fm, err := decodeFrontmatter(strings.Join(lines[1:end], "\n"))
+	if err != nil {
+		return Issue{}, err
 	}
 	return Issue{Frontmatter: fm, Body: strings.Join(lines[end+1:], "\n")}, nil
 }
+
+// ParseFrontmatter reads only the frontmatter of an Issue from r: it
+// stops at the closing --- line, never reading the body, for the views
+// that list Issues without showing them. It accepts and rejects exactly
+// what Parse does, with the same errors.
+func ParseFrontmatter(r io.Reader) (Frontmatter, error) {
+	br := bufio.NewReader(r)
+	var b strings.Builder
+	for n := 0; ; n++ {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return Frontmatter{}, fmt.Errorf("reading frontmatter: %w", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case n == 0 && line != "---":
+			return Frontmatter{}, errNoOpening
+		case n > 0 && line == "---":
+			return decodeFrontmatter(strings.TrimSuffix(b.String(), "\n"))
+		case n > 0:
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+		if err == io.EOF {
+			return Frontmatter{}, errNotClosed
+		}
+	}
+}
+
+var (
+	errNoOpening = errors.New("issue file must start with a --- frontmatter delimiter")
+	errNotClosed = errors.New("frontmatter is not closed with a --- delimiter")
+)
+
+// decodeFrontmatter decodes the YAML between the delimiters, keeping
+// every key that is not a built-in field in Extra.
+func decodeFrontmatter(yml string) (Frontmatter, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(yml), &doc); err != nil {
+		return Frontmatter{}, fmt.Errorf("parsing frontmatter: %w", err)
+	}
+	var fm Frontmatter
+	if len(doc.Content) == 0 {
+		return fm, nil
+	}
+	m := doc.Content[0]
+	if err := m.Decode(&fm); err != nil {
+		return Frontmatter{}, fmt.Errorf("parsing frontmatter: %w", err)
+	}
+	if m.Kind == yaml.MappingNode {
+		extra, err := customFields(m)
+		if err != nil {
+			return Frontmatter{}, err
+		}
+		fm.Extra = extra
+	}
+	return fm, nil
+}
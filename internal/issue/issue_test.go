@@ -5,6 +5,8 @@ package issue_test
 
 import (
 	"bytes"
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -225,3 +227,76 @@ func TestParseEmptyBody(t *testing.T) {
 		t.Errorf("Body = %q, want empty", i.Body)
 	}
 }
+
+// failAfter is a reader that yields data, then fails: a parser that
+// reads past data sees the error.
+type failAfter struct{ data *strings.Reader }
+
+func (r failAfter) Read(p []byte) (int, error) {
+	if r.data.Len() == 0 {
+		return 0, errors.New("read past the frontmatter")
+	}
+	return r.data.Read(p)
+}
+
+func TestParseFrontmatterAgreesWithParse(t *testing.T) {
+	for _, in := range []string{
+		specExample,
+		"---\ntitle: t\nstatus: open\nlabels: []\ncreated_at: 2026-08-15T09:30\n---",
+		"---\n---\n",
+		"---\n\ntitle: t\n\n---\nbody\n---\n",
+		"",
+		"---",
+		"title: t\n---\nbody\n",
+		"---\r\ntitle: t\r\n---\r\n",
+		"---\ntitle: t\n",
+		"---\ntitle: [unclosed\n---\n",
+	} {
+		want, wantErr := issue.Parse([]byte(in))
+		got, err := issue.ParseFrontmatter(strings.NewReader(in))
+		if (err == nil) != (wantErr == nil) || err != nil && err.Error() != wantErr.Error() {
+			t.Errorf("ParseFrontmatter(%q) error = %v, Parse error = %v", in, err, wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, want.Frontmatter) {
+			t.Errorf("ParseFrontmatter(%q) = %+v, Parse = %+v", in, got, want.Frontmatter)
+		}
+	}
+}
+
+func TestParseFrontmatterStopsAtTheClosingDelimiter(t *testing.T) {
+	head := "---\ntitle: t\nstatus: open\nlabels: []\ncreated_at: 2026-08-15T09:30\n---\n"
+	fm, err := issue.ParseFrontmatter(failAfter{strings.NewReader(head)})
+	if err != nil {
+		t.Fatalf("ParseFrontmatter read past the frontmatter: %v", err)
+	}
+	if fm.Title != "t" {
+		t.Errorf("Title = %q", fm.Title)
+	}
+	_, err = issue.ParseFrontmatter(failAfter{strings.NewReader("---\ntitle: t\n")})
+	if err == nil || !strings.Contains(err.Error(), "reading frontmatter") {
+		t.Errorf("ParseFrontmatter on a failing reader = %v, want a read error", err)
+	}
+}
+
+// benchIssue is an Issue with a long comment thread, the case the
+// frontmatter-only parse is for.
+var benchIssue = specExample + strings.Repeat("### 2026-08-15T09:30\nsome comment text, a line of it\n", 500)
+
+func BenchmarkParse(b *testing.B) {
+	data := []byte(benchIssue)
+	for b.Loop() {
+		if _, err := issue.Parse(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseFrontmatter(b *testing.B) {
+	data := []byte(benchIssue)
+	for b.Loop() {
+		if _, err := issue.ParseFrontmatter(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package issue
+
+import "slices"
+
+// AddLabel returns i with label appended to labels, unless it is already
+// listed. The edit is idempotent, and every other field is untouched.
+func (i Issue) AddLabel(label string) Issue {
+	if slices.Contains(i.Frontmatter.Labels, label) {
+		return i
+	}
+	i.Frontmatter.Labels = append(slices.Clone(i.Frontmatter.Labels), label)
+	return i
+}
+
+// RemoveLabel returns i with every occurrence of label removed from
+// labels. The edit is idempotent: a label i does not carry leaves it
+// untouched. Removing the last label leaves an empty list, which Render
+// keeps as labels: [] — the field is always present.
+func (i Issue) RemoveLabel(label string) Issue {
+	out := make([]string, 0, len(i.Frontmatter.Labels))
+	for _, l := range i.Frontmatter.Labels {
+		if l != label {
+			out = append(out, l)
+		}
+	}
+	i.Frontmatter.Labels = out
+	return i
+}
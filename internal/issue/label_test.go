@@ -0,0 +1,58 @@
+package issue_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+func TestAddLabelAppendsAndLeavesRestUntouched(t *testing.T) {
+	i := populated()
+	got := i.AddLabel("errands")
+
+	if !slices.Equal(got.Frontmatter.Labels, []string{"a", "errands"}) {
+		t.Errorf("Labels = %v, want [a errands]", got.Frontmatter.Labels)
+	}
+	if got.Frontmatter.Title != "t" || got.Frontmatter.Status != "in_progress" ||
+		got.Frontmatter.Rank == nil || *got.Frontmatter.Rank != 2 || got.Frontmatter.Deadline != "2026-08-22T18:00" {
+		t.Errorf("AddLabel changed unrelated fields: %+v", got.Frontmatter)
+	}
+	if !slices.Equal(i.Frontmatter.Labels, []string{"a"}) {
+		t.Errorf("AddLabel mutated the receiver: %v", i.Frontmatter.Labels)
+	}
+}
+
+func TestAddLabelIsIdempotent(t *testing.T) {
+	if got := populated().AddLabel("a"); !slices.Equal(got.Frontmatter.Labels, []string{"a"}) {
+		t.Errorf("Labels = %v, want [a] once", got.Frontmatter.Labels)
+	}
+}
+
+func TestRemoveLabel(t *testing.T) {
+	i := populated().AddLabel("b").AddLabel("c")
+	if got := i.RemoveLabel("b"); !slices.Equal(got.Frontmatter.Labels, []string{"a", "c"}) {
+		t.Errorf("Labels = %v, want [a c]", got.Frontmatter.Labels)
+	}
+	if got := i.RemoveLabel("zzz"); !slices.Equal(got.Frontmatter.Labels, []string{"a", "b", "c"}) {
+		t.Errorf("RemoveLabel(absent) = %v, want untouched", got.Frontmatter.Labels)
+	}
+	if !slices.Equal(i.Frontmatter.Labels, []string{"a", "b", "c"}) {
+		t.Errorf("RemoveLabel mutated the receiver: %v", i.Frontmatter.Labels)
+	}
+}
+
+func TestRemoveLastLabelRendersEmptyList(t *testing.T) {
+	got := populated().RemoveLabel("a")
+	if got.Frontmatter.Labels == nil || len(got.Frontmatter.Labels) != 0 {
+		t.Fatalf("Labels = %#v, want an empty list", got.Frontmatter.Labels)
+	}
+	data, err := issue.Render(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "labels: []") {
+		t.Errorf("rendered issue misses labels: []:\n%s", data)
+	}
+}
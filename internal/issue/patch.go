@@ -0,0 +1,199 @@
+package issue
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Patch renders i as an edit of original, the Issue file it was read
+// from, so a mutation leaves what the user wrote by hand alone: only the
+// frontmatter keys whose value changed are rewritten (one line each for
+// the single-line values mt writes), keys that became empty are dropped
+// and new keys are inserted after their canonical predecessor. Comments,
+// blank lines, key order, quoting and flow/block styles of the untouched
+// keys are kept byte for byte, as is a trailing comment on a rewritten
+// line.
+//
+// The keys are located through the yaml.Node of the original; the edit
+// is then spliced into its lines. When the original cannot be patched —
+// a flow mapping, several keys on one line, anchors or merge keys — or
+// the patched file would not read back as i, Patch falls back to Render.
+func Patch(original []byte, i Issue) ([]byte, error) {
+	rendered, err := Render(i)
+	if err != nil {
+		return nil, err
+	}
+	patched, ok := patchFrontmatter(original, i)
+	if !ok {
+		return rendered, nil
+	}
+	// The splice must mean exactly what Render would write.
+	back, err := Parse(patched)
+	if err != nil {
+		return rendered, nil
+	}
+	if again, err := Render(back); err != nil || !bytes.Equal(again, rendered) {
+		return rendered, nil
+	}
+	return patched, nil
+}
+
+// entry is one key of the original frontmatter: its lines, start to end
+// inclusive, and its value node.
+type entry struct {
+	key        string
+	start, end int
+	value      *yaml.Node
+}
+
+// patchFrontmatter splices the changed keys of i into original. ok is
+// false when original is not a patchable Issue file.
+func patchFrontmatter(original []byte, i Issue) ([]byte, bool) {
+	old, err := Parse(original)
+	if err != nil {
+		return nil, false
+	}
+	lines := strings.Split(string(original), "\n")
+	closing := 1
+	for lines[closing] != "---" {
+		closing++ // Parse found it
+	}
+	lines = lines[1:closing]
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &doc); err != nil || len(doc.Content) == 0 {
+		return nil, false
+	}
+	m := doc.Content[0]
+	if m.Kind != yaml.MappingNode || m.Style&yaml.FlowStyle != 0 {
+		return nil, false
+	}
+	entries, ok := entriesOf(m, lines)
+	if !ok {
+		return nil, false
+	}
+	oldChunks, _, err := chunks(old.Frontmatter)
+	if err != nil {
+		return nil, false
+	}
+	newChunks, order, err := chunks(i.Frontmatter)
+	if err != nil {
+		return nil, false
+	}
+
+	// Keys new to the file go after the closest key that precedes them
+	// in the canonical order and is already there; top when none is.
+	at := make(map[string]int, len(entries))
+	for n, e := range entries {
+		at[e.key] = n
+	}
+	var top []string
+	after := make(map[int][]string)
+	anchor := -1
+	for _, key := range order {
+		if n, ok := at[key]; ok {
+			anchor = n
+			continue
+		}
+		if anchor < 0 {
+			top = append(top, newChunks[key]...)
+		} else {
+			after[anchor] = append(after[anchor], newChunks[key]...)
+		}
+	}
+
+	var out []string
+	cursor := 0
+	for n, e := range entries {
+		out = append(out, lines[cursor:e.start]...)
+		if n == 0 {
+			out = append(out, top...)
+		}
+		newChunk, isNew := newChunks[e.key]
+		oldChunk, wasSet := oldChunks[e.key]
+		switch {
+		case isNew == wasSet && slices.Equal(newChunk, oldChunk):
+			out = append(out, lines[e.start:e.end+1]...)
+		case !isNew:
+			// The key became empty: dropped, as Render omits it.
+		case len(newChunk) == 1 && e.value.LineComment != "":
+			out = append(out, newChunk[0]+" "+e.value.LineComment)
+		default:
+			out = append(out, newChunk...)
+		}
+		out = append(out, after[n]...)
+		cursor = e.end + 1
+	}
+	out = append(out, lines[cursor:]...)
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	for _, line := range out {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	b.WriteString("---\n")
+	b.WriteString(i.Body)
+	return []byte(b.String()), true
+}
+
+// entriesOf locates the keys of the mapping m in lines, the frontmatter
+// text it was decoded from. A key's entry runs from its line to the line
+// before the next key, less the blank and comment lines that lead into
+// the next key. ok is false unless every key is a plain scalar on its own
+// line.
+func entriesOf(m *yaml.Node, lines []string) ([]entry, bool) {
+	var entries []entry
+	for n := 0; n+1 < len(m.Content); n += 2 {
+		k := m.Content[n]
+		if k.Kind != yaml.ScalarNode || k.Value == "<<" || m.Content[n+1].Kind == yaml.AliasNode || m.Content[n+1].Anchor != "" {
+			return nil, false
+		}
+		start := k.Line - 1
+		if len(entries) > 0 && start <= entries[len(entries)-1].start {
+			return nil, false
+		}
+		entries = append(entries, entry{key: k.Value, start: start, value: m.Content[n+1]})
+	}
+	for n := range entries {
+		end := len(lines) - 1
+		if n+1 < len(entries) {
+			end = entries[n+1].start - 1
+		}
+		for end > entries[n].start && isFiller(lines[end]) {
+			end--
+		}
+		entries[n].end = end
+	}
+	return entries, len(entries) > 0
+}
+
+// isFiller reports whether line is blank or a whole-line comment.
+func isFiller(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "" || strings.HasPrefix(line, "#")
+}
+
+// chunks renders each key of fm as Render would write it, one slice of
+// lines per key, with the keys in Render's order.
+func chunks(fm Frontmatter) (map[string][]string, []string, error) {
+	m, err := frontmatterNode(fm)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make(map[string][]string, len(m.Content)/2)
+	order := make([]string, 0, len(m.Content)/2)
+	for n := 0; n+1 < len(m.Content); n += 2 {
+		one := &yaml.Node{Kind: yaml.MappingNode, Content: m.Content[n : n+2]}
+		data, err := yaml.Marshal(one)
+		if err != nil {
+			return nil, nil, err
+		}
+		key := m.Content[n].Value
+		out[key] = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		order = append(order, key)
+	}
+	return out, order, nil
+}
@@ -0,0 +1,143 @@
+package issue_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the patch tests")
+
+// patchCases mutate the Issue files of testdata/patch; each result is
+// compared to testdata/patch/<name>.golden. removed and added are the
+// line counts of the expected diff, -1 to skip the count.
+var patchCases = []struct {
+	name           string
+	file           string
+	mutate         func(*issue.Frontmatter)
+	removed, added int
+}{
+	{"status", "hand-written.md", func(fm *issue.Frontmatter) { fm.Status = "done" }, 1, 1},
+	{"title", "hand-written.md", func(fm *issue.Frontmatter) { fm.Title = "Ligar para o cliente hoje" }, 1, 1},
+	{"defer", "hand-written.md", func(fm *issue.Frontmatter) { fm.DeferredUntil = "2026-08-20T08:00" }, 0, 1},
+	{"unrank", "hand-written.md", func(fm *issue.Frontmatter) { fm.Rank = nil }, 1, 0},
+	{"custom", "hand-written.md", func(fm *issue.Frontmatter) { fm.Extra[0].Value = "high" }, 1, 1},
+	{"unchanged", "hand-written.md", func(*issue.Frontmatter) {}, 0, 0},
+	{"labels", "hand-written.md", func(fm *issue.Frontmatter) { fm.Labels = append(fm.Labels, "casa") }, 3, 1},
+	{"flow-mapping", "flow-mapping.md", func(fm *issue.Frontmatter) { fm.Status = "done" }, -1, -1},
+}
+
+func TestPatchGolden(t *testing.T) {
+	for _, tt := range patchCases {
+		t.Run(tt.name, func(t *testing.T) {
+			original, err := os.ReadFile(filepath.Join("testdata", "patch", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			i, err := issue.Parse(original)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.mutate(&i.Frontmatter)
+			got, err := issue.Patch(original, i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", "patch", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("Patch() =\n%s\nwant (%s)\n%s", got, golden, want)
+			}
+			back, err := issue.Parse(got)
+			if err != nil {
+				t.Fatalf("Parse(Patch()) = %v", err)
+			}
+			if a, b := mustRender(t, back), mustRender(t, i); a != b {
+				t.Errorf("Patch() reads back as\n%s\nwant\n%s", a, b)
+			}
+			if tt.removed >= 0 {
+				removed, added := lineDiff(string(original), string(got))
+				if removed != tt.removed || added != tt.added {
+					t.Errorf("diff = -%d +%d lines, want -%d +%d", removed, added, tt.removed, tt.added)
+				}
+			}
+		})
+	}
+}
+
+func TestPatchFallsBackToRender(t *testing.T) {
+	i := issue.Issue{Frontmatter: issue.Frontmatter{Title: "t", Status: "done", Labels: []string{}, CreatedAt: "2026-08-15T09:30"}, Body: "body\n"}
+	want := mustRender(t, i)
+	for name, original := range map[string]string{
+		"not an issue": "no frontmatter\n",
+		"anchor":       "---\ntitle: t\nstatus: open\nlabels: &l []\ncreated_at: 2026-08-15T09:30\n---\nbody\n",
+		"alias":        "---\ntitle: &t t\nstatus: open\nlabels: []\ncreated_at: 2026-08-15T09:30\nx: *t\n---\nbody\n",
+		"non-mapping":  "---\n---\nbody\n",
+	} {
+		got, err := issue.Patch([]byte(original), i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("Patch(%s) =\n%s\nwant the full render\n%s", name, got, want)
+		}
+	}
+}
+
+func TestPatchDropsKeysThatBecameEmpty(t *testing.T) {
+	// A nested flow mapping is a value, not a flow frontmatter: the file
+	// is still patched, the dropped key's lines go and the rest stay.
+	original := "---\ntitle: t   # kept\nstatus: open\nlabels: []\ncreated_at: 2026-08-15T09:30\nx: {a: 1}\n---\nbody\n"
+	i, err := issue.Parse([]byte(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	i.Frontmatter.Extra = nil
+	got, err := issue.Patch([]byte(original), i)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "---\ntitle: t   # kept\nstatus: open\nlabels: []\ncreated_at: 2026-08-15T09:30\n---\nbody\n"; string(got) != want {
+		t.Errorf("Patch() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func mustRender(t *testing.T, i issue.Issue) string {
+	t.Helper()
+	data, err := issue.Render(i)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// lineDiff counts the lines a minimal diff of a to b removes and adds.
+func lineDiff(a, b string) (removed, added int) {
+	x, y := strings.Split(a, "\n"), strings.Split(b, "\n")
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return len(x) - lcs[0][0], len(y) - lcs[0][0]
+}
@@ -0,0 +1,28 @@
+package issue
+
+// Occurrence returns the next occurrence of the recurring Issue i: a
+// fresh open Issue with i's title, labels, warn_before, recur and
+// Description, created at createdAt, with the deadline and
+// deferred_until given — i's own, each advanced by one period (see
+// internal/deferral.Recur). Rank, blockers, status stamps, notes and
+// comments belong to the occurrence closed, so none is carried over.
+func (i Issue) Occurrence(createdAt, deadline, deferredUntil string) Issue {
+	fm := i.Frontmatter
+	next := Issue{
+		Frontmatter: Frontmatter{
+			Title:         fm.Title,
+			Status:        "open",
+			Labels:        append([]string{}, fm.Labels...),
+			CreatedAt:     createdAt,
+			DeferredUntil: deferredUntil,
+			Deadline:      deadline,
+			WarnBefore:    fm.WarnBefore,
+			Recur:         fm.Recur,
+		},
+		Body: DefaultBody,
+	}
+	if text := i.Description(); text != "" {
+		next = next.SetDescription(text)
+	}
+	return next
+}
@@ -0,0 +1,57 @@
+package issue_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+func TestOccurrenceCopiesTheRecurringFields(t *testing.T) {
+	i := populated().Done("2026-08-22T12:00")
+	i.Frontmatter.WarnBefore = "1d"
+	i.Frontmatter.Recur = "1w"
+	i.Frontmatter.BlockedBy = []string{"pkm-002"}
+	i = i.SetDescription("Water every plant.")
+	i.Body += "<!-- comment: abc -->\n"
+
+	got := i.Occurrence("2026-08-22T12:00", "2026-08-29T18:00", "2026-08-27T08:00")
+	want := issue.Frontmatter{
+		Title:         "t",
+		Status:        "open",
+		Labels:        []string{"a"},
+		CreatedAt:     "2026-08-22T12:00",
+		DeferredUntil: "2026-08-27T08:00",
+		Deadline:      "2026-08-29T18:00",
+		WarnBefore:    "1d",
+		Recur:         "1w",
+	}
+	if !reflect.DeepEqual(got.Frontmatter, want) {
+		t.Errorf("Occurrence() frontmatter = %+v, want %+v", got.Frontmatter, want)
+	}
+	if want := "\n## Description\nWater every plant.\n## Notes\n## Comments\n"; got.Body != want {
+		t.Errorf("Occurrence() body = %q, want %q", got.Body, want)
+	}
+	// The labels are a copy: editing the occurrence leaves i alone.
+	got.Frontmatter.Labels[0] = "b"
+	if i.Frontmatter.Labels[0] != "a" {
+		t.Errorf("Occurrence shares the labels of the receiver")
+	}
+}
+
+func TestOccurrenceWithoutDescriptionKeepsTheDefaultBody(t *testing.T) {
+	i := populated()
+	i.Frontmatter.Recur = "1d"
+	got := i.Occurrence("2026-08-22T12:00", "", "")
+	if got.Body != issue.DefaultBody {
+		t.Errorf("Occurrence() body = %q, want the default", got.Body)
+	}
+	data, err := issue.Render(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "recur: 1d\n") || strings.Contains(string(data), "deadline") {
+		t.Errorf("rendered occurrence:\n%s", data)
+	}
+}
@@ -1,26 +1,36 @@
 package issue
 
+import "strings"
+
 // The status-transition rules of an Issue. They encode the only special
 // behaviors of the spec: done is terminal (stamps completed_at), reopen
-// clears the work timestamps, and pick-next starts an Issue (stamps
-// started_at). Everything else — the free transition of `mt status` — is
-// a bare field write with no timestamps and no state machine.
+// clears the work timestamps, pick-next and start start an Issue (stamp
+// started_at, open a work_log interval), and pause puts it back (closes
+// the interval). Everything else — the free transition of `mt status` —
+// is a bare field write with no timestamps and no state machine.
+
+// WorkSep separates the start and the end of a closed work_log interval:
+// "2026-08-15T09:00/2026-08-15T11:30". An open interval — the work going
+// on — is its start alone.
+const WorkSep = "/"
 
-// Done returns i closed: status "done" and completed_at stamped with
-// now. started_at is preserved — done records completion, not a fresh
-// start.
+// Done returns i closed: status "done", completed_at stamped with now and
+// an open work_log interval closed at now. started_at is preserved — done
+// records completion, not a fresh start.
 func (i Issue) Done(now string) Issue {
 	i.Frontmatter.Status = "done"
 	i.Frontmatter.CompletedAt = now
+	i.Frontmatter.WorkLog = closeWork(i.Frontmatter.WorkLog, now)
 	return i
 }
 
-// Reopen returns i reopened: status "open" with completed_at and
-// started_at cleared. Every other field is untouched.
+// Reopen returns i reopened: status "open" with completed_at, started_at
+// and the work_log cleared. Every other field is untouched.
 func (i Issue) Reopen() Issue {
 	i.Frontmatter.Status = "open"
 	i.Frontmatter.CompletedAt = ""
 	i.Frontmatter.StartedAt = ""
+	i.Frontmatter.WorkLog = nil
 	return i
 }
 
@@ -31,10 +41,51 @@ func (i Issue) SetStatus(s string) Issue {
 	return i
 }
 
-// Start returns i started: status "in_progress" and started_at stamped
-// with now. It is the special transition used by `mt pick-next`.
+// Start returns i started: status "in_progress", started_at stamped with
+// now and a work_log interval opened at now. It is the special
+// transition of `mt pick-next` and `mt start`. An Issue resuming work —
+// paused, with intervals already logged — keeps its started_at: it
+// records when the work first began. An interval already open stays the
+// only one.
 func (i Issue) Start(now string) Issue {
-	i.Frontmatter.Status = "in_progress"
-	i.Frontmatter.StartedAt = now
+	fm := &i.Frontmatter
+	fm.Status = "in_progress"
+	if len(fm.WorkLog) == 0 || fm.StartedAt == "" {
+		fm.StartedAt = now
+	}
+	if !workOpen(fm.WorkLog) {
+		fm.WorkLog = append(fm.WorkLog[:len(fm.WorkLog):len(fm.WorkLog)], now)
+	}
+	return i
+}
+
+// Pause returns i put back: status "open" and the open work_log interval
+// closed at now, with started_at kept, unlike Reopen, so a later Start
+// resumes the work rather than restarting it. An Issue started before it
+// had a work_log gets its first interval from started_at. Every other
+// field is untouched.
+func (i Issue) Pause(now string) Issue {
+	fm := &i.Frontmatter
+	fm.Status = "open"
+	if len(fm.WorkLog) == 0 && fm.StartedAt != "" {
+		fm.WorkLog = []string{fm.StartedAt}
+	}
+	fm.WorkLog = closeWork(fm.WorkLog, now)
 	return i
 }
+
+// workOpen reports whether the last interval of log is open.
+func workOpen(log []string) bool {
+	return len(log) > 0 && !strings.Contains(log[len(log)-1], WorkSep)
+}
+
+// closeWork returns log with its open interval, if any, closed at now. A
+// log without one is returned as is.
+func closeWork(log []string, now string) []string {
+	if !workOpen(log) {
+		return log
+	}
+	closed := append([]string{}, log...)
+	closed[len(closed)-1] += WorkSep + now
+	return closed
+}
@@ -1,6 +1,7 @@
 package issue_test
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
@@ -111,6 +112,83 @@ func TestStartStampsStartedAtAndSetsInProgress(t *testing.T) {
 	}
 }
 
+func TestStartKeepsAnEarlierStartedAt(t *testing.T) {
+	got := populated().Pause("2026-08-21T11:00").Start("2026-08-22T12:00")
+	if got.Frontmatter.Status != "in_progress" || got.Frontmatter.StartedAt != "2026-08-21T09:00" {
+		t.Errorf("Start after Pause = status %q, started_at %q; want in_progress, the first start kept",
+			got.Frontmatter.Status, got.Frontmatter.StartedAt)
+	}
+}
+
+func TestStartOpensAWorkInterval(t *testing.T) {
+	got := populated().Reopen().Start("2026-08-22T12:00")
+	if want := []string{"2026-08-22T12:00"}; !reflect.DeepEqual(got.Frontmatter.WorkLog, want) {
+		t.Errorf("WorkLog = %q, want %q", got.Frontmatter.WorkLog, want)
+	}
+	// Starting again while the interval is open opens no other.
+	again := got.Start("2026-08-22T13:00")
+	if want := []string{"2026-08-22T12:00"}; !reflect.DeepEqual(again.Frontmatter.WorkLog, want) {
+		t.Errorf("WorkLog after a second Start = %q, want %q", again.Frontmatter.WorkLog, want)
+	}
+}
+
+func TestPauseSetsOpenAndKeepsStartedAt(t *testing.T) {
+	i := populated()
+	got := i.Pause("2026-08-21T11:00")
+
+	if got.Frontmatter.Status != "open" {
+		t.Errorf("Status = %q, want %q", got.Frontmatter.Status, "open")
+	}
+	if got.Frontmatter.StartedAt != "2026-08-21T09:00" {
+		t.Errorf("StartedAt = %q, want it kept", got.Frontmatter.StartedAt)
+	}
+	// Started before it had a work_log: the interval runs from started_at.
+	if want := []string{"2026-08-21T09:00/2026-08-21T11:00"}; !reflect.DeepEqual(got.Frontmatter.WorkLog, want) {
+		t.Errorf("WorkLog = %q, want %q", got.Frontmatter.WorkLog, want)
+	}
+	if got.Frontmatter.Title != "t" || got.Frontmatter.Rank == nil || *got.Frontmatter.Rank != 2 || got.Frontmatter.Deadline != "2026-08-22T18:00" {
+		t.Errorf("Pause changed unrelated fields: %+v", got.Frontmatter)
+	}
+	if i.Frontmatter.Status != "in_progress" || i.Frontmatter.WorkLog != nil {
+		t.Errorf("Pause mutated the receiver: %+v", i.Frontmatter)
+	}
+}
+
+func TestWorkLogAccumulatesAndRoundTrips(t *testing.T) {
+	i := populated().Reopen().
+		Start("2026-08-15T09:00").Pause("2026-08-15T11:30").
+		Start("2026-08-16T14:00").Pause("2026-08-16T15:00").
+		Start("2026-08-17T10:00")
+	want := []string{"2026-08-15T09:00/2026-08-15T11:30", "2026-08-16T14:00/2026-08-16T15:00", "2026-08-17T10:00"}
+	if !reflect.DeepEqual(i.Frontmatter.WorkLog, want) || i.Frontmatter.StartedAt != "2026-08-15T09:00" {
+		t.Fatalf("WorkLog = %q, started_at %q; want %q from the first start", i.Frontmatter.WorkLog, i.Frontmatter.StartedAt, want)
+	}
+	data, err := issue.Render(i)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "work_log: ['2026-08-15T09:00/2026-08-15T11:30', '2026-08-16T14:00/2026-08-16T15:00', '2026-08-17T10:00']\n") {
+		t.Errorf("rendered work_log:\n%s", data)
+	}
+	back, err := issue.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.Frontmatter.WorkLog, want) {
+		t.Errorf("parsed WorkLog = %q, want %q", back.Frontmatter.WorkLog, want)
+	}
+	done := back.Done("2026-08-17T12:00")
+	if got := done.Frontmatter.WorkLog[2]; got != "2026-08-17T10:00/2026-08-17T12:00" {
+		t.Errorf("Done left the last interval %q, want it closed", got)
+	}
+	if back.Frontmatter.WorkLog[2] != "2026-08-17T10:00" {
+		t.Errorf("Done mutated the receiver's work_log: %q", back.Frontmatter.WorkLog)
+	}
+	if got := done.Reopen().Frontmatter.WorkLog; got != nil {
+		t.Errorf("Reopen kept WorkLog %q, want it cleared", got)
+	}
+}
+
 func TestStartRendersStartedAt(t *testing.T) {
 	got, err := issue.Render(populated().Start("2026-08-22T12:00"))
 	if err != nil {
@@ -0,0 +1,89 @@
+package issue
+
+import (
+	"fmt"
+	"time"
+)
+
+// OffsetLayout is the datetime layout of a vault in offset mode: the
+// naive layout plus the UTC offset of the moment it was written —
+// YYYY-MM-DDTHH:MM-03:00, or Z for UTC.
+const OffsetLayout = "2006-01-02T15:04Z07:00"
+
+// ZoneOffset is the timezone setting that stores every datetime with its
+// UTC offset.
+const ZoneOffset = "offset"
+
+// Zone is the timezone setting of a vault (timezone: in mt.yaml): how
+// its datetime fields are written and read.
+//
+//   - unset (the zero Zone): naive datetimes, wall-clock times of the
+//     machine's local zone — a vault shared across zones, or a traveling
+//     user, sees them shift;
+//   - an IANA name (America/Sao_Paulo): naive datetimes, wall-clock times
+//     of that zone wherever mt runs;
+//   - offset: each datetime carries its UTC offset (OffsetLayout), an
+//     instant; new ones are written with the machine's local offset.
+type Zone struct {
+	name string
+	loc  *time.Location
+}
+
+// LoadZone returns the Zone of a timezone setting: "", an IANA zone name
+// or "offset".
+func LoadZone(name string) (Zone, error) {
+	switch name {
+	case "":
+		return Zone{}, nil
+	case ZoneOffset:
+		return Zone{name: name}, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return Zone{}, fmt.Errorf("unknown timezone %q: want an IANA zone name (e.g. America/Sao_Paulo) or %s", name, ZoneOffset)
+	}
+	return Zone{name: name, loc: loc}, nil
+}
+
+// String returns the setting z was loaded from, "" when unset.
+func (z Zone) String() string { return z.name }
+
+// Offset reports whether z stores datetimes with their UTC offset.
+func (z Zone) Offset() bool { return z.name == ZoneOffset }
+
+// Location is the zone the wall-clock times of z are in: the named zone,
+// else the machine's local one.
+func (z Zone) Location() *time.Location {
+	if z.loc == nil {
+		return time.Local
+	}
+	return z.loc
+}
+
+// Layout is the stored datetime layout of z.
+func (z Zone) Layout() string {
+	if z.Offset() {
+		return OffsetLayout
+	}
+	return NaiveLayout
+}
+
+// In returns t in the location of z: the now a command resolves times
+// and stamps against.
+func (z Zone) In(t time.Time) time.Time { return t.In(z.Location()) }
+
+// Format writes t, taken as is (see In), in the stored layout of z.
+func (z Zone) Format(t time.Time) string { return t.Format(z.Layout()) }
+
+// ParseStamp reads a stored datetime, OffsetLayout or NaiveLayout, the
+// naive form as a wall-clock time of loc; the result is in loc. ok is
+// false when s is neither.
+func ParseStamp(s string, loc *time.Location) (time.Time, bool) {
+	if t, err := time.Parse(OffsetLayout, s); err == nil {
+		return t.In(loc), true
+	}
+	if t, err := time.ParseInLocation(NaiveLayout, s, loc); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,64 @@
+package issue_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+func TestLoadZone(t *testing.T) {
+	unset, err := issue.LoadZone("")
+	if err != nil || unset.String() != "" || unset.Offset() || unset.Location() != time.Local || unset.Layout() != issue.NaiveLayout {
+		t.Errorf("LoadZone(\"\") = %+v, %v; want the naive machine-local zone", unset, err)
+	}
+	offset, err := issue.LoadZone("offset")
+	if err != nil || !offset.Offset() || offset.Location() != time.Local || offset.Layout() != issue.OffsetLayout {
+		t.Errorf("LoadZone(offset) = %+v, %v; want offset mode", offset, err)
+	}
+	named, err := issue.LoadZone("America/Sao_Paulo")
+	if err != nil || named.Offset() || named.String() != "America/Sao_Paulo" || named.Location().String() != "America/Sao_Paulo" {
+		t.Errorf("LoadZone(America/Sao_Paulo) = %+v, %v; want the named zone", named, err)
+	}
+	for _, bad := range []string{"Mars/Olympus", "Local", "+03:00"} {
+		if _, err := issue.LoadZone(bad); err == nil || !strings.Contains(err.Error(), "unknown timezone") {
+			t.Errorf("LoadZone(%q) = %v, want unknown timezone", bad, err)
+		}
+	}
+}
+
+func TestZoneFormat(t *testing.T) {
+	instant := time.Date(2026, 8, 15, 12, 0, 0, 0, time.UTC)
+	named, _ := issue.LoadZone("America/Sao_Paulo")
+	if got := named.Format(named.In(instant)); got != "2026-08-15T09:00" {
+		t.Errorf("named Format = %q, want the naive Sao Paulo time", got)
+	}
+	offset, _ := issue.LoadZone("offset")
+	if got := offset.Format(instant.In(named.Location())); got != "2026-08-15T09:00-03:00" {
+		t.Errorf("offset Format = %q, want the time with its offset", got)
+	}
+	if got := offset.Format(instant); got != "2026-08-15T12:00Z" {
+		t.Errorf("offset Format(UTC) = %q, want a Z suffix", got)
+	}
+}
+
+func TestParseStamp(t *testing.T) {
+	sp, _ := time.LoadLocation("America/Sao_Paulo")
+	cases := []struct {
+		s    string
+		want time.Time
+		ok   bool
+	}{
+		{"2026-08-15T09:00", time.Date(2026, 8, 15, 9, 0, 0, 0, sp), true},
+		{"2026-08-15T12:00Z", time.Date(2026, 8, 15, 9, 0, 0, 0, sp), true},
+		{"2026-08-15T14:00+02:00", time.Date(2026, 8, 15, 9, 0, 0, 0, sp), true},
+		{"not-a-date", time.Time{}, false},
+	}
+	for _, c := range cases {
+		got, ok := issue.ParseStamp(c.s, sp)
+		if ok != c.ok || !got.Equal(c.want) || (ok && got.Location() != sp) {
+			t.Errorf("ParseStamp(%q) = %v, %t; want %v, %t", c.s, got, ok, c.want, c.ok)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Package journal holds the pure logic of the undo journal: every
+// mutating command appends one Entry — the command line, a timestamp and
+// the pre- and post-image of each Issue file it touched — to an
+// append-only JSON Lines file under the vault, and `mt undo` plans the
+// reversal of the latest operations from it. Planning is decision-dense
+// (which entries are still undoable, whether the files drifted since), so
+// it lives at Seam 2: black-box unit tested, with the coverage and
+// mutation gates. Deciding what to record and writing the reverted files
+// are process concerns and stay in internal/cli.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Dir is the vault-local state directory the journal lives in. It holds
+// machine state, not Issues: Append keeps it out of Git with its own
+// .gitignore.
+const Dir = ".mt"
+
+// Change is the effect of one operation on one Issue file. A nil Before
+// means the operation created the file; a nil After means it removed it.
+type Change struct {
+	ID     string  `json:"id"`
+	Before *string `json:"before"`
+	After  *string `json:"after"`
+}
+
+// Entry is one journaled operation. Seq numbers entries from 1 in
+// append order. An undo is journaled too, with Undoes naming the entries
+// it reverted; undo entries are themselves never undone.
+type Entry struct {
+	Seq     int      `json:"seq"`
+	Time    string   `json:"time"`
+	Command string   `json:"command"`
+	Changes []Change `json:"changes"`
+	Undoes  []int    `json:"undoes,omitempty"`
+}
+
+// Path returns the journal file of vaultDir: <vault>/.mt/journal.jsonl.
+func Path(vaultDir string) string {
+	return filepath.Join(vaultDir, Dir, "journal.jsonl")
+}
+
+// Load reads every entry of the journal at path, oldest first. A missing
+// file yields no entries without error: nothing was journaled yet.
+func Load(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading journal %s: %w", path, err)
+	}
+	var entries []Entry
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, len(data)+1)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("parsing journal %s line %d: %w", path, line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// Append adds e as one line at the end of the journal at path, creating
+// the state directory (with a .gitignore that ignores it whole) on first
+// use. The line goes out in a single O_APPEND write.
+func Append(path string, e Entry) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", dir, err)
+	}
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(ignore, []byte("*\n"), 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", ignore, err)
+		}
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding journal entry: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening journal %s: %w", path, err)
+	}
+	_, writeErr := f.Write(append(line, '\n'))
+	closeErr := f.Close()
+	if writeErr != nil {
+		return fmt.Errorf("writing journal %s: %w", path, writeErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("closing journal %s: %w", path, closeErr)
+	}
+	return nil
+}
+
+// NextSeq returns the sequence number of the entry after entries.
+func NextSeq(entries []Entry) int {
+	if len(entries) == 0 {
+		return 1
+	}
+	return entries[len(entries)-1].Seq + 1
+}
+
+// Record adds c to the changes of an operation in progress. A file
+// touched again keeps its first pre-image and takes the new post-image,
+// so an entry holds one Change per file; a file whose final content
+// equals its pre-image drops out.
+func Record(changes []Change, c Change) []Change {
+	i := slices.IndexFunc(changes, func(prev Change) bool { return prev.ID == c.ID })
+	if i < 0 {
+		if sameContent(c.Before, c.After) {
+			return changes
+		}
+		return append(changes, c)
+	}
+	changes[i].After = c.After
+	if sameContent(changes[i].Before, changes[i].After) {
+		return slices.Delete(changes, i, i+1)
+	}
+	return changes
+}
+
+// Undone returns the set of entry numbers some undo entry reverted.
+func Undone(entries []Entry) map[int]bool {
+	undone := make(map[int]bool)
+	for _, e := range entries {
+		for _, seq := range e.Undoes {
+			undone[seq] = true
+		}
+	}
+	return undone
+}
+
+// Undoable returns the entries `mt undo` may still revert, newest first:
+// every entry that is not an undo and was not undone already.
+func Undoable(entries []Entry) []Entry {
+	undone := Undone(entries)
+	var out []Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if len(e.Undoes) == 0 && !undone[e.Seq] {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// PlanUndo plans the reversal of the n newest undoable entries. current
+// reads the present content of an Issue file (nil when it does not
+// exist). Each entry is reverted newest first against the state the
+// newer reversals leave behind, and every file must still hold exactly
+// the entry's post-image: a file edited since (by hand, or by an
+// operation that is not being undone) makes the whole plan fail, so
+// undo never overwrites work it does not know about. The plan is
+// returned as the Changes to apply — Before the present content, After
+// the restored one — with the reverted entry numbers.
+func PlanUndo(entries []Entry, n int, current func(id string) (*string, error)) ([]Change, []int, error) {
+	if n < 1 {
+		return nil, nil, fmt.Errorf("undo count must be at least 1, got %d", n)
+	}
+	undoable := Undoable(entries)
+	if len(undoable) == 0 {
+		return nil, nil, errors.New("nothing to undo")
+	}
+	if n > len(undoable) {
+		return nil, nil, fmt.Errorf("only %d operations can be undone, asked for %d", len(undoable), n)
+	}
+	present := make(map[string]*string)
+	state := make(map[string]*string)
+	var order []string
+	var seqs []int
+	for _, e := range undoable[:n] {
+		for _, c := range e.Changes {
+			if _, seen := state[c.ID]; !seen {
+				content, err := current(c.ID)
+				if err != nil {
+					return nil, nil, err
+				}
+				present[c.ID], state[c.ID] = content, content
+				order = append(order, c.ID)
+			}
+			if !sameContent(state[c.ID], c.After) {
+				return nil, nil, fmt.Errorf("cannot undo #%d (%s): issue %s changed since", e.Seq, e.Command, c.ID)
+			}
+			state[c.ID] = c.Before
+		}
+		seqs = append(seqs, e.Seq)
+	}
+	var plan []Change
+	for _, id := range order {
+		if !sameContent(present[id], state[id]) {
+			plan = append(plan, Change{ID: id, Before: present[id], After: state[id]})
+		}
+	}
+	return plan, seqs, nil
+}
+
+// sameContent reports whether two file images are equal, nil (no file)
+// being equal only to nil.
+func sameContent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,275 @@
+// Package journal_test holds the black-box unit tests of the undo
+// journal (Seam 2): the append/load round-trip, change recording, the
+// undoable set, and undo planning — chained reversals, drift detection,
+// created and removed files.
+package journal_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Sanmoo/my-tasks2/internal/journal"
+)
+
+func str(s string) *string { return &s }
+
+// files is an in-memory Issue directory for PlanUndo's current reader.
+type files map[string]string
+
+func (f files) read(id string) (*string, error) {
+	if s, ok := f[id]; ok {
+		return &s, nil
+	}
+	return nil, nil
+}
+
+func TestPathIsInsideTheVaultStateDir(t *testing.T) {
+	got := journal.Path("/v")
+	if want := filepath.Join("/v", ".mt", "journal.jsonl"); got != want {
+		t.Errorf("Path = %q, want %q", got, want)
+	}
+}
+
+func TestLoadMissingFileIsEmpty(t *testing.T) {
+	entries, err := journal.Load(filepath.Join(t.TempDir(), "none.jsonl"))
+	if err != nil || entries != nil {
+		t.Errorf("Load(missing) = %v, %v; want nil, nil", entries, err)
+	}
+}
+
+func TestAppendLoadRoundTrip(t *testing.T) {
+	vault := t.TempDir()
+	path := journal.Path(vault)
+	first := journal.Entry{Seq: 1, Time: "2026-10-19T10:00:00Z", Command: "mt done pkm-001",
+		Changes: []journal.Change{{ID: "pkm-001", Before: str("a\n"), After: str("b\n")}}}
+	second := journal.Entry{Seq: 2, Time: "2026-10-19T10:01:00Z", Command: "mt undo",
+		Changes: []journal.Change{{ID: "pkm-002", Before: nil, After: str("new\n")}}, Undoes: []int{1}}
+	for _, e := range []journal.Entry{first, second} {
+		if err := journal.Append(path, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := journal.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Command != first.Command || *got[0].Changes[0].After != "b\n" {
+		t.Fatalf("Load = %+v", got)
+	}
+	if got[1].Changes[0].Before != nil || !slices.Equal(got[1].Undoes, []int{1}) {
+		t.Errorf("second entry = %+v, want nil Before and Undoes [1]", got[1])
+	}
+	ignore, err := os.ReadFile(filepath.Join(vault, ".mt", ".gitignore"))
+	if err != nil || string(ignore) != "*\n" {
+		t.Errorf(".gitignore = %q, %v; want %q", ignore, err, "*\n")
+	}
+}
+
+func TestAppendKeepsAnExistingGitignore(t *testing.T) {
+	vault := t.TempDir()
+	ignore := filepath.Join(vault, ".mt", ".gitignore")
+	if err := os.MkdirAll(filepath.Dir(ignore), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ignore, []byte("journal.jsonl\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := journal.Append(journal.Path(vault), journal.Entry{Seq: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(ignore); string(data) != "journal.jsonl\n" {
+		t.Errorf(".gitignore rewritten: %q", data)
+	}
+}
+
+func TestAppendFailsWhenTheStateDirCannotBeCreated(t *testing.T) {
+	vault := t.TempDir()
+	if err := os.WriteFile(filepath.Join(vault, ".mt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := journal.Append(journal.Path(vault), journal.Entry{Seq: 1}); err == nil {
+		t.Error("Append over a .mt file succeeded")
+	}
+}
+
+func TestLoadSkipsBlankLinesAndReportsBadOnes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "j.jsonl")
+	if err := os.WriteFile(path, []byte(`{"seq":1}`+"\n\n"+`{"seq":2}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := journal.Load(path)
+	if err != nil || len(got) != 2 || got[1].Seq != 2 {
+		t.Fatalf("Load = %+v, %v", got, err)
+	}
+	if err := os.WriteFile(path, []byte(`{"seq":1}`+"\n"+"not json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := journal.Load(path); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Load(bad line) error = %v, want it to name line 2", err)
+	}
+}
+
+func TestLoadUnreadablePathFails(t *testing.T) {
+	if _, err := journal.Load(t.TempDir()); err == nil {
+		t.Error("Load(directory) succeeded")
+	}
+}
+
+func TestNextSeq(t *testing.T) {
+	if got := journal.NextSeq(nil); got != 1 {
+		t.Errorf("NextSeq(nil) = %d, want 1", got)
+	}
+	if got := journal.NextSeq([]journal.Entry{{Seq: 1}, {Seq: 7}}); got != 8 {
+		t.Errorf("NextSeq = %d, want 8", got)
+	}
+}
+
+func TestRecordMergesRepeatedFiles(t *testing.T) {
+	var changes []journal.Change
+	changes = journal.Record(changes, journal.Change{ID: "a", Before: str("1"), After: str("2")})
+	changes = journal.Record(changes, journal.Change{ID: "b", Before: str("x"), After: str("y")})
+	changes = journal.Record(changes, journal.Change{ID: "a", Before: str("2"), After: str("3")})
+	if len(changes) != 2 || *changes[0].Before != "1" || *changes[0].After != "3" {
+		t.Fatalf("Record = %+v, want a 1→3 and b", changes)
+	}
+	changes = journal.Record(changes, journal.Change{ID: "b", Before: str("y"), After: str("x")})
+	if len(changes) != 1 || changes[0].ID != "a" {
+		t.Errorf("a file back to its pre-image should drop out: %+v", changes)
+	}
+}
+
+func TestRecordSkipsNoOpWrites(t *testing.T) {
+	if got := journal.Record(nil, journal.Change{ID: "a", Before: str("1"), After: str("1")}); len(got) != 0 {
+		t.Errorf("Record(no-op) = %+v, want nothing", got)
+	}
+	if got := journal.Record(nil, journal.Change{ID: "a", Before: nil, After: str("")}); len(got) != 1 {
+		t.Errorf("creating an empty file is a change: %+v", got)
+	}
+}
+
+func TestUndoableSkipsUndosAndUndone(t *testing.T) {
+	entries := []journal.Entry{{Seq: 1}, {Seq: 2}, {Seq: 3}, {Seq: 4, Undoes: []int{3}}, {Seq: 5}}
+	var got []int
+	for _, e := range journal.Undoable(entries) {
+		got = append(got, e.Seq)
+	}
+	if want := []int{5, 2, 1}; !slices.Equal(got, want) {
+		t.Errorf("Undoable = %v, want %v", got, want)
+	}
+	if undone := journal.Undone(entries); !undone[3] || undone[2] {
+		t.Errorf("Undone = %v, want only 3", undone)
+	}
+}
+
+func TestPlanUndoRevertsTheLastEntry(t *testing.T) {
+	entries := []journal.Entry{
+		{Seq: 1, Command: "mt done pkm-001", Changes: []journal.Change{{ID: "pkm-001", Before: str("open"), After: str("done")}}},
+	}
+	plan, seqs, err := journal.PlanUndo(entries, 1, files{"pkm-001": "done"}.read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(seqs, []int{1}) || len(plan) != 1 || *plan[0].Before != "done" || *plan[0].After != "open" {
+		t.Errorf("PlanUndo = %+v %v", plan, seqs)
+	}
+}
+
+func TestPlanUndoChainsReversalsOfTheSameFile(t *testing.T) {
+	entries := []journal.Entry{
+		{Seq: 1, Changes: []journal.Change{{ID: "a", Before: str("v1"), After: str("v2")}}},
+		{Seq: 2, Changes: []journal.Change{{ID: "a", Before: str("v2"), After: str("v3")}, {ID: "b", Before: nil, After: str("b1")}}},
+	}
+	plan, seqs, err := journal.PlanUndo(entries, 2, files{"a": "v3", "b": "b1"}.read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(seqs, []int{2, 1}) {
+		t.Errorf("seqs = %v, want [2 1]", seqs)
+	}
+	if len(plan) != 2 || plan[0].ID != "a" || *plan[0].After != "v1" || plan[1].ID != "b" || plan[1].After != nil {
+		t.Errorf("plan = %+v, want a→v1 and b removed", plan)
+	}
+}
+
+func TestPlanUndoRestoresARemovedFile(t *testing.T) {
+	entries := []journal.Entry{{Seq: 1, Changes: []journal.Change{{ID: "a", Before: str("v1"), After: nil}}}}
+	plan, _, err := journal.PlanUndo(entries, 1, files{}.read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 1 || plan[0].Before != nil || *plan[0].After != "v1" {
+		t.Errorf("plan = %+v, want a recreated as v1", plan)
+	}
+}
+
+func TestPlanUndoRefusesDriftedFiles(t *testing.T) {
+	entries := []journal.Entry{
+		{Seq: 1, Command: "mt done pkm-001", Changes: []journal.Change{{ID: "pkm-001", Before: str("open"), After: str("done")}}},
+	}
+	cases := map[string]files{
+		"edited":  {"pkm-001": "done, then edited"},
+		"removed": {},
+	}
+	for name, current := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := journal.PlanUndo(entries, 1, current.read)
+			if err == nil || !strings.Contains(err.Error(), "cannot undo #1 (mt done pkm-001): issue pkm-001 changed since") {
+				t.Errorf("PlanUndo error = %v", err)
+			}
+		})
+	}
+	created := []journal.Entry{{Seq: 1, Changes: []journal.Change{{ID: "a", Before: nil, After: str("x")}}}}
+	if _, _, err := journal.PlanUndo(created, 1, files{"a": "y"}.read); err == nil {
+		t.Error("undo of a create over an edited file succeeded")
+	}
+}
+
+func TestPlanUndoSkipsUnchangedNetResult(t *testing.T) {
+	entries := []journal.Entry{
+		{Seq: 1, Changes: []journal.Change{{ID: "a", Before: str("v1"), After: str("v2")}}},
+		{Seq: 2, Changes: []journal.Change{{ID: "a", Before: str("v2"), After: str("v1")}}},
+	}
+	plan, seqs, err := journal.PlanUndo(entries, 2, files{"a": "v1"}.read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 0 || len(seqs) != 2 {
+		t.Errorf("PlanUndo = %+v %v, want an empty plan reverting 2 entries", plan, seqs)
+	}
+}
+
+func TestPlanUndoErrors(t *testing.T) {
+	one := []journal.Entry{{Seq: 1, Changes: []journal.Change{{ID: "a", Before: str("1"), After: str("2")}}}}
+	cases := []struct {
+		name    string
+		entries []journal.Entry
+		n       int
+		want    string
+	}{
+		{"zero count", one, 0, "at least 1"},
+		{"empty journal", nil, 1, "nothing to undo"},
+		{"all undone", append(slices.Clone(one), journal.Entry{Seq: 2, Undoes: []int{1}}), 1, "nothing to undo"},
+		{"too many", one, 2, "only 1 operations can be undone, asked for 2"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, _, err := journal.PlanUndo(c.entries, c.n, files{"a": "2"}.read)
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("PlanUndo error = %v, want it to contain %q", err, c.want)
+			}
+		})
+	}
+}
+
+func TestPlanUndoPropagatesReadErrors(t *testing.T) {
+	one := []journal.Entry{{Seq: 1, Changes: []journal.Change{{ID: "a", Before: str("1"), After: str("2")}}}}
+	boom := errors.New("boom")
+	_, _, err := journal.PlanUndo(one, 1, func(string) (*string, error) { return nil, boom })
+	if !errors.Is(err, boom) {
+		t.Errorf("PlanUndo error = %v, want boom", err)
+	}
+}
@@ -2,8 +2,8 @@
 // Rank ordering of issues (Rank → Backlog by created_at → ID), the
 // per-status glyphs, the visibility rules (only done hides by default;
 // status/label filters), the deferred-until availability/suffix rules,
-// the computed blocked state (an Issue is blocked while any ID in its
-// blocked_by is not done), and duplicate-rank detection. It is
+// the due-soon deadline warnings, the computed blocked state (an Issue is blocked while any ID in its
+// blocked_by is not done), the WIP limit, the explanation of a pick, and duplicate-rank detection. It is
 // decision-dense, so it lives at Seam 2: black-box unit tested, with the
 // coverage and mutation gates. Reading the issue files themselves is a
 // process concern and stays in internal/cli.
@@ -15,9 +15,12 @@ import (
 	"fmt"
 	"slices"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/Sanmoo/my-tasks2/internal/deferral"
 	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/query"
 )
 
 // Item is one Issue in a list view: the file name ID (the authority,
@@ -55,8 +58,10 @@ func Glyph(status string) string {
 // (issues without a rank form the Backlog and come last, ordered by
 // created_at), then ID as the final tiebreak everywhere. created_at is
 // compared as a string, which equals chronological order for the
-// canonical zero-padded, fixed-width stamp (issue.NaiveLayout); a
-// hand-edited stamp that drifts from that layout is mt check's to flag.
+// canonical zero-padded, fixed-width stamp (issue.NaiveLayout); two
+// stamps with UTC offsets (issue.OffsetLayout) are compared as instants.
+// A hand-edited stamp that drifts from either layout is mt check's to
+// flag.
 func Compare(a, b Item) int {
 	ar, br := a.Issue.Frontmatter.Rank, b.Issue.Frontmatter.Rank
 	if ar != nil && br != nil {
@@ -73,12 +78,23 @@ func Compare(a, b Item) int {
 	}
 	// Both Backlog: oldest created_at first (lexicographic, see Compare),
 	// then ID.
-	if c := cmp.Compare(a.Issue.Frontmatter.CreatedAt, b.Issue.Frontmatter.CreatedAt); c != 0 {
+	if c := compareCreated(a.Issue.Frontmatter.CreatedAt, b.Issue.Frontmatter.CreatedAt); c != 0 {
 		return c
 	}
 	return compareID(a.ID, b.ID)
 }
 
+// compareCreated orders two created_at stamps: as instants when both
+// carry a UTC offset, else as strings.
+func compareCreated(a, b string) int {
+	at, aErr := time.Parse(issue.OffsetLayout, a)
+	bt, bErr := time.Parse(issue.OffsetLayout, b)
+	if aErr == nil && bErr == nil {
+		return at.Compare(bt)
+	}
+	return cmp.Compare(a, b)
+}
+
 // compareID orders two IDs ascending. A plain string comparison is the
 // order of last resort: IDs are unique per vault, so it makes the list
 // order total and deterministic even under a duplicate rank.
@@ -91,21 +107,19 @@ func Sort(items []Item) {
 	})
 }
 
-// parseNaive parses a stored naive datetime in the local zone. ok is
-// false when s is empty or malformed; callers then treat the field as
+// parseStamp parses a stored datetime against now: a naive one is a
+// wall-clock time of now's location — the vault's timezone, which the
+// caller puts now in — and one with a UTC offset is converted there. ok
+// is false when s is empty or malformed; callers then treat the field as
 // absent (list is lenient — mt check owns format validation).
-func parseNaive(s string) (time.Time, bool) {
-	t, err := time.ParseInLocation(issue.NaiveLayout, s, time.Local)
-	if err != nil {
-		return time.Time{}, false
-	}
-	return t, true
+func parseStamp(s string, now time.Time) (time.Time, bool) {
+	return issue.ParseStamp(s, now.Location())
 }
 
 // IsFutureDeferred reports whether deferredUntil is a datetime after
 // now. An empty or malformed value is not deferred.
 func IsFutureDeferred(deferredUntil string, now time.Time) bool {
-	t, ok := parseNaive(deferredUntil)
+	t, ok := parseStamp(deferredUntil, now)
 	return ok && t.After(now)
 }
 
@@ -123,7 +137,7 @@ func Ready(item Item, now time.Time) bool {
 // Deadline is informational, so a future deferral does not affect this result.
 // An empty or malformed deadline is not overdue; mt check owns validation.
 func Overdue(item Item, now time.Time) bool {
-	deadline, ok := parseNaive(item.Issue.Frontmatter.Deadline)
+	deadline, ok := parseStamp(item.Issue.Frontmatter.Deadline, now)
 	return ok && deadline.Before(now) && item.Issue.Frontmatter.Status != "done"
 }
 
@@ -132,7 +146,7 @@ func Overdue(item Item, now time.Time) bool {
 // An empty or malformed value is not an expired deferral (format
 // validation is mt check's).
 func DeferralExpired(deferredUntil string, now time.Time) bool {
-	t, ok := parseNaive(deferredUntil)
+	t, ok := parseStamp(deferredUntil, now)
 	return ok && !t.After(now)
 }
 
@@ -141,7 +155,7 @@ func DeferralExpired(deferredUntil string, now time.Time) bool {
 // returns "" when deferredUntil is empty, still in the future, or
 // malformed.
 func ExpiredSuffix(deferredUntil string, now time.Time) string {
-	t, ok := parseNaive(deferredUntil)
+	t, ok := parseStamp(deferredUntil, now)
 	if !ok || t.After(now) {
 		return ""
 	}
@@ -152,7 +166,7 @@ func ExpiredSuffix(deferredUntil string, now time.Time) string {
 // that has passed relative to now. It returns "" when deadline is empty,
 // not yet passed, or malformed.
 func DeadlineSuffix(deadline string, now time.Time) string {
-	t, ok := parseNaive(deadline)
+	t, ok := parseStamp(deadline, now)
 	if !ok || !t.Before(now) {
 		return ""
 	}
@@ -180,11 +194,61 @@ func OverdueGroups(items []Item, now time.Time) (expired, late []Item) {
 	return expired, late
 }
 
+// WarnSpan returns the lead time of item's deadline warning: its own
+// warn_before, else warn, the vault's. A malformed warn_before falls back
+// to warn (mt check owns it).
+func WarnSpan(item Item, warn time.Duration) time.Duration {
+	if span, err := deferral.ParseSpan(item.Issue.Frontmatter.WarnBefore); err == nil {
+		return span
+	}
+	return warn
+}
+
+// DueSoon reports whether item is due soon at now: not done, with a
+// deadline not yet passed but at most its WarnSpan away. warn is the
+// vault's lead time; with none (0) and no warn_before of its own, no
+// Issue is due soon.
+func DueSoon(item Item, now time.Time, warn time.Duration) bool {
+	fm := item.Issue.Frontmatter
+	return fm.Status != "done" && DueSuffix(fm.Deadline, now, WarnSpan(item, warn)) != ""
+}
+
+// DueWithin reports whether item is not done and has a deadline at most
+// within after now, passed ones included: the selection of mt due.
+func DueWithin(item Item, now time.Time, within time.Duration) bool {
+	deadline, ok := parseStamp(item.Issue.Frontmatter.Deadline, now)
+	return ok && !deadline.After(now.Add(within)) && item.Issue.Frontmatter.Status != "done"
+}
+
+// DueSuffix returns the "[due MM-DD]" marker for a deadline not yet
+// passed at now and at most span away. It returns "" otherwise, and when
+// deadline is empty or malformed.
+func DueSuffix(deadline string, now time.Time, span time.Duration) string {
+	t, ok := parseStamp(deadline, now)
+	if !ok || t.Before(now) || t.After(now.Add(span)) {
+		return ""
+	}
+	return "[due " + t.Format("01-02") + "]"
+}
+
+// DueSoonGroup returns the items due soon at now (see DueSoon), in input
+// order, for the third group of the overdue view. An item whose deferral
+// has expired is left out: OverdueGroups lists it already.
+func DueSoonGroup(items []Item, now time.Time, warn time.Duration) []Item {
+	var soon []Item
+	for _, it := range items {
+		if DueSoon(it, now, warn) && !DeferralExpired(it.Issue.Frontmatter.DeferredUntil, now) {
+			soon = append(soon, it)
+		}
+	}
+	return soon
+}
+
 // DeferSuffix returns the "[defer MM-DD HH:MM]" marker for a
 // deferred_until datetime in the future relative to now. It returns ""
 // when deferredUntil is empty, not in the future, or malformed.
 func DeferSuffix(deferredUntil string, now time.Time) string {
-	t, ok := parseNaive(deferredUntil)
+	t, ok := parseStamp(deferredUntil, now)
 	if !ok || !t.After(now) {
 		return ""
 	}
@@ -216,6 +280,110 @@ func Blocked(blockedBy []string, statusByID map[string]string) bool {
 	return false
 }
 
+// OpenBlockers returns the IDs of blockedBy that are not done, in
+// order: the blockers holding an Issue, by Blocked's rule.
+func OpenBlockers(blockedBy []string, statusByID map[string]string) []string {
+	var open []string
+	for _, id := range blockedBy {
+		if statusByID[id] != "done" {
+			open = append(open, id)
+		}
+	}
+	return open
+}
+
+// Unavailable returns why item cannot be started at now — "is done, not
+// open", "is deferred until 2026-08-20T08:00", "is blocked by pkm-002" —
+// or "" when it is available: open, not deferred into the future and not
+// blocked. It is the rule PickNext applies to every candidate and mt
+// start to the Issue it is given.
+func Unavailable(item Item, now time.Time, statusByID map[string]string) string {
+	fm := item.Issue.Frontmatter
+	switch {
+	case fm.Status != "open":
+		return fmt.Sprintf("is %s, not open", fm.Status)
+	case IsFutureDeferred(fm.DeferredUntil, now):
+		return "is deferred until " + fm.DeferredUntil
+	}
+	if open := OpenBlockers(fm.BlockedBy, statusByID); len(open) > 0 {
+		return "is blocked by " + strings.Join(open, ", ")
+	}
+	return ""
+}
+
+// CheckWIP returns an error when limit Issues or more are in_progress
+// among items, so none more may start. A limit of 0 is no limit.
+func CheckWIP(items []Item, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+	n := 0
+	for _, it := range items {
+		if it.Issue.Frontmatter.Status == "in_progress" {
+			n++
+		}
+	}
+	if n >= limit {
+		return fmt.Errorf("WIP limit reached: %d in progress (wip_limit: %d); finish or pause one first", n, limit)
+	}
+	return nil
+}
+
+// Startable returns nil when the Issue id among items may be started at
+// now: it is available (see Unavailable) and the WIP limit leaves room.
+func Startable(items []Item, id string, now time.Time, wipLimit int) error {
+	i := slices.IndexFunc(items, func(it Item) bool { return it.ID == id })
+	if i < 0 {
+		return fmt.Errorf("issue %s not found", id)
+	}
+	if why := Unavailable(items[i], now, StatusByID(items)); why != "" {
+		return fmt.Errorf("issue %s %s", id, why)
+	}
+	return CheckWIP(items, wipLimit)
+}
+
+// StartableAll is Startable for putting every Issue of ids in_progress at
+// once, as mt status and create --status do: each not in_progress yet
+// must be neither deferred into the future nor blocked, and all of them
+// together must fit under the WIP limit. Their status is not checked —
+// mt status is the free transition — and the ones in_progress already
+// stay as they are.
+func StartableAll(items []Item, ids []string, now time.Time, wipLimit int) error {
+	statusByID := StatusByID(items)
+	starting := 0
+	for _, id := range ids {
+		i := slices.IndexFunc(items, func(it Item) bool { return it.ID == id })
+		if i < 0 {
+			return fmt.Errorf("issue %s not found", id)
+		}
+		if items[i].Issue.Frontmatter.Status == "in_progress" {
+			continue
+		}
+		probe := items[i]
+		probe.Issue.Frontmatter.Status = "open"
+		if why := Unavailable(probe, now, statusByID); why != "" {
+			return fmt.Errorf("issue %s %s", id, why)
+		}
+		starting++
+	}
+	if starting == 0 || wipLimit <= 0 {
+		return nil
+	}
+	if err := CheckWIP(items, wipLimit); err != nil {
+		return err
+	}
+	n := 0
+	for _, it := range items {
+		if it.Issue.Frontmatter.Status == "in_progress" {
+			n++
+		}
+	}
+	if n+starting > wipLimit {
+		return fmt.Errorf("WIP limit reached: starting %d would make %d in progress (wip_limit: %d)", starting, n+starting, wipLimit)
+	}
+	return nil
+}
+
 // Options selects the issues a list view shows.
 //
 // All reveals done issues; without it, done issues are hidden. A future
@@ -258,8 +426,41 @@ func Visible(item Item, opts Options) bool {
 // available, the oldest Backlog Issue wins, with ID as the final tie-break.
 // Future-deferred and blocked Issues are unavailable, while a deferred_until
 // exactly at now is available. Duplicate ranks anywhere in the vault are rejected
-// before candidate selection, including ranks on non-open Issues.
+// before candidate selection, including ranks on non-open Issues. The WIP
+// limit is not PickNext's to check: only starting the pick is bound by it
+// (see CheckWIP).
 func PickNext(items []Item, now time.Time) (Item, error) {
+	return PickNextWith(items, now, PickOptions{})
+}
+
+// PickOptions tune the choice of PickNextWith.
+type PickOptions struct {
+	// DueFirst prefers the candidates due soon (see DueSoon), in Rank
+	// order, over the rest; Warn is the vault's lead time.
+	DueFirst bool
+	Warn     time.Duration
+	// Labels narrows, when non-empty, the candidates to the Issues
+	// carrying at least one of these labels; Where to those matching the
+	// query. The zero Query matches every Issue.
+	Labels []string
+	Where  query.Query
+}
+
+// considers reports whether item is within the scope of o: its labels
+// and query.
+func (o PickOptions) considers(item Item) bool {
+	if len(o.Labels) > 0 && !hasAnyLabel(item.Issue.Frontmatter.Labels, o.Labels) {
+		return false
+	}
+	return o.Where.Match(item.ID, item.Issue.Frontmatter)
+}
+
+// ErrNoneAvailable is the error of PickNextWith when no Issue in its
+// scope is available.
+var ErrNoneAvailable = errors.New("no available open issues")
+
+// PickNextWith is PickNext under opts.
+func PickNextWith(items []Item, now time.Time, opts PickOptions) (Item, error) {
 	if dups := DuplicateRanks(items); len(dups) > 0 {
 		return Item{}, fmt.Errorf("duplicate rank: %d", dups[0])
 	}
@@ -267,24 +468,59 @@ func PickNext(items []Item, now time.Time) (Item, error) {
 	statusByID := StatusByID(items)
 	candidates := make([]Item, 0, len(items))
 	for _, item := range items {
-		if item.Issue.Frontmatter.Status != "open" {
-			continue
+		if opts.considers(item) && Unavailable(item, now, statusByID) == "" {
+			candidates = append(candidates, item)
 		}
-		if IsFutureDeferred(item.Issue.Frontmatter.DeferredUntil, now) {
-			continue
-		}
-		if Blocked(item.Issue.Frontmatter.BlockedBy, statusByID) {
-			continue
-		}
-		candidates = append(candidates, item)
 	}
 	if len(candidates) == 0 {
-		return Item{}, errors.New("no available open issues")
+		return Item{}, ErrNoneAvailable
 	}
 	Sort(candidates)
+	if opts.DueFirst {
+		if i := slices.IndexFunc(candidates, func(it Item) bool { return DueSoon(it, now, opts.Warn) }); i >= 0 {
+			return candidates[i], nil
+		}
+	}
 	return candidates[0], nil
 }
 
+// Skip is an Issue PickNextWith passed over, with why: a reason of
+// Unavailable, or "is not due soon".
+type Skip struct {
+	ID     string
+	Reason string
+}
+
+// ExplainPick returns the Issues in the scope of opts that PickNextWith
+// passed over before chosen — all of them when chosen is "", nothing
+// chosen — in Rank order, each with its reason. An available Issue
+// ahead of chosen was passed over only because DueFirst preferred an
+// Issue due soon. The done Issues ahead of chosen are only counted, in
+// done: a vault's history would bury the reasons that matter.
+func ExplainPick(items []Item, now time.Time, opts PickOptions, chosen string) (skips []Skip, done int) {
+	sorted := slices.Clone(items)
+	Sort(sorted)
+	statusByID := StatusByID(items)
+	for _, item := range sorted {
+		if item.ID == chosen {
+			break
+		}
+		if !opts.considers(item) {
+			continue
+		}
+		if item.Issue.Frontmatter.Status == "done" {
+			done++
+			continue
+		}
+		why := Unavailable(item, now, statusByID)
+		if why == "" {
+			why = "is not due soon"
+		}
+		skips = append(skips, Skip{ID: item.ID, Reason: why})
+	}
+	return skips, done
+}
+
 // hasAnyLabel reports whether labels contains at least one of filters.
 func hasAnyLabel(labels, filters []string) bool {
 	for _, f := range filters {
@@ -4,6 +4,9 @@
 package list_test
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
 	"slices"
 	"strings"
 	"testing"
@@ -11,6 +14,7 @@ import (
 
 	"github.com/Sanmoo/my-tasks2/internal/issue"
 	"github.com/Sanmoo/my-tasks2/internal/list"
+	"github.com/Sanmoo/my-tasks2/internal/query"
 )
 
 func intPtr(v int) *int { return &v }
@@ -71,6 +75,7 @@ func TestCompare(t *testing.T) {
 		{"backlog newer created_at last", item("b", "open", nil, "2026-08-16T09:30", ""), item("a", "open", nil, "2026-08-15T09:30", ""), 1},
 		{"backlog equal created_at tiebreak by id", item("b", "open", nil, "2026-08-15T09:30", ""), item("a", "open", nil, "2026-08-15T09:30", ""), 1},
 		{"backlog equal everything is equal", item("a", "open", nil, "2026-08-15T09:30", ""), item("a", "open", nil, "2026-08-15T09:30", ""), 0},
+		{"backlog offset created_at by instant", item("a", "open", nil, "2026-08-15T10:00+02:00", ""), item("b", "open", nil, "2026-08-15T09:30Z", ""), -1},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -161,6 +166,8 @@ func TestIsFutureDeferred(t *testing.T) {
 		{"past is not deferred", "2026-08-10T08:00", false},
 		{"exactly now is available", "2026-08-15T12:00", false},
 		{"future is deferred", "2026-08-20T08:00", true},
+		{"past offset is not deferred", "2026-08-15T12:00+09:00", false},
+		{"future offset is deferred", "2026-08-20T08:00Z", true},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -476,6 +483,126 @@ func TestVisible(t *testing.T) {
 	}
 }
 
+// withDue builds an item with a deadline and its own warn_before.
+func withDue(id, status, deadline, warnBefore string) list.Item {
+	it := item(id, status, nil, "", "")
+	it.Issue.Frontmatter.Deadline = deadline
+	it.Issue.Frontmatter.WarnBefore = warnBefore
+	return it
+}
+
+func TestWarnSpan(t *testing.T) {
+	if got := list.WarnSpan(withDue("a", "open", "", "1d"), time.Hour); got != 24*time.Hour {
+		t.Errorf("WarnSpan(own 1d) = %v, want the Issue's own", got)
+	}
+	for _, own := range []string{"", "soon"} {
+		if got := list.WarnSpan(withDue("a", "open", "", own), time.Hour); got != time.Hour {
+			t.Errorf("WarnSpan(own %q) = %v, want the vault's", own, got)
+		}
+	}
+}
+
+func TestDueSoon(t *testing.T) {
+	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.Local)
+	warn := 3 * 24 * time.Hour
+	cases := []struct {
+		name string
+		it   list.Item
+		warn time.Duration
+		want bool
+	}{
+		{"deadline within warn_before", withDue("a", "open", "2026-08-17T12:00", ""), warn, true},
+		{"deadline exactly at the edge", withDue("a", "open", "2026-08-18T12:00", ""), warn, true},
+		{"deadline exactly now", withDue("a", "open", "2026-08-15T12:00", ""), warn, true},
+		{"deadline beyond warn_before", withDue("a", "open", "2026-08-18T12:01", ""), warn, false},
+		{"passed deadline is overdue, not due soon", withDue("a", "open", "2026-08-15T11:59", ""), warn, false},
+		{"no vault warning", withDue("a", "open", "2026-08-16T12:00", ""), 0, false},
+		{"own warn_before without the vault's", withDue("a", "open", "2026-08-16T12:00", "2d"), 0, true},
+		{"own warn_before narrows the vault's", withDue("a", "open", "2026-08-17T12:00", "1d"), warn, false},
+		{"done is never due", withDue("a", "done", "2026-08-16T12:00", ""), warn, false},
+		{"no deadline", withDue("a", "open", "", ""), warn, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := list.DueSoon(c.it, now, c.warn); got != c.want {
+				t.Errorf("DueSoon(%s) = %t, want %t", c.name, got, c.want)
+			}
+		})
+	}
+}
+
+func TestDueWithin(t *testing.T) {
+	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.Local)
+	week := 7 * 24 * time.Hour
+	cases := []struct {
+		name string
+		it   list.Item
+		want bool
+	}{
+		{"passed deadline", withDue("a", "open", "2026-08-01T12:00", ""), true},
+		{"deadline in the window", withDue("a", "open", "2026-08-22T12:00", ""), true},
+		{"deadline beyond the window", withDue("a", "open", "2026-08-22T12:01", ""), false},
+		{"done", withDue("a", "done", "2026-08-16T12:00", ""), false},
+		{"malformed deadline", withDue("a", "open", "soon", ""), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := list.DueWithin(c.it, now, week); got != c.want {
+				t.Errorf("DueWithin(%s) = %t, want %t", c.name, got, c.want)
+			}
+		})
+	}
+}
+
+func TestDueSuffix(t *testing.T) {
+	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.Local)
+	day := 24 * time.Hour
+	for deadline, want := range map[string]string{
+		"2026-08-16T09:00": "[due 08-16]",
+		"2026-08-20T09:00": "",
+		"2026-08-14T09:00": "",
+		"":                 "",
+	} {
+		if got := list.DueSuffix(deadline, now, 2*day); got != want {
+			t.Errorf("DueSuffix(%q) = %q, want %q", deadline, got, want)
+		}
+	}
+}
+
+func TestDueSoonGroup(t *testing.T) {
+	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.Local)
+	expired := withDue("expired", "open", "2026-08-16T12:00", "")
+	expired.Issue.Frontmatter.DeferredUntil = "2026-08-15T08:00"
+	items := []list.Item{
+		withDue("soon-b", "open", "2026-08-17T12:00", ""),
+		expired,
+		withDue("late", "open", "2026-08-14T12:00", ""),
+		withDue("soon-a", "in_progress", "2026-08-16T12:00", ""),
+		withDue("far", "open", "2026-09-16T12:00", ""),
+	}
+	got := ids(list.DueSoonGroup(items, now, 3*24*time.Hour))
+	if want := []string{"soon-b", "soon-a"}; !slices.Equal(got, want) {
+		t.Errorf("DueSoonGroup() = %v, want %v", got, want)
+	}
+}
+
+func TestPickNextWithDueFirst(t *testing.T) {
+	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.Local)
+	due := withDue("due", "open", "2026-08-16T12:00", "")
+	due.Issue.Frontmatter.Rank = intPtr(2)
+	items := []list.Item{item("top", "open", intPtr(1), "2026-08-01T10:00", ""), due}
+	warn := 3 * 24 * time.Hour
+	if got, err := list.PickNextWith(items, now, list.PickOptions{DueFirst: true, Warn: warn}); err != nil || got.ID != "due" {
+		t.Errorf("PickNextWith(due first) = %s, %v; want due", got.ID, err)
+	}
+	if got, err := list.PickNextWith(items, now, list.PickOptions{Warn: warn}); err != nil || got.ID != "top" {
+		t.Errorf("PickNextWith(rank order) = %s, %v; want top", got.ID, err)
+	}
+	if got, err := list.PickNextWith(items[:1], now, list.PickOptions{DueFirst: true, Warn: warn}); err != nil || got.ID != "top" {
+		t.Errorf("PickNextWith(none due) = %s, %v; want the rank order", got.ID, err)
+	}
+}
+
 func TestPickNextChoosesLowestRankedAvailableOpenIssue(t *testing.T) {
 	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.Local)
 	items := []list.Item{
@@ -575,6 +702,189 @@ func TestBlocked(t *testing.T) {
 	}
 }
 
+func TestOpenBlockers(t *testing.T) {
+	byID := list.StatusByID([]list.Item{item("done", "done", nil, "", ""), item("open", "open", nil, "", "")})
+	if got := list.OpenBlockers([]string{"open", "done", "ghost"}, byID); !slices.Equal(got, []string{"open", "ghost"}) {
+		t.Errorf("OpenBlockers() = %v, want [open ghost]", got)
+	}
+	if got := list.OpenBlockers([]string{"done"}, byID); got != nil {
+		t.Errorf("OpenBlockers(all done) = %v, want none", got)
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.Local)
+	items := []list.Item{
+		item("free", "open", nil, "", "2026-08-15T12:00"),
+		item("started", "in_progress", nil, "", ""),
+		item("later", "open", nil, "", "2026-08-20T08:00"),
+		blockedByItem("held", "open", []string{"started", "done", "ghost"}),
+		item("done", "done", nil, "", ""),
+	}
+	byID := list.StatusByID(items)
+	want := []string{"", "is in_progress, not open", "is deferred until 2026-08-20T08:00", "is blocked by started, ghost", "is done, not open"}
+	for i, it := range items {
+		if got := list.Unavailable(it, now, byID); got != want[i] {
+			t.Errorf("Unavailable(%s) = %q, want %q", it.ID, got, want[i])
+		}
+	}
+}
+
+func TestCheckWIP(t *testing.T) {
+	items := []list.Item{
+		item("a", "in_progress", nil, "", ""),
+		item("b", "in_progress", nil, "", ""),
+		item("c", "open", nil, "", ""),
+	}
+	if err := list.CheckWIP(items, 0); err != nil {
+		t.Errorf("CheckWIP(no limit) = %v, want nil", err)
+	}
+	if err := list.CheckWIP(items, 3); err != nil {
+		t.Errorf("CheckWIP(under the limit) = %v, want nil", err)
+	}
+	if err := list.CheckWIP(items, 2); err == nil || !strings.Contains(err.Error(), "WIP limit reached: 2 in progress (wip_limit: 2)") {
+		t.Errorf("CheckWIP(at the limit) = %v, want the limit error", err)
+	}
+}
+
+func TestStartable(t *testing.T) {
+	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.Local)
+	items := []list.Item{
+		item("free", "open", nil, "", ""),
+		item("started", "in_progress", nil, "", ""),
+		blockedByItem("held", "open", []string{"started"}),
+	}
+	if err := list.Startable(items, "free", now, 0); err != nil {
+		t.Errorf("Startable(free) = %v, want nil", err)
+	}
+	if err := list.Startable(items, "free", now, 1); err == nil || !strings.Contains(err.Error(), "WIP limit") {
+		t.Errorf("Startable(free, limit 1) = %v, want the limit error", err)
+	}
+	if err := list.Startable(items, "held", now, 0); err == nil || err.Error() != "issue held is blocked by started" {
+		t.Errorf("Startable(held) = %v, want blocked by started", err)
+	}
+	if err := list.Startable(items, "ghost", now, 0); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Startable(ghost) = %v, want not found", err)
+	}
+}
+
+func TestStartableAll(t *testing.T) {
+	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.Local)
+	items := []list.Item{
+		item("free", "open", nil, "", ""),
+		item("also", "open", nil, "", ""),
+		item("started", "in_progress", nil, "", ""),
+		blockedByItem("held", "open", []string{"also"}),
+		item("closed", "done", nil, "", ""),
+	}
+	if err := list.StartableAll(items, []string{"free", "also", "started"}, now, 3); err != nil {
+		t.Errorf("StartableAll(within the limit) = %v, want nil", err)
+	}
+	if err := list.StartableAll(items, []string{"started"}, now, 1); err != nil {
+		t.Errorf("StartableAll(in progress already) = %v, want nil", err)
+	}
+	if err := list.StartableAll(items, []string{"free", "also"}, now, 2); err == nil || err.Error() != "WIP limit reached: starting 2 would make 3 in progress (wip_limit: 2)" {
+		t.Errorf("StartableAll(over the limit) = %v, want the limit error", err)
+	}
+	if err := list.StartableAll(items, []string{"free"}, now, 1); err == nil || !strings.Contains(err.Error(), "WIP limit reached: 1 in progress") {
+		t.Errorf("StartableAll(at the limit) = %v, want the limit error", err)
+	}
+	if err := list.StartableAll(items, []string{"free", "held"}, now, 0); err == nil || err.Error() != "issue held is blocked by also" {
+		t.Errorf("StartableAll(held) = %v, want blocked by also", err)
+	}
+	if err := list.StartableAll(items, []string{"closed"}, now, 0); err != nil {
+		t.Errorf("StartableAll(closed) = %v, want nil: the status is free", err)
+	}
+	later := []list.Item{item("later", "done", nil, "", "2026-08-20T08:00")}
+	if err := list.StartableAll(later, []string{"later"}, now, 0); err == nil || err.Error() != "issue later is deferred until 2026-08-20T08:00" {
+		t.Errorf("StartableAll(later) = %v, want deferred", err)
+	}
+	if err := list.StartableAll(items, []string{"ghost"}, now, 0); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("StartableAll(ghost) = %v, want not found", err)
+	}
+}
+
+func TestPickNextIgnoresTheWIPLimit(t *testing.T) {
+	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.Local)
+	items := []list.Item{item("started", "in_progress", nil, "", ""), item("free", "open", nil, "", "")}
+	if got, err := list.PickNext(items, now); err != nil || got.ID != "free" {
+		t.Errorf("PickNext(one in progress) = %s, %v; want free", got.ID, err)
+	}
+}
+
+func TestPickNextWithScope(t *testing.T) {
+	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.Local)
+	errand := item("errand", "open", intPtr(2), "", "")
+	errand.Issue.Frontmatter.Labels = []string{"errands"}
+	items := []list.Item{item("top", "open", intPtr(1), "", ""), errand}
+	if got, err := list.PickNextWith(items, now, list.PickOptions{Labels: []string{"errands", "home"}}); err != nil || got.ID != "errand" {
+		t.Errorf("PickNextWith(labels) = %s, %v; want errand", got.ID, err)
+	}
+	q, err := query.Parse("rank>=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := list.PickNextWith(items, now, list.PickOptions{Where: q}); err != nil || got.ID != "errand" {
+		t.Errorf("PickNextWith(where) = %s, %v; want errand", got.ID, err)
+	}
+	if _, err := list.PickNextWith(items, now, list.PickOptions{Labels: []string{"home"}}); !errors.Is(err, list.ErrNoneAvailable) {
+		t.Errorf("PickNextWith(no match) = %v, want ErrNoneAvailable", err)
+	}
+}
+
+func TestExplainPick(t *testing.T) {
+	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.Local)
+	held := blockedByItem("held", "open", []string{"started"})
+	held.Issue.Frontmatter.Rank = intPtr(3)
+	items := []list.Item{
+		item("free", "open", intPtr(5), "", ""),
+		item("later", "open", intPtr(2), "", "2026-08-20T08:00"),
+		item("started", "in_progress", intPtr(4), "", ""),
+		held,
+		item("closed", "done", intPtr(1), "", ""),
+		item("after", "open", intPtr(6), "", ""),
+	}
+	want := []list.Skip{
+		{ID: "later", Reason: "is deferred until 2026-08-20T08:00"},
+		{ID: "held", Reason: "is blocked by started"},
+		{ID: "started", Reason: "is in_progress, not open"},
+	}
+	if got, done := list.ExplainPick(items, now, list.PickOptions{}, "free"); !reflect.DeepEqual(got, want) || done != 1 {
+		t.Errorf("ExplainPick(free) = %v, %d done; want %v, 1 done", got, done, want)
+	}
+	if got, done := list.ExplainPick(items[1:5], now, list.PickOptions{}, ""); !reflect.DeepEqual(got, want) || done != 1 {
+		t.Errorf("ExplainPick(nothing chosen) = %v, %d done; want %v, 1 done", got, done, want)
+	}
+	if got, done := list.ExplainPick(items, now, list.PickOptions{Labels: []string{"x"}}, ""); got != nil || done != 0 {
+		t.Errorf("ExplainPick(out of scope) = %v, %d done; want none", got, done)
+	}
+	items[5].Issue.Frontmatter.Deadline = "2026-08-16T12:00"
+	opts := list.PickOptions{DueFirst: true, Warn: 72 * time.Hour}
+	if chosen, err := list.PickNextWith(items, now, opts); err != nil || chosen.ID != "after" {
+		t.Fatalf("PickNextWith(due first) = %s, %v; want after", chosen.ID, err)
+	}
+	got, _ := list.ExplainPick(items, now, opts, "after")
+	if last := got[len(got)-1]; last != (list.Skip{ID: "free", Reason: "is not due soon"}) {
+		t.Errorf("ExplainPick(due first) ends with %v, want free not due soon", last)
+	}
+}
+
+func TestExplainPickCountsTheDoneIssues(t *testing.T) {
+	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.Local)
+	var items []list.Item
+	for n := range 300 {
+		items = append(items, item(fmt.Sprintf("old-%03d", n), "done", intPtr(n), "", ""))
+	}
+	items = append(items, item("later", "open", intPtr(300), "", "2026-08-20T08:00"), item("free", "open", intPtr(301), "", ""))
+	skips, done := list.ExplainPick(items, now, list.PickOptions{}, "free")
+	if want := []list.Skip{{ID: "later", Reason: "is deferred until 2026-08-20T08:00"}}; !reflect.DeepEqual(skips, want) || done != 300 {
+		t.Errorf("ExplainPick() = %v, %d done; want %v, 300 done", skips, done, want)
+	}
+	if skips, done := list.ExplainPick(items[:300], now, list.PickOptions{}, ""); skips != nil || done != 300 {
+		t.Errorf("ExplainPick(nothing chosen) = %v, %d done; want none, 300 done", skips, done)
+	}
+}
+
 func TestPickNextSkipsBlockedIssues(t *testing.T) {
 	now := time.Date(2026, 8, 15, 12, 0, 0, 0, time.Local)
 	items := []list.Item{
@@ -0,0 +1,401 @@
+// Package mcp is the server side of the Model Context Protocol over
+// stdio: JSON-RPC 2.0 messages, one per line, answering the lifecycle
+// (initialize, ping), tools (tools/list, tools/call) and resources
+// (resources/list, resources/read) methods. It knows nothing of Issues:
+// the caller registers the tools and the resource provider, and the
+// errors they return are mapped onto the exit code convention
+// (internal/exitcode), so an agent sees the same usage/user split a shell
+// does.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+)
+
+// ProtocolVersion is the latest MCP revision the server speaks, answered
+// to a client that asks for one it does not know.
+const ProtocolVersion = "2025-06-18"
+
+// supportedVersions are the revisions the server accepts as the client's:
+// the messages it uses did not change between them.
+var supportedVersions = []string{ProtocolVersion, "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes: the standard ones and MCP's resource not found.
+const (
+	CodeParseError       = -32700
+	CodeInvalidRequest   = -32600
+	CodeMethodNotFound   = -32601
+	CodeInvalidParams    = -32602
+	CodeInternalError    = -32603
+	CodeResourceNotFound = -32002
+)
+
+// ErrResourceNotFound marks the error of a Read of a URI the provider
+// does not have.
+var ErrResourceNotFound = errors.New("resource not found")
+
+// Tool is a tool the server exposes: its name, what it does (read by the
+// model), the JSON Schema of its arguments and the handler. Call gets the
+// raw arguments object and returns a value encoded as a JSON object, or
+// an error reported to the model as a failed call.
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema map[string]any
+	Call        func(args json.RawMessage) (any, error)
+}
+
+// Resource is an entry of resources/list.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MIMEType    string `json:"mimeType,omitempty"`
+}
+
+// Contents is the text content of a resource, as resources/read returns
+// it.
+type Contents struct {
+	URI      string `json:"uri"`
+	MIMEType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
+
+// Resources provides the resources of the server.
+type Resources interface {
+	List() ([]Resource, error)
+	// Read returns the content of uri, or an error wrapping
+	// ErrResourceNotFound.
+	Read(uri string) (Contents, error)
+}
+
+// Server answers MCP requests with its tools and resources. A Server
+// handles one message at a time; it is not safe for concurrent use.
+type Server struct {
+	name, version string
+	// Instructions is sent to the client on initialize, as a hint for the
+	// model on how to use the tools.
+	Instructions string
+	tools        []Tool
+	resources    Resources
+}
+
+// NewServer returns a server that introduces itself as name and version,
+// with no tools or resources yet.
+func NewServer(name, version string) *Server {
+	return &Server{name: name, version: version}
+}
+
+// AddTool registers t; tools/list lists the tools in registration order.
+// A second tool of the same name replaces the first.
+func (s *Server) AddTool(t Tool) {
+	if i := slices.IndexFunc(s.tools, func(o Tool) bool { return o.Name == t.Name }); i >= 0 {
+		s.tools[i] = t
+		return
+	}
+	s.tools = append(s.tools, t)
+}
+
+// SetResources sets the resource provider. Without one the server does
+// not offer the resources capability.
+func (s *Server) SetResources(r Resources) {
+	s.resources = r
+}
+
+// Serve reads messages from in, one per line, and writes each response,
+// one per line, to out until in ends. Blank lines are skipped.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	r := bufio.NewReader(in)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if resp := s.Handle(line); resp != nil {
+				if _, werr := out.Write(append(resp, '\n')); werr != nil {
+					return fmt.Errorf("writing response: %w", werr)
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading request: %w", err)
+		}
+	}
+}
+
+// request is an incoming JSON-RPC message. ID is empty for a
+// notification; a message with no method is a response, which the
+// server, sending no requests, ignores.
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is an outgoing JSON-RPC response: a result or an error.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error object. Data carries the exit code the error
+// would end mt with, when it came from a handler.
+type Error struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Data    *ErrorData `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// ErrorData is the structured part of an error: the exit code convention
+// (1 user error, 2 usage error) and its name.
+type ErrorData struct {
+	ExitCode int    `json:"exit_code"`
+	Kind     string `json:"kind"`
+}
+
+// dataOf maps err onto the exit code convention.
+func dataOf(err error) *ErrorData {
+	code := exitcode.For(err)
+	kind := "user"
+	if code == exitcode.UsageError {
+		kind = "usage"
+	}
+	return &ErrorData{ExitCode: code, Kind: kind}
+}
+
+// Handle answers one message: the encoded response, or nil for a
+// notification or a response.
+func (s *Server) Handle(msg []byte) []byte {
+	if !json.Valid(msg) {
+		return encode(response{ID: json.RawMessage("null"), Error: &Error{Code: CodeParseError, Message: "parse error: not a JSON message"}})
+	}
+	var req request
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return encode(response{ID: json.RawMessage("null"), Error: &Error{Code: CodeInvalidRequest, Message: "invalid request: want a JSON-RPC object"}})
+	}
+	if req.Method == "" || len(req.ID) == 0 {
+		// Responses are ignored; so are notifications (initialized,
+		// cancelled), which need no answer.
+		return nil
+	}
+	resp := response{ID: req.ID}
+	if req.JSONRPC != "2.0" {
+		resp.Error = &Error{Code: CodeInvalidRequest, Message: `invalid request: want "jsonrpc": "2.0"`}
+		return encode(resp)
+	}
+	result, err := s.dispatch(req.Method, req.Params)
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error(), Data: dataOf(err)}
+		}
+		resp.Error = rpcErr
+	} else {
+		resp.Result = result
+	}
+	return encode(resp)
+}
+
+// encode marshals a response. Results are built from JSON-safe values, so
+// an error here is a bug; it is answered as an internal error.
+func encode(resp response) []byte {
+	resp.JSONRPC = "2.0"
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(response{
+			JSONRPC: "2.0",
+			ID:      resp.ID,
+			Error:   &Error{Code: CodeInternalError, Message: "encoding response: " + err.Error()},
+		})
+	}
+	return data
+}
+
+// dispatch runs the method and returns its result.
+func (s *Server) dispatch(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		return s.initialize(params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(params)
+	case "resources/list":
+		if s.resources != nil {
+			return s.listResources()
+		}
+	case "resources/read":
+		if s.resources != nil {
+			return s.readResource(params)
+		}
+	}
+	return nil, &Error{Code: CodeMethodNotFound, Message: "method not found: " + method}
+}
+
+// decodeParams reads the params object of a method into v.
+func decodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &Error{Code: CodeInvalidParams, Message: "invalid params: " + err.Error()}
+	}
+	return nil
+}
+
+// initialize answers the handshake: the client's protocol version when
+// the server supports it, else the latest, and the capabilities.
+func (s *Server) initialize(params json.RawMessage) (any, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	version := ProtocolVersion
+	if slices.Contains(supportedVersions, p.ProtocolVersion) {
+		version = p.ProtocolVersion
+	}
+	capabilities := map[string]any{"tools": map[string]any{}}
+	if s.resources != nil {
+		capabilities["resources"] = map[string]any{}
+	}
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities":    capabilities,
+		"serverInfo":      map[string]string{"name": s.name, "version": s.version},
+		"instructions":    s.Instructions,
+	}, nil
+}
+
+// listTools answers tools/list.
+func (s *Server) listTools() any {
+	tools := make([]map[string]any, 0, len(s.tools))
+	for _, t := range s.tools {
+		schema := t.InputSchema
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+		tools = append(tools, map[string]any{
+			"name":        t.Name,
+			"description": t.Description,
+			"inputSchema": schema,
+		})
+	}
+	return map[string]any{"tools": tools}
+}
+
+// textContent is a text content block of a tool result.
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// toolResult is the result of tools/call. A failed call is a result too,
+// with isError, so the model sees the error and can correct itself.
+type toolResult struct {
+	Content           []textContent `json:"content"`
+	StructuredContent any           `json:"structuredContent,omitempty"`
+	IsError           bool          `json:"isError"`
+}
+
+// callTool answers tools/call: an unknown tool is a protocol error; the
+// tool's own error is a failed result carrying the message and the exit
+// code.
+func (s *Server) callTool(params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(s.tools, func(t Tool) bool { return t.Name == p.Name })
+	if i < 0 {
+		return nil, &Error{Code: CodeInvalidParams, Message: "unknown tool: " + p.Name}
+	}
+	if len(p.Arguments) == 0 || string(p.Arguments) == "null" {
+		p.Arguments = json.RawMessage("{}")
+	}
+	v, err := s.tools[i].Call(p.Arguments)
+	if err != nil {
+		data := dataOf(err)
+		return toolResult{
+			Content: []textContent{{Type: "text", Text: err.Error()}},
+			StructuredContent: map[string]any{
+				"error":     err.Error(),
+				"exit_code": data.ExitCode,
+				"kind":      data.Kind,
+			},
+			IsError: true,
+		}, nil
+	}
+	text, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s result: %w", p.Name, err)
+	}
+	result := toolResult{Content: []textContent{{Type: "text", Text: string(text)}}}
+	if bytes.HasPrefix(text, []byte("{")) {
+		result.StructuredContent = json.RawMessage(text)
+	}
+	return result, nil
+}
+
+// listResources answers resources/list.
+func (s *Server) listResources() (any, error) {
+	resources, err := s.resources.List()
+	if err != nil {
+		return nil, err
+	}
+	if resources == nil {
+		resources = []Resource{}
+	}
+	return map[string]any{"resources": resources}, nil
+}
+
+// readResource answers resources/read.
+func (s *Server) readResource(params json.RawMessage) (any, error) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	contents, err := s.resources.Read(p.URI)
+	if errors.Is(err, ErrResourceNotFound) {
+		return nil, &Error{Code: CodeResourceNotFound, Message: err.Error(), Data: dataOf(err)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"contents": []Contents{contents}}, nil
+}
+
+// DecodeArguments reads the arguments of a tool call into v, strictly:
+// an unknown argument or one of the wrong type is a usage error, as a
+// bad flag is for the command.
+func DecodeArguments(args json.RawMessage, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return exitcode.Usage(fmt.Errorf("invalid arguments: %w", err))
+	}
+	return nil
+}
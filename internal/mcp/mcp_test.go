@@ -0,0 +1,285 @@
+// Package mcp_test holds the black-box unit tests of the MCP server
+// (Seam 2): the JSON-RPC framing, the lifecycle, tools and resources
+// methods, and the mapping of errors onto the exit code convention.
+package mcp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Sanmoo/my-tasks2/internal/exitcode"
+	"github.com/Sanmoo/my-tasks2/internal/mcp"
+)
+
+// notes is a resource provider of fixed text resources.
+type notes map[string]string
+
+func (n notes) List() ([]mcp.Resource, error) {
+	if n == nil {
+		return nil, nil
+	}
+	if _, ok := n["broken"]; ok {
+		return nil, errors.New("listing failed")
+	}
+	var out []mcp.Resource
+	for uri := range n {
+		out = append(out, mcp.Resource{URI: uri, Name: uri, MIMEType: "text/plain"})
+	}
+	return out, nil
+}
+
+func (n notes) Read(uri string) (mcp.Contents, error) {
+	text, ok := n[uri]
+	if !ok {
+		return mcp.Contents{}, fmt.Errorf("%s: %w", uri, mcp.ErrResourceNotFound)
+	}
+	return mcp.Contents{URI: uri, MIMEType: "text/plain", Text: text}, nil
+}
+
+// newServer returns a server with an echo tool, a failing tool and the
+// resources res.
+func newServer(res mcp.Resources) *mcp.Server {
+	s := mcp.NewServer("mt", "test")
+	s.Instructions = "use the tools"
+	s.AddTool(mcp.Tool{
+		Name:        "echo",
+		Description: "echoes its text",
+		InputSchema: map[string]any{"type": "object", "properties": map[string]any{"text": map[string]any{"type": "string"}}},
+		Call: func(args json.RawMessage) (any, error) {
+			var a struct {
+				Text string `json:"text"`
+			}
+			if err := mcp.DecodeArguments(args, &a); err != nil {
+				return nil, err
+			}
+			return map[string]string{"text": a.Text}, nil
+		},
+	})
+	s.AddTool(mcp.Tool{
+		Name: "fail",
+		Call: func(json.RawMessage) (any, error) { return nil, errors.New("nothing to do") },
+	})
+	s.AddTool(mcp.Tool{
+		Name: "many",
+		Call: func(json.RawMessage) (any, error) { return []int{1, 2}, nil },
+	})
+	if res != nil {
+		s.SetResources(res)
+	}
+	return s
+}
+
+// handle sends one message and decodes the response; nil when there is
+// none.
+func handle(t *testing.T, s *mcp.Server, msg string) map[string]any {
+	t.Helper()
+	data := s.Handle([]byte(msg))
+	if data == nil {
+		return nil
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatalf("response %s: %v", data, err)
+	}
+	if resp["jsonrpc"] != "2.0" {
+		t.Errorf("response %s: want jsonrpc 2.0", data)
+	}
+	return resp
+}
+
+// result returns the result object of a successful response.
+func result(t *testing.T, resp map[string]any) map[string]any {
+	t.Helper()
+	r, ok := resp["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("response %v has no result", resp)
+	}
+	return r
+}
+
+// rpcError returns the error object of a failed response.
+func rpcError(t *testing.T, resp map[string]any) map[string]any {
+	t.Helper()
+	e, ok := resp["error"].(map[string]any)
+	if !ok {
+		t.Fatalf("response %v has no error", resp)
+	}
+	return e
+}
+
+func TestInitializeNegotiatesTheVersion(t *testing.T) {
+	s := newServer(notes{})
+	r := result(t, handle(t, s, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"c","version":"1"}}}`))
+	if r["protocolVersion"] != "2025-03-26" {
+		t.Errorf("protocolVersion = %v, want the client's", r["protocolVersion"])
+	}
+	if info := r["serverInfo"].(map[string]any); info["name"] != "mt" || info["version"] != "test" {
+		t.Errorf("serverInfo = %v", info)
+	}
+	caps := r["capabilities"].(map[string]any)
+	if _, ok := caps["tools"]; !ok {
+		t.Errorf("capabilities = %v, want tools", caps)
+	}
+	if _, ok := caps["resources"]; !ok {
+		t.Errorf("capabilities = %v, want resources", caps)
+	}
+	if r["instructions"] != "use the tools" {
+		t.Errorf("instructions = %v", r["instructions"])
+	}
+
+	r = result(t, handle(t, newServer(nil), `{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`))
+	if r["protocolVersion"] != mcp.ProtocolVersion {
+		t.Errorf("protocolVersion = %v, want %s for an unknown one", r["protocolVersion"], mcp.ProtocolVersion)
+	}
+	if _, ok := r["capabilities"].(map[string]any)["resources"]; ok {
+		t.Errorf("capabilities = %v, want no resources without a provider", r["capabilities"])
+	}
+}
+
+func TestHandleFraming(t *testing.T) {
+	s := newServer(nil)
+	if resp := handle(t, s, `{"jsonrpc":"2.0","method":"notifications/initialized"}`); resp != nil {
+		t.Errorf("notification answered with %v", resp)
+	}
+	if resp := handle(t, s, `{"jsonrpc":"2.0","id":7,"result":{}}`); resp != nil {
+		t.Errorf("response answered with %v", resp)
+	}
+	if resp := handle(t, s, `{"jsonrpc":"2.0","id":"a","method":"ping"}`); resp["id"] != "a" || len(result(t, resp)) != 0 {
+		t.Errorf("ping = %v, want an empty result with the request id", resp)
+	}
+	for name, tc := range map[string]struct {
+		msg  string
+		code float64
+	}{
+		"not JSON":       {`{"jsonrpc":`, mcp.CodeParseError},
+		"batch":          {`[{"jsonrpc":"2.0","id":1,"method":"ping"}]`, mcp.CodeInvalidRequest},
+		"no version":     {`{"id":1,"method":"ping"}`, mcp.CodeInvalidRequest},
+		"unknown method": {`{"jsonrpc":"2.0","id":1,"method":"sampling/createMessage"}`, mcp.CodeMethodNotFound},
+		"bad params":     {`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":[1]}`, mcp.CodeInvalidParams},
+		"no resources":   {`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`, mcp.CodeMethodNotFound},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := rpcError(t, handle(t, s, tc.msg))["code"]; got != tc.code {
+				t.Errorf("code = %v, want %v", got, tc.code)
+			}
+		})
+	}
+}
+
+func TestToolsList(t *testing.T) {
+	s := newServer(nil)
+	s.AddTool(mcp.Tool{Name: "echo", Description: "replaced"})
+	tools := result(t, handle(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))["tools"].([]any)
+	if len(tools) != 3 {
+		t.Fatalf("tools = %v, want 3", tools)
+	}
+	echo, fail := tools[0].(map[string]any), tools[1].(map[string]any)
+	if echo["name"] != "echo" || echo["description"] != "replaced" {
+		t.Errorf("first tool = %v, want the replaced echo in its place", echo)
+	}
+	if schema := fail["inputSchema"].(map[string]any); schema["type"] != "object" {
+		t.Errorf("default inputSchema = %v, want an object schema", schema)
+	}
+}
+
+func TestToolsCall(t *testing.T) {
+	s := newServer(nil)
+	r := result(t, handle(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"text":"oi"}}}`))
+	if r["isError"] != false || r["structuredContent"].(map[string]any)["text"] != "oi" {
+		t.Errorf("echo result = %v", r)
+	}
+	if text := r["content"].([]any)[0].(map[string]any)["text"]; text != `{"text":"oi"}` {
+		t.Errorf("echo text content = %v, want the JSON result", text)
+	}
+
+	r = result(t, handle(t, s, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo"}}`))
+	if r["isError"] != false || r["structuredContent"].(map[string]any)["text"] != "" {
+		t.Errorf("echo without arguments = %v, want empty arguments", r)
+	}
+
+	r = result(t, handle(t, s, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"many"}}`))
+	if _, ok := r["structuredContent"]; ok || r["content"].([]any)[0].(map[string]any)["text"] != "[1,2]" {
+		t.Errorf("array result = %v, want text content only", r)
+	}
+
+	if e := rpcError(t, handle(t, s, `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"nope"}}`)); e["code"] != float64(mcp.CodeInvalidParams) {
+		t.Errorf("unknown tool error = %v", e)
+	}
+}
+
+func TestToolErrorsCarryTheExitCode(t *testing.T) {
+	s := newServer(nil)
+	for name, tc := range map[string]struct {
+		msg, kind string
+		code      float64
+	}{
+		"user error":       {`{"name":"fail"}`, "user", exitcode.UserError},
+		"unknown argument": {`{"name":"echo","arguments":{"txt":"oi"}}`, "usage", exitcode.UsageError},
+		"wrong type":       {`{"name":"echo","arguments":{"text":3}}`, "usage", exitcode.UsageError},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := result(t, handle(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":`+tc.msg+`}`))
+			sc := r["structuredContent"].(map[string]any)
+			if r["isError"] != true || sc["exit_code"] != tc.code || sc["kind"] != tc.kind || sc["error"] == "" {
+				t.Errorf("result = %v, want a failed call with exit code %v (%s)", r, tc.code, tc.kind)
+			}
+		})
+	}
+}
+
+func TestResources(t *testing.T) {
+	s := newServer(notes{"mt://a": "alpha"})
+	list := result(t, handle(t, s, `{"jsonrpc":"2.0","id":1,"method":"resources/list"}`))["resources"].([]any)
+	if len(list) != 1 || list[0].(map[string]any)["uri"] != "mt://a" {
+		t.Errorf("resources = %v", list)
+	}
+	contents := result(t, handle(t, s, `{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"mt://a"}}`))["contents"].([]any)
+	if c := contents[0].(map[string]any); c["text"] != "alpha" || c["mimeType"] != "text/plain" {
+		t.Errorf("contents = %v", contents)
+	}
+	e := rpcError(t, handle(t, s, `{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"mt://b"}}`))
+	if e["code"] != float64(mcp.CodeResourceNotFound) || e["data"].(map[string]any)["exit_code"] != float64(exitcode.UserError) {
+		t.Errorf("missing resource error = %v", e)
+	}
+
+	empty := result(t, handle(t, newServer(notes(nil)), `{"jsonrpc":"2.0","id":4,"method":"resources/list"}`))
+	if list, ok := empty["resources"].([]any); !ok || len(list) != 0 {
+		t.Errorf("empty resources = %v, want []", empty)
+	}
+	e = rpcError(t, handle(t, newServer(notes{"broken": ""}), `{"jsonrpc":"2.0","id":5,"method":"resources/list"}`))
+	if e["code"] != float64(mcp.CodeInternalError) || !strings.Contains(e["message"].(string), "listing failed") {
+		t.Errorf("failed listing error = %v", e)
+	}
+	e = rpcError(t, handle(t, s, `{"jsonrpc":"2.0","id":6,"method":"resources/read","params":{"uri":3}}`))
+	if e["code"] != float64(mcp.CodeInvalidParams) {
+		t.Errorf("bad read params error = %v", e)
+	}
+}
+
+func TestServeAnswersLineByLine(t *testing.T) {
+	in := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n\n" +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	var out strings.Builder
+	if err := newServer(nil).Serve(in, &out); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"id":1`) || !strings.Contains(lines[1], `"id":2`) {
+		t.Errorf("output = %q, want two responses, one per line", out.String())
+	}
+}
+
+func TestServeReportsWriteErrors(t *testing.T) {
+	err := newServer(nil).Serve(strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`+"\n"), failingWriter{})
+	if err == nil || !strings.Contains(err.Error(), "writing response") {
+		t.Errorf("Serve error = %v", err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("closed") }
@@ -686,3 +686,27 @@ func duplicateRanksAfter(issues []priority.Issue, changes []priority.Change) []i
 	slices.Sort(dups)
 	return dups
 }
+
+func TestParsePlacement(t *testing.T) {
+	tests := []struct {
+		in       string
+		action   priority.QuickAction
+		position int
+	}{
+		{"top", priority.MoveTop, 0},
+		{"bottom", priority.MoveBottom, 0},
+		{"1", priority.MoveToRank, 1},
+		{"12", priority.MoveToRank, 12},
+	}
+	for _, tt := range tests {
+		action, position, err := priority.ParsePlacement(tt.in)
+		if err != nil || action != tt.action || position != tt.position {
+			t.Errorf("ParsePlacement(%q) = %v, %d, %v; want %v, %d", tt.in, action, position, err, tt.action, tt.position)
+		}
+	}
+	for _, in := range []string{"", "middle", "0", "-1", "Top", "2.5"} {
+		if _, _, err := priority.ParsePlacement(in); err == nil || !strings.Contains(err.Error(), "rank must be top, bottom or a positive position") {
+			t.Errorf("ParsePlacement(%q) error = %v, want the placement error", in, err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package priority
 import (
 	"fmt"
 	"slices"
+	"strconv"
 )
 
 // QuickAction identifies the immediate order change requested by a quick
@@ -20,6 +21,23 @@ const (
 	RemoveRank
 )
 
+// ParsePlacement reads a queue placement as spelled on the command line
+// and in templates: "top", "bottom" or a one-based position. Whether the
+// position fits the queue is decided by QuickPlan.
+func ParsePlacement(s string) (QuickAction, int, error) {
+	switch s {
+	case "top":
+		return MoveTop, 0, nil
+	case "bottom":
+		return MoveBottom, 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, 0, fmt.Errorf("rank must be top, bottom or a positive position, got %q", s)
+	}
+	return MoveToRank, n, nil
+}
+
 // QuickPlan computes the minimal rank changes for a quick ordering action.
 // The position is the one-based final queue position for MoveToRank and is
 // ignored by the other actions. Non-prioritizable issues are not part of the
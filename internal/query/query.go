@@ -0,0 +1,321 @@
+// Package query holds the pure logic of the --where selector: a small
+// query language over an Issue's ID and frontmatter, shared by list and
+// the batch commands. A query is whitespace-separated terms, all of
+// which must match (AND):
+//
+//	status=open labels=errands rank<=3 deadline<2026-09-01 title~"material"
+//
+// The vault's custom fields are queryable too, by name: an int field
+// compares numerically, a list field by membership, the others as text.
+//
+// It is decision-dense, so it lives at Seam 2: black-box unit tested,
+// with the coverage and mutation gates.
+package query
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+)
+
+// op is a comparison operator of a term.
+type op string
+
+const (
+	opEq       op = "="
+	opNe       op = "!="
+	opLt       op = "<"
+	opLe       op = "<="
+	opGt       op = ">"
+	opGe       op = ">="
+	opContains op = "~"
+)
+
+// ops lists the operators in tokenizing order: two-char operators before
+// their one-char prefixes, so "<=" is never read as "<" plus "=...".
+var ops = []op{opNe, opLe, opGe, opEq, opLt, opGt, opContains}
+
+// kind is how a field's value compares.
+type kind int
+
+const (
+	kindText kind = iota // plain string: =, !=, ~ and lexicographic order
+	kindInt              // rank: numeric order
+	kindList             // labels, blocked_by: = and != test membership
+)
+
+// fields maps each queryable field to its kind. Datetime fields are text:
+// the canonical YYYY-MM-DDTHH:MM layout orders lexicographically, so a
+// date prefix such as 2026-09-01 compares as expected.
+var fields = map[string]kind{
+	"id":             kindText,
+	"title":          kindText,
+	"status":         kindText,
+	"labels":         kindList,
+	"created_at":     kindText,
+	"rank":           kindInt,
+	"deferred_until": kindText,
+	"deadline":       kindText,
+	"started_at":     kindText,
+	"completed_at":   kindText,
+	"blocked_by":     kindList,
+}
+
+// aliases are accepted spellings of field names.
+var aliases = map[string]string{"label": "labels"}
+
+// Query is a parsed --where selector. The zero Query matches everything.
+type Query struct {
+	terms []term
+}
+
+// term is one field-operator-value comparison. custom marks a field of
+// the vault's own, read from the frontmatter's Extra.
+type term struct {
+	field  string
+	kind   kind
+	custom bool
+	op     op
+	value  string
+}
+
+// Parse parses a --where selector. Values may be double-quoted to hold
+// spaces (title~"comprar material"). An empty value tests absence:
+// deadline= matches Issues without a deadline, deadline!= those with
+// one. custom declares the vault's custom fields. Parse rejects unknown
+// fields, unknown operators, ordering operators on list fields and
+// non-integer values of integer fields.
+func Parse(s string, custom ...issue.FieldSpec) (Query, error) {
+	words, err := split(s)
+	if err != nil {
+		return Query{}, err
+	}
+	if len(words) == 0 {
+		return Query{}, errors.New("empty query: want terms like status=open or rank<=3")
+	}
+	q := Query{terms: make([]term, 0, len(words))}
+	for _, w := range words {
+		t, err := parseTerm(w, custom)
+		if err != nil {
+			return Query{}, err
+		}
+		q.terms = append(q.terms, t)
+	}
+	return q, nil
+}
+
+// split splits s on whitespace outside double quotes, dropping the
+// quotes themselves.
+func split(s string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	inWord, quoted := false, false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inWord = true
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("invalid query %q: unclosed quote", s)
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, nil
+}
+
+// parseTerm parses one field-operator-value word.
+func parseTerm(w string, custom []issue.FieldSpec) (term, error) {
+	at := strings.IndexAny(w, "=!<>~")
+	if at <= 0 {
+		return term{}, fmt.Errorf("invalid query term %q: want <field><op><value> with op one of = != < <= > >= ~", w)
+	}
+	name := w[:at]
+	if alias, ok := aliases[name]; ok {
+		name = alias
+	}
+	k, ok := fields[name]
+	spec := slices.IndexFunc(custom, func(f issue.FieldSpec) bool { return f.Name == name })
+	switch {
+	case ok:
+	case spec >= 0:
+		k = customKind(custom[spec].Type)
+	default:
+		return term{}, fmt.Errorf("invalid query term %q: unknown field %q", w, name)
+	}
+	rest := w[at:]
+	var found op
+	for _, o := range ops {
+		if strings.HasPrefix(rest, string(o)) {
+			found = o
+			break
+		}
+	}
+	if found == "" {
+		return term{}, fmt.Errorf("invalid query term %q: unknown operator", w)
+	}
+	value := rest[len(found):]
+	switch {
+	case value == "" && found != opEq && found != opNe:
+		return term{}, fmt.Errorf("invalid query term %q: %s needs a value", w, found)
+	case k == kindList && found != opEq && found != opNe:
+		return term{}, fmt.Errorf("invalid query term %q: %s supports only = and !=", w, name)
+	case k == kindInt && value != "":
+		if _, err := strconv.Atoi(value); err != nil {
+			return term{}, fmt.Errorf("invalid query term %q: %s must be compared with an integer", w, name)
+		}
+	}
+	return term{field: name, kind: k, custom: !ok, op: found, value: value}, nil
+}
+
+// customKind is how a custom field of type t compares.
+func customKind(t issue.FieldType) kind {
+	switch t {
+	case issue.TypeInt:
+		return kindInt
+	case issue.TypeList:
+		return kindList
+	default:
+		return kindText
+	}
+}
+
+// Match reports whether the Issue with file-name id and frontmatter fm
+// satisfies every term of q.
+func (q Query) Match(id string, fm issue.Frontmatter) bool {
+	for _, t := range q.terms {
+		if !t.match(id, fm) {
+			return false
+		}
+	}
+	return true
+}
+
+// match evaluates one term. A term on an absent value (empty text, nil
+// rank, empty list) matches only the absence tests (field= and
+// field!=value) — ordering never matches a missing value.
+func (t term) match(id string, fm issue.Frontmatter) bool {
+	switch t.kind {
+	case kindList:
+		values := t.listValue(fm)
+		if t.value == "" {
+			return (len(values) == 0) == (t.op == opEq)
+		}
+		return slices.Contains(values, t.value) == (t.op == opEq)
+	case kindInt:
+		got, ok := t.intValue(fm)
+		if !ok {
+			if t.value == "" {
+				return t.op == opEq
+			}
+			return t.op == opNe
+		}
+		if t.value == "" {
+			return t.op == opNe
+		}
+		want, _ := strconv.Atoi(t.value) // validated by Parse
+		return compare(t.op, cmp.Compare(got, want))
+	default:
+		got := t.textValue(id, fm)
+		if t.value == "" {
+			return (got == "") == (t.op == opEq)
+		}
+		if t.op == opContains {
+			return strings.Contains(strings.ToLower(got), strings.ToLower(t.value))
+		}
+		if got == "" {
+			return t.op == opNe
+		}
+		return compare(t.op, cmp.Compare(got, t.value))
+	}
+}
+
+// compare applies an operator to a cmp.Compare result.
+func compare(o op, c int) bool {
+	switch o {
+	case opEq:
+		return c == 0
+	case opNe:
+		return c != 0
+	case opLt:
+		return c < 0
+	case opLe:
+		return c <= 0
+	case opGt:
+		return c > 0
+	case opGe:
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+// listValue returns the values of a list field. A custom value that is
+// not a list counts as absent.
+func (t term) listValue(fm issue.Frontmatter) []string {
+	if t.custom {
+		v, _ := fm.Extra.Get(t.field)
+		list, _ := v.([]string)
+		return list
+	}
+	if t.field == "labels" {
+		return fm.Labels
+	}
+	return fm.BlockedBy
+}
+
+// intValue returns the value of an integer field, ok false when it is
+// absent (or, for a custom field, not an integer).
+func (t term) intValue(fm issue.Frontmatter) (int, bool) {
+	if t.custom {
+		v, _ := fm.Extra.Get(t.field)
+		n, ok := v.(int)
+		return n, ok
+	}
+	if fm.Rank == nil {
+		return 0, false
+	}
+	return *fm.Rank, true
+}
+
+// textValue returns the value of a text field.
+func (t term) textValue(id string, fm issue.Frontmatter) string {
+	if t.custom {
+		v, _ := fm.Extra.Get(t.field)
+		return issue.FormatValue(v)
+	}
+	switch t.field {
+	case "id":
+		return id
+	case "title":
+		return fm.Title
+	case "status":
+		return fm.Status
+	case "created_at":
+		return fm.CreatedAt
+	case "deferred_until":
+		return fm.DeferredUntil
+	case "deadline":
+		return fm.Deadline
+	case "started_at":
+		return fm.StartedAt
+	default:
+		return fm.CompletedAt
+	}
+}
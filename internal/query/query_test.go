@@ -0,0 +1,221 @@
+// Package query_test holds the black-box unit tests of the --where
+// selector (Seam 2): parsing (quoting, operators, field validation) and
+// matching over text, rank, datetime and list fields, including the
+// absence tests.
+package query_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/query"
+)
+
+func intPtr(v int) *int { return &v }
+
+// sample is the frontmatter most match cases run against.
+var sample = issue.Frontmatter{
+	Title:     "Comprar material de Assaí",
+	Status:    "open",
+	Labels:    []string{"compras", "familia"},
+	CreatedAt: "2026-08-15T09:30",
+	Rank:      intPtr(3),
+	Deadline:  "2026-08-30T18:00",
+	BlockedBy: []string{"pkm-042"},
+}
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		query string
+		want  bool
+	}{
+		{"status=open", true},
+		{"status=done", false},
+		{"status!=done", true},
+		{"status!=open", false},
+		{"id=pkm-055", true},
+		{"id!=pkm-055", false},
+		{"title~material", true},
+		{"title~MATERIAL", true},
+		{`title~"material de"`, true},
+		{"title~tarefa", false},
+		{"labels=compras", true},
+		{"label=familia", true},
+		{"labels=trabalho", false},
+		{"labels!=trabalho", true},
+		{"labels!=compras", false},
+		{"labels=", false},
+		{"labels!=", true},
+		{"blocked_by=pkm-042", true},
+		{"blocked_by=", false},
+		{"rank=3", true},
+		{"rank!=3", false},
+		{"rank<3", false},
+		{"rank<=3", true},
+		{"rank>2", true},
+		{"rank>=4", false},
+		{"rank=", false},
+		{"rank!=", true},
+		{"deadline<2026-09-01", true},
+		{"deadline>2026-09-01", false},
+		{"deadline>=2026-08-30T18:00", true},
+		{"deadline<=2026-08-30", false},
+		{"deadline=", false},
+		{"deadline!=", true},
+		{"deferred_until=", true},
+		{"deferred_until!=", false},
+		{"deferred_until<2099-01-01", false},
+		{"deferred_until!=2026-01-01", true},
+		{"started_at=", true},
+		{"completed_at=", true},
+		{"created_at<2026-08-16", true},
+		{"status=open labels=compras rank<=3", true},
+		{"status=open labels=trabalho", false},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			q, err := query.Parse(c.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", c.query, err)
+			}
+			if got := q.Match("pkm-055", sample); got != c.want {
+				t.Errorf("Parse(%q).Match = %v, want %v", c.query, got, c.want)
+			}
+		})
+	}
+}
+
+func TestMatchUnrankedIssue(t *testing.T) {
+	fm := issue.Frontmatter{Title: "t", Status: "open"}
+	cases := []struct {
+		query string
+		want  bool
+	}{
+		{"rank=", true},
+		{"rank!=", false},
+		{"rank=1", false},
+		{"rank!=1", true},
+		{"rank<1", false},
+		{"rank>=1", false},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			q, err := query.Parse(c.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", c.query, err)
+			}
+			if got := q.Match("pkm-001", fm); got != c.want {
+				t.Errorf("Parse(%q).Match(unranked) = %v, want %v", c.query, got, c.want)
+			}
+		})
+	}
+}
+
+func TestZeroQueryMatchesEverything(t *testing.T) {
+	if !(query.Query{}).Match("pkm-001", issue.Frontmatter{}) {
+		t.Error("zero Query does not match")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		query string
+		want  string
+	}{
+		{"", "empty query"},
+		{"   ", "empty query"},
+		{"status", "want <field><op><value>"},
+		{"=open", "want <field><op><value>"},
+		{"color=red", `unknown field "color"`},
+		{"status!open", "unknown operator"},
+		{"labels<x", "supports only = and !="},
+		{"labels~x", "supports only = and !="},
+		{"rank<x", "must be compared with an integer"},
+		{"rank<", "needs a value"},
+		{"title~", "needs a value"},
+		{`title~"open`, "unclosed quote"},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			_, err := query.Parse(c.query)
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("Parse(%q) error = %v, want it to contain %q", c.query, err, c.want)
+			}
+		})
+	}
+}
+
+func TestParseSplitsOnAnyWhitespace(t *testing.T) {
+	q, err := query.Parse("status=open\tlabels=compras\nrank=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.Match("pkm-055", sample) {
+		t.Error("query split on tabs/newlines does not match")
+	}
+	if q.Match("pkm-055", issue.Frontmatter{Status: "open", Labels: []string{"compras"}}) {
+		t.Error("query split on tabs/newlines lost its rank term")
+	}
+}
+
+func TestMatchCustomFields(t *testing.T) {
+	custom := []issue.FieldSpec{
+		{Name: "energy", Type: issue.TypeEnum, Values: []string{"low", "high"}},
+		{Name: "estimate", Type: issue.TypeInt},
+		{Name: "contexts", Type: issue.TypeList},
+		{Name: "url", Type: issue.TypeString},
+	}
+	fm := sample
+	fm.Extra = issue.Fields{
+		{Name: "energy", Value: "low"},
+		{Name: "estimate", Value: 3},
+		{Name: "contexts", Value: []string{"phone", "desk"}},
+	}
+	cases := []struct {
+		query string
+		want  bool
+	}{
+		{"energy=low", true},
+		{"energy=high", false},
+		{"energy!=high", true},
+		{"estimate<=3", true},
+		{"estimate>3", false},
+		{"contexts=phone", true},
+		{"contexts=car", false},
+		{"url=", true},
+		{"url!=", false},
+		{"url~x", false},
+		{"energy=low status=open rank=3", true},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			q, err := query.Parse(c.query, custom...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := q.Match("pkm-001", fm); got != c.want {
+				t.Errorf("Match(%q) = %v, want %v", c.query, got, c.want)
+			}
+		})
+	}
+
+	wrong := sample
+	wrong.Extra = issue.Fields{{Name: "estimate", Value: "three"}, {Name: "contexts", Value: "phone"}}
+	for _, c := range []string{"estimate=", "contexts="} {
+		q, _ := query.Parse(c, custom...)
+		if !q.Match("pkm-001", wrong) {
+			t.Errorf("Match(%q) of a mistyped value = false, want it absent", c)
+		}
+	}
+
+	for s, want := range map[string]string{
+		"contexts<x":    "supports only = and !=",
+		"estimate>lots": "must be compared with an integer",
+		"client=x":      `unknown field "client"`,
+	} {
+		if _, err := query.Parse(s, custom...); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want %q", s, err, want)
+		}
+	}
+}
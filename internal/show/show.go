@@ -12,6 +12,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/glamour"
 
@@ -27,6 +28,9 @@ type Options struct {
 	// Width is the terminal width in columns; 0 means unknown and the
 	// body wraps at the default width.
 	Width int
+	// Location is the vault's timezone: datetimes stored with a UTC
+	// offset are shown in it. nil shows them as stored.
+	Location *time.Location
 }
 
 // The ayu palette (the nd show palette), one hex per role per
@@ -46,15 +50,18 @@ var (
 //	Rank: 3
 //	Deferred until: 2026-08-23 00:00
 //	Deadline: 2026-08-30 00:00
+//	Warn before: 3d
 //	Started: 2026-06-27 09:00
 //	Completed: 2026-06-30 10:51
 //	Blocked by: bjd-001, bjd-002
+//	energy: low
 //
 //	## Description
 //	...
 //
 // Metadata lines appear only when their field is set (labels and
-// blocked_by only when non-empty). The body is glamour-rendered when
+// blocked_by only when non-empty); custom fields follow, labeled by
+// their name. The body is glamour-rendered when
 // Color is on, verbatim otherwise. The returned string ends with a
 // trailing newline (the body's own).
 func Render(i issue.Issue, id string, opts Options) string {
@@ -74,34 +81,67 @@ func Render(i issue.Issue, id string, opts Options) string {
 	b.WriteString(fg(fm.Status, pick(statusColor, dark), opts.Color))
 	b.WriteString("]\n")
 
-	meta(&b, "Created", displayTime(fm.CreatedAt), opts.Color, dark)
+	for _, f := range Fields(fm, opts.Location) {
+		meta(&b, f.Label, f.Value, opts.Color, dark)
+	}
+
+	if i.Body != "" {
+		b.WriteString("\n")
+		b.WriteString(renderBody(i.Body, opts, dark))
+	}
+	return b.String()
+}
+
+// Field is one metadata line of the show view.
+type Field struct {
+	Label string
+	Value string
+}
+
+// Fields returns the metadata lines of the show view of fm, in view
+// order: Created, then each set field (labels and blocked_by only when
+// non-empty), then each non-empty custom field in file order. Times are
+// in the display form (YYYY-MM-DD HH:MM); custom values are shown as
+// stored, lists joined with ", ". A time stored with a UTC offset is
+// shown in loc, the vault's timezone, when loc is set.
+func Fields(fm issue.Frontmatter, loc *time.Location) []Field {
+	fields := []Field{{"Created", displayTime(fm.CreatedAt, loc)}}
 	if len(fm.Labels) > 0 {
-		meta(&b, "Labels", strings.Join(fm.Labels, ", "), opts.Color, dark)
+		fields = append(fields, Field{"Labels", strings.Join(fm.Labels, ", ")})
 	}
 	if fm.Rank != nil {
-		meta(&b, "Rank", strconv.Itoa(*fm.Rank), opts.Color, dark)
+		fields = append(fields, Field{"Rank", strconv.Itoa(*fm.Rank)})
 	}
 	if fm.DeferredUntil != "" {
-		meta(&b, "Deferred until", displayTime(fm.DeferredUntil), opts.Color, dark)
+		fields = append(fields, Field{"Deferred until", displayTime(fm.DeferredUntil, loc)})
 	}
 	if fm.Deadline != "" {
-		meta(&b, "Deadline", displayTime(fm.Deadline), opts.Color, dark)
+		fields = append(fields, Field{"Deadline", displayTime(fm.Deadline, loc)})
+	}
+	if fm.WarnBefore != "" {
+		fields = append(fields, Field{"Warn before", fm.WarnBefore})
+	}
+	if fm.Recur != "" {
+		fields = append(fields, Field{"Recurs every", fm.Recur})
 	}
 	if fm.StartedAt != "" {
-		meta(&b, "Started", displayTime(fm.StartedAt), opts.Color, dark)
+		fields = append(fields, Field{"Started", displayTime(fm.StartedAt, loc)})
+	}
+	if len(fm.WorkLog) > 0 {
+		fields = append(fields, Field{"Worked", worked(fm.WorkLog, loc)})
 	}
 	if fm.CompletedAt != "" {
-		meta(&b, "Completed", displayTime(fm.CompletedAt), opts.Color, dark)
+		fields = append(fields, Field{"Completed", displayTime(fm.CompletedAt, loc)})
 	}
 	if len(fm.BlockedBy) > 0 {
-		meta(&b, "Blocked by", strings.Join(fm.BlockedBy, ", "), opts.Color, dark)
+		fields = append(fields, Field{"Blocked by", strings.Join(fm.BlockedBy, ", ")})
 	}
-
-	if i.Body != "" {
-		b.WriteString("\n")
-		b.WriteString(renderBody(i.Body, opts, dark))
+	for _, f := range fm.Extra {
+		if !issue.IsEmpty(f.Value) {
+			fields = append(fields, Field{f.Name, issue.FormatValue(f.Value)})
+		}
 	}
-	return b.String()
+	return fields
 }
 
 // meta writes one "Label: value" line; the label is accented when
@@ -111,11 +151,49 @@ func meta(b *strings.Builder, label, value string, color, dark bool) {
 	b.WriteString(" " + value + "\n")
 }
 
-// displayTime renders a stored naive datetime for display: the
-// canonical YYYY-MM-DDTHH:MM becomes YYYY-MM-DD HH:MM. Only the first
-// T is touched, so a value that does not follow the canonical layout
-// passes through almost unchanged (mt check owns format validation).
-func displayTime(v string) string {
+// worked renders a work_log: the time its closed intervals add up to
+// and the number of sessions, then the start of the open one, if any —
+// "3h30m over 2 sessions, on since 2026-08-17 10:00". A malformed
+// interval counts as a session but adds no time (mt check owns it).
+func worked(log []string, loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	var total time.Duration
+	since := ""
+	for _, entry := range log {
+		start, end, closed := strings.Cut(entry, issue.WorkSep)
+		if !closed {
+			since = displayTime(start, loc)
+			continue
+		}
+		s, okStart := issue.ParseStamp(start, loc)
+		e, okEnd := issue.ParseStamp(end, loc)
+		if okStart && okEnd && e.After(s) {
+			total += e.Sub(s)
+		}
+	}
+	sessions := "sessions"
+	if len(log) == 1 {
+		sessions = "session"
+	}
+	out := fmt.Sprintf("%dh%02dm over %d %s", int(total.Hours()), int(total.Minutes())%60, len(log), sessions)
+	if since != "" {
+		out += ", on since " + since
+	}
+	return out
+}
+
+// displayTime renders a stored datetime for display: the canonical
+// YYYY-MM-DDTHH:MM becomes YYYY-MM-DD HH:MM, and a value with a UTC
+// offset (issue.OffsetLayout) becomes that form in loc, when set. Only
+// the first T of any other value is touched, so one that does not follow
+// a canonical layout passes through almost unchanged (mt check owns
+// format validation).
+func displayTime(v string, loc *time.Location) string {
+	if t, err := time.Parse(issue.OffsetLayout, v); err == nil && loc != nil {
+		return t.In(loc).Format("2006-01-02 15:04")
+	}
 	return strings.Replace(v, "T", " ", 1)
 }
 
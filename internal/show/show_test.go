@@ -1,8 +1,10 @@
 package show_test
 
 import (
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Sanmoo/my-tasks2/internal/issue"
 	"github.com/Sanmoo/my-tasks2/internal/show"
@@ -88,13 +90,96 @@ Created: 2026-06-26 18:00
 	if got != want {
 		t.Errorf("Render(plain minimal) = %q, want %q", got, want)
 	}
-	for _, absent := range []string{"Labels:", "Rank:", "Deferred until:", "Deadline:", "Started:", "Completed:", "Blocked by:"} {
+	for _, absent := range []string{"Labels:", "Rank:", "Deferred until:", "Deadline:", "Warn before:", "Started:", "Completed:", "Blocked by:"} {
 		if strings.Contains(got, absent) {
 			t.Errorf("plain minimal render must omit %q:\n%q", absent, got)
 		}
 	}
 }
 
+func TestFieldsShowsWarnBeforeAfterDeadline(t *testing.T) {
+	fm := full().Frontmatter
+	fm.WarnBefore = "3d"
+	got := show.Fields(fm, nil)
+	if got[4].Label != "Deadline" || got[5] != (show.Field{Label: "Warn before", Value: "3d"}) {
+		t.Errorf("Fields(warn_before) = %v, want Warn before: 3d after the deadline", got)
+	}
+}
+
+func TestFieldsShowsRecurAfterWarnBefore(t *testing.T) {
+	fm := full().Frontmatter
+	fm.WarnBefore = "3d"
+	fm.Recur = "1w"
+	got := show.Fields(fm, nil)
+	if got[5].Label != "Warn before" || got[6] != (show.Field{Label: "Recurs every", Value: "1w"}) {
+		t.Errorf("Fields(recur) = %v, want Recurs every: 1w after warn_before", got)
+	}
+}
+
+func TestFieldsShowsTheTimeWorked(t *testing.T) {
+	fm := full().Frontmatter
+	for _, tt := range []struct {
+		log  []string
+		want string
+	}{
+		{[]string{"2026-08-15T09:00/2026-08-15T11:30", "2026-08-16T14:00/2026-08-16T15:00"}, "3h30m over 2 sessions"},
+		{[]string{"2026-08-15T09:00/2026-08-15T09:45", "2026-08-17T10:00"}, "0h45m over 2 sessions, on since 2026-08-17 10:00"},
+		{[]string{"2026-08-15T09:00-03:00/2026-08-15T13:00Z"}, "1h00m over 1 session"},
+		{[]string{"bogus/2026-08-15T09:00", "2026-08-15T10:00/2026-08-15T09:00"}, "0h00m over 2 sessions"},
+	} {
+		fm.WorkLog = tt.log
+		var got string
+		for _, f := range show.Fields(fm, nil) {
+			if f.Label == "Worked" {
+				got = f.Value
+			}
+		}
+		if got != tt.want {
+			t.Errorf("Fields(work_log %q) Worked = %q, want %q", tt.log, got, tt.want)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	got := show.Fields(full().Frontmatter, nil)
+	labels := make([]string, len(got))
+	for i, f := range got {
+		labels[i] = f.Label
+	}
+	if strings.Join(labels, ",") != "Created,Labels,Rank,Deferred until,Deadline,Started,Completed,Blocked by" {
+		t.Errorf("Fields(full) labels = %v", labels)
+	}
+	if got[0].Value != "2026-06-26 18:00" || got[1].Value != "compras, familia" || got[7].Value != "bjd-001, bjd-002" {
+		t.Errorf("Fields(full) = %v", got)
+	}
+	if got := show.Fields(minimal().Frontmatter, nil); len(got) != 1 || got[0] != (show.Field{Label: "Created", Value: "2026-06-26 18:00"}) {
+		t.Errorf("Fields(minimal) = %v, want just Created", got)
+	}
+}
+
+func TestFieldsShowsCustomFields(t *testing.T) {
+	fm := minimal().Frontmatter
+	fm.Extra = issue.Fields{{Name: "energy", Value: "low"}, {Name: "url", Value: ""}, {Name: "contexts", Value: []string{"phone", "desk"}}}
+	got := show.Fields(fm, nil)
+	want := []show.Field{{Label: "Created", Value: "2026-06-26 18:00"}, {Label: "energy", Value: "low"}, {Label: "contexts", Value: "phone, desk"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("Fields(custom) = %v, want %v", got, want)
+	}
+}
+
+func TestFieldsShowsOffsetTimesInTheVaultZone(t *testing.T) {
+	fm := minimal().Frontmatter
+	fm.CreatedAt = "2026-06-26T18:00-03:00"
+	fm.Deadline = "2026-06-30T10:00"
+	utc := show.Fields(fm, time.UTC)
+	if utc[0].Value != "2026-06-26 21:00" || utc[1].Value != "2026-06-30 10:00" {
+		t.Errorf("Fields(offset, UTC) = %v, want Created converted and the naive Deadline as is", utc)
+	}
+	if got := show.Fields(fm, nil); got[0].Value != "2026-06-26 18:00-03:00" {
+		t.Errorf("Fields(offset, nil) = %v, want the stored value", got)
+	}
+}
+
 func TestRenderPlainEmptyBody(t *testing.T) {
 	i := minimal()
 	i.Body = ""
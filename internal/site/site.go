@@ -0,0 +1,556 @@
+// Package site holds the pure logic of `mt export html`: the static,
+// read-only HTML snapshot of a vault. From the vault's Issues it builds
+// every page — the index in Rank order, the status and label facet
+// pages, one page per Issue (the show metadata, the rendered Markdown
+// body with comment permalinks, blocked_by and backlink navigation) and
+// the overdue page — as a map of relative paths to content. The pages
+// are self-contained (inline style, no scripts, no external assets) and
+// link to each other with relative URLs, so the directory can be opened
+// from disk or published as is. Writing the files is a process concern
+// and stays in internal/cli.
+package site
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+
+	"github.com/Sanmoo/my-tasks2/internal/list"
+	"github.com/Sanmoo/my-tasks2/internal/show"
+)
+
+// The paths of the fixed pages, relative to the export directory.
+const (
+	IndexPage   = "index.html"
+	OverduePage = "overdue.html"
+)
+
+// builtinStatuses are the statuses listed first among the status facets,
+// in lifecycle order; custom statuses follow alphabetically.
+var builtinStatuses = []string{"open", "in_progress", "done"}
+
+// Options control the export.
+type Options struct {
+	// Title names the vault in the page titles and headers.
+	Title string
+	// Now is the time of the export: the overdue page and the row markers
+	// are computed against it, and the footer shows it. Its location is
+	// the vault's timezone, which the Issue pages show datetimes in.
+	Now time.Time
+}
+
+// IssuePage returns the path of the page of the Issue id.
+func IssuePage(id string) string {
+	return "issues/" + id + ".html"
+}
+
+// StatusPage returns the path of the facet page of status.
+func StatusPage(status string) string {
+	return "status/" + slug(status) + ".html"
+}
+
+// LabelPage returns the path of the facet page of label.
+func LabelPage(label string) string {
+	return "labels/" + slug(label) + ".html"
+}
+
+// slug makes s safe as a file name and a URL path segment: ASCII
+// lowercase letters, digits, - and _ are kept, every other byte becomes ~
+// and its two hex digits. The mapping is injective, so two facets never
+// share a page, and case-insensitive file systems cannot fold two names
+// together.
+func slug(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "~%02x", c)
+	}
+	return b.String()
+}
+
+// Build returns the pages of the export of items, keyed by path relative
+// to the export directory. items are the vault's Issues; Build orders
+// them itself, by the list order.
+func Build(items []list.Item, opts Options) (map[string][]byte, error) {
+	items = slices.Clone(items)
+	list.Sort(items)
+	s := &builder{
+		opts:       opts,
+		items:      items,
+		statusByID: list.StatusByID(items),
+		blocks:     make(map[string][]string),
+		pages:      make(map[string][]byte),
+	}
+	for _, it := range items {
+		for _, blocker := range it.Issue.Frontmatter.BlockedBy {
+			s.blocks[blocker] = append(s.blocks[blocker], it.ID)
+		}
+	}
+
+	var open []list.Item
+	for _, it := range items {
+		if list.Visible(it, list.Options{}) {
+			open = append(open, it)
+		}
+	}
+	if err := s.listing(IndexPage, opts.Title, open); err != nil {
+		return nil, err
+	}
+	for _, status := range s.statuses() {
+		var matched []list.Item
+		for _, it := range items {
+			if it.Issue.Frontmatter.Status == status {
+				matched = append(matched, it)
+			}
+		}
+		if err := s.listing(StatusPage(status), "Status: "+status, matched); err != nil {
+			return nil, err
+		}
+	}
+	for _, label := range s.labels() {
+		var matched []list.Item
+		for _, it := range items {
+			if slices.Contains(it.Issue.Frontmatter.Labels, label) {
+				matched = append(matched, it)
+			}
+		}
+		if err := s.listing(LabelPage(label), "Label: "+label, matched); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.overdue(); err != nil {
+		return nil, err
+	}
+	for _, it := range items {
+		if err := s.issue(it); err != nil {
+			return nil, err
+		}
+	}
+	return s.pages, nil
+}
+
+// builder holds the state of one export.
+type builder struct {
+	opts       Options
+	items      []list.Item
+	statusByID map[string]string
+	// blocks maps an Issue ID to the Issues it blocks (the backlinks of
+	// blocked_by), in list order.
+	blocks map[string][]string
+	pages  map[string][]byte
+}
+
+// statuses returns the statuses of the vault's Issues: the built-in ones
+// present first, in lifecycle order, then the custom ones alphabetically.
+func (s *builder) statuses() []string {
+	seen := make(map[string]bool)
+	for _, it := range s.items {
+		seen[it.Issue.Frontmatter.Status] = true
+	}
+	var out []string
+	for _, status := range builtinStatuses {
+		if seen[status] {
+			out = append(out, status)
+			delete(seen, status)
+		}
+	}
+	var custom []string
+	for status := range seen {
+		custom = append(custom, status)
+	}
+	slices.Sort(custom)
+	return append(out, custom...)
+}
+
+// labels returns the labels of the vault's Issues, alphabetically.
+func (s *builder) labels() []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, it := range s.items {
+		for _, label := range it.Issue.Frontmatter.Labels {
+			if !seen[label] {
+				seen[label] = true
+				out = append(out, label)
+			}
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// facet is a link of the facet bar: a status or label page and how many
+// Issues it lists.
+type facet struct {
+	Name  string
+	Href  string
+	Count int
+}
+
+// facets returns the status and label facets, with hrefs relative to
+// root.
+func (s *builder) facets(root string) (statuses, labels []facet) {
+	for _, status := range s.statuses() {
+		n := 0
+		for _, it := range s.items {
+			if it.Issue.Frontmatter.Status == status {
+				n++
+			}
+		}
+		statuses = append(statuses, facet{status, root + StatusPage(status), n})
+	}
+	for _, label := range s.labels() {
+		n := 0
+		for _, it := range s.items {
+			if slices.Contains(it.Issue.Frontmatter.Labels, label) {
+				n++
+			}
+		}
+		labels = append(labels, facet{label, root + LabelPage(label), n})
+	}
+	return statuses, labels
+}
+
+// row is an Issue in a listing: the list line's parts and its markers.
+type row struct {
+	Glyph   string
+	ID      string
+	Href    string
+	Title   string
+	Status  string
+	Rank    string
+	Labels  []string
+	Markers []string
+}
+
+// rows builds the listing rows of items, with hrefs relative to root.
+func (s *builder) rows(items []list.Item, root string) []row {
+	out := make([]row, 0, len(items))
+	for _, it := range items {
+		fm := it.Issue.Frontmatter
+		r := row{
+			Glyph:  list.Glyph(fm.Status),
+			ID:     it.ID,
+			Href:   root + IssuePage(it.ID),
+			Title:  fm.Title,
+			Status: fm.Status,
+			Labels: fm.Labels,
+		}
+		if fm.Rank != nil {
+			r.Rank = fmt.Sprint(*fm.Rank)
+		}
+		if fm.Status != "done" {
+			r.Markers = s.markers(fm.DeferredUntil, fm.Deadline, fm.BlockedBy)
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// markers returns the attention markers of a non-done Issue: its
+// deferral (future or expired), its passed deadline and [blocked].
+func (s *builder) markers(deferredUntil, deadline string, blockedBy []string) []string {
+	var out []string
+	for _, marker := range []string{
+		list.DeferSuffix(deferredUntil, s.opts.Now),
+		list.ExpiredSuffix(deferredUntil, s.opts.Now),
+		list.DeadlineSuffix(deadline, s.opts.Now),
+	} {
+		if marker != "" {
+			out = append(out, marker)
+		}
+	}
+	if list.Blocked(blockedBy, s.statusByID) {
+		out = append(out, "[blocked]")
+	}
+	return out
+}
+
+// root returns the relative prefix from the page at path back to the
+// export directory.
+func root(path string) string {
+	return strings.Repeat("../", strings.Count(path, "/"))
+}
+
+// page is the data of every page: the layout's parts and one of the
+// bodies.
+type page struct {
+	Vault     string
+	Title     string
+	Root      string
+	Generated string
+	Statuses  []facet
+	Labels    []facet
+	// A listing page.
+	Rows []row
+	// The overdue page.
+	Overdue       bool
+	Expired, Late []row
+	// An Issue page.
+	Issue *issuePage
+}
+
+// issuePage is the body of an Issue page.
+type issuePage struct {
+	Glyph     string
+	ID        string
+	Status    string
+	Fields    []show.Field
+	BlockedBy []link
+	Blocks    []link
+	Markers   []string
+	Body      template.HTML
+}
+
+// link is an Issue reference: a link when the Issue exists, plain text
+// otherwise (a stale blocked_by).
+type link struct {
+	ID    string
+	Href  string
+	Title string
+}
+
+// render executes the layout for the page at path.
+func (s *builder) render(path string, p page) error {
+	p.Vault = s.opts.Title
+	p.Root = root(path)
+	p.Generated = s.opts.Now.Format("2006-01-02 15:04")
+	p.Statuses, p.Labels = s.facets(p.Root)
+	var buf bytes.Buffer
+	if err := layout.Execute(&buf, p); err != nil {
+		return fmt.Errorf("rendering %s: %w", path, err)
+	}
+	s.pages[path] = buf.Bytes()
+	return nil
+}
+
+// listing renders a listing page of items.
+func (s *builder) listing(path, title string, items []list.Item) error {
+	return s.render(path, page{Title: title, Rows: s.rows(items, root(path))})
+}
+
+// overdue renders the overdue page: the two groups of mt overdue.
+func (s *builder) overdue() error {
+	expired, late := list.OverdueGroups(s.items, s.opts.Now)
+	return s.render(OverduePage, page{
+		Title:   "Overdue",
+		Overdue: true,
+		Expired: s.rows(expired, ""),
+		Late:    s.rows(late, ""),
+	})
+}
+
+// issue renders the page of one Issue.
+func (s *builder) issue(it list.Item) error {
+	fm := it.Issue.Frontmatter
+	path := IssuePage(it.ID)
+	body, err := renderBody(it.Issue.Body)
+	if err != nil {
+		return fmt.Errorf("rendering issue %s: %w", it.ID, err)
+	}
+	var markers []string
+	if fm.Status != "done" {
+		markers = s.markers(fm.DeferredUntil, fm.Deadline, fm.BlockedBy)
+	}
+	fields := show.Fields(fm, s.opts.Now.Location())
+	// Blocked by is navigation, below the metadata.
+	fields = slices.DeleteFunc(fields, func(f show.Field) bool { return f.Label == "Blocked by" })
+	return s.render(path, page{
+		Title: fm.Title,
+		Issue: &issuePage{
+			Glyph:     list.Glyph(fm.Status),
+			ID:        it.ID,
+			Status:    fm.Status,
+			Fields:    fields,
+			BlockedBy: s.links(fm.BlockedBy),
+			Blocks:    s.links(s.blocks[it.ID]),
+			Markers:   markers,
+			Body:      body,
+		},
+	})
+}
+
+// links resolves Issue IDs to links between Issue pages.
+func (s *builder) links(ids []string) []link {
+	out := make([]link, 0, len(ids))
+	for _, id := range ids {
+		l := link{ID: id}
+		if i := slices.IndexFunc(s.items, func(it list.Item) bool { return it.ID == id }); i >= 0 {
+			l.Href = id + ".html"
+			l.Title = s.items[i].Issue.Frontmatter.Title
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
+// commentHeadingRe and commentMarkerRe match the lines of a comment
+// block (see issue.AppendComment): the ### heading with its timestamp
+// and, for a reply, the parent anchor; and the anchor marker.
+var (
+	commentHeadingRe = regexp.MustCompile(`^### (\S+)(?: \(reply to ([0-9a-z]+)\))?$`)
+	commentMarkerRe  = regexp.MustCompile(`^<!-- comment: ([0-9a-z]+) -->$`)
+)
+
+// CommentID is the HTML id of the comment with anchor on its Issue page:
+// the fragment of its permalink.
+func CommentID(anchor string) string {
+	return "comment-" + anchor
+}
+
+// permalinkComments rewrites the comment blocks of body for rendering:
+// the heading of each anchored comment gets the anchor as its id and
+// links to itself, a reply's parent reference links to the parent, and
+// the marker line, an HTML comment, is dropped.
+func permalinkComments(body string) string {
+	lines := strings.Split(body, "\n")
+	heading := -1
+	for i, line := range lines {
+		if commentHeadingRe.MatchString(line) {
+			heading = i
+			continue
+		}
+		m := commentMarkerRe.FindStringSubmatch(line)
+		if m == nil {
+			if strings.HasPrefix(line, "#") {
+				heading = -1
+			}
+			continue
+		}
+		lines[i] = ""
+		if heading < 0 {
+			continue
+		}
+		h := commentHeadingRe.FindStringSubmatch(lines[heading])
+		id := CommentID(m[1])
+		text := fmt.Sprintf("### [%s](#%s)", h[1], id)
+		if h[2] != "" {
+			text += fmt.Sprintf(" (reply to [%s](#%s))", h[2], CommentID(h[2]))
+		}
+		lines[heading] = text + " {#" + id + "}"
+		heading = -1
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdown renders an Issue body: CommonMark with the GitHub extensions
+// and heading attributes (for the comment ids). Raw HTML in the body is
+// not passed through.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithAttribute()),
+)
+
+// renderBody renders an Issue body to HTML, comments permalinked.
+func renderBody(body string) (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(permalinkComments(body)), &buf); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// statusClass is the CSS class of a status: the built-in ones have their
+// color, any other renders plain.
+func statusClass(status string) string {
+	if slices.Contains(builtinStatuses, status) {
+		return "s-" + status
+	}
+	return "s-other"
+}
+
+// layout is the template of every page.
+var layout = template.Must(template.New("page").Funcs(template.FuncMap{
+	"statusClass": statusClass,
+	"join":        strings.Join,
+}).Parse(pageTemplate))
+
+const pageTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}{{if ne .Title .Vault}} · {{.Vault}}{{end}}</title>
+<style>
+:root { --fg: #1f2430; --muted: #828c99; --accent: #399ee6; --progress: #f2ae49; --done: #9099a1; --bg: #fcfcfc; --rule: #e6e6e6; }
+@media (prefers-color-scheme: dark) {
+  :root { --fg: #cbccc6; --muted: #6c7680; --accent: #59c2ff; --progress: #ffb454; --done: #8090a0; --bg: #1f2430; --rule: #33415e; }
+}
+body { font: 16px/1.5 system-ui, sans-serif; color: var(--fg); background: var(--bg); max-width: 52rem; margin: 0 auto; padding: 1rem; }
+a { color: var(--accent); text-decoration: none; }
+a:hover { text-decoration: underline; }
+nav, footer, .muted { color: var(--muted); font-size: .9rem; }
+nav p { margin: .25rem 0; }
+h1 { font-size: 1.4rem; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: .3rem .5rem; border-bottom: 1px solid var(--rule); vertical-align: top; }
+.s-open { color: var(--fg); } .s-in_progress { color: var(--progress); } .s-done { color: var(--done); } .s-other { color: var(--muted); }
+.marker { color: var(--progress); font-size: .85rem; }
+dl { display: grid; grid-template-columns: max-content 1fr; gap: .2rem 1rem; }
+dt { color: var(--accent); } dd { margin: 0; }
+.body { border-top: 1px solid var(--rule); margin-top: 1rem; }
+pre { overflow-x: auto; }
+</style>
+</head>
+<body>
+<nav>
+<p><a href="{{.Root}}index.html">{{.Vault}}</a> · <a href="{{.Root}}overdue.html">overdue</a></p>
+<p>Status:{{range .Statuses}} <a class="{{statusClass .Name}}" href="{{.Href}}">{{.Name}}</a> ({{.Count}}){{end}}</p>
+{{- if .Labels}}
+<p>Labels:{{range .Labels}} <a href="{{.Href}}">{{.Name}}</a> ({{.Count}}){{end}}</p>
+{{- end}}
+</nav>
+<main>
+{{- with .Issue}}
+<h1><span class="{{statusClass .Status}}">{{.Glyph}}</span> {{.ID}} · {{$.Title}} <span class="{{statusClass .Status}}">[{{.Status}}]</span>{{range .Markers}} <span class="marker">{{.}}</span>{{end}}</h1>
+<dl>
+{{- range .Fields}}
+<dt>{{.Label}}</dt><dd>{{.Value}}</dd>
+{{- end}}
+{{- if .BlockedBy}}
+<dt>Blocked by</dt><dd>{{range $i, $l := .BlockedBy}}{{if $i}}, {{end}}{{template "link" $l}}{{end}}</dd>
+{{- end}}
+{{- if .Blocks}}
+<dt>Blocks</dt><dd>{{range $i, $l := .Blocks}}{{if $i}}, {{end}}{{template "link" $l}}{{end}}</dd>
+{{- end}}
+</dl>
+<div class="body">
+{{.Body}}
+</div>
+{{- else}}
+<h1>{{.Title}}</h1>
+{{- if .Overdue}}
+<h2>Expired deferrals</h2>
+{{template "rows" .Expired}}
+<h2>Deadlines passed</h2>
+{{template "rows" .Late}}
+{{- else}}
+{{template "rows" .Rows}}
+{{- end}}
+{{- end}}
+</main>
+<footer><p>Exported by mt on {{.Generated}}.</p></footer>
+</body>
+</html>
+{{define "link"}}{{if .Href}}<a href="{{.Href}}" title="{{.Title}}">{{.ID}}</a>{{else}}{{.ID}}{{end}}{{end}}
+{{define "rows"}}{{if .}}
+<table>
+{{- range .}}
+<tr><td class="{{statusClass .Status}}">{{.Glyph}}</td><td><a href="{{.Href}}">{{.ID}}</a></td><td>{{.Title}}{{range .Markers}} <span class="marker">{{.}}</span>{{end}}</td><td class="muted">{{join .Labels ", "}}</td><td class="muted">{{.Rank}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p class="muted">No Issues.</p>
+{{- end}}{{end}}
+`
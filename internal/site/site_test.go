@@ -0,0 +1,231 @@
+// Package site_test holds the black-box unit tests of the HTML export
+// (Seam 2): the page set, the listings' order and facets, the Issue
+// pages' metadata, navigation and comment permalinks, and the overdue
+// page.
+package site_test
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/list"
+	"github.com/Sanmoo/my-tasks2/internal/site"
+)
+
+func ptr(n int) *int { return &n }
+
+var now = time.Date(2026, 8, 16, 12, 0, 0, 0, time.Local)
+
+// vault is a small vault: two ranked Issues (the second blocked by the
+// first), a Backlog Issue past its deadline with comments, a done Issue
+// and one with a custom status and an odd label.
+func vault() []list.Item {
+	body := issue.AppendComment(issue.DefaultBody, "2026-08-15T09:00", "primeiro **forte**", "4f2b9c1a")
+	body, _ = issue.AppendReply(body, "2026-08-15T10:00", "resposta", "9e0d7c2b", "4f2b9c1a")
+	return []list.Item{
+		{ID: "pkm-003", Issue: issue.Issue{Frontmatter: issue.Frontmatter{
+			Title: "late <one>", Status: "open", Labels: []string{"casa"}, CreatedAt: "2026-01-01T10:00", Deadline: "2026-08-01T10:00",
+		}, Body: body}},
+		{ID: "pkm-002", Issue: issue.Issue{Frontmatter: issue.Frontmatter{
+			Title: "second", Status: "open", CreatedAt: "2026-01-01T10:00", Rank: ptr(2), BlockedBy: []string{"pkm-001", "pkm-404"},
+		}}},
+		{ID: "pkm-001", Issue: issue.Issue{Frontmatter: issue.Frontmatter{
+			Title: "first", Status: "in_progress", Labels: []string{"casa"}, CreatedAt: "2026-01-01T10:00", Rank: ptr(1),
+		}, Body: "## Description\n\n<script>alert(1)</script>\n"}},
+		{ID: "pkm-004", Issue: issue.Issue{Frontmatter: issue.Frontmatter{
+			Title: "closed", Status: "done", CreatedAt: "2026-01-01T10:00", CompletedAt: "2026-02-01T10:00", Deadline: "2026-01-15T10:00",
+		}}},
+		{ID: "pkm-005", Issue: issue.Issue{Frontmatter: issue.Frontmatter{
+			Title: "waiting", Status: "waiting", Labels: []string{"Big Deal/2"}, CreatedAt: "2026-01-02T10:00", DeferredUntil: "2026-09-01T08:00",
+		}}},
+	}
+}
+
+func build(t *testing.T) map[string]string {
+	t.Helper()
+	pages, err := site.Build(vault(), site.Options{Title: "pkm", Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make(map[string]string, len(pages))
+	for path, data := range pages {
+		out[path] = string(data)
+	}
+	return out
+}
+
+// order returns the Issue IDs in the order page links them.
+func order(page string) []string {
+	var ids []string
+	for _, m := range regexp.MustCompile(`<a href="[./]*issues/[^"]+">([^<]+)</a>`).FindAllStringSubmatch(page, -1) {
+		ids = append(ids, m[1])
+	}
+	return ids
+}
+
+func TestBuildPageSet(t *testing.T) {
+	pages := build(t)
+	var paths []string
+	for path := range pages {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	want := []string{
+		"index.html",
+		"issues/pkm-001.html", "issues/pkm-002.html", "issues/pkm-003.html", "issues/pkm-004.html", "issues/pkm-005.html",
+		"labels/casa.html", "labels/~42ig~20~44eal~2f2.html",
+		"overdue.html",
+		"status/done.html", "status/in_progress.html", "status/open.html", "status/waiting.html",
+	}
+	if !slices.Equal(paths, want) {
+		t.Errorf("pages = %v, want %v", paths, want)
+	}
+	for path, page := range pages {
+		if !strings.HasPrefix(page, "<!DOCTYPE html>") || strings.Contains(page, "<script") ||
+			strings.Contains(page, "http://") || strings.Contains(page, "https://") {
+			t.Errorf("%s is not a self-contained page", path)
+		}
+	}
+}
+
+func TestIndexIsInRankOrderWithoutDone(t *testing.T) {
+	pages := build(t)
+	if got := order(pages["index.html"]); !slices.Equal(got, []string{"pkm-001", "pkm-002", "pkm-003", "pkm-005"}) {
+		t.Errorf("index order = %v", got)
+	}
+	index := pages["index.html"]
+	for _, want := range []string{
+		`<title>pkm</title>`,
+		`<a href="issues/pkm-001.html">pkm-001</a>`,
+		`late &lt;one&gt; <span class="marker">[deadline 08-01]</span>`,
+		`<span class="marker">[blocked]</span>`,
+		`<span class="marker">[defer 09-01 08:00]</span>`,
+		`<a class="s-open" href="status/open.html">open</a> (2)`,
+		`<a class="s-other" href="status/waiting.html">waiting</a> (1)`,
+		`<a href="labels/casa.html">casa</a> (2)`,
+		`Exported by mt on 2026-08-16 12:00.`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index lacks %q:\n%s", want, index)
+		}
+	}
+	if got := regexp.MustCompile(`Status:(.*)</p>`).FindStringSubmatch(index)[1]; !regexp.MustCompile(`open.*in_progress.*done.*waiting`).MatchString(got) {
+		t.Errorf("status facets = %s, want built-ins in lifecycle order, then custom", got)
+	}
+}
+
+func TestFacetPages(t *testing.T) {
+	pages := build(t)
+	if got := order(pages["status/done.html"]); !slices.Equal(got, []string{"pkm-004"}) {
+		t.Errorf("done facet = %v", got)
+	}
+	if strings.Contains(pages["status/done.html"], "[deadline") {
+		t.Error("a done Issue carries attention markers")
+	}
+	casa := pages["labels/casa.html"]
+	if got := order(casa); !slices.Equal(got, []string{"pkm-001", "pkm-003"}) {
+		t.Errorf("casa facet = %v", got)
+	}
+	if !strings.Contains(casa, `<a href="../issues/pkm-001.html">`) || !strings.Contains(casa, `<a href="../index.html">pkm</a>`) ||
+		!strings.Contains(casa, `<title>Label: casa · pkm</title>`) {
+		t.Errorf("facet page links are not relative to it:\n%s", casa)
+	}
+}
+
+func TestIssuePage(t *testing.T) {
+	pages := build(t)
+	second := pages["issues/pkm-002.html"]
+	for _, want := range []string{
+		`pkm-002 · second`,
+		`<dt>Created</dt><dd>2026-01-01 10:00</dd>`,
+		`<dt>Rank</dt><dd>2</dd>`,
+		`<dt>Blocked by</dt><dd><a href="pkm-001.html" title="first">pkm-001</a>, pkm-404</dd>`,
+		`<a href="../overdue.html">overdue</a>`,
+	} {
+		if !strings.Contains(second, want) {
+			t.Errorf("pkm-002 page lacks %q:\n%s", want, second)
+		}
+	}
+	first := pages["issues/pkm-001.html"]
+	if !strings.Contains(first, `<dt>Blocks</dt><dd><a href="pkm-002.html" title="second">pkm-002</a></dd>`) {
+		t.Errorf("pkm-001 page lacks the backlink:\n%s", first)
+	}
+	if strings.Contains(first, "<script>") || !strings.Contains(first, "<h2>Description</h2>") {
+		t.Errorf("pkm-001 body is not rendered Markdown without raw HTML:\n%s", first)
+	}
+}
+
+func TestIssuePageCommentPermalinks(t *testing.T) {
+	page := build(t)["issues/pkm-003.html"]
+	for _, want := range []string{
+		`<h3 id="comment-4f2b9c1a"><a href="#comment-4f2b9c1a">2026-08-15T09:00</a></h3>`,
+		`<p>primeiro <strong>forte</strong></p>`,
+		`<h3 id="comment-9e0d7c2b"><a href="#comment-9e0d7c2b">2026-08-15T10:00</a> (reply to <a href="#comment-4f2b9c1a">4f2b9c1a</a>)</h3>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("pkm-003 page lacks %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "comment: 4f2b9c1a") || strings.Contains(page, "raw HTML omitted") {
+		t.Errorf("the anchor markers leaked into the page:\n%s", page)
+	}
+	if site.CommentID("4f2b9c1a") != "comment-4f2b9c1a" {
+		t.Errorf("CommentID = %q", site.CommentID("4f2b9c1a"))
+	}
+}
+
+func TestUnanchoredHeadingsStayPlain(t *testing.T) {
+	items := []list.Item{{ID: "pkm-001", Issue: issue.Issue{
+		Frontmatter: issue.Frontmatter{Title: "t", Status: "open", CreatedAt: "2026-01-01T10:00"},
+		Body:        "## Comments\n### 2026-08-15T09:00\nhand-written\n## Notes\n<!-- comment: deadbeef -->\n",
+	}}}
+	pages, err := site.Build(items, site.Options{Title: "pkm", Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(pages["issues/pkm-001.html"])
+	if strings.Contains(page, `id="comment-`) || !strings.Contains(page, "<h3>2026-08-15T09:00</h3>") {
+		t.Errorf("a heading without its marker got a permalink:\n%s", page)
+	}
+}
+
+func TestOverduePage(t *testing.T) {
+	page := build(t)["overdue.html"]
+	expired, late, ok := strings.Cut(page, "Deadlines passed")
+	if !ok {
+		t.Fatalf("overdue page lacks the deadline group:\n%s", page)
+	}
+	if got := order(expired); len(got) != 0 {
+		t.Errorf("expired group = %v, want none", got)
+	}
+	if got := order(late); !slices.Equal(got, []string{"pkm-003"}) {
+		t.Errorf("deadline group = %v, want pkm-003", got)
+	}
+
+	pages, err := site.Build(nil, site.Options{Title: "empty", Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(pages["index.html"]), "No Issues.") || len(pages) != 2 {
+		t.Errorf("empty vault pages = %v", pages)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	if got := site.IssuePage("pkm-001"); got != "issues/pkm-001.html" {
+		t.Errorf("IssuePage = %q", got)
+	}
+	if got := site.StatusPage("in_progress"); got != "status/in_progress.html" {
+		t.Errorf("StatusPage = %q", got)
+	}
+	if a, b := site.LabelPage("Casa"), site.LabelPage("casa"); a == b {
+		t.Errorf("LabelPage folds case: %q", a)
+	}
+	if got := site.LabelPage("../x"); strings.Contains(strings.TrimPrefix(got, "labels/"), "/") {
+		t.Errorf("LabelPage(../x) = %q escapes its directory", got)
+	}
+}
@@ -0,0 +1,271 @@
+// Package template holds the pure logic of Issue templates: the Markdown
+// files in a vault's templates/ directory that `mt create --template`
+// starts a new Issue from. A template carries a partial frontmatter and
+// a body with placeholders:
+//
+//	---
+//	labels: [bug]
+//	deadline: +3d
+//	deferred_until: +1d
+//	rank: top
+//	blocked_by: [pkm-001]
+//	---
+//
+//	## Description
+//	{{title}} — reported {{date}}.
+//	## Notes
+//	## Comments
+//
+// Every frontmatter field is optional. deadline and deferred_until are
+// offsets from the creation time (+<n><unit>, as mt defer takes them);
+// rank places the new Issue in the queue (top, bottom or a position). It
+// is decision-dense, so it lives at Seam 2: black-box unit tested, with the coverage and mutation
+// gates. Reading the templates directory is a process concern and stays
+// in internal/cli.
+package template
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Sanmoo/my-tasks2/internal/deferral"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/priority"
+)
+
+// DirName is the vault directory holding the templates, one <name>.md
+// file each.
+const DirName = "templates"
+
+// Placeholders are the names a template body may reference as {{name}}.
+var Placeholders = []string{"title", "id", "date", "now"}
+
+// Template is a parsed template file.
+type Template struct {
+	// Name is the file name without .md: what --template selects.
+	Name string
+	// Labels are added before the labels given on the command line.
+	Labels []string
+	// Deadline and DeferredUntil are offsets from the creation time
+	// (+<n><unit>: +3d, +2bd, +1mo…), empty when the template sets none.
+	Deadline      string
+	DeferredUntil string
+	// Rank is the queue placement as priority.ParsePlacement reads it
+	// (top, bottom or a position), or empty for the Backlog.
+	Rank string
+	// BlockedBy are the blockers every Issue from the template starts
+	// with.
+	BlockedBy []string
+	// Body is the Markdown body, placeholders unexpanded; empty means
+	// issue.DefaultBody.
+	Body string
+}
+
+// frontmatter is the on-disk shape of a template's frontmatter. The
+// fields mirror the Issue schema, restricted to what a template may set.
+type frontmatter struct {
+	Labels        []string `yaml:"labels"`
+	Deadline      string   `yaml:"deadline"`
+	DeferredUntil string   `yaml:"deferred_until"`
+	Rank          string   `yaml:"rank"`
+	BlockedBy     []string `yaml:"blocked_by"`
+}
+
+// Values are the placeholder values of one Issue being created. Zone is
+// the vault's timezone setting: the layout of the resolved offsets, with
+// Now in its location.
+type Values struct {
+	Title string
+	ID    string
+	Now   time.Time
+	Zone  issue.Zone
+}
+
+// Path returns the file of the template name in vaultDir.
+func Path(vaultDir, name string) string {
+	return filepath.Join(vaultDir, DirName, name+".md")
+}
+
+// ValidName reports whether name can name a template file: a non-empty
+// single file name component.
+func ValidName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// Parse reads the template name from its file content. A file that does
+// not start with a --- line is all body. Parse rejects unknown
+// frontmatter fields, offsets that are not relative durations, a rank
+// that is neither top, bottom nor a positive position, and unknown
+// placeholders.
+func Parse(name string, data []byte) (Template, error) {
+	t := Template{Name: name}
+	text := string(data)
+	if strings.HasPrefix(text, "---\n") {
+		head, body, ok := strings.Cut("\n"+text[len("---\n"):], "\n---\n")
+		if !ok {
+			if !strings.HasSuffix(text, "\n---") {
+				return Template{}, fmt.Errorf("template %s: frontmatter is not closed with a --- delimiter", name)
+			}
+			head, body = strings.TrimSuffix(text[len("---\n"):], "\n---"), ""
+		}
+		if err := t.parseFrontmatter(head); err != nil {
+			return Template{}, fmt.Errorf("template %s: %w", name, err)
+		}
+		text = body
+	}
+	t.Body = text
+	if err := checkPlaceholders(t.Body); err != nil {
+		return Template{}, fmt.Errorf("template %s: %w", name, err)
+	}
+	return t, nil
+}
+
+// parseFrontmatter decodes and validates the template's frontmatter.
+func (t *Template) parseFrontmatter(head string) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(head), &node); err != nil {
+		return fmt.Errorf("malformed frontmatter: %w", err)
+	}
+	if len(node.Content) == 0 {
+		return nil
+	}
+	mapping := node.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return errors.New("malformed frontmatter: expected a YAML mapping")
+	}
+	for i := 0; i < len(mapping.Content); i += 2 {
+		switch key := mapping.Content[i].Value; key {
+		case "labels", "deadline", "deferred_until", "rank", "blocked_by":
+		default:
+			return fmt.Errorf("unknown field %q (a template sets labels, deadline, deferred_until, rank, blocked_by)", key)
+		}
+	}
+	var fm frontmatter
+	if err := mapping.Decode(&fm); err != nil {
+		return fmt.Errorf("malformed frontmatter: %w", err)
+	}
+	for _, offset := range []struct{ field, value string }{
+		{"deadline", fm.Deadline},
+		{"deferred_until", fm.DeferredUntil},
+	} {
+		if offset.value == "" {
+			continue
+		}
+		if !strings.HasPrefix(offset.value, "+") {
+			return fmt.Errorf("%s must be an offset like +3d, got %q", offset.field, offset.value)
+		}
+		if _, err := deferral.Parse(offset.value, time.Time{}, deferral.Options{}); err != nil {
+			return fmt.Errorf("%s: %w", offset.field, err)
+		}
+	}
+	if fm.Rank != "" {
+		if _, _, err := priority.ParsePlacement(fm.Rank); err != nil {
+			return err
+		}
+	}
+	for _, ref := range fm.BlockedBy {
+		if !ValidName(ref) {
+			return fmt.Errorf("invalid blocked_by reference %q", ref)
+		}
+	}
+	t.Labels, t.Deadline, t.DeferredUntil, t.Rank, t.BlockedBy = fm.Labels, fm.Deadline, fm.DeferredUntil, fm.Rank, fm.BlockedBy
+	return nil
+}
+
+// checkPlaceholders rejects unclosed and unknown {{...}} placeholders.
+func checkPlaceholders(body string) error {
+	rest := body
+	for {
+		open := strings.Index(rest, "{{")
+		if open < 0 {
+			return nil
+		}
+		end := strings.Index(rest[open:], "}}")
+		if end < 0 {
+			return errors.New("unclosed {{ placeholder")
+		}
+		name := strings.TrimSpace(rest[open+2 : open+end])
+		if !slices.Contains(Placeholders, name) {
+			return fmt.Errorf("unknown placeholder {{%s}} (known: %s)", name, strings.Join(Placeholders, ", "))
+		}
+		rest = rest[open+end+2:]
+	}
+}
+
+// MissingRefs returns the blocked_by references of t that name none of
+// ids — templates are validated against the vault's Issues by check.
+func (t Template) MissingRefs(ids []string) []string {
+	var missing []string
+	for _, ref := range t.BlockedBy {
+		if !slices.Contains(ids, ref) {
+			missing = append(missing, ref)
+		}
+	}
+	return missing
+}
+
+// Apply fills a new Issue from t: labels (the template's first, then
+// labels, without repeats), blocked_by, deadline and deferred_until
+// resolved from v.Now, and the body with its placeholders expanded. The
+// rank placement is left to the caller, which owns the queue.
+func (t Template) Apply(i issue.Issue, labels []string, v Values) issue.Issue {
+	merged := make([]string, 0, len(t.Labels)+len(labels))
+	for _, l := range append(slices.Clone(t.Labels), labels...) {
+		if !slices.Contains(merged, l) {
+			merged = append(merged, l)
+		}
+	}
+	i.Frontmatter.Labels = merged
+	i.Frontmatter.BlockedBy = slices.Clone(t.BlockedBy)
+	// Offsets were validated by Parse; a relative duration always parses.
+	if t.Deadline != "" {
+		i.Frontmatter.Deadline, _ = deferral.Parse(t.Deadline, v.Now, deferral.Options{Zone: v.Zone})
+	}
+	if t.DeferredUntil != "" {
+		i.Frontmatter.DeferredUntil, _ = deferral.Parse(t.DeferredUntil, v.Now, deferral.Options{Zone: v.Zone})
+	}
+	if t.Body != "" {
+		i.Body = Expand(t.Body, v)
+	}
+	return i
+}
+
+// Expand replaces the placeholders of body with v: {{title}}, {{id}},
+// {{date}} (YYYY-MM-DD) and {{now}} (issue.NaiveLayout). Spaces inside
+// the braces are allowed.
+func Expand(body string, v Values) string {
+	values := map[string]string{
+		"title": v.Title,
+		"id":    v.ID,
+		"date":  v.Now.Format("2006-01-02"),
+		"now":   v.Now.Format(issue.NaiveLayout),
+	}
+	var b strings.Builder
+	rest := body
+	for {
+		open := strings.Index(rest, "{{")
+		if open < 0 {
+			break
+		}
+		end := strings.Index(rest[open:], "}}")
+		if end < 0 {
+			break
+		}
+		name := strings.TrimSpace(rest[open+2 : open+end])
+		value, ok := values[name]
+		if !ok {
+			value = rest[open : open+end+2]
+		}
+		b.WriteString(rest[:open])
+		b.WriteString(value)
+		rest = rest[open+end+2:]
+	}
+	b.WriteString(rest)
+	return b.String()
+}
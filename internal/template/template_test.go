@@ -0,0 +1,164 @@
+// Package template_test holds the black-box unit tests of Issue
+// templates (Seam 2): parsing and validating the partial frontmatter and
+// placeholders, and applying a template to a new Issue.
+package template_test
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sanmoo/my-tasks2/internal/issue"
+	"github.com/Sanmoo/my-tasks2/internal/template"
+)
+
+const bugTemplate = `---
+labels: [bug]
+deadline: +3d
+deferred_until: +1d
+rank: top
+blocked_by: [pkm-001]
+---
+
+## Description
+{{title}} ({{ id }}) — reported {{date}} at {{now}}.
+## Notes
+## Comments
+`
+
+var now = time.Date(2026, 8, 15, 9, 30, 0, 0, time.Local)
+
+func TestParseFullTemplate(t *testing.T) {
+	tpl, err := template.Parse("bug", []byte(bugTemplate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tpl.Name != "bug" || !slices.Equal(tpl.Labels, []string{"bug"}) || tpl.Deadline != "+3d" ||
+		tpl.DeferredUntil != "+1d" || tpl.Rank != "top" || !slices.Equal(tpl.BlockedBy, []string{"pkm-001"}) {
+		t.Errorf("Parse = %+v", tpl)
+	}
+	if !strings.HasPrefix(tpl.Body, "\n## Description\n{{title}}") {
+		t.Errorf("Body = %q, want the text after the closing delimiter", tpl.Body)
+	}
+}
+
+func TestParseWithoutFrontmatterIsAllBody(t *testing.T) {
+	tpl, err := template.Parse("plain", []byte("## Description\n{{title}}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tpl.Body != "## Description\n{{title}}\n" || tpl.Labels != nil {
+		t.Errorf("Parse = %+v", tpl)
+	}
+}
+
+func TestParseFrontmatterOnly(t *testing.T) {
+	for name, data := range map[string]string{
+		"closing delimiter last": "---\nlabels: [errand]\n---",
+		"empty body":             "---\nlabels: [errand]\n---\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			tpl, err := template.Parse("errand", []byte(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tpl.Body != "" || !slices.Equal(tpl.Labels, []string{"errand"}) {
+				t.Errorf("Parse = %+v", tpl)
+			}
+		})
+	}
+	tpl, err := template.Parse("empty", []byte("---\n---\nbody\n"))
+	if err != nil || tpl.Body != "body\n" {
+		t.Errorf("Parse(empty frontmatter) = %+v, %v", tpl, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		name, data, want string
+	}{
+		{"unclosed", "---\nlabels: [a]\n", "not closed"},
+		{"bad yaml", "---\nlabels: [a\n---\n", "malformed frontmatter"},
+		{"not a mapping", "---\n- a\n---\n", "expected a YAML mapping"},
+		{"wrong type", "---\nlabels: {a: b}\n---\n", "malformed frontmatter"},
+		{"unknown field", "---\nstatus: done\n---\n", `unknown field "status"`},
+		{"absolute deadline", "---\ndeadline: 26-08-20 08:00\n---\n", "deadline must be an offset"},
+		{"bad offset", "---\ndeferred_until: +3x\n---\n", "deferred_until:"},
+		{"bad rank", "---\nrank: middle\n---\n", "rank must be top, bottom or a positive position"},
+		{"zero rank", "---\nrank: 0\n---\n", "rank must be"},
+		{"bad blocker", "---\nblocked_by: [../x]\n---\n", "invalid blocked_by reference"},
+		{"unknown placeholder", "Hi {{author}}", "unknown placeholder {{author}}"},
+		{"unclosed placeholder", "Hi {{title", "unclosed {{ placeholder"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := template.Parse("t", []byte(c.data))
+			if err == nil || !strings.Contains(err.Error(), c.want) || !strings.Contains(err.Error(), "template t") {
+				t.Errorf("Parse error = %v, want it to name the template and contain %q", err, c.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tpl, err := template.Parse("bug", []byte(bugTemplate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := issue.Issue{Frontmatter: issue.Frontmatter{Title: "crash", Status: "open", CreatedAt: "2026-08-15T09:30"}, Body: issue.DefaultBody}
+	got := tpl.Apply(base, []string{"urgent", "bug"}, template.Values{Title: "crash", ID: "pkm-abcd", Now: now})
+	fm := got.Frontmatter
+	if !slices.Equal(fm.Labels, []string{"bug", "urgent"}) {
+		t.Errorf("Labels = %v, want [bug urgent]", fm.Labels)
+	}
+	if fm.Deadline != "2026-08-18T09:30" || fm.DeferredUntil != "2026-08-16T09:30" {
+		t.Errorf("Deadline, DeferredUntil = %q, %q", fm.Deadline, fm.DeferredUntil)
+	}
+	if !slices.Equal(fm.BlockedBy, []string{"pkm-001"}) {
+		t.Errorf("BlockedBy = %v", fm.BlockedBy)
+	}
+	if want := "\n## Description\ncrash (pkm-abcd) — reported 2026-08-15 at 2026-08-15T09:30.\n## Notes\n## Comments\n"; got.Body != want {
+		t.Errorf("Body = %q, want %q", got.Body, want)
+	}
+	if fm.Title != "crash" || fm.Status != "open" || fm.Rank != nil {
+		t.Errorf("Apply touched other fields: %+v", fm)
+	}
+}
+
+func TestApplyEmptyTemplateKeepsTheIssue(t *testing.T) {
+	base := issue.Issue{Frontmatter: issue.Frontmatter{Title: "t"}, Body: issue.DefaultBody}
+	got := (template.Template{}).Apply(base, nil, template.Values{Now: now})
+	if got.Body != issue.DefaultBody || got.Frontmatter.Deadline != "" || got.Frontmatter.DeferredUntil != "" || len(got.Frontmatter.Labels) != 0 {
+		t.Errorf("Apply(empty) = %+v", got)
+	}
+	if got.Frontmatter.Labels == nil {
+		t.Error("Labels is nil: a new Issue always writes labels: []")
+	}
+}
+
+func TestExpandLeavesUnknownAndUnclosedText(t *testing.T) {
+	got := template.Expand("{{title}} {{other}} {{title", template.Values{Title: "x", Now: now})
+	if got != "x {{other}} {{title" {
+		t.Errorf("Expand = %q", got)
+	}
+}
+
+func TestMissingRefs(t *testing.T) {
+	tpl := template.Template{BlockedBy: []string{"pkm-001", "pkm-404"}}
+	if got := tpl.MissingRefs([]string{"pkm-001", "pkm-002"}); !slices.Equal(got, []string{"pkm-404"}) {
+		t.Errorf("MissingRefs = %v, want [pkm-404]", got)
+	}
+}
+
+func TestPathAndValidName(t *testing.T) {
+	if got, want := template.Path("/v", "bug"), filepath.Join("/v", "templates", "bug.md"); got != want {
+		t.Errorf("Path = %q, want %q", got, want)
+	}
+	for name, want := range map[string]bool{"bug": true, "": false, ".": false, "..": false, "a/b": false, `a\b`: false} {
+		if got := template.ValidName(name); got != want {
+			t.Errorf("ValidName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
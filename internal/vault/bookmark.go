@@ -36,9 +36,10 @@ func (g Global) AddBookmark(name, path string) (Global, error) {
 	if !IsValidBookmarkName(name) {
 		return Global{}, fmt.Errorf("invalid bookmark name %q: use letters, digits, '-' or '_'", name)
 	}
-	bookmarks := cloneBookmarks(g.Bookmarks)
-	bookmarks[name] = path
-	return Global{Default: g.Default, Bookmarks: bookmarks}, nil
+	out := g
+	out.Bookmarks = cloneBookmarks(g.Bookmarks)
+	out.Bookmarks[name] = path
+	return out, nil
 }
 
 // RemoveBookmark returns a copy of g without the bookmark name. When name
@@ -49,13 +50,13 @@ func (g Global) RemoveBookmark(name string) (Global, error) {
 	if _, ok := g.Bookmarks[name]; !ok {
 		return Global{}, fmt.Errorf("bookmark @%s not found", name)
 	}
-	bookmarks := cloneBookmarks(g.Bookmarks)
-	delete(bookmarks, name)
-	def := g.Default
-	if def == name {
-		def = ""
+	out := g
+	out.Bookmarks = cloneBookmarks(g.Bookmarks)
+	delete(out.Bookmarks, name)
+	if out.Default == name {
+		out.Default = ""
 	}
-	return Global{Default: def, Bookmarks: bookmarks}, nil
+	return out, nil
 }
 
 // Names returns the bookmark names in sorted order, for stable listing.
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/Sanmoo/my-tasks2/internal/hook"
 	"github.com/Sanmoo/my-tasks2/internal/vault"
 )
 
@@ -253,8 +254,10 @@ func TestSaveGlobalOmitsEmptyDefault(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if strings.Contains(string(data), "default:") {
-		t.Errorf("config should omit the default key when unset:\n%s", data)
+	for _, key := range []string{"default:", "hooks:", "serve:"} {
+		if strings.Contains(string(data), key) {
+			t.Errorf("config should omit the %s key when unset:\n%s", key, data)
+		}
 	}
 }
 
@@ -291,6 +294,35 @@ func TestSaveGlobalRoundTrip(t *testing.T) {
 	}
 }
 
+// TestBookmarkEditsKeepHooks guards the bookmark subcommands: adding or
+// removing a bookmark rewrites the global config, which must not drop
+// the user's hooks or serve token.
+func TestBookmarkEditsKeepHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	orig := vault.Global{Hooks: hook.Config{hook.Done: {{"notify-send", "Issue done"}}}, ServeToken: "s3cret"}
+	added, err := orig.AddBookmark("bjd", "/v/bjd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := added.SaveGlobal(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := vault.LoadGlobal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed, err := loaded.RemoveBookmark("bjd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := removed.Hooks[hook.Done]; len(got) != 1 || got[0].String() != "notify-send Issue done" {
+		t.Errorf("hooks after add, save, load, remove = %v", removed.Hooks)
+	}
+	if removed.ServeToken != "s3cret" {
+		t.Errorf("serve token after add, save, load, remove = %q", removed.ServeToken)
+	}
+}
+
 // TestAddThenResolveBookmark encodes the ticket's "a freshly added
 // bookmark resolves via @nome on the next command": add → save → load →
 // resolve, with ~ expansion applied at resolution time.
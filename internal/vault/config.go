@@ -14,6 +14,10 @@ import (
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/Sanmoo/my-tasks2/internal/deferral"
+	"github.com/Sanmoo/my-tasks2/internal/hook"
+	"github.com/Sanmoo/my-tasks2/internal/issue"
 )
 
 // vaultConfigName is the vault config file at the vault root.
@@ -27,6 +31,11 @@ type Global struct {
 	Default string
 	// Bookmarks maps bookmark names to vault paths.
 	Bookmarks map[string]string
+	// Hooks are the user's hooks, run for every vault before the vault's
+	// own.
+	Hooks hook.Config
+	// ServeToken is the bearer token clients of mt serve must present.
+	ServeToken string
 }
 
 // globalFile is the on-disk shape of the global config:
@@ -34,9 +43,20 @@ type Global struct {
 //	default: bjd
 //	bookmarks:
 //	  bjd: ~/dev/github.com/Sanmoo/pkm/.vault
+//	hooks:
+//	  done: [[notify-send, "Issue done"]]
+//	serve:
+//	  token: 9f2c...
 type globalFile struct {
 	Default   string            `yaml:"default,omitempty"`
 	Bookmarks map[string]string `yaml:"bookmarks,omitempty"`
+	Hooks     hook.Config       `yaml:"hooks,omitempty"`
+	Serve     serveFile         `yaml:"serve,omitempty"`
+}
+
+// serveFile is the serve: section of the global config.
+type serveFile struct {
+	Token string `yaml:"token,omitempty"`
 }
 
 // LoadGlobal reads the global config from path. A missing file yields
@@ -54,7 +74,7 @@ func LoadGlobal(path string) (Global, error) {
 	if err := yaml.Unmarshal(data, &f); err != nil {
 		return Global{}, fmt.Errorf("parsing global config %s: %w", path, err)
 	}
-	return Global{Default: f.Default, Bookmarks: f.Bookmarks}, nil
+	return Global{Default: f.Default, Bookmarks: f.Bookmarks, Hooks: f.Hooks, ServeToken: f.Serve.Token}, nil
 }
 
 // GlobalConfigPath returns the global config path: $XDG_CONFIG_HOME/mt/
@@ -72,7 +92,7 @@ func (g Global) SaveGlobal(path string) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return fmt.Errorf("creating config directory: %w", err)
 	}
-	data, err := yaml.Marshal(globalFile{Default: g.Default, Bookmarks: g.Bookmarks})
+	data, err := yaml.Marshal(globalFile{Default: g.Default, Bookmarks: g.Bookmarks, Hooks: g.Hooks, Serve: serveFile{Token: g.ServeToken}})
 	if err != nil {
 		return fmt.Errorf("encoding global config: %w", err)
 	}
@@ -82,22 +102,54 @@ func (g Global) SaveGlobal(path string) error {
 	return nil
 }
 
-// Vault is the per-vault config (mt.yaml): the ID prefix and the
-// configured Status list.
+// Vault is the per-vault config (mt.yaml): the ID prefix, the
+// configured Status list, the vault's hooks, its custom frontmatter
+// fields, the default time of day of its time expressions, its
+// timezone, the lead time of its deadline warnings and its WIP limit.
 type Vault struct {
 	// Prefix is the ID prefix for issues of this vault (ex.: pkm).
 	Prefix string
 	// Status is the status list of the vault; empty means the defaults.
 	Status []string
+	// Hooks maps lifecycle events to the commands run for them.
+	Hooks hook.Config
+	// Fields declares the custom frontmatter fields, in on-disk order.
+	Fields []issue.FieldSpec
+	// DefaultTime is the HH:MM a time expression that names a day but no
+	// time resolves to; empty means deferral.DefaultTime.
+	DefaultTime string
+	// Zone is how the vault's datetimes are written and read; the zero
+	// Zone (no timezone set) is naive machine-local time.
+	Zone issue.Zone
+	// WarnBefore is the span before a deadline during which its Issue is
+	// due soon (e.g. 3d, see deferral.ParseSpan); empty means no warning.
+	WarnBefore string
+	// WIPLimit is the most Issues in_progress at once that pick-next and
+	// start allow; 0 means no limit.
+	WIPLimit int
 }
 
 // vaultFile is the on-disk shape of the vault config:
 //
 //	prefix: pkm
 //	status: [open, in_progress, done]
+//	hooks:
+//	  pre_done: [./scripts/check-done]
+//	fields:
+//	  - {name: energy, type: enum, values: [low, high]}
+//	default_time: "08:30"
+//	timezone: America/Sao_Paulo
+//	warn_before: 3d
+//	wip_limit: 2
 type vaultFile struct {
-	Prefix string   `yaml:"prefix"`
-	Status []string `yaml:"status,flow"`
+	Prefix      string            `yaml:"prefix"`
+	Status      []string          `yaml:"status,flow"`
+	Hooks       hook.Config       `yaml:"hooks,omitempty"`
+	Fields      []issue.FieldSpec `yaml:"fields,omitempty"`
+	DefaultTime string            `yaml:"default_time,omitempty"`
+	Timezone    string            `yaml:"timezone,omitempty"`
+	WarnBefore  string            `yaml:"warn_before,omitempty"`
+	WIPLimit    int               `yaml:"wip_limit,omitempty"`
 }
 
 // DefaultStatus are the statuses that apply when the vault config
@@ -120,12 +172,29 @@ func (v Vault) IsStatus(s string) bool {
 	return slices.Contains(v.StatusList(), s)
 }
 
-// LoadVault reads the vault config from dir/mt.yaml.
+// Field returns the declaration of the custom field name.
+func (v Vault) Field(name string) (issue.FieldSpec, bool) {
+	for _, f := range v.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return issue.FieldSpec{}, false
+}
+
+// ErrNotVault is the error of LoadVault when dir has no mt.yaml.
+var ErrNotVault = errors.New("not a vault")
+
+// LoadVault reads the vault config from dir/mt.yaml. Invalid custom
+// field declarations fail the load: every Issue would be checked against
+// them; so does a malformed default_time, which every time expression
+// naming a day would hit, an unknown timezone, a malformed warn_before
+// or a negative wip_limit.
 func LoadVault(dir string) (Vault, error) {
 	path := filepath.Join(dir, vaultConfigName)
 	data, err := os.ReadFile(path)
 	if errors.Is(err, os.ErrNotExist) {
-		return Vault{}, fmt.Errorf("not a vault: %s missing in %s — run 'mt init'", vaultConfigName, dir)
+		return Vault{}, fmt.Errorf("%w: %s missing in %s — run 'mt init'", ErrNotVault, vaultConfigName, dir)
 	}
 	if err != nil {
 		return Vault{}, fmt.Errorf("reading vault config %s: %w", path, err)
@@ -134,7 +203,31 @@ func LoadVault(dir string) (Vault, error) {
 	if err := yaml.Unmarshal(data, &f); err != nil {
 		return Vault{}, fmt.Errorf("parsing vault config %s: %w", path, err)
 	}
-	return Vault{Prefix: f.Prefix, Status: f.Status}, nil
+	if err := issue.ValidateFieldSpecs(f.Fields); err != nil {
+		return Vault{}, fmt.Errorf("vault config %s: %w", path, err)
+	}
+	if f.DefaultTime != "" {
+		if _, _, err := deferral.ParseTimeOfDay(f.DefaultTime); err != nil {
+			return Vault{}, fmt.Errorf("vault config %s: default_time: %w", path, err)
+		}
+	}
+	zone, err := issue.LoadZone(f.Timezone)
+	if err != nil {
+		return Vault{}, fmt.Errorf("vault config %s: %w", path, err)
+	}
+	if f.WarnBefore != "" {
+		if _, err := deferral.ParseSpan(f.WarnBefore); err != nil {
+			return Vault{}, fmt.Errorf("vault config %s: warn_before: %w", path, err)
+		}
+	}
+	if f.WIPLimit < 0 {
+		return Vault{}, fmt.Errorf("vault config %s: wip_limit must not be negative, got %d", path, f.WIPLimit)
+	}
+	return Vault{
+		Prefix: f.Prefix, Status: f.Status, Hooks: f.Hooks, Fields: f.Fields,
+		DefaultTime: f.DefaultTime, Zone: zone, WarnBefore: f.WarnBefore,
+		WIPLimit: f.WIPLimit,
+	}, nil
 }
 
 // Save creates a usable Vault at dir: the issues/ directory plus
@@ -150,7 +243,11 @@ func (v Vault) Save(dir string) error {
 	if err := os.MkdirAll(filepath.Join(dir, "issues"), 0o755); err != nil {
 		return fmt.Errorf("creating issues directory: %w", err)
 	}
-	data, err := yaml.Marshal(vaultFile{Prefix: v.Prefix, Status: v.StatusList()})
+	data, err := yaml.Marshal(vaultFile{
+		Prefix: v.Prefix, Status: v.StatusList(), Hooks: v.Hooks, Fields: v.Fields,
+		DefaultTime: v.DefaultTime, Timezone: v.Zone.String(), WarnBefore: v.WarnBefore,
+		WIPLimit: v.WIPLimit,
+	})
 	if err != nil {
 		return fmt.Errorf("encoding vault config: %w", err)
 	}
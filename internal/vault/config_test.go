@@ -4,11 +4,13 @@
 package vault_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/Sanmoo/my-tasks2/internal/issue"
 	"github.com/Sanmoo/my-tasks2/internal/vault"
 )
 
@@ -201,6 +203,9 @@ func TestLoadVaultMissingConfigFails(t *testing.T) {
 	if err == nil {
 		t.Fatal("LoadVault(no mt.yaml) = nil error, want failure")
 	}
+	if !errors.Is(err, vault.ErrNotVault) {
+		t.Errorf("LoadVault(no mt.yaml) = %v, want ErrNotVault", err)
+	}
 	for _, want := range []string{"not a vault", "mt init"} {
 		if !strings.Contains(err.Error(), want) {
 			t.Errorf("error %q does not mention %q", err, want)
@@ -218,6 +223,185 @@ func TestLoadVaultMalformedYAMLFails(t *testing.T) {
 	}
 }
 
+func TestLoadVaultReadsHooks(t *testing.T) {
+	dir := t.TempDir()
+	content := "prefix: pkm\nhooks:\n  pre_done: [./check]\n  commented:\n    - [notify, --urgent]\n"
+	if err := os.WriteFile(filepath.Join(dir, "mt.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := vault.LoadVault(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Hooks["pre_done"]; len(got) != 1 || got[0].String() != "./check" {
+		t.Errorf("Hooks[pre_done] = %v", got)
+	}
+	if got := v.Hooks["commented"]; len(got) != 1 || got[0].String() != "notify --urgent" {
+		t.Errorf("Hooks[commented] = %v", got)
+	}
+}
+
+func TestLoadVaultUnknownHookEventFails(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mt.yaml"), []byte("prefix: pkm\nhooks:\n  closed: [x]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vault.LoadVault(dir); err == nil || !strings.Contains(err.Error(), `unknown hook event "closed"`) {
+		t.Errorf("LoadVault(unknown hook event) = %v, want the event named", err)
+	}
+}
+
+func TestLoadVaultReadsCustomFields(t *testing.T) {
+	dir := t.TempDir()
+	content := "prefix: pkm\nfields:\n  - {name: energy, type: enum, values: [low, high]}\n  - {name: url, type: string}\n"
+	if err := os.WriteFile(filepath.Join(dir, "mt.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := vault.LoadVault(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Fields) != 2 || v.Fields[0].Name != "energy" || len(v.Fields[0].Values) != 2 || v.Fields[1].Type != issue.TypeString {
+		t.Errorf("Fields = %+v", v.Fields)
+	}
+	if f, ok := v.Field("url"); !ok || f.Type != issue.TypeString {
+		t.Errorf("Field(url) = %+v, %v", f, ok)
+	}
+	if _, ok := v.Field("client"); ok {
+		t.Error("Field(client) found an undeclared field")
+	}
+
+	saved := t.TempDir()
+	if err := v.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := vault.LoadVault(saved); err != nil || len(again.Fields) != 2 {
+		t.Errorf("LoadVault(saved) = %+v, %v; want the fields kept", again.Fields, err)
+	}
+}
+
+func TestLoadVaultInvalidCustomFieldFails(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mt.yaml"), []byte("prefix: pkm\nfields:\n  - {name: energy, type: color}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vault.LoadVault(dir); err == nil || !strings.Contains(err.Error(), `unknown type "color"`) {
+		t.Errorf("LoadVault(unknown field type) = %v, want the type named", err)
+	}
+}
+
+func TestLoadVaultReadsDefaultTime(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mt.yaml"), []byte("prefix: pkm\ndefault_time: \"08:30\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := vault.LoadVault(dir)
+	if err != nil || v.DefaultTime != "08:30" {
+		t.Fatalf("LoadVault() = %+v, %v; want default_time 08:30", v, err)
+	}
+	saved := t.TempDir()
+	if err := v.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := vault.LoadVault(saved); err != nil || again.DefaultTime != "08:30" {
+		t.Errorf("LoadVault(saved) = %+v, %v; want the default time kept", again, err)
+	}
+}
+
+func TestLoadVaultInvalidDefaultTimeFails(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mt.yaml"), []byte("prefix: pkm\ndefault_time: 9h\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vault.LoadVault(dir); err == nil || !strings.Contains(err.Error(), "default_time") {
+		t.Errorf("LoadVault(default_time 9h) = %v, want default_time named", err)
+	}
+}
+
+func TestLoadVaultReadsTimezone(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mt.yaml"), []byte("prefix: pkm\ntimezone: America/Sao_Paulo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := vault.LoadVault(dir)
+	if err != nil || v.Zone.String() != "America/Sao_Paulo" || v.Zone.Location().String() != "America/Sao_Paulo" {
+		t.Fatalf("LoadVault() = %+v, %v; want timezone America/Sao_Paulo", v, err)
+	}
+	saved := t.TempDir()
+	if err := v.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := vault.LoadVault(saved); err != nil || again.Zone.String() != "America/Sao_Paulo" {
+		t.Errorf("LoadVault(saved) = %+v, %v; want the timezone kept", again, err)
+	}
+}
+
+func TestLoadVaultInvalidTimezoneFails(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mt.yaml"), []byte("prefix: pkm\ntimezone: Mars/Olympus\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vault.LoadVault(dir); err == nil || !strings.Contains(err.Error(), "Mars/Olympus") {
+		t.Errorf("LoadVault(timezone Mars/Olympus) = %v, want the timezone named", err)
+	}
+}
+
+func TestLoadVaultReadsWarnBefore(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mt.yaml"), []byte("prefix: pkm\nwarn_before: 3d\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := vault.LoadVault(dir)
+	if err != nil || v.WarnBefore != "3d" {
+		t.Fatalf("LoadVault() = %+v, %v; want warn_before 3d", v, err)
+	}
+	saved := t.TempDir()
+	if err := v.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := vault.LoadVault(saved); err != nil || again.WarnBefore != "3d" {
+		t.Errorf("LoadVault(saved) = %+v, %v; want warn_before kept", again, err)
+	}
+}
+
+func TestLoadVaultInvalidWarnBeforeFails(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mt.yaml"), []byte("prefix: pkm\nwarn_before: soon\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vault.LoadVault(dir); err == nil || !strings.Contains(err.Error(), "warn_before") {
+		t.Errorf("LoadVault(warn_before soon) = %v, want warn_before named", err)
+	}
+}
+
+func TestLoadVaultReadsWIPLimit(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mt.yaml"), []byte("prefix: pkm\nwip_limit: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := vault.LoadVault(dir)
+	if err != nil || v.WIPLimit != 2 {
+		t.Fatalf("LoadVault() = %+v, %v; want wip_limit 2", v, err)
+	}
+	saved := t.TempDir()
+	if err := v.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := vault.LoadVault(saved); err != nil || again.WIPLimit != 2 {
+		t.Errorf("LoadVault(saved) = %+v, %v; want wip_limit kept", again, err)
+	}
+}
+
+func TestLoadVaultNegativeWIPLimitFails(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mt.yaml"), []byte("prefix: pkm\nwip_limit: -1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vault.LoadVault(dir); err == nil || !strings.Contains(err.Error(), "wip_limit") {
+		t.Errorf("LoadVault(wip_limit -1) = %v, want wip_limit named", err)
+	}
+}
+
 func TestLoadVaultUnreadablePathFails(t *testing.T) {
 	// mt.yaml under a path where the parent is a regular file: the
 	// read fails with a non-NotExist error, exercising the wrap path.
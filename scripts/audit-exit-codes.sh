@@ -73,10 +73,16 @@ run status "$ID1" bogus
 run status "$ID1"
 run done
 run done a b
+run start "$ID1"
+run pause "$ID1"
+run pause "$ID1"
+run start
+run pause a b
 
 label "defer"
 run defer "$ID1" +2d
 run defer "$ID1" "26-08-20 08:00"
+run defer "$ID1" next friday 18:00
 run defer "$ID1" banana
 run defer "$ID1"
 
@@ -88,6 +94,18 @@ run undefer "$ID1"
 run undefer "$ID1" extra
 run undefer nope
 
+label "label"
+run label
+run label add "$ID1" errands
+run label add "$ID1" errands
+run label add nope errands
+run label add "$ID1"
+run label add "$ID1" ""
+run label rm "$ID1" errands
+run label rm "$ID1" errands
+run label rm nope errands
+run label rm "$ID1"
+
 label "dep"
 ID2=$("$MT" q "second issue" | tr -d '[:space:]')
 run dep
@@ -103,6 +121,14 @@ run dep rm "$ID1" "$ID2"
 run dep rm "$ID1" nope
 run dep rm "$ID1"
 run dep rm "$ID1" "$ID2" extra
+run dep tree "$ID1"
+run dep tree nope
+run dep tree
+run dep impact
+run dep impact extra
+run graph
+run graph --format mermaid
+run graph --format png
 
 label "prioritize / rank"
 run prioritize
@@ -122,16 +148,121 @@ run list --label foo
 run list extra
 run ready
 run overdue
+run due
+run due --within soon
+run due extra
+run agenda
+run agenda --days 0
+run agenda extra
 run check
 run check --fix
+run check --fix --dry-run
+run check --dry-run
 run check extra
+run check --format yaml
+run check --format json
+run migrate-times --dry-run
+run migrate-times --from Mars/Olympus
+run migrate-times extra
 run pick-next
 run pick-next extra
+run pick-next --peek --explain
+run pick-next --label nope
+run pick-next --where "rank<"
+run pick-next --max-estimate 2
+run pick-next --max-estimate -1
+
+label "handles"
+run list
+run show 1
+run show 999
+run done 0
+
+label "batch"
+run done "$ID1" nope-404
+run done --where 'colour=red'
+run done --where status=open "$ID1"
+run done --where status=nope
+run list --format ids
+run list --format xml
+run undefer "$ID1" "$ID1"
+
+label "undo"
+run journal
+run journal extra
+run undo
+run undo 99
+run undo zero
+run undo 1 2
+
+label "templates"
+run create --template nope "x"
+run create --template ../x "x"
+mkdir -p "$V/templates"
+printf -- '---\nlabels: [bug]\nrank: top\n---\n## Description\n{{title}}\n' >"$V/templates/bug.md"
+run create --template bug "from template"
+printf -- '---\nstatus: done\n---\n' >"$V/templates/bad.md"
+run create --template bad "x"
+run check
+rm "$V/templates/bad.md"
+
+label "create flags"
+run create --deadline tomorrow "x"
+run create --rank middle "x"
+run create --description d --body - "x"
+run create --defer +1d --status in_progress "x"
+run create --status nope "x"
+run create --blocked-by nope-404 "x"
+run create --rank 99 "x"
+run q --rank top --blocked-by "$ID1" --deadline +2d "full capture"
+run create --recur daily "x"
+RID=$("$MT" q --recur 1d --deadline +1d "recurring" | tr -d '[:space:]')
+run done "$RID"
+run done "$RID"
 
 label "comment"
 run comment "$ID1" hello there
 run comment "$ID1"
 run comment
+run comments "$ID1"
+run comments
+run comment --reply-to deadbeef "$ID1" reply
+run comment edit "$ID1" deadbeef
+run comment redact "$ID1"
+run comment --edit
+run comment "$ID1" "## not a comment"
+run comment - - </dev/null
+
+label "hooks"
+cp "$V/mt.yaml" "$BASE/mt.yaml.bak"
+printf 'hooks:\n  pre_done: [[sh, -c, "exit 1"]]\n  done: [[sh, -c, "exit 1"]]\n' >>"$V/mt.yaml"
+run done "$ID1"
+printf 'hooks:\n  pre_closed: [x]\n' >"$V/mt.yaml"
+run done "$ID1"
+cp "$BASE/mt.yaml.bak" "$V/mt.yaml"
+printf 'hooks:\n  done: [[sh, -c, "exit 1"]]\n' >>"$V/mt.yaml"
+run done "$ID1"
+run reopen "$ID1"
+cp "$BASE/mt.yaml.bak" "$V/mt.yaml"
+
+label "reindex"
+run reindex extra
+run reindex
+
+label "serve"
+run serve extra
+run serve --addr nope
+run serve
+
+label "export"
+run export html
+run export html "$V"
+run export html "$BASE/site"
+
+label "mcp"
+run mcp extra
+run mcp </dev/null
+printf '{"jsonrpc":"2.0","id":1,"method":"ping"}\n' | run mcp
 
 label "vault addressing"
 run list @nope
@@ -140,6 +271,11 @@ run list --vault "$V"
 run list @pkm @pkm
 run list --vault "$V" --vault "$V"
 
+label "clock"
+run ready --at "mon 9:00"
+run ready --at someday
+MT_NOW=someday run ready
+
 label "help"
 run --help
 run help